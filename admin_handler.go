@@ -0,0 +1,89 @@
+package confgo
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// ReloadStatus describes the last reload of the configuration.
+type ReloadStatus struct {
+	// Version is the number of successfully loaded configurations, zero if none is loaded yet.
+	Version uint64 `json:"version"`
+	// Reloads is the number of reloads, successful or not.
+	Reloads uint64 `json:"reloads"`
+	// LastReload is the time of the last reload, successful or not.
+	LastReload time.Time `json:"last_reload"`
+	// LastSuccess is the time of the last successful reload.
+	LastSuccess time.Time `json:"last_success"`
+	// StaleSince is the time of the first failed reload after the last successful one,
+	// zero if the last reload has succeeded.
+	StaleSince time.Time `json:"stale_since"`
+	// Err is the error of the last reload, nil if it has succeeded.
+	Err error `json:"-"`
+	// Panics is the number of panics recovered in reloads, subscribers and update callbacks.
+	Panics uint64 `json:"panics"`
+}
+
+// ReloadStatus returns the status of the last configuration reload.
+func (cm *ConfigManager) ReloadStatus() ReloadStatus {
+	if next := cm.handedOff.Load(); next != nil {
+		return next.ReloadStatus()
+	}
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+	return cm.reloadStatus
+}
+
+// adminResponse is the response of the admin handler.
+type adminResponse struct {
+	Version    uint64    `json:"version"`
+	LastReload time.Time `json:"last_reload"`
+	LastError  string    `json:"last_error,omitempty"`
+	Config     Redacted  `json:"config"`
+}
+
+// Handler returns an HTTP handler for debugging and operating the manager. On GET it responds with JSON
+// containing the current configuration with secret fields redacted, its version, the time and the error
+// of the last reload. On POST it reloads the configuration first and responds the same way,
+// with status 500 if the reload fails or 503 if the manager is not running.
+func (cm *ConfigManager) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status := http.StatusOK
+		switch r.Method {
+		case http.MethodGet, http.MethodHead:
+		case http.MethodPost:
+			active := cm.active()
+			if !active.isRunning.Load() {
+				http.Error(w, ErrNotRunning.Error(), http.StatusServiceUnavailable)
+				return
+			}
+			if err := active.reload(); err != nil {
+				status = http.StatusInternalServerError
+			}
+		default:
+			w.Header().Set("Allow", "GET, HEAD, POST")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		cm.writeAdminResponse(w, status)
+	})
+}
+
+// writeAdminResponse writes the response of the admin handler with the status code.
+func (cm *ConfigManager) writeAdminResponse(w http.ResponseWriter, status int) {
+	reloadStatus := cm.ReloadStatus()
+	resp := adminResponse{
+		Version:    reloadStatus.Version,
+		LastReload: reloadStatus.LastReload,
+		LastError:  "",
+		Config:     cm.RedactedConfig(),
+	}
+	if reloadStatus.Err != nil {
+		resp.LastError = reloadStatus.Err.Error()
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(resp)
+}
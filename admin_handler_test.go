@@ -0,0 +1,75 @@
+package confgo
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestConfigManager_Handler(t *testing.T) {
+	t.Parallel()
+
+	type config struct {
+		Host     string `json:"host"`
+		Password string `json:"password" secret:"true"`
+	}
+	source := &fakeSource{data: []byte(`{"host": "a", "password": "p@ss"}`)}
+	cm, err := NewConfigManagerFor[config](func(cm *ConfigManager) error {
+		cm.AddLoader(Loader{Source: source, Formatter: NewJSONFormatter()})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("NewConfigManagerFor() error = %v", err)
+	}
+	handler := cm.Handler()
+
+	serve := func(method string) (int, adminResponse) {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(method, "/config", nil))
+		var resp adminResponse
+		if rec.Code != http.StatusMethodNotAllowed && rec.Code != http.StatusServiceUnavailable {
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatalf("decode response error = %v", err)
+			}
+		}
+		return rec.Code, resp
+	}
+
+	if code, _ := serve(http.MethodPost); code != http.StatusServiceUnavailable {
+		t.Errorf("POST before start status = %d, want %d", code, http.StatusServiceUnavailable)
+	}
+
+	if err := cm.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer cm.MustStop()
+
+	code, resp := serve(http.MethodGet)
+	if code != http.StatusOK || resp.Version != 1 || resp.LastError != "" || resp.LastReload.IsZero() {
+		t.Errorf("GET = %d %+v, want 200 with version 1 and no error", code, resp)
+	}
+	if resp.Config["host"] != "a" || resp.Config["password"] != redactedValue {
+		t.Errorf("GET config = %v, want host a and redacted password", resp.Config)
+	}
+
+	source.data = []byte(`{"host": "b"}`)
+	if code, resp = serve(http.MethodPost); code != http.StatusOK || resp.Version != 2 || resp.Config["host"] != "b" {
+		t.Errorf("POST = %d %+v, want 200 with version 2 and host b", code, resp)
+	}
+
+	source.err = errors.New("read error")
+	code, resp = serve(http.MethodPost)
+	if code != http.StatusInternalServerError || resp.Version != 2 || resp.LastError == "" || resp.Config["host"] != "b" {
+		t.Errorf("POST of failing reload = %d %+v, want 500 with version 2, the error and host b", code, resp)
+	}
+	if status := cm.ReloadStatus(); !errors.Is(status.Err, source.err) {
+		t.Errorf("ReloadStatus().Err = %v, want %v", status.Err, source.err)
+	}
+
+	if code, _ := serve(http.MethodDelete); code != http.StatusMethodNotAllowed {
+		t.Errorf("DELETE status = %d, want %d", code, http.StatusMethodNotAllowed)
+	}
+}
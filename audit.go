@@ -0,0 +1,91 @@
+package confgo
+
+import (
+	"reflect"
+	"slices"
+	"time"
+)
+
+const defaultAuditLogSize = 100
+
+// ChangeMeta describes who changes the configuration at runtime and why.
+type ChangeMeta struct {
+	Actor  string
+	Reason string
+}
+
+// AuditAction is a kind of runtime configuration change.
+type AuditAction string
+
+const (
+	AuditSetOverride    AuditAction = "set_override"
+	AuditRemoveOverride AuditAction = "remove_override"
+	AuditSetConfig      AuditAction = "set_config"
+	AuditApplyPatch     AuditAction = "apply_patch"
+	AuditClearPatch     AuditAction = "clear_patch"
+)
+
+// AuditRecord is a single change of a config field made through the manager API.
+type AuditRecord struct {
+	Time   time.Time   `json:"time"`
+	Action AuditAction `json:"action"`
+	// Path is the dotted path of the changed field. It is empty if the change is not bound to a single field.
+	Path     string `json:"path"`
+	OldValue any    `json:"old_value"`
+	NewValue any    `json:"new_value"`
+	Actor    string `json:"actor"`
+	Reason   string `json:"reason"`
+}
+
+// WithAuditLogSize sets the maximum number of records kept in the audit log. The oldest records are dropped first.
+// The default size is 100, zero or negative size disables auditing.
+func WithAuditLogSize(size int) Option {
+	return func(cm *ConfigManager) error {
+		cm.auditSize = size
+		return nil
+	}
+}
+
+// AuditLog returns the records of runtime changes made through the manager API, oldest first.
+func (cm *ConfigManager) AuditLog() []AuditRecord {
+	cm.auditMu.Lock()
+	defer cm.auditMu.Unlock()
+	return slices.Clone(cm.audit)
+}
+
+// FieldAudit returns the records of runtime changes of the field at the dotted path, oldest first.
+func (cm *ConfigManager) FieldAudit(path string) []AuditRecord {
+	cm.auditMu.Lock()
+	defer cm.auditMu.Unlock()
+	records := make([]AuditRecord, 0)
+	for _, r := range cm.audit {
+		if r.Path == path {
+			records = append(records, r)
+		}
+	}
+	return records
+}
+
+// auditValue returns the value recorded in the audit log for a field: "[REDACTED]" if the field is secret,
+// the Redacted representation if the value may hold nested secret fields and the value itself otherwise.
+func auditValue(value any, secret bool) any {
+	if secret {
+		return redactedValue
+	}
+	if value != nil && hasSecretFields(reflect.TypeOf(value), make(map[reflect.Type]bool)) {
+		return redactValue(reflect.ValueOf(value))
+	}
+	return value
+}
+
+func (cm *ConfigManager) recordAudit(records ...AuditRecord) {
+	cm.auditMu.Lock()
+	defer cm.auditMu.Unlock()
+	if cm.auditSize <= 0 {
+		return
+	}
+	cm.audit = append(cm.audit, records...)
+	if extra := len(cm.audit) - cm.auditSize; extra > 0 {
+		cm.audit = slices.Delete(cm.audit, 0, extra)
+	}
+}
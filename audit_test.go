@@ -0,0 +1,90 @@
+package confgo
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestWithAuditLogSize(t *testing.T) {
+	t.Parallel()
+
+	cm := newTestOverridesManager(t, TestConfig{}, WithAuditLogSize(2))
+	for i := range 3 {
+		if err := cm.SetOverride("int", i+1, ChangeMeta{}); err != nil {
+			t.Fatalf("SetOverride() error = %v", err)
+		}
+	}
+	log := cm.AuditLog()
+	if len(log) != 2 || log[0].NewValue != 2 || log[1].NewValue != 3 {
+		t.Fatalf("AuditLog() = %v, want the last two records", log)
+	}
+
+	disabled := newTestOverridesManager(t, TestConfig{}, WithAuditLogSize(0))
+	if err := disabled.SetOverride("int", 1, ChangeMeta{}); err != nil {
+		t.Fatalf("SetOverride() error = %v", err)
+	}
+	if got := len(disabled.AuditLog()); got != 0 {
+		t.Fatalf("len(AuditLog()) = %d, want 0", got)
+	}
+}
+
+func TestConfigManager_AuditLog_Secrets(t *testing.T) {
+	t.Parallel()
+
+	type db struct {
+		Host     string `json:"host"`
+		Password string `json:"password" secret:"1"`
+	}
+	type config struct {
+		Token string `json:"token" secret:"true"`
+		DB    *db    `json:"db"`
+	}
+	cm, err := NewConfigManagerFor[config](WithLoader(Loader{
+		Source:    &fakeSource{data: []byte(`{"token": "t1", "db": {"host": "a", "password": "p1"}}`)},
+		Formatter: NewJSONFormatter(),
+	}))
+	if err != nil {
+		t.Fatalf("NewConfigManagerFor() error = %v", err)
+	}
+	if err := cm.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer cm.MustStop()
+
+	if err := cm.SetOverride("token", "t2", ChangeMeta{}); err != nil {
+		t.Fatalf("SetOverride() error = %v", err)
+	}
+	if err := cm.RemoveOverride("token", ChangeMeta{}); err != nil {
+		t.Fatalf("RemoveOverride() error = %v", err)
+	}
+	if err := cm.SetOverride("db", db{Host: "b", Password: "p2"}, ChangeMeta{}); err != nil {
+		t.Fatalf("SetOverride() error = %v", err)
+	}
+	if err := cm.SetConfig(&config{Token: "t3", DB: &db{Host: "c", Password: "p3"}}, ChangeMeta{}); err != nil {
+		t.Fatalf("SetConfig() error = %v", err)
+	}
+	if err := cm.ApplyPatch([]byte(`{"token": "t4", "db": {"password": "p4"}}`)); err != nil {
+		t.Fatalf("ApplyPatch() error = %v", err)
+	}
+
+	log := cm.AuditLog()
+	if len(log) == 0 {
+		t.Fatal("AuditLog() is empty")
+	}
+	data, err := json.Marshal(log)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	for _, secret := range []string{"t1", "t2", "t3", "t4", "p1", "p2", "p3", "p4"} {
+		if strings.Contains(string(data), `"`+secret+`"`) {
+			t.Errorf("AuditLog() = %s, want %q redacted", data, secret)
+		}
+	}
+	if !strings.Contains(string(data), `"host":"b"`) {
+		t.Errorf("AuditLog() = %s, want non-secret fields of structs recorded", data)
+	}
+	if got := cm.FieldAudit("token"); len(got) == 0 || got[0].NewValue != redactedValue {
+		t.Errorf("FieldAudit(%q) = %+v, want redacted values", "token", got)
+	}
+}
@@ -0,0 +1,101 @@
+package confgo
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const canonicalIndent = 2
+
+// Format is a name of a configuration data format.
+type Format string
+
+const (
+	FormatJSON Format = "json"
+	FormatYAML Format = "yaml"
+	FormatEnv  Format = "env"
+)
+
+// Canonicalize returns the canonical form of data in the given format, so that semantically equal data
+// produces byte-identical output across processes and restarts, which makes it suitable for hashing and diffing:
+//   - JSON objects keys are sorted, the output is indented with two spaces, numbers are kept as written;
+//   - YAML mapping keys are sorted, the output is indented with two spaces, comments are dropped;
+//   - env lines are trimmed and sorted by key, empty and comment lines are dropped,
+//     the last value of a repeated key wins.
+//
+// The output always ends with a newline.
+func Canonicalize(format Format, data []byte) ([]byte, error) {
+	switch format {
+	case FormatJSON:
+		return canonicalJSON(data)
+	case FormatYAML:
+		return canonicalYAML(data)
+	case FormatEnv:
+		return canonicalEnv(data), nil
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownFormat, format)
+	}
+}
+
+func canonicalJSON(data []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, fmt.Errorf("decode json: %w", err)
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", strings.Repeat(" ", canonicalIndent))
+	// Maps are encoded with sorted keys.
+	if err := enc.Encode(v); err != nil {
+		return nil, fmt.Errorf("encode json: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func canonicalYAML(data []byte) ([]byte, error) {
+	var v any
+	if err := yaml.Unmarshal(data, &v); err != nil {
+		return nil, fmt.Errorf("decode yaml: %w", err)
+	}
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(canonicalIndent)
+	// Maps are encoded with sorted keys.
+	if err := enc.Encode(v); err != nil {
+		return nil, fmt.Errorf("encode yaml: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("encode yaml: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func canonicalEnv(data []byte) []byte {
+	values := make(map[string]string)
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		values[strings.TrimSpace(key)] = value
+	}
+
+	var buf bytes.Buffer
+	for _, key := range slices.Sorted(maps.Keys(values)) {
+		buf.WriteString(key + "=" + values[key] + "\n")
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,87 @@
+package confgo
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCanonicalize(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		format  Format
+		data    string
+		want    string
+		wantErr error
+	}{
+		{
+			name:   "json keys are sorted and indented",
+			format: FormatJSON,
+			data:   `{"b": {"y": 1, "x": [3, 2]},   "a": "<tag>", "c": 10000000000000000001}`,
+			want: `{
+  "a": "<tag>",
+  "b": {
+    "x": [
+      3,
+      2
+    ],
+    "y": 1
+  },
+  "c": 10000000000000000001
+}
+`,
+		},
+		{
+			name:    "invalid json",
+			format:  FormatJSON,
+			data:    `{"a":`,
+			wantErr: nil,
+		},
+		{
+			name:   "yaml keys are sorted and comments are dropped",
+			format: FormatYAML,
+			data:   "b:\n    y: 1 # comment\n    x: [3, 2]\na: str\n",
+			want:   "a: str\nb:\n  x:\n    - 3\n    - 2\n  \"y\": 1\n",
+		},
+		{
+			name:   "env keys are sorted and the last value wins",
+			format: FormatEnv,
+			data:   "# comment\nB=2\n\n  A = 1\nB=3",
+			want:   "A= 1\nB=3\n",
+		},
+		{
+			name:    "unknown format",
+			format:  Format("toml"),
+			data:    "a = 1",
+			wantErr: ErrUnknownFormat,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := Canonicalize(tt.format, []byte(tt.data))
+			if tt.want == "" {
+				if err == nil || (tt.wantErr != nil && !errors.Is(err, tt.wantErr)) {
+					t.Fatalf("Canonicalize() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Canonicalize() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Canonicalize() = %q, want %q", got, tt.want)
+			}
+
+			again, err := Canonicalize(tt.format, got)
+			if err != nil {
+				t.Fatalf("Canonicalize() of canonical data error = %v", err)
+			}
+			if string(again) != string(got) {
+				t.Errorf("Canonicalize() is not idempotent: %q, want %q", again, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package confgo
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// CertificatePaths is a pair of PEM encoded certificate and key file paths.
+type CertificatePaths struct {
+	CertFile string
+	KeyFile  string
+}
+
+// CertificatePathsFunc extracts CertificatePaths from the configuration.
+type CertificatePathsFunc func(cfg any) CertificatePaths
+
+// CertificateReloader keeps a TLS certificate loaded from the cert/key paths referenced in the configuration.
+//
+// The key pair is loaded again when either file is modified or when the paths change in the configuration.
+// If the new key pair cannot be loaded, the previous certificate is kept and onError is called.
+// Use GetCertificate as tls.Config.GetCertificate so rotated certificates are served without restarts.
+type CertificateReloader struct {
+	cm          *ConfigManager
+	paths       CertificatePathsFunc
+	onError     CallbackErrFunc
+	cert        atomic.Pointer[tls.Certificate]
+	mu          sync.Mutex
+	current     CertificatePaths
+	watchers    []Watcher
+	interval    time.Duration
+	unsubscribe func()
+}
+
+// NewCertificateReloader creates a new CertificateReloader for the paths extracted from cm configuration.
+// onError is called if a key pair cannot be reloaded, it may be nil.
+func NewCertificateReloader(cm *ConfigManager, paths CertificatePathsFunc, onError CallbackErrFunc) *CertificateReloader {
+	return &CertificateReloader{
+		cm:          cm,
+		paths:       paths,
+		onError:     onError,
+		cert:        atomic.Pointer[tls.Certificate]{},
+		mu:          sync.Mutex{},
+		current:     CertificatePaths{},
+		watchers:    nil,
+		interval:    pollInterval,
+		unsubscribe: nil,
+	}
+}
+
+// Start loads the key pair referenced in the current configuration and starts watching for its changes.
+// Config manager must be started beforehand.
+func (cr *CertificateReloader) Start() error {
+	cfg := cr.cm.Config()
+	if cfg == nil {
+		return ErrConfigNotLoaded
+	}
+
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+	if cr.unsubscribe != nil {
+		return nil
+	}
+	paths := cr.paths(cfg)
+	if err := cr.load(paths); err != nil {
+		return err
+	}
+	cr.watch(paths)
+	cr.unsubscribe = cr.cm.Subscribe(func(_, newCfg any) {
+		cr.onPathsChange(cr.paths(newCfg))
+	})
+	return nil
+}
+
+// Stop stops watching certificate files and configuration changes.
+// The last loaded certificate is still returned by GetCertificate.
+func (cr *CertificateReloader) Stop() error {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+	if cr.unsubscribe == nil {
+		return nil
+	}
+	cr.unsubscribe()
+	cr.unsubscribe = nil
+	return cr.stopWatchers()
+}
+
+// GetCertificate returns the last successfully loaded certificate. It is meant to be used as tls.Config.GetCertificate.
+func (cr *CertificateReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	cert := cr.cert.Load()
+	if cert == nil {
+		return nil, ErrCertificateNotLoaded
+	}
+	return cert, nil
+}
+
+// GetClientCertificate returns the last successfully loaded certificate.
+// It is meant to be used as tls.Config.GetClientCertificate.
+func (cr *CertificateReloader) GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	return cr.GetCertificate(nil)
+}
+
+// TLSConfig returns a server tls.Config serving the certificates of the reloader.
+func (cr *CertificateReloader) TLSConfig() *tls.Config {
+	return &tls.Config{
+		GetCertificate: cr.GetCertificate,
+		MinVersion:     tls.VersionTLS12,
+	}
+}
+
+func (cr *CertificateReloader) load(paths CertificatePaths) error {
+	cert, err := tls.LoadX509KeyPair(paths.CertFile, paths.KeyFile)
+	if err != nil {
+		return fmt.Errorf("load tls key pair: %w", err)
+	}
+	cr.cert.Store(&cert)
+	cr.current = paths
+	return nil
+}
+
+func (cr *CertificateReloader) reportError(err error) {
+	if cr.onError != nil {
+		cr.onError(err)
+	}
+}
+
+func (cr *CertificateReloader) watch(paths CertificatePaths) {
+	for _, file := range []string{paths.CertFile, paths.KeyFile} {
+		w := NewModTimeWatcher(NewFileSource(file))
+		w.interval = cr.interval
+		w.Watch(cr.onFileChange)
+		cr.watchers = append(cr.watchers, w)
+	}
+}
+
+func (cr *CertificateReloader) stopWatchers() error {
+	errs := make([]error, 0)
+	for _, w := range cr.watchers {
+		if err := w.Stop(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	cr.watchers = nil
+	return errors.Join(errs...)
+}
+
+func (cr *CertificateReloader) onFileChange() {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+	if err := cr.load(cr.current); err != nil {
+		cr.reportError(err)
+	}
+}
+
+func (cr *CertificateReloader) onPathsChange(paths CertificatePaths) {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+	if cr.unsubscribe == nil || paths == cr.current {
+		return
+	}
+	if err := cr.load(paths); err != nil {
+		cr.reportError(err)
+		return
+	}
+	if err := cr.stopWatchers(); err != nil {
+		cr.reportError(err)
+	}
+	cr.watch(paths)
+}
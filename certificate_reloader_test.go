@@ -0,0 +1,159 @@
+package confgo
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type testTLSConfig struct {
+	CertFile string `json:"cert_file"`
+	KeyFile  string `json:"key_file"`
+}
+
+func testCertificatePaths(cfg any) CertificatePaths {
+	tlsCfg, _ := cfg.(*testTLSConfig)
+	return CertificatePaths{CertFile: tlsCfg.CertFile, KeyFile: tlsCfg.KeyFile}
+}
+
+// writeTestKeyPair writes a self-signed certificate with the given common name and its key.
+func writeTestKeyPair(t *testing.T, certFile, keyFile, commonName string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshal key: %v", err)
+	}
+	if err := writeFile(certFile, string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))); err != nil {
+		t.Fatalf("write certificate: %v", err)
+	}
+	if err := writeFile(keyFile, string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}))); err != nil {
+		t.Fatalf("write key: %v", err)
+	}
+}
+
+func certificateCommonName(t *testing.T, cr *CertificateReloader) string {
+	t.Helper()
+
+	cert, err := cr.GetCertificate(&tls.ClientHelloInfo{})
+	if err != nil {
+		t.Fatalf("GetCertificate() error = %v", err)
+	}
+	parsed, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatalf("parse certificate: %v", err)
+	}
+	return parsed.Subject.CommonName
+}
+
+func waitForCommonName(t *testing.T, cr *CertificateReloader, want string) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if certificateCommonName(t, cr) == want {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("certificate common name = %q, want %q", certificateCommonName(t, cr), want)
+}
+
+func TestCertificateReloader(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	writeTestKeyPair(t, certFile, keyFile, "first")
+	configFile := filepath.Join(dir, "config.json")
+	if err := updateJSONFile(configFile, map[string]any{"cert_file": certFile, "key_file": keyFile}); err != nil {
+		t.Fatalf("failed to setup json config: %v", err)
+	}
+
+	watcher := NewTriggerWatcher()
+	cm, err := NewConfigManagerFor[testTLSConfig]()
+	if err != nil {
+		t.Fatalf("NewConfigManagerFor() error = %v", err)
+	}
+	cm.AddLoader(Loader{Source: NewFileSource(configFile), Formatter: NewJSONFormatter(), Watcher: watcher})
+	cm.MustStart()
+	defer cm.MustStop()
+
+	errs := make(chan error, 10)
+	cr := NewCertificateReloader(cm, testCertificatePaths, func(err error) { errs <- err })
+	cr.interval = 10 * time.Millisecond
+	if _, err := cr.GetCertificate(nil); err != ErrCertificateNotLoaded {
+		t.Fatalf("GetCertificate() error = %v, want %v", err, ErrCertificateNotLoaded)
+	}
+	if err := cr.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer func() {
+		if err := cr.Stop(); err != nil {
+			t.Errorf("Stop() error = %v", err)
+		}
+	}()
+	if got := certificateCommonName(t, cr); got != "first" {
+		t.Fatalf("certificate common name = %q, want %q", got, "first")
+	}
+
+	// Rotation in place, after watchers have recorded initial modification times.
+	time.Sleep(50 * time.Millisecond)
+	writeTestKeyPair(t, certFile, keyFile, "second")
+	modTime := time.Now().Add(time.Minute)
+	for _, file := range []string{certFile, keyFile} {
+		if err := os.Chtimes(file, modTime, modTime); err != nil {
+			t.Fatalf("change file times: %v", err)
+		}
+	}
+	waitForCommonName(t, cr, "second")
+
+	// Paths change in the configuration.
+	newCertFile, newKeyFile := filepath.Join(dir, "new.crt"), filepath.Join(dir, "new.key")
+	writeTestKeyPair(t, newCertFile, newKeyFile, "third")
+	if err := updateJSONFile(configFile, map[string]any{"cert_file": newCertFile, "key_file": newKeyFile}); err != nil {
+		t.Fatalf("failed to update json config: %v", err)
+	}
+	watcher.Trigger()
+	if got := certificateCommonName(t, cr); got != "third" {
+		t.Fatalf("certificate common name = %q, want %q", got, "third")
+	}
+
+	// Invalid paths keep the previous certificate.
+	if err := updateJSONFile(configFile, map[string]any{"cert_file": "missing.crt", "key_file": "missing.key"}); err != nil {
+		t.Fatalf("failed to update json config: %v", err)
+	}
+	watcher.Trigger()
+	select {
+	case <-errs:
+		// ok
+	default:
+		t.Fatalf("expected reload error")
+	}
+	if got := certificateCommonName(t, cr); got != "third" {
+		t.Fatalf("certificate common name = %q, want %q", got, "third")
+	}
+}
@@ -1,13 +1,20 @@
 package confgo
 
 import (
+	"context"
+	"crypto/sha256"
 	"errors"
 	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
 	"reflect"
+	"slices"
 	"sync"
 	"sync/atomic"
-
-	"dario.cat/mergo"
+	"time"
 )
 
 // Source represents a configuration source that can provide raw data.
@@ -16,6 +23,13 @@ type Source interface {
 	Read() ([]byte, error)
 }
 
+// SourceContext is implemented by sources which can abort reading, e.g. remote ones.
+// The manager prefers ReadContext to Read, so a read is canceled once the ReadTimeout of its loader passes.
+type SourceContext interface {
+	// ReadContext reads configuration data from the source until ctx is done.
+	ReadContext(ctx context.Context) ([]byte, error)
+}
+
 // Formatter converts raw data into structured configuration objects.
 type Formatter interface {
 	// Unmarshal converts raw data into a structured configuration object.
@@ -43,6 +57,9 @@ type CallbackErrFunc func(err error)
 // ValidateFunc is a function that validates a configuration.
 type ValidateFunc func() error
 
+// ConfigValidateFunc is a function that validates a candidate configuration before it replaces the current one.
+type ConfigValidateFunc func(cfg any) error
+
 // Validator defines an interface for validating configuration objects.
 // If the config struct implements this interface, then on every config reload Validate method is called.
 // Otherwise, no config validation is performed.
@@ -59,25 +76,222 @@ type Merger interface {
 	Merge(other any) error
 }
 
+// Defaulter defines an interface for setting default configuration values.
+// If the config struct implements this interface, then on every config reload SetDefaults method is called
+// on the merge base before config layers are merged into it.
+type Defaulter interface {
+	// SetDefaults sets default values of the configuration.
+	SetDefaults()
+}
+
+// Transformer transforms raw data read by a Source before a Formatter unmarshals it.
+type Transformer interface {
+	// Transform returns the transformed data.
+	Transform(data []byte) ([]byte, error)
+}
+
 // Loader defines a set of required Source, required Formatter and optional Watcher with callbacks.
+// Optional Transformers are applied in order to the data read by the Source before the Formatter unmarshals it.
 type Loader struct {
+	// Name identifies the loader in reload errors, logs and statuses, e.g. "defaults" or "vault".
+	// Names must be unique, the loader is identified by its index if the name is empty.
+	Name            string
 	Source          Source
+	Transformers    []Transformer
 	Formatter       Formatter
 	Watcher         Watcher
 	OnUpdateSuccess CallbackFunc
 	OnUpdateError   CallbackErrFunc
+	// ReadTimeout limits the duration of reading the source, so a hung source cannot block reloads forever.
+	// Zero means no limit.
+	ReadTimeout time.Duration
+	// ErrorPolicy defines whether a failure of the loader fails the reload, LoaderErrorFail by default,
+	// or the layer is skipped and reported by DegradedLayers.
+	ErrorPolicy LoaderErrorPolicy
+	// Retry defines how failed reads of the source are retried during reloads, every read is limited by ReadTimeout.
+	// The zero value does not retry.
+	Retry RetryPolicy
+	// Priority defines the precedence of the loader: loaders are merged in ascending order of priorities,
+	// so values of a loader override values of the loaders with lower priorities.
+	// Loaders with equal priorities are merged in the order they are added. Zero by default.
+	Priority int
+
+	// skipIfMissing makes reload skip the loader if its source does not exist.
+	skipIfMissing bool
+	// id identifies the loader in the manager, since indexes of loaders change when loaders are removed.
+	id uint64
 }
 
 func (l *Loader) validate() error {
-	if l.Source == nil {
+	if f, ok := l.Source.(FuncSource); l.Source == nil || ok && f == nil {
 		return ErrSourceIsNil
 	}
 	if l.Formatter == nil {
 		return ErrFormatterIsNil
 	}
+	for i, t := range l.Transformers {
+		if t == nil {
+			return fmt.Errorf("transformer #%d: %w", i, ErrTransformerIsNil)
+		}
+	}
 	return nil
 }
 
+// read reads the data of the source, giving up once ReadTimeout passes.
+// Sources which do not implement SourceContext cannot be canceled, so on timeout they are left
+// to finish reading in the background.
+func (l *Loader) read(ctx context.Context) ([]byte, error) {
+	if l.ReadTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, l.ReadTimeout)
+		defer cancel()
+	}
+	if s, ok := l.Source.(SourceContext); ok {
+		return s.ReadContext(ctx)
+	}
+	if l.ReadTimeout <= 0 {
+		return l.Source.Read()
+	}
+
+	type result struct {
+		data []byte
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		data, err := l.Source.Read()
+		done <- result{data: data, err: err}
+	}()
+	select {
+	case r := <-done:
+		return r.data, r.err
+	case <-ctx.Done():
+		return nil, fmt.Errorf("read timeout %s: %w", l.ReadTimeout, ctx.Err())
+	}
+}
+
+// transform applies the loader transformers to the data in order.
+func (l *Loader) transform(data []byte) ([]byte, error) {
+	for i, t := range l.Transformers {
+		var err error
+		if data, err = t.Transform(data); err != nil {
+			return nil, fmt.Errorf("transformer #%d: %w", i, err)
+		}
+	}
+	return data, nil
+}
+
+// label returns a human-readable identification of the loader i by its name or index and its source.
+func (l *Loader) label(i int) string {
+	if l.Name != "" {
+		return fmt.Sprintf("loader %q (%s)", l.Name, l.describe())
+	}
+	return fmt.Sprintf("loader #%d (%s)", i, l.describe())
+}
+
+// logAttrs returns the attributes identifying the loader i in logs and spans.
+func (l *Loader) logAttrs(i int) []slog.Attr {
+	attrs := []slog.Attr{slog.Int("loader", i)}
+	if l.Name != "" {
+		attrs = append(attrs, slog.String("name", l.Name))
+	}
+	return append(attrs, slog.String("source", l.describe()))
+}
+
+// slogArgs converts the attributes to the arguments of slog.Logger methods.
+func slogArgs(attrs []slog.Attr) []any {
+	args := make([]any, len(attrs))
+	for i, a := range attrs {
+		args[i] = a
+	}
+	return args
+}
+
+// describe returns a human-readable description of the loader source.
+func (l *Loader) describe() string {
+	switch s := l.Source.(type) {
+	case *FileSource:
+		return fmt.Sprintf("file %q", s.path)
+	case *EnvSource:
+		if s.prefix != "" {
+			return fmt.Sprintf("env %q", s.prefix)
+		}
+		return "env"
+	case *VaultSource:
+		return fmt.Sprintf("vault %q", s.mount+"/"+s.path)
+	case *FSSource:
+		return fmt.Sprintf("fs file %q", s.path)
+	case *DirSource:
+		return fmt.Sprintf("dir %q", s.dir)
+	case *GlobSource:
+		return fmt.Sprintf("glob %q", s.pattern)
+	case *ExecSource:
+		return fmt.Sprintf("command %q", s.name)
+	case *SQLSource:
+		return "sql query"
+	case *ObjectStoreSource:
+		return fmt.Sprintf("object %q", s.bucket+"/"+s.key)
+	case *GRPCSource:
+		return fmt.Sprintf("grpc config %q", s.name)
+	case *WebSocketSource:
+		return fmt.Sprintf("websocket %q", s.url)
+	case *KafkaSource:
+		return "kafka topic"
+	case *MQTTSource:
+		return fmt.Sprintf("mqtt topic %q", s.topic)
+	case *pushSource:
+		return "pushed config"
+	case *FlagSource:
+		return fmt.Sprintf("flags %q", s.flags.Name())
+	case *BytesSource:
+		return "bytes"
+	case *StringSource:
+		return "string"
+	case *ReaderSource:
+		return "reader"
+	case *MapSource:
+		return "map"
+	case *StructSource:
+		return "struct"
+	default:
+		return fmt.Sprintf("source %T", l.Source)
+	}
+}
+
+// sourceKey returns the key identifying the data read by the loader source.
+// Sources without a known identity, e.g. custom ones, report false.
+func (l *Loader) sourceKey() (string, bool) {
+	switch s := l.Source.(type) {
+	case *FileSource:
+		path, err := filepath.Abs(s.path)
+		if err != nil {
+			path = filepath.Clean(s.path)
+		}
+		return "file:" + path, true
+	case *DirSource:
+		path, err := filepath.Abs(s.dir)
+		if err != nil {
+			path = filepath.Clean(s.dir)
+		}
+		return "dir:" + path, true
+	case *GlobSource:
+		pattern, err := filepath.Abs(s.pattern)
+		if err != nil {
+			pattern = filepath.Clean(s.pattern)
+		}
+		return "glob:" + pattern, true
+	case *EnvSource:
+		if s.prefix != "" {
+			return "env:" + s.prefix, true
+		}
+		return "env", true
+	case *VaultSource:
+		return "vault:" + s.addr + "/" + s.namespace + "/" + s.mount + "/" + s.path, true
+	default:
+		return "", false
+	}
+}
+
 // ConfigManager is a main object that manages configurations.
 // It handles loading, merging, validating, and watching configuration sources.
 // The manager supports multiple loaders that can read from different sources
@@ -85,13 +299,63 @@ func (l *Loader) validate() error {
 // It also provides validation capabilities through custom validation functions.
 // Configuration updates can be watched and automatically reloaded when changes occur.
 type ConfigManager struct {
-	constructor     ConstructorFunc
-	loaders         []Loader
-	validators      []ValidateFunc
-	namedValidators map[string]ValidateFunc
-	isRunning       atomic.Bool
-	current         any
-	mu              sync.RWMutex
+	constructor      ConstructorFunc
+	loaders          []Loader
+	loaderSeq        uint64
+	loadersMu        sync.Mutex
+	validators       []ValidateFunc
+	configValidators []ConfigValidateFunc
+	namedValidators  namedValidators
+	isRunning        atomic.Bool
+	// stopOnDone unregisters the stop of the manager started by StartContext once its context is done.
+	stopOnDone       func() bool
+	current          atomic.Pointer[configSnapshot]
+	degraded         []DegradedLayer
+	provenance       map[string]string
+	provenanceMeta   map[string]ChangeMeta
+	reloadStatus     ReloadStatus
+	loaderStatuses   map[uint64]LoaderStatus
+	maxStaleness     time.Duration
+	debounceWindow   time.Duration
+	debouncer        debouncer
+	layerCache       layerCache
+	skipUnchanged    bool
+	copyOnRead       bool
+	explicitValues   bool
+	keyNormalizer    KeyNormalizer
+	mergeOptions     []MergeOption
+	mu               sync.RWMutex
+	reloadMu         sync.Mutex
+	notifyMu         sync.Mutex
+	notifying        bool
+	pendingSwaps     []*configSwap
+	notifiedVersion  uint64
+	devMode          bool
+	populateSections bool
+	defaults         any
+	structValidator  StructValidator
+	secretResolvers  map[string]SecretResolver
+	devOut           io.Writer
+	logger           *slog.Logger
+	tracer           ReloadTracer
+	subscribers      []*subscriber
+	handedOff        atomic.Pointer[ConfigManager]
+	subMu            sync.Mutex
+	chanSubscribers  atomic.Int64
+	chanDelivered    atomic.Uint64
+	chanDropped      atomic.Uint64
+	overrides        map[string]any
+	overrideMeta     map[string]ChangeMeta
+	adminConfig      any
+	adminConfigMeta  ChangeMeta
+	patches          []map[string]any
+	overridesMu      sync.Mutex
+	audit            []AuditRecord
+	auditSize        int
+	auditMu          sync.Mutex
+	facts            facts
+	validationReport validationReportHolder
+	ready            readySignal
 }
 
 // Option is a functional option for configuring ConfigManager.
@@ -102,13 +366,69 @@ type Option func(cm *ConfigManager) error
 // Note that constructor must return pointer to an empty struct.
 func NewConfigManager(constructor ConstructorFunc, opts ...Option) (*ConfigManager, error) {
 	cm := &ConfigManager{
-		constructor:     constructor,
-		loaders:         make([]Loader, 0),
-		validators:      make([]ValidateFunc, 0),
-		namedValidators: make(map[string]ValidateFunc),
-		isRunning:       atomic.Bool{},
-		current:         nil,
-		mu:              sync.RWMutex{},
+		constructor:      constructor,
+		loaders:          make([]Loader, 0),
+		loaderSeq:        0,
+		loadersMu:        sync.Mutex{},
+		validators:       make([]ValidateFunc, 0),
+		configValidators: make([]ConfigValidateFunc, 0),
+		namedValidators:  make(namedValidators, 0),
+		isRunning:        atomic.Bool{},
+		stopOnDone:       nil,
+		current:          atomic.Pointer[configSnapshot]{},
+		degraded:         nil,
+		provenance:       nil,
+		provenanceMeta:   nil,
+		reloadStatus:     ReloadStatus{},
+		loaderStatuses:   nil,
+		maxStaleness:     0,
+		debounceWindow:   0,
+		debouncer:        debouncer{mu: sync.Mutex{}, timer: nil, pending: nil},
+		layerCache:       layerCache{mu: sync.Mutex{}, layers: nil},
+		skipUnchanged:    false,
+		copyOnRead:       false,
+		explicitValues:   false,
+		keyNormalizer:    nil,
+		mergeOptions:     nil,
+		mu:               sync.RWMutex{},
+		reloadMu:         sync.Mutex{},
+		notifyMu:         sync.Mutex{},
+		notifying:        false,
+		pendingSwaps:     nil,
+		notifiedVersion:  0,
+		devMode:          false,
+		populateSections: false,
+		defaults:         nil,
+		structValidator:  nil,
+		secretResolvers:  make(map[string]SecretResolver),
+		devOut:           os.Stderr,
+		logger:           nil,
+		tracer:           nil,
+		subscribers:      make([]*subscriber, 0),
+		handedOff:        atomic.Pointer[ConfigManager]{},
+		subMu:            sync.Mutex{},
+		chanSubscribers:  atomic.Int64{},
+		chanDelivered:    atomic.Uint64{},
+		chanDropped:      atomic.Uint64{},
+		overrides:        make(map[string]any),
+		overrideMeta:     make(map[string]ChangeMeta),
+		adminConfig:      nil,
+		adminConfigMeta:  ChangeMeta{},
+		patches:          nil,
+		overridesMu:      sync.Mutex{},
+		audit:            make([]AuditRecord, 0),
+		auditSize:        defaultAuditLogSize,
+		auditMu:          sync.Mutex{},
+		facts:            facts{},
+		validationReport: validationReportHolder{
+			mu:     sync.Mutex{},
+			report: ValidationReport{},
+		},
+		ready: readySignal{
+			mu:     sync.Mutex{},
+			ch:     nil,
+			closed: false,
+		},
 	}
 
 	for _, opt := range opts {
@@ -118,6 +438,9 @@ func NewConfigManager(constructor ConstructorFunc, opts ...Option) (*ConfigManag
 			}
 		}
 	}
+	if err := cm.checkDuplicateLoaders(); err != nil {
+		return nil, err
+	}
 
 	return cm, nil
 }
@@ -143,14 +466,47 @@ func (cm *ConfigManager) validateConstructor() error {
 	return nil
 }
 
+// checkDuplicateLoaders reports loaders with the same name and loaders which read the same source with the same
+// formatter, e.g. the same file added twice or WithEnv used twice, which would be loaded and watched twice.
+func (cm *ConfigManager) checkDuplicateLoaders() error {
+	seen := make(map[string]int)
+	names := make(map[string]int)
+	for i, l := range cm.loaders {
+		if l.Name != "" {
+			if j, ok := names[l.Name]; ok {
+				return fmt.Errorf("%w: name %q is used by loaders %d and %d", ErrDuplicateLoader, l.Name, j, i)
+			}
+			names[l.Name] = i
+		}
+		key, ok := l.sourceKey()
+		if !ok || l.Formatter == nil {
+			continue
+		}
+		key += fmt.Sprintf(";%T", l.Formatter)
+		if j, ok := seen[key]; ok {
+			return fmt.Errorf("%w: %s is added as loaders %d and %d", ErrDuplicateLoader, l.describe(), j, i)
+		}
+		seen[key] = i
+	}
+	return nil
+}
+
 func (cm *ConfigManager) validatePreRunState() error {
 	if err := cm.validateConstructor(); err != nil {
 		return fmt.Errorf("validate constructor: %w", err)
 	}
+	if cm.defaults != nil {
+		if cfg := cm.constructor(); reflect.TypeOf(cm.defaults) != reflect.TypeOf(cfg) {
+			return fmt.Errorf("defaults: %w: got %T, want %T", ErrConfigTypeMismatch, cm.defaults, cfg)
+		}
+	}
 
-	for name, v := range cm.namedValidators {
-		if v == nil {
-			return fmt.Errorf("validator %q: %w", name, ErrValidatorIsNil)
+	if err := cm.checkDuplicateLoaders(); err != nil {
+		return err
+	}
+	for _, v := range cm.namedValidators {
+		if v.fn == nil {
+			return fmt.Errorf("validator %q: %w", v.name, ErrValidatorIsNil)
 		}
 	}
 	for i, v := range cm.validators {
@@ -158,109 +514,411 @@ func (cm *ConfigManager) validatePreRunState() error {
 			return fmt.Errorf("validator #%d: %w", i, ErrValidatorIsNil)
 		}
 	}
+	for i, v := range cm.configValidators {
+		if v == nil {
+			return fmt.Errorf("config validator #%d: %w", i, ErrValidatorIsNil)
+		}
+	}
 
 	if len(cm.loaders) == 0 {
 		return ErrNoLoadersDefined
 	}
 	for i, l := range cm.loaders {
 		if err := l.validate(); err != nil {
-			return fmt.Errorf("loader #%d: %w", i, err)
+			return fmt.Errorf("%s: %w", l.label(i), err)
 		}
 	}
 
 	return nil
 }
 
+// runWatchers marks the manager running and starts the watchers of its loaders.
+// Loaders added from then on start their watchers themselves, see AddLoader.
 func (cm *ConfigManager) runWatchers() {
-	for _, l := range cm.loaders {
-		if l.Watcher != nil {
-			l.Watcher.Watch(func() {
-				if err := cm.reload(); err != nil {
-					if l.OnUpdateError != nil {
-						l.OnUpdateError(err)
-					}
-					return
-				}
-				if l.OnUpdateSuccess != nil {
-					l.OnUpdateSuccess()
-				}
-			})
-		}
+	cm.loadersMu.Lock()
+	cm.isRunning.Store(true)
+	loaders := cm.snapshotLoadersLocked()
+	cm.loadersMu.Unlock()
+	for _, l := range loaders {
+		cm.watch(l)
 	}
 }
 
+// watch starts the watcher of the loader, if any.
+func (cm *ConfigManager) watch(l Loader) {
+	if l.Watcher == nil {
+		return
+	}
+	id := l.id
+	l.Watcher.Watch(func() {
+		cm.trigger(id)
+	})
+}
+
 func (cm *ConfigManager) merge(dst, src any) error {
-	if m, ok := dst.(Merger); ok {
-		if err := m.Merge(src); err != nil {
-			return err
-		}
-		return nil
+	return Merge(dst, src, cm.mergeOptions...)
+}
+
+// validate runs Validate method of Validator, the struct tag validator, the named, positional and config validators
+// and returns all their errors joined, so a reload error reports every problem of the config at once.
+func (cm *ConfigManager) validate(config any) error {
+	errs := validateConfig(config, cm.structValidator)
+	if err := cm.runNamedValidators(); err != nil {
+		errs = append(errs, err)
 	}
-	// Do we need to let to configure mergo for user?
-	if err := mergo.Merge(dst, src, mergo.WithOverride); err != nil {
-		return err
+	if err := runValidators(cm.validators); err != nil {
+		errs = append(errs, err)
 	}
-	return nil
+	if err := runConfigValidators(config, cm.configValidators); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
 }
 
-func (cm *ConfigManager) validate(config any) error {
-	if v, ok := config.(Validator); ok {
-		if err := v.Validate(); err != nil {
-			return err
-		}
+// unmarshal unmarshals the data read by a loader with its formatter, applying facts if any are configured.
+// A panic of the formatter is returned as an error.
+func (cm *ConfigManager) unmarshal(formatter Formatter, data []byte, v any) (err error) {
+	defer cm.recoverPanic(&err, "unmarshal")
+	if len(cm.facts.providers) > 0 {
+		return cm.unmarshalFacts(formatter, data, v)
 	}
-	for name, v := range cm.namedValidators {
-		if err := v(); err != nil {
-			return fmt.Errorf("named validator %q: %w", name, err)
-		}
+	return formatter.Unmarshal(data, v)
+}
+
+// runNamedValidators runs every named validator in the order of registration,
+// stores their results in the validation report and returns all their errors joined.
+func (cm *ConfigManager) runNamedValidators() error {
+	report := ValidationReport{
+		Time:    time.Now(),
+		Results: make([]ValidatorResult, 0, len(cm.namedValidators)),
 	}
-	for i, v := range cm.validators {
-		if err := v(); err != nil {
-			return fmt.Errorf("validator %d: %w", i, err)
+	errs := make([]error, 0)
+	for _, v := range cm.namedValidators {
+		err := v.fn()
+		report.Results = append(report.Results, ValidatorResult{Name: v.name, Err: err})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("named validator %q: %w", v.name, err))
 		}
 	}
-	return nil
+	cm.validationReport.store(report)
+	return errors.Join(errs...)
 }
 
+// reload loads the configuration re-reading every loader and records the reload status.
 func (cm *ConfigManager) reload() error {
-	// We can probably optimize here by merging only those configs which were updated.
-	merged := cm.constructor()
-	for _, l := range cm.loaders {
-		data, err := l.Source.Read()
-		if err != nil {
-			return fmt.Errorf("read data from modTimer: %w", err)
+	return cm.reloadLayers(context.Background(), nil)
+}
+
+// reloadLayers loads the configuration re-reading only the loaders with the ids in changed and the loaders
+// without watchers, whose changes are noticed by no one else, and reusing the cached layers of the others,
+// or re-reading every loader if changed is nil,
+// and records the reload status. With WithSkipUnchanged, if the data of none of the changed loaders
+// has changed since its last read, the reload is skipped and errConfigUnchanged is returned.
+// Reloads are serialized from reading the layers to swapping the configuration, so a partial reload never merges
+// a cached layer which a concurrent reload is replacing and never swaps over the result of that reload.
+func (cm *ConfigManager) reloadLayers(ctx context.Context, changed []uint64) error {
+	cm.reloadMu.Lock()
+	loaders := cm.snapshotLoaders()
+	ctx, endSpan := cm.startSpan(ctx, SpanReload, slog.Int("loaders", len(loaders)))
+	swap, err := cm.safeLoad(ctx, loaders, changed)
+	cm.reloadMu.Unlock()
+	// Subscribers are notified without the reload lock, so they may change the configuration, e.g. with Set.
+	if swap != nil {
+		cm.deliverSwap(swap)
+	}
+	if errors.Is(err, errConfigUnchanged) {
+		endSpan(nil)
+		cm.log().Debug("confgo: config reload skipped, data unchanged", "loaders", changed)
+		return err
+	}
+	endSpan(err)
+	if err != nil {
+		cm.log().Error("confgo: config reload failed", "error", err)
+	}
+	cm.mu.Lock()
+	cm.reloadStatus.Reloads++
+	cm.reloadStatus.LastReload = time.Now()
+	cm.reloadStatus.Err = err
+	switch {
+	case err == nil:
+		cm.reloadStatus.LastSuccess = cm.reloadStatus.LastReload
+		cm.reloadStatus.StaleSince = time.Time{}
+	case cm.reloadStatus.StaleSince.IsZero():
+		cm.reloadStatus.StaleSince = cm.reloadStatus.LastReload
+	}
+	cm.mu.Unlock()
+	return err
+}
+
+// configSwap is the configuration swapped by a reload along with the one it has replaced.
+type configSwap struct {
+	prev    any
+	next    any
+	version uint64
+}
+
+// safeLoad loads the configuration, returning a panic of the load as an error.
+func (cm *ConfigManager) safeLoad(ctx context.Context, loaders []Loader, changed []uint64) (swap *configSwap, err error) {
+	defer cm.recoverPanic(&err, "reload")
+	return cm.load(ctx, loaders, changed)
+}
+
+// load loads and swaps the configuration. The subscribers are left to be notified by the caller.
+func (cm *ConfigManager) load(ctx context.Context, loaders []Loader, changed []uint64) (*configSwap, error) {
+	start := time.Now()
+	merged, err := cm.mergeBase()
+	if err != nil {
+		return nil, fmt.Errorf("apply defaults: %w", err)
+	}
+	st := &loadState{
+		merged:        merged,
+		degraded:      make([]DegradedLayer, 0),
+		provenance:    make(map[string]string),
+		partial:       changed != nil,
+		skipUnchanged: changed != nil && cm.skipUnchanged,
+		changedLayers: 0,
+	}
+	recordProvenance(st.provenance, merged, ProvenanceDefault)
+	for i, l := range loaders {
+		if st.partial && l.Watcher != nil && !slices.Contains(changed, l.id) {
+			ok, err := cm.mergeCachedLayer(i, l, st)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				continue
+			}
 		}
-		temp := cm.constructor()
-		if err := l.Formatter.Unmarshal(data, temp); err != nil {
-			return fmt.Errorf("unmarshal data into config type: %w", err)
+		if err := cm.loadLayer(ctx, i, l, st); err != nil {
+			if l.ErrorPolicy == LoaderErrorFail {
+				return nil, fmt.Errorf("%s: %w", l.label(i), err)
+			}
+			cm.skipFailedLayer(i, l, st, err)
 		}
-		if err := cm.merge(merged, temp); err != nil {
-			return fmt.Errorf("merge: %w", err)
+	}
+	if st.skipUnchanged && st.changedLayers == 0 {
+		return nil, errConfigUnchanged
+	}
+	provenance, degraded := st.provenance, st.degraded
+	provenanceMeta := make(map[string]ChangeMeta)
+	if err := cm.applyOverrides(merged, provenance, provenanceMeta); err != nil {
+		return nil, fmt.Errorf("apply overrides: %w", err)
+	}
+	if err := cm.resolveSecrets(merged); err != nil {
+		return nil, fmt.Errorf("resolve secrets: %w", err)
+	}
+	if cm.populateSections {
+		if err := populateSections(reflect.ValueOf(merged), "", make(map[reflect.Type]bool)); err != nil {
+			return nil, fmt.Errorf("populate sections: %w", err)
 		}
 	}
-	if err := cm.validate(merged); err != nil {
-		return fmt.Errorf("validate config: %w", err)
+	validateStart := time.Now()
+	_, endSpan := cm.startSpan(ctx, SpanValidate)
+	err = cm.validate(merged)
+	endSpan(err)
+	if err != nil {
+		cm.log().Warn("confgo: config validation failed", "duration", time.Since(validateStart), "error", err)
+		return nil, fmt.Errorf("validate config: %w", err)
 	}
+	cm.log().Debug("confgo: config validated", "duration", time.Since(validateStart))
 
 	cm.mu.Lock()
-	defer cm.mu.Unlock()
-	cm.current = merged
+	prev, _ := cm.loadCurrent()
+	cm.degraded = degraded
+	cm.provenance = provenance
+	cm.provenanceMeta = provenanceMeta
+	cm.reloadStatus.Version++
+	version := cm.reloadStatus.Version
+	cm.current.Store(&configSnapshot{config: merged, version: version})
+	cm.mu.Unlock()
+	cm.ready.signal()
+	cm.log().Info("confgo: config swapped",
+		"version", version, "degraded_layers", len(degraded), "duration", time.Since(start))
+
+	return &configSwap{prev: prev, next: merged, version: version}, nil
+}
+
+// discardLogger is used if no logger is set with WithLogger.
+var discardLogger = slog.New(slog.DiscardHandler)
+
+// log returns the logger set with WithLogger or the one discarding all events.
+func (cm *ConfigManager) log() *slog.Logger {
+	if cm.logger == nil {
+		return discardLogger
+	}
+	return cm.logger
+}
+
+// loadLayer reads the data of the loader and merges it into the loaded config.
+// If the loader is skipped, it is appended to the degraded layers, otherwise the fields set by the layer
+// are recorded in the provenance. With WithSkipUnchanged partial reloads merge the cached layer
+// if the data has not changed.
+func (cm *ConfigManager) loadLayer(ctx context.Context, i int, l Loader, st *loadState) error {
+	attrs := l.logAttrs(i)
+	ctx, endSpan := cm.startSpan(ctx, SpanLoader, attrs...)
+	log := cm.log().With(slogArgs(attrs)...)
+	cached := cm.layerCache.get(l.id)
+	raw, err := cm.readLayer(ctx, log, l)
+	if err != nil {
+		if l.skipIfMissing && errors.Is(err, fs.ErrNotExist) {
+			log.Info("confgo: config loader skipped", "reason", DegradedReasonMissing, "error", err)
+			st.degraded = append(st.degraded, DegradedLayer{
+				Loader: i,
+				Name:   l.Name,
+				Source: l.describe(),
+				Reason: DegradedReasonMissing,
+				Err:    err,
+			})
+			if cached == nil || cached.skipErr == nil {
+				st.changedLayers++
+			}
+			cm.recordLoaderStatus(l, true, err)
+			cm.layerCache.set(l.id, &cachedLayer{parsed: nil, sum: [sha256.Size]byte{}, skipErr: err})
+			endSpan(nil)
+			return nil
+		}
+		cm.recordLoaderStatus(l, false, err)
+		cm.layerCache.set(l.id, nil)
+		endSpan(err)
+		return err
+	}
+
+	sum := sha256.Sum256(raw)
+	if st.skipUnchanged && cached != nil && cached.skipErr == nil && cached.sum == sum {
+		if _, err := cm.mergeCachedLayer(i, l, st); err != nil {
+			log.Warn("confgo: config loader merge failed", "error", err)
+			cm.recordLoaderStatus(l, false, err)
+			endSpan(err)
+			return err
+		}
+		cm.recordLoaderStatus(l, false, nil)
+		endSpan(nil)
+		return nil
+	}
+	st.changedLayers++
+
+	data, err := l.transform(raw)
+	if err != nil {
+		log.Warn("confgo: config loader transform failed", "error", err)
+		cm.recordLoaderStatus(l, false, err)
+		cm.layerCache.set(l.id, nil)
+		endSpan(err)
+		return fmt.Errorf("transform data: %w", err)
+	}
+	mergeStart := time.Now()
+	temp := cm.constructor()
+	data = cm.normalizeKeys(l.Formatter, data, reflect.TypeOf(temp))
+	_, endUnmarshalSpan := cm.startSpan(ctx, SpanUnmarshal)
+	err = cm.unmarshal(l.Formatter, data, temp)
+	var present []string
+	if err == nil {
+		present, err = cm.present(l.Formatter, data, temp)
+	}
+	endUnmarshalSpan(err)
+	if err != nil {
+		log.Warn("confgo: config loader unmarshal failed", "error", err)
+		cm.recordLoaderStatus(l, false, err)
+		cm.layerCache.set(l.id, nil)
+		endSpan(err)
+		return fmt.Errorf("unmarshal data into config type: %w", err)
+	}
+	cm.layerCache.set(l.id, &cachedLayer{
+		parsed:  deepCopy(reflect.ValueOf(temp)).Interface(),
+		present: present,
+		sum:     sum,
+		skipErr: nil,
+	})
+	_, endMergeSpan := cm.startSpan(ctx, SpanMerge)
+	err = cm.mergeLayer(st, temp, present, l.describe())
+	endMergeSpan(err)
+	if err != nil {
+		log.Warn("confgo: config loader merge failed", "error", err)
+		cm.recordLoaderStatus(l, false, err)
+		cm.layerCache.set(l.id, nil)
+		endSpan(err)
+		return fmt.Errorf("merge: %w", err)
+	}
+	log.Debug("confgo: config loader merged", "duration", time.Since(mergeStart))
+	cm.recordLoaderStatus(l, false, nil)
+	endSpan(nil)
 	return nil
 }
 
+// readLayer reads the raw data of the loader.
+func (cm *ConfigManager) readLayer(ctx context.Context, log *slog.Logger, l Loader) ([]byte, error) {
+	_, endSpan := cm.startSpan(ctx, SpanRead)
+	readStart := time.Now()
+	data, err := l.readWithRetry(ctx, log)
+	if err != nil {
+		endSpan(err)
+		if l.skipIfMissing && errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+		log.Warn("confgo: config loader read failed", "duration", time.Since(readStart), "error", err)
+		return nil, fmt.Errorf("read data from modTimer: %w", err)
+	}
+	endSpan(nil)
+	log.Debug("confgo: config loader read", "bytes", len(data), "duration", time.Since(readStart))
+	return data, nil
+}
+
+// mergeBase constructs the config layers are merged into. Defaults are applied in the order of increasing priority:
+// SetDefaults method of Defaulter, default tags of zero fields and non-zero fields of WithDefaults struct.
+func (cm *ConfigManager) mergeBase() (any, error) {
+	base := cm.constructor()
+	if d, ok := base.(Defaulter); ok {
+		d.SetDefaults()
+	}
+	if err := applyDefaultTags(reflect.ValueOf(base), ""); err != nil {
+		return nil, err
+	}
+	if cm.defaults != nil {
+		// The defaults are copied so merging layers never modifies maps and slices shared with the caller.
+		defaults := deepCopy(reflect.ValueOf(cm.defaults)).Interface()
+		if err := Merge(base, defaults, MergeIgnoreMerger); err != nil {
+			return nil, err
+		}
+	}
+	return base, nil
+}
+
 // Start initializes and starts the configuration manager.
 func (cm *ConfigManager) Start() error {
+	return cm.StartContext(context.Background())
+}
+
+// StartContext is like Start, but ties the lifecycle of the manager to ctx: the initial load is aborted
+// if ctx is canceled, and once ctx is done the manager and all watchers are stopped as if by Stop.
+// Errors of such a stop are logged. It suits signal-aware main functions and errgroups:
+//
+//	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+//	defer cancel()
+//	if err := cm.StartContext(ctx); err != nil {
+//		return err
+//	}
+func (cm *ConfigManager) StartContext(ctx context.Context) error {
 	if cm.isRunning.Load() {
 		return nil
 	}
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("start config manager: %w", err)
+	}
 	if err := cm.validatePreRunState(); err != nil {
 		return fmt.Errorf("validate config manager state: %w", err)
 	}
-	if err := cm.reload(); err != nil {
+	if err := cm.reloadLayers(ctx, nil); err != nil {
 		return fmt.Errorf("initial load config: %w", err)
 	}
 	cm.runWatchers()
-	cm.isRunning.Store(true)
+	stop := context.AfterFunc(ctx, func() {
+		if err := cm.Stop(); err != nil {
+			cm.log().Error("confgo: stop config manager on context done", "error", err)
+		}
+	})
+	cm.loadersMu.Lock()
+	cm.stopOnDone = stop
+	cm.loadersMu.Unlock()
+	cm.log().Info("confgo: config manager started", "loaders", len(cm.snapshotLoaders()))
 	return nil
 }
 
@@ -273,21 +931,32 @@ func (cm *ConfigManager) MustStart() {
 
 // Stop halts the configuration manager and stops all watchers.
 func (cm *ConfigManager) Stop() error {
-	if !cm.isRunning.Load() {
+	cm.loadersMu.Lock()
+	if !cm.isRunning.CompareAndSwap(true, false) {
+		cm.loadersMu.Unlock()
 		return nil
 	}
-	defer cm.isRunning.Store(false)
+	loaders := cm.snapshotLoadersLocked()
+	if cm.stopOnDone != nil {
+		cm.stopOnDone()
+		cm.stopOnDone = nil
+	}
+	cm.loadersMu.Unlock()
 	errs := make([]error, 0)
-	for _, l := range cm.loaders {
+	for _, l := range loaders {
 		if l.Watcher != nil {
 			if err := l.Watcher.Stop(); err != nil {
 				errs = append(errs, err)
 			}
 		}
 	}
+	cm.stopDebounce()
 	if len(errs) > 0 {
-		return fmt.Errorf("stop running watchers: %w", errors.Join(errs...))
+		err := fmt.Errorf("stop running watchers: %w", errors.Join(errs...))
+		cm.log().Error("confgo: config manager stopped with errors", "error", err)
+		return err
 	}
+	cm.log().Info("confgo: config manager stopped")
 	return nil
 }
 
@@ -299,13 +968,110 @@ func (cm *ConfigManager) MustStop() {
 }
 
 // AddLoader adds a new loader to the configuration manager.
+//
+// The loader is placed after the loaders with the same or lower Priority, see MergeOrder.
+// In development mode file loaders are also followed by their "*.local.*" override loaders.
+//
+// AddLoader is safe to call while the manager is running: the loader is validated, its watcher is started
+// and the configuration is reloaded reading only the added loaders, as if their watchers have triggered.
+// The result of the reload is reported to OnUpdateSuccess or OnUpdateError callback of the loader.
+// An invalid loader, e.g. without a formatter or with a name already in use, is not added
+// and the error is reported to OnUpdateError callback.
 func (cm *ConfigManager) AddLoader(l Loader) {
-	cm.loaders = append(cm.loaders, l)
+	loaders := []Loader{l}
+	if cm.devMode {
+		loaders = cm.devLoaders(l)
+	}
+	cm.loadersMu.Lock()
+	running := cm.isRunning.Load()
+	if running {
+		if err := cm.checkAddedLoader(l); err != nil {
+			cm.loadersMu.Unlock()
+			cm.log().Error("confgo: config loader not added", "name", l.Name, "source", l.describe(), "error", err)
+			cm.reportUpdate(l, err)
+			return
+		}
+	}
+	ids := make([]uint64, 0, len(loaders))
+	cm.mu.Lock()
+	for i := range loaders {
+		cm.loaderSeq++
+		loaders[i].id = cm.loaderSeq
+		ids = append(ids, loaders[i].id)
+		cm.loaders = insertLoader(cm.loaders, loaders[i])
+	}
+	cm.mu.Unlock()
+	cm.loadersMu.Unlock()
+	if !running {
+		return
+	}
+
+	for _, l := range loaders {
+		cm.watch(l)
+	}
+	cm.reloadTriggered(ids)
+}
+
+// checkAddedLoader reports whether the loader added to the running manager is invalid.
+// It must be called with cm.loadersMu held.
+func (cm *ConfigManager) checkAddedLoader(l Loader) error {
+	if err := l.validate(); err != nil {
+		return err
+	}
+	if l.Name != "" && slices.ContainsFunc(cm.loaders, func(other Loader) bool { return other.Name == l.Name }) {
+		return fmt.Errorf("%w: name %q is already used", ErrDuplicateLoader, l.Name)
+	}
+	return nil
 }
 
-// Config returns the current configuration.
+// configSnapshot is the current configuration along with its version. It is swapped atomically,
+// so reading the configuration takes no locks.
+type configSnapshot struct {
+	config  any
+	version uint64
+}
+
+// loadCurrent returns the current configuration and its version, nil and zero if no configuration is loaded.
+func (cm *ConfigManager) loadCurrent() (any, uint64) {
+	snapshot := cm.current.Load()
+	if snapshot == nil {
+		return nil, 0
+	}
+	return snapshot.config, snapshot.version
+}
+
+// readCurrent is loadCurrent for consumers, it returns a deep copy of the configuration with WithImmutableSnapshots.
+func (cm *ConfigManager) readCurrent() (any, uint64) {
+	cfg, version := cm.loadCurrent()
+	if cm.copyOnRead && cfg != nil {
+		cfg = deepCopy(reflect.ValueOf(cfg)).Interface()
+	}
+	return cfg, version
+}
+
+// Config returns the current configuration. It is a single atomic load, so it is cheap enough
+// to be called on every request. The configuration is shared by all the callers and must not be modified,
+// unless the manager is created with WithImmutableSnapshots.
 func (cm *ConfigManager) Config() any {
-	cm.mu.RLock()
-	defer cm.mu.RUnlock()
-	return cm.current
+	if next := cm.handedOff.Load(); next != nil {
+		return next.Config()
+	}
+	cfg, _ := cm.readCurrent()
+	return cfg
+}
+
+// ConfigWithVersion returns the current configuration along with its version, the generation number
+// incremented on every successful reload, so consumers can cheaply detect whether the configuration
+// has changed since they last looked. The version is zero until the configuration is loaded.
+func (cm *ConfigManager) ConfigWithVersion() (any, uint64) {
+	if next := cm.handedOff.Load(); next != nil {
+		return next.ConfigWithVersion()
+	}
+	return cm.readCurrent()
+}
+
+// Version returns the version of the current configuration as ConfigWithVersion does.
+func (cm *ConfigManager) Version() uint64 {
+	_, version := cm.ConfigWithVersion()
+	return version
 }
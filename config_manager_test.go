@@ -1,10 +1,15 @@
 package confgo
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 )
 
 var _ Source = (*fakeSource)(nil)
@@ -133,17 +138,15 @@ func (c *testConfigAsValidatorAndMerger) Merge(other any) error {
 
 type testConfigManagerFields struct {
 	constructor     ConstructorFunc
-	current         any
 	loaders         []Loader
 	validators      []ValidateFunc
-	namedValidators map[string]ValidateFunc
+	namedValidators namedValidators
 	// mu            sync.RWMutex
 }
 
 func newTestConfigManager(fields testConfigManagerFields) *ConfigManager {
 	return &ConfigManager{
 		constructor:     fields.constructor,
-		current:         fields.current,
 		loaders:         fields.loaders,
 		validators:      fields.validators,
 		namedValidators: fields.namedValidators,
@@ -466,9 +469,9 @@ func TestConfigManager_validate(t *testing.T) {
 		{
 			name: "with custom named validator",
 			fields: testConfigManagerFields{
-				namedValidators: map[string]ValidateFunc{"test": func() error {
+				namedValidators: namedValidators{{name: "test", fn: func() error {
 					return fmt.Errorf("test")
-				}},
+				}}},
 			},
 			args: args{
 				config: &TestConfig{Int: 123},
@@ -611,7 +614,7 @@ func TestConfigManager_validatePreRunState(t *testing.T) {
 			name: "named validator is nil",
 			fields: testConfigManagerFields{
 				constructor:     testConfigConstructor,
-				namedValidators: map[string]ValidateFunc{"test": nil},
+				namedValidators: namedValidators{{name: "test", fn: nil}},
 			},
 			wantErr: true,
 		},
@@ -645,7 +648,7 @@ func TestConfigManager_validatePreRunState(t *testing.T) {
 				constructor:     testConfigConstructor,
 				loaders:         []Loader{{Source: &fakeSource{}, Formatter: &fakeFormatter{}}},
 				validators:      []ValidateFunc{func() error { return nil }},
-				namedValidators: map[string]ValidateFunc{"test": func() error { return nil }},
+				namedValidators: namedValidators{{name: "test", fn: func() error { return nil }}},
 			},
 			wantErr: false,
 		},
@@ -856,6 +859,54 @@ func TestConfigManager_New(t *testing.T) {
 			options: []Option{WithDynamicJSONFile("test_file.json", nil, nil, nil)},
 			wantErr: false,
 		},
+		{
+			name:    "with file",
+			options: []Option{WithFile("test_file.yml"), WithDynamicFile(".env", nil, nil)},
+			wantErr: false,
+		},
+		{
+			name:    "with file of unknown format",
+			options: []Option{WithFile("test_file.toml")},
+			wantErr: true,
+		},
+		{
+			name:    "with the same file detected and explicit",
+			options: []Option{WithFile("test_file.json"), WithJSONFile("test_file.json")},
+			wantErr: true,
+		},
+		{
+			name:    "with env twice",
+			options: []Option{WithEnv, WithEnv},
+			wantErr: true,
+		},
+		{
+			name:    "with the same json file twice",
+			options: []Option{WithJSONFile("test_file.json"), WithDynamicJSONFile("./test_file.json", nil, nil)},
+			wantErr: true,
+		},
+		{
+			name:    "with the same file in different formats",
+			options: []Option{WithJSONFile("test_file.json"), WithYAMLFile("test_file.json")},
+			wantErr: false,
+		},
+		{
+			name:    "with loader",
+			options: []Option{WithLoader(Loader{Source: &fakeSource{}, Formatter: NewJSONFormatter()})},
+			wantErr: false,
+		},
+		{
+			name: "with loaders of the same name",
+			options: []Option{WithLoaders(
+				Loader{Name: "custom", Source: &fakeSource{}, Formatter: NewJSONFormatter()},
+				Loader{Name: "custom", Source: &fakeSource{}, Formatter: NewYAMLFormatter()},
+			)},
+			wantErr: true,
+		},
+		{
+			name:    "with dev mode and local file",
+			options: []Option{WithJSONFile("test_file.json"), WithDevMode, WithJSONFile("test_file.local.json")},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -868,6 +919,32 @@ func TestConfigManager_New(t *testing.T) {
 	}
 }
 
+func Test_formatterForFile(t *testing.T) {
+	t.Parallel()
+
+	for file, want := range map[string]Formatter{
+		"config.json":     &JSONFormatter{},
+		"config.JSONC":    &JSONCFormatter{json: &JSONFormatter{}},
+		"dir/config.yaml": &YAMLFormatter{},
+		"config.yml":      &YAMLFormatter{},
+		".env":            &DotenvFormatter{},
+	} {
+		got, err := formatterForFile(file)
+		if err != nil {
+			t.Errorf("formatterForFile(%q) error = %v", file, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("formatterForFile(%q) = %#v, want %#v", file, got, want)
+		}
+	}
+	for _, file := range []string{"config.toml", "config"} {
+		if _, err := formatterForFile(file); !errors.Is(err, ErrUnknownFormat) {
+			t.Errorf("formatterForFile(%q) error = %v, want %v", file, err, ErrUnknownFormat)
+		}
+	}
+}
+
 //nolint:cyclop
 func TestConfigManager_Start_Static(t *testing.T) {
 	type args struct {
@@ -927,6 +1004,34 @@ func TestConfigManager_Start_Static(t *testing.T) {
 			wantErr: false,
 			want:    &TestConfig{Int: 123},
 		},
+		{
+			name: "with env prefix",
+			args: args{
+				constructor: testConfigConstructor,
+				options:     []Option{WithEnvPrefix("MYAPP_")},
+			},
+			setup: func(t *testing.T) {
+				t.Helper()
+				t.Setenv("INT", "123")
+				t.Setenv("MYAPP_INT", "5")
+			},
+			wantErr: false,
+			want:    &TestConfig{Int: 5},
+		},
+		{
+			name: "with env and env prefix",
+			args: args{
+				constructor: testConfigConstructor,
+				options:     []Option{WithEnv, WithEnvPrefix("MYAPP_")},
+			},
+			setup: func(t *testing.T) {
+				t.Helper()
+				t.Setenv("INT", "123")
+				t.Setenv("OTHERAPP_INT", "7")
+			},
+			wantErr: false,
+			want:    &TestConfig{Int: 123},
+		},
 		{
 			name: "with multiple loaders",
 			args: args{
@@ -1168,3 +1273,287 @@ func TestConfigManager_Start_DynamicUpdate(t *testing.T) {
 		t.Fatalf("OnUpdateError was not called")
 	}
 }
+
+func TestConfigManager_WithLogger(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	source := &fakeSource{data: []byte(`{"int": 1}`)}
+	cm, err := NewConfigManager(testConfigConstructor, WithLogger(logger), func(cm *ConfigManager) error {
+		cm.AddLoader(Loader{Source: source, Formatter: NewJSONFormatter()})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("NewConfigManager() error = %v", err)
+	}
+	if err := cm.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	source.err = errors.New("read error")
+	_ = cm.reload()
+	if err := cm.Stop(); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+
+	log := buf.String()
+	for _, want := range []string{
+		`level=DEBUG msg="confgo: config loader read" loader=0 source="source *confgo.fakeSource" bytes=10`,
+		`level=DEBUG msg="confgo: config loader merged" loader=0`,
+		`level=DEBUG msg="confgo: config validated"`,
+		`level=INFO msg="confgo: config swapped" version=1 degraded_layers=0`,
+		`level=INFO msg="confgo: config manager started" loaders=1`,
+		`level=WARN msg="confgo: config loader read failed" loader=0 source="source *confgo.fakeSource"`,
+		`level=ERROR msg="confgo: config reload failed" error="loader #0 (source *confgo.fakeSource): read data from modTimer: read error"`,
+		`level=INFO msg="confgo: config manager stopped"`,
+	} {
+		if !strings.Contains(log, want) {
+			t.Errorf("log does not contain %q:\n%s", want, log)
+		}
+	}
+}
+
+func TestConfigManager_ConfigWithVersion(t *testing.T) {
+	t.Parallel()
+
+	formatter := &fakeFormatter{data: TestConfig{Int: 1}}
+	cm, err := NewConfigManager(testConfigConstructor, func(cm *ConfigManager) error {
+		cm.AddLoader(Loader{Source: &fakeSource{data: []byte("test")}, Formatter: formatter})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("NewConfigManager() error = %v", err)
+	}
+	if cfg, version := cm.ConfigWithVersion(); cfg != nil || version != 0 {
+		t.Errorf("ConfigWithVersion() before load = %v, %d, want nil, 0", cfg, version)
+	}
+
+	if err := cm.reload(); err != nil {
+		t.Fatalf("reload() error = %v", err)
+	}
+	cfg, version := cm.ConfigWithVersion()
+	if version != 1 || !reflect.DeepEqual(cfg, &TestConfig{Int: 1}) {
+		t.Errorf("ConfigWithVersion() = %v, %d, want {Int: 1}, 1", cfg, version)
+	}
+
+	formatter.err = errors.New("unmarshal error")
+	_ = cm.reload()
+	if got := cm.Version(); got != 1 {
+		t.Errorf("Version() after failed reload = %d, want 1", got)
+	}
+
+	formatter.err = nil
+	if err := cm.reload(); err != nil {
+		t.Fatalf("reload() error = %v", err)
+	}
+	if got := cm.Version(); got != 2 {
+		t.Errorf("Version() = %d, want 2", got)
+	}
+}
+
+func TestConfigManager_WithImmutableSnapshots(t *testing.T) {
+	t.Parallel()
+
+	cm, err := NewConfigManager(testConfigConstructor, WithImmutableSnapshots, func(cm *ConfigManager) error {
+		cm.AddLoader(Loader{
+			Source:    &fakeSource{data: []byte(`{"int": 1, "map": {"a": "1"}, "slice": ["a"], "inner_ptr": {"int": 2}}`)},
+			Formatter: NewJSONFormatter(),
+		})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("NewConfigManager() error = %v", err)
+	}
+	if err := cm.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer cm.MustStop()
+
+	cfg := cm.Config().(*TestConfig)
+	cfg.Int = 10
+	cfg.Map["a"] = "10"
+	cfg.Slice[0] = "b"
+	cfg.InnerPtr.Int = 20
+
+	want := &TestConfig{Int: 1, Map: map[string]string{"a": "1"}, Slice: []string{"a"}, InnerPtr: &testInnerConfig{Int: 2}}
+	if got := cm.Config(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Config() = %+v, want %+v", got, want)
+	}
+	if got, _ := cm.ConfigWithVersion(); got == cm.Config() {
+		t.Errorf("ConfigWithVersion() returned the shared config")
+	}
+}
+
+func TestConfigManager_WithImmutableSnapshots_Interface(t *testing.T) {
+	t.Parallel()
+
+	type config struct {
+		Extra map[string]any `json:"extra"`
+		Any   any            `json:"any"`
+	}
+	cm, err := NewConfigManagerFor[config](WithImmutableSnapshots, WithLoader(Loader{
+		Source:    &fakeSource{data: []byte(`{"extra": {"db": {"host": "a"}}, "any": {"list": [1]}}`)},
+		Formatter: NewJSONFormatter(),
+	}))
+	if err != nil {
+		t.Fatalf("NewConfigManagerFor() error = %v", err)
+	}
+	if err := cm.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer cm.MustStop()
+
+	cfg := cm.Config().(*config)
+	cfg.Extra["db"].(map[string]any)["host"] = "b"
+	cfg.Any.(map[string]any)["list"].([]any)[0] = 2.0
+
+	want := &config{
+		Extra: map[string]any{"db": map[string]any{"host": "a"}},
+		Any:   map[string]any{"list": []any{1.0}},
+	}
+	if got := cm.Config(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Config() = %+v, want %+v", got, want)
+	}
+}
+
+// blockingSource blocks reading until unblock is closed or, when read with a context, until the context is done.
+type blockingSource struct {
+	unblock chan struct{}
+}
+
+func (s *blockingSource) Read() ([]byte, error) {
+	<-s.unblock
+	return []byte("{}"), nil
+}
+
+type blockingContextSource struct {
+	blockingSource
+}
+
+func (s *blockingContextSource) ReadContext(ctx context.Context) ([]byte, error) {
+	select {
+	case <-s.unblock:
+		return []byte("{}"), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func TestLoader_read_Timeout(t *testing.T) {
+	t.Parallel()
+
+	unblock := make(chan struct{})
+	t.Cleanup(func() { close(unblock) })
+	tests := []struct {
+		name   string
+		source Source
+	}{
+		{name: "source", source: &blockingSource{unblock: unblock}},
+		{name: "context source", source: &blockingContextSource{blockingSource{unblock: unblock}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cm := newTestConfigManager(testConfigManagerFields{
+				constructor: testConfigConstructor,
+				loaders: []Loader{{
+					Source:      tt.source,
+					Formatter:   NewJSONFormatter(),
+					ReadTimeout: 10 * time.Millisecond,
+				}},
+			})
+			if err := cm.reload(); !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("reload() error = %v, want %v", err, context.DeadlineExceeded)
+			}
+		})
+	}
+}
+
+func TestLoader_read_NoTimeout(t *testing.T) {
+	t.Parallel()
+
+	source := &blockingContextSource{blockingSource{unblock: make(chan struct{})}}
+	l := Loader{Source: source, Formatter: NewJSONFormatter()}
+	close(source.unblock)
+	data, err := l.read(context.Background())
+	if err != nil || string(data) != "{}" {
+		t.Errorf("read() = %q, %v, want {}", data, err)
+	}
+}
+
+func TestConfigManager_NamedLoaders(t *testing.T) {
+	t.Parallel()
+
+	cm, err := NewConfigManager(testConfigConstructor, func(cm *ConfigManager) error {
+		cm.AddLoader(Loader{Name: "base", Source: &fakeSource{data: []byte(`{}`)}, Formatter: NewJSONFormatter()})
+		cm.AddLoader(Loader{Name: "remote", Source: &fakeSource{err: errors.New("test error")}, Formatter: NewJSONFormatter()})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("NewConfigManager() error = %v", err)
+	}
+	err = cm.Start()
+	if want := `loader "remote" (source *confgo.fakeSource): `; err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("Start() error = %v, want it to contain %q", err, want)
+	}
+	if got := cm.Status().Loaders; len(got) != 2 || got[0].Name != "base" || got[1].Name != "remote" {
+		t.Errorf("Status().Loaders = %+v, want loaders named base and remote", got)
+	}
+
+	_, err = NewConfigManager(testConfigConstructor, func(cm *ConfigManager) error {
+		cm.AddLoader(Loader{Name: "base", Source: &fakeSource{data: []byte(`{}`)}, Formatter: NewJSONFormatter()})
+		cm.AddLoader(Loader{Name: "base", Source: &fakeSource{data: []byte(`{}`)}, Formatter: NewJSONFormatter()})
+		return nil
+	})
+	if !errors.Is(err, ErrDuplicateLoader) {
+		t.Errorf("NewConfigManager() error = %v, want %v", err, ErrDuplicateLoader)
+	}
+}
+
+type stopNotifyWatcher struct {
+	*TriggerWatcher
+	stopped chan struct{}
+}
+
+func (w stopNotifyWatcher) Stop() error {
+	close(w.stopped)
+	return nil
+}
+
+func TestConfigManager_StartContext(t *testing.T) {
+	t.Parallel()
+
+	watcher := stopNotifyWatcher{TriggerWatcher: NewTriggerWatcher(), stopped: make(chan struct{})}
+	cm, err := NewConfigManager(testConfigConstructor, func(cm *ConfigManager) error {
+		cm.AddLoader(Loader{Source: &fakeSource{data: []byte(`{"int": 1}`)}, Formatter: NewJSONFormatter(), Watcher: watcher})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("NewConfigManager() error = %v", err)
+	}
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := cm.StartContext(canceled); !errors.Is(err, context.Canceled) {
+		t.Fatalf("StartContext() error = %v, want %v", err, context.Canceled)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := cm.StartContext(ctx); err != nil {
+		t.Fatalf("StartContext() error = %v", err)
+	}
+	if !cm.Status().Running {
+		t.Fatalf("Status().Running = false, want true")
+	}
+	cancel()
+	select {
+	case <-watcher.stopped:
+	case <-time.After(time.Second):
+		t.Fatalf("watcher is not stopped after the context is canceled")
+	}
+	if cm.Status().Running {
+		t.Errorf("Status().Running = true, want false")
+	}
+}
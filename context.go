@@ -0,0 +1,35 @@
+package confgo
+
+import "context"
+
+// pinnedConfigKey is the context key of the configuration pinned by a manager.
+type pinnedConfigKey struct {
+	cm *ConfigManager
+}
+
+// WithPinned returns a copy of ctx with the current configuration pinned to it.
+// ConfigFrom called with the returned context or its descendants returns the pinned configuration
+// even if it has been reloaded since, so all goroutines of a single operation, e.g. a request fan-out
+// or a batch job, read the same snapshot throughout.
+//
+// If a configuration is already pinned to ctx by the manager, ctx is returned as is.
+func (cm *ConfigManager) WithPinned(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(pinnedConfigKey{cm: cm}).(pinnedConfig); ok {
+		return ctx
+	}
+	return context.WithValue(ctx, pinnedConfigKey{cm: cm}, pinnedConfig{cfg: cm.Config()})
+}
+
+// pinnedConfig wraps the pinned configuration, so that a pinned nil configuration is told from no pinning.
+type pinnedConfig struct {
+	cfg any
+}
+
+// ConfigFrom returns the configuration pinned to ctx by WithPinned or the current configuration
+// if nothing is pinned.
+func (cm *ConfigManager) ConfigFrom(ctx context.Context) any {
+	if pinned, ok := ctx.Value(pinnedConfigKey{cm: cm}).(pinnedConfig); ok {
+		return pinned.cfg
+	}
+	return cm.Config()
+}
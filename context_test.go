@@ -0,0 +1,43 @@
+package confgo
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+func TestConfigManager_WithPinned(t *testing.T) {
+	t.Parallel()
+
+	formatter := &fakeFormatter{data: TestConfig{Int: 1}}
+	cm, watcher := newTestTriggeredManager(t, formatter)
+	other, _ := newTestTriggeredManager(t, &fakeFormatter{data: TestConfig{Int: 100}})
+
+	ctx := cm.WithPinned(context.Background())
+	formatter.data = TestConfig{Int: 2}
+	watcher.Trigger()
+	if same := cm.WithPinned(ctx); same != ctx {
+		t.Errorf("WithPinned() of a pinned context returned a new context")
+	}
+
+	var wg sync.WaitGroup
+	got := make([]int, 4)
+	for i := range got {
+		wg.Go(func() {
+			got[i] = cm.ConfigFrom(ctx).(*TestConfig).Int
+		})
+	}
+	wg.Wait()
+	for i, n := range got {
+		if n != 1 {
+			t.Errorf("goroutine #%d read Int = %d, want the pinned 1", i, n)
+		}
+	}
+
+	if n := cm.ConfigFrom(context.Background()).(*TestConfig).Int; n != 2 {
+		t.Errorf("ConfigFrom() without pinning Int = %d, want 2", n)
+	}
+	if n := other.ConfigFrom(ctx).(*TestConfig).Int; n != 100 {
+		t.Errorf("ConfigFrom() of another manager Int = %d, want 100", n)
+	}
+}
@@ -0,0 +1,156 @@
+package confgo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const dbPingTimeout = 10 * time.Second
+
+// DBSettings is a set of *sql.DB settings which can be changed at runtime.
+type DBSettings struct {
+	// DriverName and DSN are passed to sql.Open. Changing any of them opens a new pool.
+	DriverName      string
+	DSN             string
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+}
+
+func (s DBSettings) sameDataSource(other DBSettings) bool {
+	return s.DriverName == other.DriverName && s.DSN == other.DSN
+}
+
+// DBSettingsFunc extracts DBSettings from the configuration.
+type DBSettingsFunc func(cfg any) DBSettings
+
+// DBPool keeps a *sql.DB in sync with the configuration.
+//
+// Pool limits and timeouts are applied to the current *sql.DB in place. When the driver name or DSN changes,
+// a new pool is opened and pinged first, then swapped in, and only then the old pool is closed, which lets
+// already started queries finish. If the new pool cannot be opened, the old one is kept and onError is called.
+//
+// Since the pool may be replaced at any time, get it via DB for every unit of work instead of storing it.
+type DBPool struct {
+	cm          *ConfigManager
+	settings    DBSettingsFunc
+	onError     CallbackErrFunc
+	db          atomic.Pointer[sql.DB]
+	mu          sync.Mutex
+	current     DBSettings
+	unsubscribe func()
+}
+
+// NewDBPool creates a new DBPool with the settings extracted from cm configuration.
+// onError is called if the pool cannot be reconfigured, it may be nil.
+func NewDBPool(cm *ConfigManager, settings DBSettingsFunc, onError CallbackErrFunc) *DBPool {
+	return &DBPool{
+		cm:          cm,
+		settings:    settings,
+		onError:     onError,
+		db:          atomic.Pointer[sql.DB]{},
+		mu:          sync.Mutex{},
+		current:     DBSettings{},
+		unsubscribe: nil,
+	}
+}
+
+// Start opens the pool with the settings of the current configuration and subscribes to its changes.
+// Config manager must be started beforehand.
+func (p *DBPool) Start() error {
+	cfg := p.cm.Config()
+	if cfg == nil {
+		return ErrConfigNotLoaded
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.unsubscribe != nil {
+		return nil
+	}
+	settings := p.settings(cfg)
+	db, err := openDB(settings)
+	if err != nil {
+		return err
+	}
+	p.db.Store(db)
+	p.current = settings
+	p.unsubscribe = p.cm.Subscribe(func(_, newCfg any) {
+		p.apply(p.settings(newCfg))
+	})
+	return nil
+}
+
+// DB returns the current pool or nil if the pool is not started.
+func (p *DBPool) DB() *sql.DB {
+	return p.db.Load()
+}
+
+// Close stops watching configuration changes and closes the current pool.
+func (p *DBPool) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.unsubscribe == nil {
+		return nil
+	}
+	p.unsubscribe()
+	p.unsubscribe = nil
+	return p.db.Swap(nil).Close()
+}
+
+func (p *DBPool) apply(settings DBSettings) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.unsubscribe == nil || settings == p.current {
+		return
+	}
+
+	if settings.sameDataSource(p.current) {
+		configureDB(p.db.Load(), settings)
+		p.current = settings
+		return
+	}
+
+	db, err := openDB(settings)
+	if err != nil {
+		if p.onError != nil {
+			p.onError(fmt.Errorf("reopen db pool: %w", err))
+		}
+		return
+	}
+	old := p.db.Swap(db)
+	p.current = settings
+	go func() {
+		// Close waits for the queries started on the old pool to finish.
+		if err := old.Close(); err != nil && p.onError != nil {
+			p.onError(fmt.Errorf("close replaced db pool: %w", err))
+		}
+	}()
+}
+
+func openDB(settings DBSettings) (*sql.DB, error) {
+	db, err := sql.Open(settings.DriverName, settings.DSN)
+	if err != nil {
+		return nil, fmt.Errorf("open db: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), dbPingTimeout)
+	defer cancel()
+	if err := db.PingContext(ctx); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("ping db: %w", err)
+	}
+	configureDB(db, settings)
+	return db, nil
+}
+
+func configureDB(db *sql.DB, settings DBSettings) {
+	db.SetMaxOpenConns(settings.MaxOpenConns)
+	db.SetMaxIdleConns(settings.MaxIdleConns)
+	db.SetConnMaxLifetime(settings.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(settings.ConnMaxIdleTime)
+}
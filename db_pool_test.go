@@ -0,0 +1,140 @@
+package confgo
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+const testDBDriverName = "confgo_test_driver"
+
+var _ driver.Driver = (*testDBDriver)(nil)
+
+type testDBDriver struct{}
+
+func (d *testDBDriver) Open(dsn string) (driver.Conn, error) {
+	if dsn == "unreachable" {
+		return nil, errors.New("connection refused")
+	}
+	return &testDBConn{}, nil
+}
+
+var (
+	_ driver.Conn   = (*testDBConn)(nil)
+	_ driver.Pinger = (*testDBConn)(nil)
+)
+
+type testDBConn struct{}
+
+func (c *testDBConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (c *testDBConn) Close() error                        { return nil }
+func (c *testDBConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+func (c *testDBConn) Ping(context.Context) error          { return nil }
+
+func registerTestDBDriver() {
+	if !slices.Contains(sql.Drivers(), testDBDriverName) {
+		sql.Register(testDBDriverName, &testDBDriver{})
+	}
+}
+
+type testDBConfig struct {
+	DSN          string `json:"dsn"`
+	MaxOpenConns int    `json:"max_open_conns"`
+}
+
+func testDBSettings(cfg any) DBSettings {
+	dbCfg, _ := cfg.(*testDBConfig)
+	return DBSettings{
+		DriverName:      testDBDriverName,
+		DSN:             dbCfg.DSN,
+		MaxOpenConns:    dbCfg.MaxOpenConns,
+		ConnMaxLifetime: time.Minute,
+	}
+}
+
+//nolint:cyclop
+func TestDBPool(t *testing.T) {
+	registerTestDBDriver()
+
+	file := filepath.Join(t.TempDir(), "config.json")
+	if err := updateJSONFile(file, map[string]any{"dsn": "first", "max_open_conns": 5}); err != nil {
+		t.Fatalf("failed to setup json config: %v", err)
+	}
+
+	watcher := NewTriggerWatcher()
+	cm, err := NewConfigManagerFor[testDBConfig]()
+	if err != nil {
+		t.Fatalf("NewConfigManagerFor() error = %v", err)
+	}
+	cm.AddLoader(Loader{Source: NewFileSource(file), Formatter: NewJSONFormatter(), Watcher: watcher})
+	cm.MustStart()
+	defer cm.MustStop()
+
+	errs := make(chan error, 10)
+	pool := NewDBPool(cm, testDBSettings, func(err error) { errs <- err })
+	if err := pool.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer func() {
+		if err := pool.Close(); err != nil {
+			t.Errorf("Close() error = %v", err)
+		}
+	}()
+
+	first := pool.DB()
+	if got := first.Stats().MaxOpenConnections; got != 5 {
+		t.Fatalf("MaxOpenConnections = %d, want 5", got)
+	}
+
+	// Limits are applied in place.
+	if err := updateJSONFile(file, map[string]any{"dsn": "first", "max_open_conns": 10}); err != nil {
+		t.Fatalf("failed to update json config: %v", err)
+	}
+	watcher.Trigger()
+	if pool.DB() != first {
+		t.Fatalf("pool was replaced on limits change")
+	}
+	if got := first.Stats().MaxOpenConnections; got != 10 {
+		t.Fatalf("MaxOpenConnections = %d, want 10", got)
+	}
+
+	// DSN change opens a new pool and closes the old one.
+	if err := updateJSONFile(file, map[string]any{"dsn": "second", "max_open_conns": 10}); err != nil {
+		t.Fatalf("failed to update json config: %v", err)
+	}
+	watcher.Trigger()
+	second := pool.DB()
+	if second == first {
+		t.Fatalf("pool was not replaced on dsn change")
+	}
+	if err := second.PingContext(t.Context()); err != nil {
+		t.Fatalf("Ping() on new pool error = %v", err)
+	}
+	deadline := time.Now().Add(time.Second)
+	for first.PingContext(t.Context()) == nil {
+		if time.Now().After(deadline) {
+			t.Fatalf("old pool was not closed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Unreachable DSN keeps the current pool.
+	if err := updateJSONFile(file, map[string]any{"dsn": "unreachable", "max_open_conns": 10}); err != nil {
+		t.Fatalf("failed to update json config: %v", err)
+	}
+	watcher.Trigger()
+	select {
+	case <-errs:
+		// ok
+	default:
+		t.Fatalf("expected reopen error")
+	}
+	if pool.DB() != second {
+		t.Fatalf("pool was replaced by unreachable one")
+	}
+}
@@ -0,0 +1,80 @@
+package confgo
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+)
+
+// debouncer coalesces the watcher triggers received within the debounce window into a single reload.
+type debouncer struct {
+	mu    sync.Mutex
+	timer *time.Timer
+	// pending are the ids of the loaders whose watchers have triggered since the last reload.
+	pending []uint64
+}
+
+// trigger handles the trigger of the watcher of the loader with the id: it reloads the configuration immediately
+// or, if WithDebounce is set, after the debounce window passes without other triggers.
+func (cm *ConfigManager) trigger(id uint64) {
+	if cm.debounceWindow <= 0 {
+		cm.reloadTriggered([]uint64{id})
+		return
+	}
+	d := &cm.debouncer
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !slices.Contains(d.pending, id) {
+		d.pending = append(d.pending, id)
+	}
+	if d.timer == nil {
+		d.timer = time.AfterFunc(cm.debounceWindow, cm.flushDebounced)
+		return
+	}
+	// The timer may have already fired, then the pending triggers are flushed twice and the second flush is a no-op.
+	d.timer.Reset(cm.debounceWindow)
+}
+
+// flushDebounced reloads the configuration for the pending triggers.
+func (cm *ConfigManager) flushDebounced() {
+	d := &cm.debouncer
+	d.mu.Lock()
+	pending := d.pending
+	d.pending = nil
+	d.mu.Unlock()
+	if len(pending) == 0 {
+		return
+	}
+	cm.log().Debug("confgo: debounced watcher triggers", "loaders", pending)
+	cm.reloadTriggered(pending)
+}
+
+// stopDebounce cancels the pending triggers.
+func (cm *ConfigManager) stopDebounce() {
+	d := &cm.debouncer
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.timer != nil {
+		d.timer.Stop()
+		d.timer = nil
+	}
+	d.pending = nil
+}
+
+// reloadTriggered reloads the configuration re-reading only the triggered loaders and the loaders without watchers
+// and calls their update callbacks with the result. No callbacks are called if their data has not changed.
+// Panics of the reload and of the callbacks are recovered, see reportUpdate.
+func (cm *ConfigManager) reloadTriggered(ids []uint64) {
+	err := cm.reloadLayers(context.Background(), ids)
+	if errors.Is(err, errConfigUnchanged) {
+		return
+	}
+	for _, l := range cm.snapshotLoaders() {
+		if !slices.Contains(ids, l.id) {
+			continue
+		}
+		cm.reportUpdate(l, err)
+	}
+}
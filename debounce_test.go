@@ -0,0 +1,85 @@
+package confgo
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestConfigManager_WithDebounce(t *testing.T) {
+	t.Parallel()
+
+	watcher1, watcher2 := NewTriggerWatcher(), NewTriggerWatcher()
+	var success1, success2 atomic.Int64
+	cm, err := NewConfigManager(testConfigConstructor, WithDebounce(50*time.Millisecond), func(cm *ConfigManager) error {
+		cm.AddLoader(Loader{
+			Source:          &fakeSource{data: []byte("test")},
+			Formatter:       &fakeFormatter{data: TestConfig{Int: 1}},
+			Watcher:         watcher1,
+			OnUpdateSuccess: func() { success1.Add(1) },
+		})
+		cm.AddLoader(Loader{
+			Source:          &fakeSource{data: []byte("test")},
+			Formatter:       &fakeFormatter{data: TestConfig{Int: 2}},
+			Watcher:         watcher2,
+			OnUpdateSuccess: func() { success2.Add(1) },
+		})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("NewConfigManager() error = %v", err)
+	}
+	if err := cm.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer cm.MustStop()
+
+	for range 3 {
+		watcher1.Trigger()
+		watcher2.Trigger()
+	}
+	if got := cm.ReloadStatus().Reloads; got != 1 {
+		t.Errorf("Reloads within the debounce window = %d, want 1", got)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for success1.Load() == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if got := cm.ReloadStatus().Reloads; got != 2 {
+		t.Errorf("Reloads = %d, want 2", got)
+	}
+	if success1.Load() != 1 || success2.Load() != 1 {
+		t.Errorf("OnUpdateSuccess calls = %d, %d, want 1, 1", success1.Load(), success2.Load())
+	}
+}
+
+func TestConfigManager_WithDebounce_Stop(t *testing.T) {
+	t.Parallel()
+
+	watcher := NewTriggerWatcher()
+	cm, err := NewConfigManager(testConfigConstructor, WithDebounce(20*time.Millisecond), func(cm *ConfigManager) error {
+		cm.AddLoader(Loader{
+			Source:    &fakeSource{data: []byte("test")},
+			Formatter: &fakeFormatter{data: TestConfig{Int: 1}},
+			Watcher:   watcher,
+		})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("NewConfigManager() error = %v", err)
+	}
+	if err := cm.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	watcher.Trigger()
+	if err := cm.Stop(); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if got := cm.ReloadStatus().Reloads; got != 1 {
+		t.Errorf("Reloads after Stop = %d, want 1", got)
+	}
+}
@@ -0,0 +1,467 @@
+package confgo
+
+import (
+	"fmt"
+	"maps"
+	"net"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/caarlos0/env/v11"
+	"gopkg.in/yaml.v3"
+)
+
+// DecodeHook decodes string config values into values of a specific Go type.
+//
+// Formatters apply decode hooks to string values of fields of the hook type, including the ones nested in
+// pointers, slices and maps, while values of other kinds are decoded by the formatter as usual.
+// JSON and YAML formatters use the default hooks for time.Duration, time.Time, url.URL and net.IP,
+// which may be replaced by decoders registered with RegisterDecoder and by hooks of the same types
+// passed with JSONDecodeHooks, YAMLDecodeHooks or EnvDecodeHooks.
+type DecodeHook struct {
+	typ    reflect.Type
+	decode func(s string) (reflect.Value, error)
+}
+
+// NewDecodeHook creates a new DecodeHook decoding string values into values of type T.
+func NewDecodeHook[T any](decode func(s string) (T, error)) DecodeHook {
+	return DecodeHook{
+		typ: reflect.TypeFor[T](),
+		decode: func(s string) (reflect.Value, error) {
+			v, err := decode(s)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			return reflect.ValueOf(&v).Elem(), nil
+		},
+	}
+}
+
+// DefaultDecodeHooks returns the hooks used by formatters by default:
+// time.Duration as accepted by time.ParseDuration, time.Time as RFC 3339, url.URL and net.IP.
+func DefaultDecodeHooks() []DecodeHook {
+	return []DecodeHook{
+		NewDecodeHook(time.ParseDuration),
+		NewDecodeHook(func(s string) (time.Time, error) {
+			return time.Parse(time.RFC3339Nano, s)
+		}),
+		NewDecodeHook(func(s string) (url.URL, error) {
+			u, err := url.Parse(s)
+			if err != nil {
+				return url.URL{}, err
+			}
+			return *u, nil
+		}),
+		NewDecodeHook(func(s string) (net.IP, error) {
+			ip := net.ParseIP(s)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid IP address %q", s)
+			}
+			return ip, nil
+		}),
+	}
+}
+
+// decodeHooks maps types to the hooks decoding them.
+type decodeHooks map[reflect.Type]DecodeHook
+
+func newDecodeHooks(hooks ...[]DecodeHook) decodeHooks {
+	res := make(decodeHooks)
+	for _, list := range hooks {
+		for _, h := range list {
+			if h.typ != nil && h.decode != nil {
+				res[h.typ] = h
+			}
+		}
+	}
+	return res
+}
+
+var (
+	registeredHooks   = make(decodeHooks)
+	registeredHooksMu sync.RWMutex
+)
+
+// RegisterDecoder registers the decoder of string config values into values of type T, which is used by
+// all formatters, so custom value types do not have to implement unmarshalling for every format.
+// Registered decoders replace the default decode hooks of the same type and are replaced by the hooks
+// passed to a formatter. Registering a decoder for an already registered type replaces it.
+//
+// Decoders are expected to be registered on initialization, before formatters are used.
+func RegisterDecoder[T any](decode func(raw string) (T, error)) {
+	hook := NewDecodeHook(decode)
+	registeredHooksMu.Lock()
+	defer registeredHooksMu.Unlock()
+	registeredHooks[hook.typ] = hook
+}
+
+// withRegistered returns the registered hooks overridden by the hooks.
+func (h decodeHooks) withRegistered() decodeHooks {
+	registeredHooksMu.RLock()
+	res := maps.Clone(registeredHooks)
+	registeredHooksMu.RUnlock()
+	maps.Copy(res, h)
+	return res
+}
+
+// withDefaults returns the default hooks overridden by the registered hooks and then by the hooks.
+func (h decodeHooks) withDefaults() decodeHooks {
+	res := newDecodeHooks(DefaultDecodeHooks())
+	maps.Copy(res, h.withRegistered())
+	return res
+}
+
+// envParsers returns the registered hooks overridden by the hooks as parsers of the env package.
+// The types of the default hooks are parsed by the env package itself.
+func (h decodeHooks) envParsers() map[reflect.Type]env.ParserFunc {
+	hooks := h.withRegistered()
+	res := make(map[reflect.Type]env.ParserFunc, len(hooks))
+	for typ, hook := range hooks {
+		res[typ] = func(s string) (any, error) {
+			v, err := hook.decode(s)
+			if err != nil {
+				return nil, err
+			}
+			return v.Interface(), nil
+		}
+	}
+	return res
+}
+
+// usedBy reports whether values of any hook type may be found in values of typ.
+func (h decodeHooks) usedBy(typ reflect.Type, visited map[reflect.Type]bool) bool {
+	if _, ok := h[typ]; ok {
+		return true
+	}
+	if visited[typ] {
+		return false
+	}
+	visited[typ] = true
+	switch typ.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+		return h.usedBy(typ.Elem(), visited)
+	case reflect.Struct:
+		for i := range typ.NumField() {
+			if typ.Field(i).IsExported() && h.usedBy(typ.Field(i).Type, visited) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hookDoc is a node of a parsed document walked to find the values decoded by hooks.
+type hookDoc interface {
+	// str returns the value of a string node.
+	str() (string, bool)
+	// fields returns the children of an object node by their keys.
+	fields() map[string]hookDoc
+	// items returns the children of an array node.
+	items() []hookDoc
+	// setZero replaces the node with the one which formatters decode into the zero value of typ.
+	setZero(typ reflect.Type)
+}
+
+// hookStep is a step of the path to a value in the decoded config.
+type hookStep struct {
+	field []int
+	index int
+	key   *reflect.Value
+}
+
+type hookedValue struct {
+	path  []hookStep
+	value reflect.Value
+}
+
+// hookKeys returns the keys of the struct fields by which the document objects are matched along with
+// the indexes of the fields, and whether the keys are matched case-insensitively.
+type hookKeys func(typ reflect.Type) (map[string][]int, bool)
+
+// extractHooked decodes the string values of doc which are decoded into the hook types in typ,
+// replacing them with zero values in doc, so that they are set after the formatter decodes the rest.
+func (h decodeHooks) extractHooked(
+	doc hookDoc,
+	typ reflect.Type,
+	keys hookKeys,
+	path []hookStep,
+	res *[]hookedValue,
+) error {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if hook, ok := h[typ]; ok {
+		s, ok := doc.str()
+		if !ok {
+			return nil
+		}
+		value, err := hook.decode(s)
+		if err != nil {
+			return fmt.Errorf("%w: %s: %w", ErrInvalidFieldValue, typ, err)
+		}
+		doc.setZero(typ)
+		*res = append(*res, hookedValue{path: path, value: value})
+		return nil
+	}
+
+	switch typ.Kind() {
+	case reflect.Struct:
+		fieldKeys, fold := keys(typ)
+		for key, child := range doc.fields() {
+			index, ok := fieldKeys[key]
+			if !ok && fold {
+				for k, i := range fieldKeys {
+					if strings.EqualFold(k, key) {
+						index, ok = i, true
+						break
+					}
+				}
+			}
+			if !ok {
+				continue
+			}
+			step := hookStep{field: index, index: 0, key: nil}
+			if err := h.extractHooked(child, typ.FieldByIndex(index).Type, keys, appendStep(path, step), res); err != nil {
+				return fmt.Errorf("%s: %w", key, err)
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i, child := range doc.items() {
+			if typ.Kind() == reflect.Array && i >= typ.Len() {
+				break
+			}
+			step := hookStep{field: nil, index: i, key: nil}
+			if err := h.extractHooked(child, typ.Elem(), keys, appendStep(path, step), res); err != nil {
+				return fmt.Errorf("%d: %w", i, err)
+			}
+		}
+	case reflect.Map:
+		if typ.Key().Kind() != reflect.String {
+			return nil
+		}
+		for key, child := range doc.fields() {
+			k := reflect.ValueOf(key).Convert(typ.Key())
+			step := hookStep{field: nil, index: 0, key: &k}
+			if err := h.extractHooked(child, typ.Elem(), keys, appendStep(path, step), res); err != nil {
+				return fmt.Errorf("%s: %w", key, err)
+			}
+		}
+	}
+	return nil
+}
+
+func appendStep(path []hookStep, step hookStep) []hookStep {
+	return append(path[:len(path):len(path)], step)
+}
+
+// setHooked sets the value at the path in v, allocating nil pointers and maps on the way.
+func setHooked(v reflect.Value, path []hookStep, value reflect.Value) {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+	if len(path) == 0 {
+		v.Set(value)
+		return
+	}
+
+	step := path[0]
+	switch {
+	case step.field != nil:
+		for i, idx := range step.field {
+			if i > 0 {
+				for v.Kind() == reflect.Ptr {
+					if v.IsNil() {
+						v.Set(reflect.New(v.Type().Elem()))
+					}
+					v = v.Elem()
+				}
+			}
+			v = v.Field(idx)
+		}
+		setHooked(v, path[1:], value)
+	case step.key != nil:
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+		// Map elements are not addressable, so the element is copied, modified and put back.
+		elem := reflect.New(v.Type().Elem()).Elem()
+		if cur := v.MapIndex(*step.key); cur.IsValid() {
+			elem.Set(cur)
+		}
+		setHooked(elem, path[1:], value)
+		v.SetMapIndex(*step.key, elem)
+	default:
+		if step.index < v.Len() {
+			setHooked(v.Index(step.index), path[1:], value)
+		}
+	}
+}
+
+// structKeys collects the keys of the fields of the struct type, flattening the fields for which inline is true.
+func structKeys(typ reflect.Type, key func(sf reflect.StructField) (name string, inline, ok bool)) map[string][]int {
+	res := make(map[string][]int)
+	var collect func(typ reflect.Type, prefix []int)
+	collect = func(typ reflect.Type, prefix []int) {
+		for i := range typ.NumField() {
+			sf := typ.Field(i)
+			name, inline, ok := key(sf)
+			if !ok {
+				continue
+			}
+			index := append(prefix[:len(prefix):len(prefix)], i)
+			fieldType := sf.Type
+			for fieldType.Kind() == reflect.Ptr {
+				fieldType = fieldType.Elem()
+			}
+			if inline && fieldType.Kind() == reflect.Struct {
+				collect(fieldType, index)
+				continue
+			}
+			if _, exists := res[name]; !exists {
+				res[name] = index
+			}
+		}
+	}
+	collect(typ, nil)
+	return res
+}
+
+// jsonHookKeys matches the struct fields as the json package does.
+func jsonHookKeys(typ reflect.Type) (map[string][]int, bool) {
+	return structKeys(typ, func(sf reflect.StructField) (string, bool, bool) {
+		name, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+		if name == "-" || !sf.IsExported() && !sf.Anonymous {
+			return "", false, false
+		}
+		if name != "" {
+			return name, false, true
+		}
+		return sf.Name, sf.Anonymous, true
+	}), true
+}
+
+// yamlHookKeys matches the struct fields as the yaml package does.
+func yamlHookKeys(typ reflect.Type) (map[string][]int, bool) {
+	return structKeys(typ, func(sf reflect.StructField) (string, bool, bool) {
+		name, flags, _ := strings.Cut(sf.Tag.Get("yaml"), ",")
+		if name == "-" || !sf.IsExported() {
+			return "", false, false
+		}
+		if name == "" {
+			name = strings.ToLower(sf.Name)
+		}
+		return name, strings.Contains(flags, "inline"), true
+	}), false
+}
+
+// jsonHookDoc is a node of a document decoded by the json package into generic values.
+type jsonHookDoc struct {
+	get func() any
+	set func(v any)
+}
+
+func (d jsonHookDoc) str() (string, bool) {
+	s, ok := d.get().(string)
+	return s, ok
+}
+
+func (d jsonHookDoc) fields() map[string]hookDoc {
+	obj, ok := d.get().(map[string]any)
+	if !ok {
+		return nil
+	}
+	res := make(map[string]hookDoc, len(obj))
+	for key := range obj {
+		res[key] = jsonHookDoc{
+			get: func() any { return obj[key] },
+			set: func(v any) { obj[key] = v },
+		}
+	}
+	return res
+}
+
+func (d jsonHookDoc) items() []hookDoc {
+	arr, ok := d.get().([]any)
+	if !ok {
+		return nil
+	}
+	res := make([]hookDoc, 0, len(arr))
+	for i := range arr {
+		res = append(res, jsonHookDoc{
+			get: func() any { return arr[i] },
+			set: func(v any) { arr[i] = v },
+		})
+	}
+	return res
+}
+
+func (d jsonHookDoc) setZero(reflect.Type) {
+	// The json package leaves values as they are when decoding null into them.
+	d.set(nil)
+}
+
+// yamlHookDoc is a node of a document parsed by the yaml package.
+type yamlHookDoc struct {
+	node *yaml.Node
+	// item is true for items of sequences.
+	item bool
+}
+
+func newYAMLHookDoc(node *yaml.Node, item bool) yamlHookDoc {
+	for node.Kind == yaml.DocumentNode && len(node.Content) == 1 || node.Kind == yaml.AliasNode {
+		if node.Kind == yaml.AliasNode {
+			node = node.Alias
+			continue
+		}
+		node = node.Content[0]
+	}
+	return yamlHookDoc{node: node, item: item}
+}
+
+func (d yamlHookDoc) str() (string, bool) {
+	if d.node.Kind != yaml.ScalarNode || d.node.ShortTag() != "!!str" {
+		return "", false
+	}
+	return d.node.Value, true
+}
+
+func (d yamlHookDoc) fields() map[string]hookDoc {
+	if d.node.Kind != yaml.MappingNode {
+		return nil
+	}
+	res := make(map[string]hookDoc, len(d.node.Content)/pairLen)
+	for i := 0; i+1 < len(d.node.Content); i += pairLen {
+		res[d.node.Content[i].Value] = newYAMLHookDoc(d.node.Content[i+1], false)
+	}
+	return res
+}
+
+func (d yamlHookDoc) items() []hookDoc {
+	if d.node.Kind != yaml.SequenceNode {
+		return nil
+	}
+	res := make([]hookDoc, 0, len(d.node.Content))
+	for _, child := range d.node.Content {
+		res = append(res, newYAMLHookDoc(child, true))
+	}
+	return res
+}
+
+func (d yamlHookDoc) setZero(typ reflect.Type) {
+	// The yaml package skips sequence items decoded from null into structs and other values
+	// which cannot be nil, so items are replaced with the encoded zero values.
+	if d.item {
+		var zero yaml.Node
+		if err := zero.Encode(reflect.Zero(typ).Interface()); err == nil {
+			*d.node = zero
+			return
+		}
+	}
+	*d.node = yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}
+}
@@ -0,0 +1,205 @@
+package confgo
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// applyDefaultTags sets the fields of the struct value v which hold zero values to the values of their
+// default tags, e.g. `default:"8080"`, recursing into nested structs and allocated pointers to structs.
+//
+// Values are parsed with the decode hooks and RegisterDecoder decoders, encoding.TextUnmarshaler or by kind;
+// slices are written as comma-separated items and maps as comma-separated key:value pairs.
+func applyDefaultTags(v reflect.Value, prefix string) error {
+	return applyDefaults(v, prefix, decodeHooks(nil).withDefaults())
+}
+
+func applyDefaults(v reflect.Value, prefix string, hooks decodeHooks) error {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct || isLeafStruct(v.Type()) {
+		return nil
+	}
+	for i := range v.NumField() {
+		sf := v.Type().Field(i)
+		key, ok := fieldKey(sf)
+		if !ok {
+			continue
+		}
+		path := joinPath(prefix, key)
+		field := v.Field(i)
+		if raw, ok := sf.Tag.Lookup("default"); ok && field.IsZero() {
+			value, err := parseDefault(sf.Type, raw, hooks)
+			if err != nil {
+				return fmt.Errorf("default of field %q: %w", path, err)
+			}
+			field.Set(value)
+			continue
+		}
+		if err := applyDefaults(field, path, hooks); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseDefault parses the raw default value into a value of typ.
+func parseDefault(typ reflect.Type, raw string, hooks decodeHooks) (reflect.Value, error) {
+	if hook, ok := hooks[typ]; ok {
+		value, err := hook.decode(raw)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("%w: %w", ErrInvalidFieldValue, err)
+		}
+		return value, nil
+	}
+	if typ.Kind() == reflect.Ptr {
+		elem, err := parseDefault(typ.Elem(), raw, hooks)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		ptr := reflect.New(typ.Elem())
+		ptr.Elem().Set(elem)
+		return ptr, nil
+	}
+	value := reflect.New(typ)
+	if u, ok := value.Interface().(encoding.TextUnmarshaler); ok {
+		if err := u.UnmarshalText([]byte(raw)); err != nil {
+			return reflect.Value{}, fmt.Errorf("%w: %w", ErrInvalidFieldValue, err)
+		}
+		return value.Elem(), nil
+	}
+	value = value.Elem()
+
+	var err error
+	switch typ.Kind() {
+	case reflect.String:
+		value.SetString(raw)
+	case reflect.Bool:
+		var b bool
+		b, err = strconv.ParseBool(raw)
+		value.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var n int64
+		n, err = strconv.ParseInt(raw, 0, typ.Bits())
+		value.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		var n uint64
+		n, err = strconv.ParseUint(raw, 0, typ.Bits())
+		value.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		f, err = strconv.ParseFloat(raw, typ.Bits())
+		value.SetFloat(f)
+	case reflect.Slice:
+		return parseDefaultSlice(typ, raw, hooks)
+	case reflect.Map:
+		return parseDefaultMap(typ, raw, hooks)
+	default:
+		return reflect.Value{}, fmt.Errorf("%w: unsupported type %s", ErrInvalidFieldValue, typ)
+	}
+	if err != nil {
+		return reflect.Value{}, fmt.Errorf("%w: %w", ErrInvalidFieldValue, err)
+	}
+	return value, nil
+}
+
+func parseDefaultSlice(typ reflect.Type, raw string, hooks decodeHooks) (reflect.Value, error) {
+	slice := reflect.MakeSlice(typ, 0, 0)
+	if raw == "" {
+		return slice, nil
+	}
+	for _, item := range strings.Split(raw, ",") {
+		elem, err := parseDefault(typ.Elem(), strings.TrimSpace(item), hooks)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		slice = reflect.Append(slice, elem)
+	}
+	return slice, nil
+}
+
+func parseDefaultMap(typ reflect.Type, raw string, hooks decodeHooks) (reflect.Value, error) {
+	m := reflect.MakeMap(typ)
+	if raw == "" {
+		return m, nil
+	}
+	for _, pair := range strings.Split(raw, ",") {
+		rawKey, rawValue, ok := strings.Cut(pair, ":")
+		if !ok {
+			return reflect.Value{}, fmt.Errorf("%w: map item %q must be formatted as key:value", ErrInvalidFieldValue, pair)
+		}
+		key, err := parseDefault(typ.Key(), strings.TrimSpace(rawKey), hooks)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		value, err := parseDefault(typ.Elem(), strings.TrimSpace(rawValue), hooks)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		m.SetMapIndex(key, value)
+	}
+	return m, nil
+}
+
+// deepCopy returns a copy of v which shares no pointers, slices or maps with it.
+// Unexported fields are copied shallowly.
+func deepCopy(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		ptr := reflect.New(v.Type().Elem())
+		ptr.Elem().Set(deepCopy(v.Elem()))
+		return ptr
+	case reflect.Struct:
+		out := reflect.New(v.Type()).Elem()
+		out.Set(v)
+		for i := range v.NumField() {
+			if v.Type().Field(i).IsExported() {
+				out.Field(i).Set(deepCopy(v.Field(i)))
+			}
+		}
+		return out
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := range v.Len() {
+			out.Index(i).Set(deepCopy(v.Index(i)))
+		}
+		return out
+	case reflect.Array:
+		out := reflect.New(v.Type()).Elem()
+		for i := range v.Len() {
+			out.Index(i).Set(deepCopy(v.Index(i)))
+		}
+		return out
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.MakeMapWithSize(v.Type(), v.Len())
+		for iter := v.MapRange(); iter.Next(); {
+			out.SetMapIndex(iter.Key(), deepCopy(iter.Value()))
+		}
+		return out
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.New(v.Type()).Elem()
+		out.Set(deepCopy(v.Elem()))
+		return out
+	default:
+		return v
+	}
+}
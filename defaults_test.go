@@ -0,0 +1,183 @@
+package confgo
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_applyDefaultTags(t *testing.T) {
+	t.Parallel()
+
+	type inner struct {
+		Level string `json:"level" default:"info"`
+	}
+	type config struct {
+		Str      string            `json:"str" default:"text"`
+		Bool     bool              `json:"bool" default:"true"`
+		Int      int               `json:"int" default:"0x10"`
+		Uint     uint8             `json:"uint" default:"255"`
+		Float    float64           `json:"float" default:"1.5"`
+		Timeout  time.Duration     `json:"timeout" default:"5s"`
+		Size     ByteSize          `json:"size" default:"1KiB"`
+		Hosts    []string          `json:"hosts" default:"a, b"`
+		Ports    map[string]int    `json:"ports" default:"http:80,https:443"`
+		Empty    []int             `json:"empty" default:""`
+		Ptr      *int              `json:"ptr" default:"7"`
+		Set      string            `json:"set" default:"ignored"`
+		Inner    inner             `json:"inner"`
+		Section  *inner            `json:"section"`
+		Nil      *inner            `json:"nil"`
+		Untagged map[string]string `json:"untagged"`
+	}
+
+	seven := 7
+	cfg := &config{Set: "kept", Section: &inner{}}
+	if err := applyDefaultTags(reflect.ValueOf(cfg), ""); err != nil {
+		t.Fatalf("applyDefaultTags() error = %v", err)
+	}
+	want := &config{
+		Str:     "text",
+		Bool:    true,
+		Int:     16,
+		Uint:    255,
+		Float:   1.5,
+		Timeout: 5 * time.Second,
+		Size:    1024,
+		Hosts:   []string{"a", "b"},
+		Ports:   map[string]int{"http": 80, "https": 443},
+		Empty:   []int{},
+		Ptr:     &seven,
+		Set:     "kept",
+		Inner:   inner{Level: "info"},
+		Section: &inner{Level: "info"},
+	}
+	if !reflect.DeepEqual(cfg, want) {
+		t.Errorf("applyDefaultTags() = %+v, want %+v", cfg, want)
+	}
+}
+
+func Test_applyDefaultTags_Invalid(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		cfg  any
+	}{
+		{name: "int", cfg: &struct {
+			Port int `default:"port"`
+		}{}},
+		{name: "overflow", cfg: &struct {
+			Port uint8 `default:"256"`
+		}{}},
+		{name: "duration", cfg: &struct {
+			Timeout time.Duration `default:"5"`
+		}{}},
+		{name: "map", cfg: &struct {
+			Ports map[string]int `default:"http"`
+		}{}},
+		{name: "unsupported", cfg: &struct {
+			Ch chan int `default:"1"`
+		}{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if err := applyDefaultTags(reflect.ValueOf(tt.cfg), ""); !errors.Is(err, ErrInvalidFieldValue) {
+				t.Errorf("applyDefaultTags() error = %v, want %v", err, ErrInvalidFieldValue)
+			}
+		})
+	}
+}
+
+func TestConfigManager_DefaultTags(t *testing.T) {
+	t.Parallel()
+
+	type config struct {
+		Host string `json:"host" default:"localhost"`
+		Port int    `json:"port" default:"8080"`
+	}
+
+	cm, err := NewConfigManagerFor[config](func(cm *ConfigManager) error {
+		cm.AddLoader(Loader{Source: &fakeSource{data: []byte(`{"port": 9090}`)}, Formatter: NewJSONFormatter()})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("NewConfigManagerFor() error = %v", err)
+	}
+	if err := cm.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer cm.MustStop()
+
+	want := &config{Host: "localhost", Port: 9090}
+	if got := cm.Config(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Config() = %+v, want %+v", got, want)
+	}
+}
+
+type testDefaulterConfig struct {
+	Host  string            `json:"host"`
+	Port  int               `json:"port" default:"8080"`
+	Level string            `json:"level" default:"info"`
+	Tags  map[string]string `json:"tags"`
+}
+
+func (c *testDefaulterConfig) SetDefaults() {
+	c.Host = "localhost"
+	c.Level = "debug"
+}
+
+func TestConfigManager_WithDefaults(t *testing.T) {
+	t.Parallel()
+
+	defaults := testDefaulterConfig{Port: 9090, Tags: map[string]string{"env": "dev"}}
+	cm, err := NewConfigManagerFor[testDefaulterConfig](WithDefaults(defaults), func(cm *ConfigManager) error {
+		cm.AddLoader(Loader{
+			Source:    &fakeSource{data: []byte(`{"host": "example.com", "tags": {"region": "eu"}}`)},
+			Formatter: NewJSONFormatter(),
+		})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("NewConfigManagerFor() error = %v", err)
+	}
+	if err := cm.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer cm.MustStop()
+
+	want := &testDefaulterConfig{
+		Host:  "example.com",
+		Port:  9090,
+		Level: "debug",
+		Tags:  map[string]string{"env": "dev", "region": "eu"},
+	}
+	if got := cm.Config(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Config() = %+v, want %+v", got, want)
+	}
+	if want := map[string]string{"env": "dev"}; !reflect.DeepEqual(defaults.Tags, want) {
+		t.Errorf("defaults.Tags = %v, want %v", defaults.Tags, want)
+	}
+}
+
+func TestConfigManager_WithDefaults_TypeMismatch(t *testing.T) {
+	t.Parallel()
+
+	if _, err := NewConfigManagerFor[testDefaulterConfig](WithDefaults(42)); !errors.Is(err, ErrConfigTypeMismatch) {
+		t.Errorf("NewConfigManagerFor() error = %v, want %v", err, ErrConfigTypeMismatch)
+	}
+
+	cm, err := NewConfigManagerFor[testDefaulterConfig](WithDefaults(&testInnerConfig{}), func(cm *ConfigManager) error {
+		cm.AddLoader(Loader{Source: &fakeSource{data: []byte(`{}`)}, Formatter: NewJSONFormatter()})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("NewConfigManagerFor() error = %v", err)
+	}
+	if err := cm.Start(); !errors.Is(err, ErrConfigTypeMismatch) {
+		t.Errorf("Start() error = %v, want %v", err, ErrConfigTypeMismatch)
+	}
+}
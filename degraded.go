@@ -0,0 +1,68 @@
+package confgo
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+)
+
+// Reasons of skipping a loader reported in DegradedLayer.
+const (
+	DegradedReasonMissing = "missing"
+	// DegradedReasonFailed is reported for the failed loaders with LoaderErrorWarn or LoaderErrorIgnore policy.
+	DegradedReasonFailed = "failed"
+)
+
+// LoaderErrorPolicy defines what a reload does when a loader fails to read, transform, unmarshal or merge its layer.
+type LoaderErrorPolicy int
+
+const (
+	// LoaderErrorFail fails the reload, so the current configuration is kept.
+	LoaderErrorFail LoaderErrorPolicy = iota
+	// LoaderErrorWarn skips the layer logging the error with Warn level and merges the other layers.
+	LoaderErrorWarn
+	// LoaderErrorIgnore skips the layer logging the error with Debug level and merges the other layers.
+	LoaderErrorIgnore
+)
+
+// DegradedLayer describes a loader which was skipped by the reload that produced the current configuration.
+type DegradedLayer struct {
+	// Loader is the index of the skipped loader.
+	Loader int `json:"loader"`
+	// Name is the name of the skipped loader, if any.
+	Name   string `json:"name,omitempty"`
+	Source string `json:"source"`
+	Reason string `json:"reason"`
+	// Err is the error which caused the loader to be skipped.
+	Err error `json:"-"`
+}
+
+// DegradedLayers returns the loaders skipped by the reload that produced the current configuration,
+// in the order of loaders. It is empty if every loader has been applied.
+//
+// Applications may use it to decide whether to operate, warn or refuse traffic depending on
+// which configuration sources have actually been applied.
+func (cm *ConfigManager) DegradedLayers() []DegradedLayer {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+	return slices.Clone(cm.degraded)
+}
+
+// skipFailedLayer records the layer of the loader i, which has failed with err, as a degraded one
+// according to the error policy of the loader.
+func (cm *ConfigManager) skipFailedLayer(i int, l Loader, st *loadState, err error) {
+	level := slog.LevelWarn
+	if l.ErrorPolicy == LoaderErrorIgnore {
+		level = slog.LevelDebug
+	}
+	args := append(slogArgs(l.logAttrs(i)), "reason", DegradedReasonFailed, "error", err)
+	cm.log().Log(context.Background(), level, "confgo: config loader skipped", args...)
+	st.degraded = append(st.degraded, DegradedLayer{
+		Loader: i,
+		Name:   l.Name,
+		Source: l.describe(),
+		Reason: DegradedReasonFailed,
+		Err:    err,
+	})
+	st.changedLayers++
+}
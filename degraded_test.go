@@ -0,0 +1,95 @@
+package confgo
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestConfigManager_DegradedLayers(t *testing.T) {
+	t.Parallel()
+
+	localPath := filepath.Join(t.TempDir(), "config.local.json")
+	cm := newTestConfigManager(testConfigManagerFields{
+		constructor: testConfigConstructor,
+		loaders: []Loader{
+			{Source: &fakeSource{data: []byte("test")}, Formatter: &fakeFormatter{data: TestConfig{Int: 1}}},
+			{Source: NewFileSource(localPath), Formatter: NewJSONFormatter(), skipIfMissing: true},
+		},
+	})
+
+	if got := cm.DegradedLayers(); len(got) != 0 {
+		t.Errorf("DegradedLayers() before load = %v, want empty", got)
+	}
+	if err := cm.reload(); err != nil {
+		t.Fatalf("reload() error = %v", err)
+	}
+	got := cm.DegradedLayers()
+	if len(got) != 1 {
+		t.Fatalf("DegradedLayers() = %v, want 1 layer", got)
+	}
+	if got[0].Loader != 1 || got[0].Reason != DegradedReasonMissing || !errors.Is(got[0].Err, fs.ErrNotExist) {
+		t.Errorf("DegradedLayers()[0] = %+v, want missing loader 1", got[0])
+	}
+
+	if err := writeFile(localPath, `{"int": 2}`); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	if err := cm.reload(); err != nil {
+		t.Fatalf("reload() error = %v", err)
+	}
+	if got := cm.DegradedLayers(); len(got) != 0 {
+		t.Errorf("DegradedLayers() = %v, want empty", got)
+	}
+}
+
+func TestConfigManager_LoaderErrorPolicy(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		policy  LoaderErrorPolicy
+		wantErr bool
+	}{
+		{name: "fail", policy: LoaderErrorFail, wantErr: true},
+		{name: "warn", policy: LoaderErrorWarn, wantErr: false},
+		{name: "ignore", policy: LoaderErrorIgnore, wantErr: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			testErr := errors.New("test error")
+			cm := newTestConfigManager(testConfigManagerFields{
+				constructor: testConfigConstructor,
+				loaders: []Loader{
+					{Source: &fakeSource{data: []byte("test")}, Formatter: &fakeFormatter{data: TestConfig{Int: 1}}},
+					{Source: &fakeSource{err: testErr}, Formatter: NewJSONFormatter(), ErrorPolicy: tt.policy},
+					{
+						Source:    &fakeSource{data: []byte("test")},
+						Formatter: &fakeFormatter{data: TestConfig{Slice: []string{"a"}}},
+					},
+				},
+			})
+			err := cm.reload()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("reload() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			want := &TestConfig{Int: 1, Slice: []string{"a"}}
+			if got := cm.Config(); !reflect.DeepEqual(got, want) {
+				t.Errorf("Config() = %+v, want %+v", got, want)
+			}
+			got := cm.DegradedLayers()
+			if len(got) != 1 || got[0].Loader != 1 || got[0].Reason != DegradedReasonFailed ||
+				!errors.Is(got[0].Err, testErr) {
+				t.Errorf("DegradedLayers() = %+v, want failed loader 1", got)
+			}
+		})
+	}
+}
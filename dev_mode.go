@@ -0,0 +1,91 @@
+package confgo
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+)
+
+const (
+	// devPollInterval is the poll interval of the file watchers in development mode, see WithDevMode
+	// for why files are polled instead of watched for filesystem events.
+	devPollInterval = 250 * time.Millisecond
+	localFileSuffix = ".local"
+)
+
+// localFilePath returns the path of the local override file for the given path,
+// e.g. "config.json" -> "config.local.json".
+func localFilePath(path string) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + localFileSuffix + ext
+}
+
+// devLoaders tunes the loader for development mode and returns it along with
+// the loaders that must be added right after it.
+func (cm *ConfigManager) devLoaders(l Loader) []Loader {
+	fileSource, ok := l.Source.(*FileSource)
+	if !ok {
+		return []Loader{l}
+	}
+
+	if l.Watcher == nil {
+		l.Watcher = NewModTimeWatcher(fileSource, WatchSizeAndInode)
+	}
+	switch w := l.Watcher.(type) {
+	case *ModTimeWatcher:
+		w.interval = devPollInterval
+	case *SymlinkWatcher:
+		w.interval = devPollInterval
+	}
+
+	localSource := NewFileSource(localFilePath(fileSource.path))
+	localWatcher := NewModTimeWatcher(localSource, WatchSizeAndInode)
+	localWatcher.interval = devPollInterval
+	local := Loader{
+		Name:            "",
+		Source:          localSource,
+		Transformers:    l.Transformers,
+		Formatter:       l.Formatter,
+		Watcher:         localWatcher,
+		OnUpdateSuccess: l.OnUpdateSuccess,
+		OnUpdateError:   l.OnUpdateError,
+		Priority:        l.Priority,
+		skipIfMissing:   true,
+	}
+	if l.Name != "" {
+		local.Name = l.Name + ".local"
+	}
+
+	return []Loader{l, local}
+}
+
+// logDiff writes changed fields between old and new configs into the development output.
+func (cm *ConfigManager) logDiff(oldCfg, newCfg any) {
+	if oldCfg == nil {
+		_, _ = fmt.Fprintf(cm.devOut, "confgo: config loaded\n")
+		return
+	}
+	changes := diffConfigs(oldCfg, newCfg)
+	if len(changes) == 0 {
+		_, _ = fmt.Fprintf(cm.devOut, "confgo: config reloaded, no changes\n")
+		return
+	}
+	_, _ = fmt.Fprintf(cm.devOut, "confgo: config reloaded, %d field(s) changed:\n", len(changes))
+	for _, c := range changes {
+		if c.secret {
+			_, _ = fmt.Fprintf(cm.devOut, "  %s: %s\n", c.path, redactedValue)
+			continue
+		}
+		_, _ = fmt.Fprintf(cm.devOut, "  %s: %s -> %s\n", c.path, formatDevValue(c.oldValue), formatDevValue(c.newValue))
+	}
+}
+
+func formatDevValue(v any) string {
+	val := reflect.ValueOf(v)
+	if !val.IsValid() || (val.Kind() == reflect.Ptr && val.IsNil()) {
+		return "<nil>"
+	}
+	return fmt.Sprintf("%v", reflect.Indirect(val).Interface())
+}
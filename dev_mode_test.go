@@ -0,0 +1,119 @@
+package confgo
+
+import (
+	"bytes"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_localFilePath(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		path string
+		want string
+	}{
+		{name: "json", path: "config.json", want: "config.local.json"},
+		{name: "nested dir", path: "/etc/app/config.yaml", want: "/etc/app/config.local.yaml"},
+		{name: "no extension", path: "config", want: "config.local"},
+		{name: "multiple dots", path: "app.prod.yml", want: "app.prod.local.yml"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := localFilePath(tt.path); got != tt.want {
+				t.Errorf("localFilePath() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithDevMode_AddsLocalLoaders(t *testing.T) {
+	t.Parallel()
+
+	cm, err := NewConfigManagerFor[TestConfig](
+		WithJSONFile("before.json"),
+		WithDevMode,
+		WithEnv,
+		WithDynamicYAMLFile("after.yaml", nil, nil),
+		WithDevMode,
+	)
+	if err != nil {
+		t.Fatalf("NewConfigManagerFor() error = %v", err)
+	}
+
+	wantPaths := []string{"before.json", "before.local.json", "", "after.yaml", "after.local.yaml"}
+	if len(cm.loaders) != len(wantPaths) {
+		t.Fatalf("got %d loaders, want %d", len(cm.loaders), len(wantPaths))
+	}
+	for i, l := range cm.loaders {
+		fileSource, ok := l.Source.(*FileSource)
+		if !ok {
+			if wantPaths[i] != "" {
+				t.Fatalf("loader #%d: got %T, want file source", i, l.Source)
+			}
+			continue
+		}
+		if fileSource.path != wantPaths[i] {
+			t.Errorf("loader #%d: path = %q, want %q", i, fileSource.path, wantPaths[i])
+		}
+		mtw, ok := l.Watcher.(*ModTimeWatcher)
+		if !ok {
+			t.Fatalf("loader #%d: got watcher %T, want *ModTimeWatcher", i, l.Watcher)
+		}
+		if mtw.interval != devPollInterval {
+			t.Errorf("loader #%d: interval = %v, want %v", i, mtw.interval, devPollInterval)
+		}
+		if wantLocal := strings.Contains(wantPaths[i], ".local."); l.skipIfMissing != wantLocal {
+			t.Errorf("loader #%d: skipIfMissing = %v, want %v", i, l.skipIfMissing, wantLocal)
+		}
+	}
+}
+
+func TestWithDevMode_LocalOverride(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	basePath := filepath.Join(dir, "config.json")
+	localPath := filepath.Join(dir, "config.local.json")
+	if err := updateJSONFile(basePath, map[string]any{"int": 1, "inner": map[string]any{"string": "base"}}); err != nil {
+		t.Fatalf("failed to setup json config: %v", err)
+	}
+
+	cm, err := NewConfigManagerFor[TestConfig](WithDevMode, WithJSONFile(basePath))
+	if err != nil {
+		t.Fatalf("NewConfigManagerFor() error = %v", err)
+	}
+	out := &bytes.Buffer{}
+	cm.devOut = out
+
+	if err := cm.reload(); err != nil {
+		t.Fatalf("reload() without local file error = %v", err)
+	}
+	want := &TestConfig{Int: 1, Inner: testInnerConfig{String: "base"}}
+	if got := cm.Config(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Config() = %v, want %v", got, want)
+	}
+
+	if err := updateJSONFile(localPath, map[string]any{"int": 2}); err != nil {
+		t.Fatalf("failed to setup local json config: %v", err)
+	}
+	if err := cm.reload(); err != nil {
+		t.Fatalf("reload() with local file error = %v", err)
+	}
+	want = &TestConfig{Int: 2, Inner: testInnerConfig{String: "base"}}
+	if got := cm.Config(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Config() = %v, want %v", got, want)
+	}
+
+	wantOut := "confgo: config loaded\n" +
+		"confgo: config reloaded, 1 field(s) changed:\n" +
+		"  int: 1 -> 2\n"
+	if out.String() != wantOut {
+		t.Errorf("dev output = %q, want %q", out.String(), wantOut)
+	}
+}
@@ -0,0 +1,60 @@
+package confgo
+
+import (
+	"reflect"
+)
+
+// fieldChange describes a single leaf field whose value differs between two configs.
+type fieldChange struct {
+	path     string
+	oldValue any
+	newValue any
+	// secret is true if the field or one of its parents is tagged `secret:"true"`.
+	secret bool
+}
+
+func valueInterface(v reflect.Value) any {
+	if !v.IsValid() || !v.CanInterface() {
+		return nil
+	}
+	return v.Interface()
+}
+
+// diffConfigs returns the list of leaf fields that differ between old and new configs.
+// Structs (and pointers to structs) are walked recursively, everything else is compared as a whole.
+func diffConfigs(oldCfg, newCfg any) []fieldChange {
+	changes := make([]fieldChange, 0)
+	diffValues("", reflect.ValueOf(oldCfg), reflect.ValueOf(newCfg), false, &changes)
+	return changes
+}
+
+func diffValues(path string, oldVal, newVal reflect.Value, secret bool, changes *[]fieldChange) {
+	for oldVal.IsValid() && oldVal.Kind() == reflect.Ptr && !oldVal.IsNil() &&
+		newVal.IsValid() && newVal.Kind() == reflect.Ptr && !newVal.IsNil() {
+		oldVal, newVal = oldVal.Elem(), newVal.Elem()
+	}
+
+	if oldVal.IsValid() && newVal.IsValid() &&
+		oldVal.Kind() == reflect.Struct && oldVal.Type() == newVal.Type() && !isLeafStruct(oldVal.Type()) {
+		for i := range oldVal.NumField() {
+			sf := oldVal.Type().Field(i)
+			key, ok := fieldKey(sf)
+			if !ok {
+				continue
+			}
+			diffValues(joinPath(path, key), oldVal.Field(i), newVal.Field(i), secret || isSecretField(sf), changes)
+		}
+		return
+	}
+
+	oldIface, newIface := valueInterface(oldVal), valueInterface(newVal)
+	if reflect.DeepEqual(oldIface, newIface) {
+		return
+	}
+	*changes = append(*changes, fieldChange{
+		path:     path,
+		oldValue: oldIface,
+		newValue: newIface,
+		secret:   secret,
+	})
+}
@@ -0,0 +1,85 @@
+package confgo
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_diffConfigs(t *testing.T) {
+	t.Parallel()
+
+	type args struct {
+		oldCfg any
+		newCfg any
+	}
+	tests := []struct {
+		name string
+		args args
+		want []fieldChange
+	}{
+		{
+			name: "no changes",
+			args: args{
+				oldCfg: &TestConfig{Int: 1, Slice: []string{"a"}},
+				newCfg: &TestConfig{Int: 1, Slice: []string{"a"}},
+			},
+			want: []fieldChange{},
+		},
+		{
+			name: "top level field",
+			args: args{
+				oldCfg: &TestConfig{Int: 1},
+				newCfg: &TestConfig{Int: 2},
+			},
+			want: []fieldChange{{path: "int", oldValue: 1, newValue: 2}},
+		},
+		{
+			name: "inner struct field",
+			args: args{
+				oldCfg: &TestConfig{Inner: testInnerConfig{String: "a"}},
+				newCfg: &TestConfig{Inner: testInnerConfig{String: "b"}},
+			},
+			want: []fieldChange{{path: "inner.string", oldValue: "a", newValue: "b"}},
+		},
+		{
+			name: "inner struct pointer field",
+			args: args{
+				oldCfg: &TestConfig{InnerPtr: &testInnerConfig{Int: 1}},
+				newCfg: &TestConfig{InnerPtr: &testInnerConfig{Int: 2}},
+			},
+			want: []fieldChange{{path: "inner_ptr.int", oldValue: 1, newValue: 2}},
+		},
+		{
+			name: "nil pointer becomes set",
+			args: args{
+				oldCfg: &TestConfig{},
+				newCfg: &TestConfig{InnerPtr: &testInnerConfig{Int: 2}},
+			},
+			want: []fieldChange{{
+				path:     "inner_ptr",
+				oldValue: (*testInnerConfig)(nil),
+				newValue: &testInnerConfig{Int: 2},
+			}},
+		},
+		{
+			name: "slices and maps are compared as a whole",
+			args: args{
+				oldCfg: &TestConfig{Slice: []string{"a"}, Map: map[string]string{"a": "b"}},
+				newCfg: &TestConfig{Slice: []string{"a", "b"}, Map: map[string]string{"a": "c"}},
+			},
+			want: []fieldChange{
+				{path: "map", oldValue: map[string]string{"a": "b"}, newValue: map[string]string{"a": "c"}},
+				{path: "slice", oldValue: []string{"a"}, newValue: []string{"a", "b"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := diffConfigs(tt.args.oldCfg, tt.args.newCfg); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("diffConfigs() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
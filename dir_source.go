@@ -0,0 +1,137 @@
+package confgo
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var (
+	_ Source    = (*DirSource)(nil)
+	_ Formatter = (*DirSource)(nil)
+	_ ModTimer  = (*DirSource)(nil)
+)
+
+// DirSource is both a Source and a Formatter of the files of a directory, e.g. "/etc/app/conf.d",
+// which are merged as sequential layers in the lexical order of their names, so "10-base.yaml"
+// is overridden by "20-local.yaml". The Formatter of every file is picked by its extension as WithFile does.
+// Files with other extensions, hidden files and subdirectories are ignored.
+//
+// The files are merged by Merge with the "merge" struct tags only, the options of WithMergeOptions
+// apply to merging the whole directory into the other layers. The data read by the source is only meaningful
+// to the source itself, so the loader must use the source as its formatter and must have no transformers,
+// see WithDir.
+type DirSource struct {
+	dir string
+}
+
+func NewDirSource(dir string) *DirSource {
+	return &DirSource{dir: dir}
+}
+
+// dirFile is a config file as encoded in the data read by DirSource and GlobSource.
+type dirFile struct {
+	Name string `json:"name"`
+	Data []byte `json:"data"`
+}
+
+// files returns the names of the config files of the directory in lexical order.
+func (ds *DirSource) files() ([]string, error) {
+	entries, err := os.ReadDir(ds.dir)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if e.IsDir() || strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		if _, err := formatterForFile(e.Name()); err != nil {
+			continue
+		}
+		names = append(names, e.Name())
+	}
+	return names, nil
+}
+
+// Read reads the config files of the directory.
+func (ds *DirSource) Read() ([]byte, error) {
+	names, err := ds.files()
+	if err != nil {
+		return nil, err
+	}
+	return readFileLayers(ds.dir, names)
+}
+
+// Unmarshal parses the files read by Read and merges them into v in order.
+func (ds *DirSource) Unmarshal(data []byte, v any) error {
+	return unmarshalFileLayers(data, v)
+}
+
+// readFileLayers reads the config files with the names in the directory, in order.
+func readFileLayers(dir string, names []string) ([]byte, error) {
+	files := make([]dirFile, 0, len(names))
+	for _, name := range names {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, dirFile{Name: name, Data: data})
+	}
+	return json.Marshal(files)
+}
+
+// unmarshalFileLayers parses the files read by readFileLayers with the Formatters picked by their extensions
+// and merges them into v in order.
+func unmarshalFileLayers(data []byte, v any) error {
+	var files []dirFile
+	if err := json.Unmarshal(data, &files); err != nil {
+		return fmt.Errorf("decode files data: %w", err)
+	}
+	typ := reflect.TypeOf(v)
+	if typ.Kind() != reflect.Ptr {
+		return fmt.Errorf("%w: %T is not a pointer to a struct", ErrConfigTypeMismatch, v)
+	}
+	for _, f := range files {
+		formatter, err := formatterForFile(f.Name)
+		if err != nil {
+			return err
+		}
+		layer := reflect.New(typ.Elem()).Interface()
+		if err := formatter.Unmarshal(f.Data, layer); err != nil {
+			return fmt.Errorf("file %q: %w", f.Name, err)
+		}
+		if err := Merge(v, layer); err != nil {
+			return fmt.Errorf("file %q: merge: %w", f.Name, err)
+		}
+	}
+	return nil
+}
+
+// ModTime returns the latest modification time of the directory and its config files,
+// so ModTimeWatcher reports files added, removed or changed in the directory.
+func (ds *DirSource) ModTime() (time.Time, error) {
+	info, err := os.Stat(ds.dir)
+	if err != nil {
+		return time.Time{}, err
+	}
+	modTime := info.ModTime()
+	names, err := ds.files()
+	if err != nil {
+		return time.Time{}, err
+	}
+	for _, name := range names {
+		info, err := os.Stat(filepath.Join(ds.dir, name))
+		if err != nil {
+			return time.Time{}, err
+		}
+		if info.ModTime().After(modTime) {
+			modTime = info.ModTime()
+		}
+	}
+	return modTime, nil
+}
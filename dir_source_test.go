@@ -0,0 +1,61 @@
+package confgo
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDirSource(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"10-base.json":   `{"int": 1, "inner": {"int": 1, "string": "base"}, "slice": ["a"]}`,
+		"20-local.yaml":  "inner:\n  string: local\n",
+		"30-env.env":     "INT=3\n",
+		".hidden.json":   `{"int": 4}`,
+		"README.md":      "# conf.d",
+		"sub/99-x.json":  `{"int": 5}`,
+		"40-broken.toml": "int = 6",
+	}
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cm, err := NewConfigManager(testConfigConstructor, WithDir(dir))
+	if err != nil {
+		t.Fatalf("NewConfigManager() error = %v", err)
+	}
+	if err := cm.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer cm.MustStop()
+
+	want := &TestConfig{Int: 3, Inner: testInnerConfig{Int: 1, String: "local"}, Slice: []string{"a"}}
+	if got := cm.Config(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Config() = %+v, want %+v", got, want)
+	}
+
+	modTime := time.Now().Add(time.Hour).Truncate(time.Second)
+	if err := os.Chtimes(filepath.Join(dir, "20-local.yaml"), modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := NewDirSource(dir).ModTime(); err != nil || !got.Equal(modTime) {
+		t.Errorf("ModTime() = %v, %v, want %v", got, err, modTime)
+	}
+
+	if _, err := NewDirSource(filepath.Join(dir, "missing")).Read(); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Read() error = %v, want %v", err, fs.ErrNotExist)
+	}
+}
@@ -0,0 +1,117 @@
+package confgo
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// configFileExtensions are the extensions of the config files searched by WithConfigName in order of preference.
+// ".toml" is not among them since WithFile has no TOML formatter.
+var configFileExtensions = []string{".json", ".jsonc", ".yaml", ".yml"}
+
+// ConfigNameOption configures the config file discovery of WithConfigName.
+type ConfigNameOption func(d *configDiscovery)
+
+// ConfigSearchPaths replaces the default directories searched for the config files, listed in order of precedence.
+func ConfigSearchPaths(dirs ...string) ConfigNameOption {
+	return func(d *configDiscovery) {
+		d.dirs = slices.Clone(dirs)
+	}
+}
+
+// ConfigLoadAll makes WithConfigName load the config files found in all the directories instead of the first one,
+// the files of the earlier directories overriding the later ones, e.g. ./app.yaml overrides /etc/app/app.yaml.
+func ConfigLoadAll(d *configDiscovery) {
+	d.all = true
+}
+
+// ConfigOptional makes WithConfigName add no loaders instead of failing if no config file is found.
+func ConfigOptional(d *configDiscovery) {
+	d.optional = true
+}
+
+type configDiscovery struct {
+	dirs     []string
+	all      bool
+	optional bool
+}
+
+// WithConfigName adds a Loader layer with the config file named name found in the standard locations,
+// searched in order of precedence:
+//   - the working directory;
+//   - the "name" directory in the user config directory, i.e. $XDG_CONFIG_HOME/name or ~/.config/name on Unix;
+//   - /etc/name.
+//
+// The first file found is loaded, see ConfigSearchPaths and ConfigLoadAll. In every directory the files with
+// the extensions ".json", ".jsonc", ".yaml" and ".yml" are looked for in this order, and the first of them
+// is loaded with the formatter picked by WithFile. The files are looked for once, when the option is applied,
+// and the error wrapping ErrConfigFileNotFound is returned if there are none unless ConfigOptional is set.
+// TOML files are unsupported and are not looked for, as WithFile has no formatter for them.
+func WithConfigName(name string, opts ...ConfigNameOption) Option {
+	return func(cm *ConfigManager) error {
+		d := &configDiscovery{dirs: defaultConfigDirs(name), all: false, optional: false}
+		for _, opt := range opts {
+			if opt != nil {
+				opt(d)
+			}
+		}
+		files, err := d.find(name)
+		if err != nil {
+			return err
+		}
+		if len(files) == 0 {
+			if d.optional {
+				return nil
+			}
+			return fmt.Errorf("%w: %q in %s", ErrConfigFileNotFound, name, strings.Join(d.dirs, ", "))
+		}
+		// Later loaders override the earlier ones, so the files of the preferred directories go last.
+		for _, file := range slices.Backward(files) {
+			if err := WithFile(file)(cm); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// defaultConfigDirs returns the standard directories of the config files of the application in order of precedence.
+func defaultConfigDirs(name string) []string {
+	dirs := []string{"."}
+	if userDir, err := os.UserConfigDir(); err == nil {
+		dirs = append(dirs, filepath.Join(userDir, name))
+	}
+	return append(dirs, filepath.Join("/etc", name))
+}
+
+// find returns the config files named name found in the directories in order of precedence,
+// at most one file per directory and only the first one unless all is set.
+func (d *configDiscovery) find(name string) ([]string, error) {
+	files := make([]string, 0, 1)
+	for _, dir := range d.dirs {
+		for _, ext := range configFileExtensions {
+			file := filepath.Join(dir, name+ext)
+			info, err := os.Stat(file)
+			if err != nil {
+				if errors.Is(err, fs.ErrNotExist) {
+					continue
+				}
+				return nil, fmt.Errorf("look for config file: %w", err)
+			}
+			if info.IsDir() {
+				continue
+			}
+			files = append(files, file)
+			if !d.all {
+				return files, nil
+			}
+			break
+		}
+	}
+	return files, nil
+}
@@ -0,0 +1,80 @@
+package confgo
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWithConfigName(t *testing.T) {
+	t.Parallel()
+
+	local, user, system, empty := t.TempDir(), t.TempDir(), t.TempDir(), t.TempDir()
+	files := map[string]string{
+		filepath.Join(local, "app.yaml"):    "int: 1\n",
+		filepath.Join(user, "app.json"):     `{"int": 2, "inner": {"int": 3}}`,
+		filepath.Join(user, "app.yml"):      "int: 4\n",
+		filepath.Join(system, "app.json"):   `{"int": 5, "inner": {"int": 6, "string": "a"}}`,
+		filepath.Join(system, "other.json"): `{"int": 7}`,
+	}
+	for file, data := range files {
+		if err := os.WriteFile(file, []byte(data), 0o600); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(empty, "app.json"), 0o700); err != nil {
+		t.Fatalf("Mkdir() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		opts    []ConfigNameOption
+		want    *TestConfig
+		wantErr error
+	}{
+		{
+			name: "first found",
+			opts: []ConfigNameOption{ConfigSearchPaths(empty, user, system)},
+			want: &TestConfig{Int: 2, Inner: testInnerConfig{Int: 3}},
+		},
+		{
+			name: "all found",
+			opts: []ConfigNameOption{ConfigSearchPaths(local, empty, user, system), ConfigLoadAll},
+			want: &TestConfig{Int: 1, Inner: testInnerConfig{Int: 3, String: "a"}},
+		},
+		{
+			name:    "not found",
+			opts:    []ConfigNameOption{ConfigSearchPaths(empty)},
+			wantErr: ErrConfigFileNotFound,
+		},
+		{
+			name:    "optional not found",
+			opts:    []ConfigNameOption{ConfigSearchPaths(empty), ConfigOptional},
+			wantErr: ErrNoLoadersDefined,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cm, err := NewConfigManager(testConfigConstructor, WithConfigName("app", tt.opts...))
+			if err == nil {
+				err = cm.Start()
+			}
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error = %v", err)
+			}
+			defer cm.MustStop()
+			if got := cm.Config().(*TestConfig); got.Int != tt.want.Int || got.Inner != tt.want.Inner {
+				t.Errorf("Config() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
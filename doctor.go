@@ -0,0 +1,201 @@
+package confgo
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// DoctorSeverity is a severity of a DoctorFinding.
+type DoctorSeverity int
+
+const (
+	DoctorInfo DoctorSeverity = iota
+	DoctorWarning
+	DoctorError
+)
+
+func (s DoctorSeverity) String() string {
+	switch s {
+	case DoctorInfo:
+		return "info"
+	case DoctorWarning:
+		return "warning"
+	case DoctorError:
+		return "error"
+	default:
+		return fmt.Sprintf("severity(%d)", int(s))
+	}
+}
+
+func (s DoctorSeverity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// Names of the checks performed by Doctor.
+const (
+	DoctorCheckUnwatchedFile    = "unwatched_file"
+	DoctorCheckPlaintextSecret  = "plaintext_secret"
+	DoctorCheckShadowedLoader   = "shadowed_loader"
+	DoctorCheckEnvTagCollision  = "env_tag_collision"
+	DoctorCheckLoaderReadFailed = "loader_read_failed"
+)
+
+// DoctorFinding is a single potential misconfiguration found by Doctor.
+type DoctorFinding struct {
+	Check    string         `json:"check"`
+	Severity DoctorSeverity `json:"severity"`
+	Message  string         `json:"message"`
+	// Loader is the index of the loader the finding is about or -1.
+	Loader int `json:"loader"`
+	// Field is the dotted path of the config field the finding is about, if any.
+	Field string `json:"field,omitempty"`
+}
+
+func (f DoctorFinding) String() string {
+	return fmt.Sprintf("[%s] %s: %s", f.Severity, f.Check, f.Message)
+}
+
+// secretFieldPattern matches names of fields that likely hold sensitive values.
+var secretFieldPattern = regexp.MustCompile(`(?i)(password|passwd|secret|token|api_?key|private_?key|credential)`)
+
+// Doctor checks the manager for common misconfigurations and returns the list of findings:
+//   - file loaders without a watcher, whose changes are never applied, with update callbacks which are never called;
+//   - fields that look like secrets but are not tagged with `secret:"true"`, so they are printed in plaintext;
+//   - loaders whose every value is overridden by the loaders that follow them;
+//   - env tags bound to more than one field.
+//
+// The loaders are checked as they have been loaded by the last reload, their sources are not read again.
+func (cm *ConfigManager) Doctor() []DoctorFinding {
+	loaders := cm.snapshotLoaders()
+	findings := make([]DoctorFinding, 0)
+	findings = append(findings, checkUnwatchedFiles(loaders)...)
+	if cm.constructor != nil {
+		typ := reflect.TypeOf(cm.constructor())
+		findings = append(findings, checkPlaintextSecrets(typ)...)
+		findings = append(findings, checkEnvTagCollisions(typ)...)
+		findings = append(findings, cm.checkShadowedLoaders(loaders)...)
+	}
+	return findings
+}
+
+func checkUnwatchedFiles(loaders []Loader) []DoctorFinding {
+	findings := make([]DoctorFinding, 0)
+	for i, l := range loaders {
+		if _, ok := l.Source.(*FileSource); !ok || l.Watcher != nil {
+			continue
+		}
+		finding := DoctorFinding{
+			Check:    DoctorCheckUnwatchedFile,
+			Severity: DoctorInfo,
+			Message:  fmt.Sprintf("%s is not watched, its changes are applied only on restart", l.describe()),
+			Loader:   i,
+			Field:    "",
+		}
+		if l.OnUpdateSuccess != nil || l.OnUpdateError != nil {
+			finding.Severity = DoctorWarning
+			finding.Message = fmt.Sprintf("%s has update callbacks but no watcher, callbacks are never called", l.describe())
+		}
+		findings = append(findings, finding)
+	}
+	return findings
+}
+
+func checkPlaintextSecrets(typ reflect.Type) []DoctorFinding {
+	findings := make([]DoctorFinding, 0)
+	walkStructFields(typ, "", func(path string, sf reflect.StructField) {
+		// The fields redacted by their own secret tag or by the one of a parent are not reported.
+		if isSecretPath(typ, path) {
+			return
+		}
+		key, _ := fieldKey(sf)
+		if !secretFieldPattern.MatchString(sf.Name) && !secretFieldPattern.MatchString(key) {
+			return
+		}
+		findings = append(findings, DoctorFinding{
+			Check:    DoctorCheckPlaintextSecret,
+			Severity: DoctorWarning,
+			Message:  fmt.Sprintf("field %q looks sensitive but is not tagged with `secret:\"true\"`", path),
+			Loader:   -1,
+			Field:    path,
+		})
+	})
+	return findings
+}
+
+func checkEnvTagCollisions(typ reflect.Type) []DoctorFinding {
+	fieldsByEnv, envs := envBindings(typ)
+	findings := make([]DoctorFinding, 0)
+	for _, env := range envs {
+		if fields := fieldsByEnv[env]; len(fields) > 1 {
+			findings = append(findings, DoctorFinding{
+				Check:    DoctorCheckEnvTagCollision,
+				Severity: DoctorError,
+				Message:  fmt.Sprintf("env %q is bound to multiple fields: %s", env, strings.Join(fields, ", ")),
+				Loader:   -1,
+				Field:    fields[0],
+			})
+		}
+	}
+	return findings
+}
+
+// checkShadowedLoaders reports the loaders which have failed in the last reload and the loaders whose every value
+// is overridden by the following ones. The layers are taken from the layer cache of the last reload, so they are
+// parsed exactly as the reload has parsed them and the sources, whose reads may have side effects, are not read again.
+// Loaders which have not been loaded yet are not checked.
+func (cm *ConfigManager) checkShadowedLoaders(loaders []Loader) []DoctorFinding {
+	findings := make([]DoctorFinding, 0)
+	cm.mu.RLock()
+	statuses := maps.Clone(cm.loaderStatuses)
+	cm.mu.RUnlock()
+	setByLoader := make([][]string, len(loaders))
+	for i, l := range loaders {
+		layer := cm.layerCache.get(l.id)
+		switch {
+		case layer != nil && layer.skipErr == nil && cm.explicitValues:
+			setByLoader[i] = layer.present
+		case layer != nil && layer.skipErr == nil:
+			setByLoader[i] = nonZeroPaths(layer.parsed)
+		case layer == nil && statuses[l.id].Err != nil:
+			findings = append(findings, DoctorFinding{
+				Check:    DoctorCheckLoaderReadFailed,
+				Severity: DoctorError,
+				Message:  fmt.Sprintf("%s: %v", l.describe(), statuses[l.id].Err),
+				Loader:   i,
+				Field:    "",
+			})
+		}
+	}
+
+	for i := range loaders {
+		if len(setByLoader[i]) == 0 {
+			continue
+		}
+		overridden := make(map[string]bool)
+		for _, paths := range setByLoader[i+1:] {
+			for _, p := range paths {
+				overridden[p] = true
+			}
+		}
+		shadowed := true
+		for _, p := range setByLoader[i] {
+			if !overridden[p] {
+				shadowed = false
+				break
+			}
+		}
+		if shadowed {
+			findings = append(findings, DoctorFinding{
+				Check:    DoctorCheckShadowedLoader,
+				Severity: DoctorWarning,
+				Message:  fmt.Sprintf("every value of %s is overridden by the following loaders", loaders[i].describe()),
+				Loader:   i,
+				Field:    "",
+			})
+		}
+	}
+	return findings
+}
@@ -0,0 +1,239 @@
+package confgo
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+type testDoctorConfig struct {
+	Host     string `json:"host" env:"HOST"`
+	Password string `json:"password" env:"PASSWORD"`
+	APIKey   string `json:"api_key" secret:"true"`
+	Inner    struct {
+		Host  string `json:"host" env:"HOST"`
+		Token string `json:"token"`
+	} `json:"inner"`
+}
+
+func Test_checkPlaintextSecrets(t *testing.T) {
+	t.Parallel()
+
+	type config struct {
+		Password string `json:"password" secret:"1"`
+		Token    string `json:"token" secret:"True"`
+		APIKey   string `json:"api_key" secret:"false"`
+		DB       struct {
+			Password string `json:"password"`
+		} `json:"db" secret:"true"`
+	}
+	got := checkPlaintextSecrets(reflect.TypeOf(config{}))
+	if len(got) != 1 || got[0].Field != "api_key" {
+		t.Errorf("checkPlaintextSecrets() = %+v, want the api_key finding only", got)
+	}
+}
+
+func TestConfigManager_Doctor(t *testing.T) {
+	t.Parallel()
+
+	type innerCfg = struct {
+		Host  string `json:"host" env:"HOST"`
+		Token string `json:"token"`
+	}
+	cm := newTestConfigManager(testConfigManagerFields{
+		constructor: func() any { return &testDoctorConfig{} },
+		loaders: []Loader{
+			{
+				Source:    &fakeSource{data: []byte("base")},
+				Formatter: &fakeFormatter{data: testDoctorConfig{Host: "base"}},
+			},
+			{
+				Source:        NewFileSource("config.json"),
+				Formatter:     &fakeFormatter{data: testDoctorConfig{}},
+				OnUpdateError: func(error) {},
+				ErrorPolicy:   LoaderErrorWarn,
+			},
+			{
+				Source:      &fakeSource{err: errors.New("unavailable")},
+				Formatter:   &fakeFormatter{data: testDoctorConfig{}},
+				ErrorPolicy: LoaderErrorWarn,
+			},
+			{
+				Source:    &fakeSource{data: []byte("override")},
+				Formatter: &fakeFormatter{data: testDoctorConfig{Host: "override", Inner: innerCfg{Host: "inner"}}},
+			},
+		},
+	})
+	if err := cm.reload(); err != nil {
+		t.Fatalf("reload() error = %v", err)
+	}
+
+	got := cm.Doctor()
+	want := []DoctorFinding{
+		{
+			Check:    DoctorCheckUnwatchedFile,
+			Severity: DoctorWarning,
+			Message:  `file "config.json" has update callbacks but no watcher, callbacks are never called`,
+			Loader:   1,
+		},
+		{
+			Check:    DoctorCheckPlaintextSecret,
+			Severity: DoctorWarning,
+			Message:  "field \"password\" looks sensitive but is not tagged with `secret:\"true\"`",
+			Loader:   -1,
+			Field:    "password",
+		},
+		{
+			Check:    DoctorCheckPlaintextSecret,
+			Severity: DoctorWarning,
+			Message:  "field \"inner.token\" looks sensitive but is not tagged with `secret:\"true\"`",
+			Loader:   -1,
+			Field:    "inner.token",
+		},
+		{
+			Check:    DoctorCheckEnvTagCollision,
+			Severity: DoctorError,
+			Message:  `env "HOST" is bound to multiple fields: host, inner.host`,
+			Loader:   -1,
+			Field:    "host",
+		},
+		{
+			Check:    DoctorCheckLoaderReadFailed,
+			Severity: DoctorError,
+			Message:  `file "config.json": read data from modTimer: open config.json: no such file or directory`,
+			Loader:   1,
+		},
+		{
+			Check:    DoctorCheckLoaderReadFailed,
+			Severity: DoctorError,
+			Message:  "source *confgo.fakeSource: read data from modTimer: unavailable",
+			Loader:   2,
+		},
+		{
+			Check:    DoctorCheckShadowedLoader,
+			Severity: DoctorWarning,
+			Message:  "every value of source *confgo.fakeSource is overridden by the following loaders",
+			Loader:   0,
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Doctor() =\n%v\nwant\n%v", got, want)
+	}
+}
+
+func TestConfigManager_Doctor_Healthy(t *testing.T) {
+	t.Parallel()
+
+	cm := newTestConfigManager(testConfigManagerFields{
+		constructor: testConfigConstructor,
+		loaders: []Loader{
+			{Source: &fakeSource{data: []byte("1")}, Formatter: &fakeFormatter{data: TestConfig{Int: 1}}},
+			{Source: &fakeSource{data: []byte("2")}, Formatter: &fakeFormatter{data: TestConfig{Slice: []string{"a"}}}},
+		},
+	})
+	if err := cm.reload(); err != nil {
+		t.Fatalf("reload() error = %v", err)
+	}
+	if got := cm.Doctor(); len(got) != 0 {
+		t.Errorf("Doctor() = %v, want no findings", got)
+	}
+}
+
+func TestConfigManager_Doctor_DoesNotReadSources(t *testing.T) {
+	t.Parallel()
+
+	source := &countingSource{mu: sync.Mutex{}, reads: 0}
+	cm := newTestConfigManager(testConfigManagerFields{
+		constructor: testConfigConstructor,
+		loaders: []Loader{
+			{Source: source, Formatter: NewJSONFormatter()},
+			{Source: &fakeSource{data: []byte(`{"int": 2}`)}, Formatter: NewJSONFormatter()},
+		},
+	})
+	if got := cm.Doctor(); len(got) != 0 {
+		t.Errorf("Doctor() before the first reload = %v, want no findings", got)
+	}
+	if err := cm.reload(); err != nil {
+		t.Fatalf("reload() error = %v", err)
+	}
+
+	got := cm.Doctor()
+	if len(got) != 1 || got[0].Check != DoctorCheckShadowedLoader || got[0].Loader != 0 {
+		t.Errorf("Doctor() = %v, want the first loader reported as shadowed", got)
+	}
+	if source.reads != 1 {
+		t.Errorf("source has been read %d times, want 1", source.reads)
+	}
+}
+
+func TestConfigManager_Doctor_RecursiveType(t *testing.T) {
+	t.Parallel()
+
+	type node struct {
+		Name     string `json:"name"`
+		Password string `json:"password"`
+		Next     *node  `json:"next"`
+	}
+	cm := newTestConfigManager(testConfigManagerFields{
+		constructor: func() any { return new(node) },
+		loaders: []Loader{
+			{Source: &fakeSource{data: []byte("1")}, Formatter: &fakeFormatter{data: node{Name: "a", Password: "p"}}},
+		},
+	})
+	done := make(chan []DoctorFinding, 1)
+	go func() {
+		done <- cm.Doctor()
+	}()
+	select {
+	case findings := <-done:
+		if len(findings) == 0 {
+			t.Errorf("Doctor() = %v, want the plaintext password finding", findings)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Doctor() of a recursive config type has not returned")
+	}
+}
+
+func TestConfigManager_Doctor_ConcurrentLoaders(t *testing.T) {
+	t.Parallel()
+
+	cm, err := NewConfigManager(testConfigConstructor,
+		WithLoader(Loader{Source: &fakeSource{data: []byte(`{"int": 1}`)}, Formatter: NewJSONFormatter()}))
+	if err != nil {
+		t.Fatalf("NewConfigManager() error = %v", err)
+	}
+	if err := cm.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer cm.MustStop()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range 20 {
+			cm.AddLoader(Loader{Name: "extra", Source: &fakeSource{data: []byte(`{"slice": ["a"]}`)}, Formatter: NewJSONFormatter()})
+			_ = cm.RemoveLoader("extra")
+		}
+	}()
+	for range 20 {
+		cm.Doctor()
+	}
+	<-done
+}
+
+func TestDoctorSeverity_MarshalText(t *testing.T) {
+	t.Parallel()
+
+	for severity, want := range map[DoctorSeverity]string{
+		DoctorInfo:         "info",
+		DoctorWarning:      "warning",
+		DoctorError:        "error",
+		DoctorSeverity(42): "severity(42)",
+	} {
+		got, err := severity.MarshalText()
+		if err != nil || string(got) != want {
+			t.Errorf("MarshalText() = %q, %v, want %q", got, err, want)
+		}
+	}
+}
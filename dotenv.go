@@ -0,0 +1,220 @@
+package confgo
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/caarlos0/env/v11"
+)
+
+var _ Formatter = (*DotenvFormatter)(nil)
+
+// DotenvFormatter is a formatter that parses .env files and converts them into structured data
+// via the env package, as EnvFormatter does. On top of plain KEY=VALUE lines it supports:
+//   - blank lines and comments starting with "#", both on their own lines and after values;
+//   - the "export" prefix, e.g. "export KEY=VALUE";
+//   - single-quoted values which are taken literally;
+//   - double-quoted values with \n, \r, \t, \", \\ and \$ escapes;
+//   - quoted values spanning multiple lines.
+//
+// Later assignments of the same key override earlier ones.
+type DotenvFormatter struct{}
+
+func NewDotenvFormatter() *DotenvFormatter {
+	return &DotenvFormatter{}
+}
+
+func (df *DotenvFormatter) Unmarshal(data []byte, v any) error {
+	vars, err := parseDotenv(data)
+	if err != nil {
+		return err
+	}
+	return env.ParseWithOptions(v, env.Options{
+		Environment: vars,
+		FuncMap:     decodeHooks(nil).envParsers(),
+	})
+}
+
+// dotenvParser parses .env data keeping track of the current line for error messages.
+type dotenvParser struct {
+	data []byte
+	pos  int
+	line int
+}
+
+func parseDotenv(data []byte) (map[string]string, error) {
+	p := &dotenvParser{data: data, pos: 0, line: 1}
+	res := make(map[string]string)
+	for {
+		p.skipBlank()
+		if p.eof() {
+			return res, nil
+		}
+		if p.peek() == '#' {
+			p.skipLine()
+			continue
+		}
+		line := p.line
+		key, value, err := p.parseAssignment()
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %w", ErrInvalidDotenv, line, err)
+		}
+		res[key] = value
+	}
+}
+
+func (p *dotenvParser) eof() bool {
+	return p.pos >= len(p.data)
+}
+
+func (p *dotenvParser) peek() byte {
+	return p.data[p.pos]
+}
+
+func (p *dotenvParser) next() byte {
+	c := p.data[p.pos]
+	p.pos++
+	if c == '\n' {
+		p.line++
+	}
+	return c
+}
+
+// skipBlank skips whitespace including line breaks.
+func (p *dotenvParser) skipBlank() {
+	for !p.eof() && strings.IndexByte(" \t\r\n", p.peek()) >= 0 {
+		p.next()
+	}
+}
+
+// skipSpaces skips whitespace within the current line.
+func (p *dotenvParser) skipSpaces() {
+	for !p.eof() && (p.peek() == ' ' || p.peek() == '\t') {
+		p.next()
+	}
+}
+
+// skipLine skips the rest of the current line including the line break.
+func (p *dotenvParser) skipLine() {
+	for !p.eof() && p.next() != '\n' {
+	}
+}
+
+func (p *dotenvParser) parseAssignment() (string, string, error) {
+	key := p.parseKey()
+	if key == "export" && !p.eof() && (p.peek() == ' ' || p.peek() == '\t') {
+		p.skipSpaces()
+		key = p.parseKey()
+	}
+	if key == "" {
+		return "", "", errors.New("expected variable name")
+	}
+	p.skipSpaces()
+	if p.eof() || p.peek() != '=' {
+		return "", "", fmt.Errorf("expected '=' after %q", key)
+	}
+	p.next()
+	valueStart := p.pos
+	p.skipSpaces()
+
+	var (
+		value string
+		err   error
+	)
+	if !p.eof() && (p.peek() == '"' || p.peek() == '\'') {
+		value, err = p.parseQuoted()
+	} else {
+		// The whitespace is kept, so that a comment right after it is not taken for the value.
+		p.pos = valueStart
+		value = p.parseUnquoted()
+	}
+	if err != nil {
+		return "", "", fmt.Errorf("value of %q: %w", key, err)
+	}
+	return key, value, nil
+}
+
+func (p *dotenvParser) parseKey() string {
+	start := p.pos
+	for !p.eof() && isDotenvKeyByte(p.peek(), p.pos == start) {
+		p.next()
+	}
+	return string(p.data[start:p.pos])
+}
+
+func isDotenvKeyByte(c byte, first bool) bool {
+	switch {
+	case c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z':
+		return true
+	case '0' <= c && c <= '9' || c == '.':
+		return !first
+	default:
+		return false
+	}
+}
+
+// parseUnquoted parses the value up to the end of the line or an inline comment, trimming whitespace.
+func (p *dotenvParser) parseUnquoted() string {
+	start := p.pos
+	end := len(p.data)
+	if i := bytes.IndexByte(p.data[start:], '\n'); i >= 0 {
+		end = start + i
+	}
+	value := p.data[start:end]
+	for i := 1; i < len(value); i++ {
+		if value[i] == '#' && (value[i-1] == ' ' || value[i-1] == '\t') {
+			value = value[:i]
+			break
+		}
+	}
+	p.pos = end
+	return strings.TrimSpace(string(value))
+}
+
+// parseQuoted parses a single- or double-quoted value, which may span multiple lines,
+// and makes sure only whitespace or a comment follows it on the line.
+func (p *dotenvParser) parseQuoted() (string, error) {
+	quote := p.next()
+	var sb strings.Builder
+	for {
+		if p.eof() {
+			return "", errors.New("unterminated quoted value")
+		}
+		c := p.next()
+		if c == quote {
+			break
+		}
+		if c == '\\' && quote == '"' && !p.eof() {
+			unescapeDotenv(&sb, p.next())
+			continue
+		}
+		sb.WriteByte(c)
+	}
+
+	p.skipSpaces()
+	if !p.eof() && p.peek() != '\n' && p.peek() != '\r' && p.peek() != '#' {
+		return "", fmt.Errorf("unexpected %q after quoted value", p.peek())
+	}
+	p.skipLine()
+	return sb.String(), nil
+}
+
+// unescapeDotenv writes the character escaped with a backslash in a double-quoted value.
+// Unknown escapes are kept as is, e.g. in Windows paths.
+func unescapeDotenv(sb *strings.Builder, c byte) {
+	switch c {
+	case 'n':
+		sb.WriteByte('\n')
+	case 'r':
+		sb.WriteByte('\r')
+	case 't':
+		sb.WriteByte('\t')
+	case '"', '\\', '$':
+		sb.WriteByte(c)
+	default:
+		sb.WriteByte('\\')
+		sb.WriteByte(c)
+	}
+}
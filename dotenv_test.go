@@ -0,0 +1,107 @@
+package confgo
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseDotenv(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		raw  string
+		want map[string]string
+	}{
+		{
+			name: "empty",
+			raw:  "",
+			want: map[string]string{},
+		},
+		{
+			name: "comments and blank lines",
+			raw:  "# comment\n\n  # indented comment\nFOO=bar\r\n",
+			want: map[string]string{"FOO": "bar"},
+		},
+		{
+			name: "export prefix and spaces around equal sign",
+			raw:  "export FOO = bar baz\nexport=1",
+			want: map[string]string{"FOO": "bar baz", "export": "1"},
+		},
+		{
+			name: "inline comments",
+			raw:  "FOO=bar # comment\nBAR=baz#not-a-comment\nEMPTY= # comment",
+			want: map[string]string{"FOO": "bar", "BAR": "baz#not-a-comment", "EMPTY": ""},
+		},
+		{
+			name: "single quoted",
+			raw:  `FOO='bar \n # "baz"' # comment`,
+			want: map[string]string{"FOO": `bar \n # "baz"`},
+		},
+		{
+			name: "double quoted with escapes",
+			raw:  `FOO="a\tb\n\"c\" \$HOME \\ C:\dir"`,
+			want: map[string]string{"FOO": "a\tb\n\"c\" $HOME \\ C:\\dir"},
+		},
+		{
+			name: "multi-line values",
+			raw:  "KEY=\"-----BEGIN-----\nabc\n-----END-----\"\nNEXT='1\n2'\n",
+			want: map[string]string{"KEY": "-----BEGIN-----\nabc\n-----END-----", "NEXT": "1\n2"},
+		},
+		{
+			name: "repeated key",
+			raw:  "FOO=1\nFOO=2",
+			want: map[string]string{"FOO": "2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := parseDotenv([]byte(tt.raw))
+			if err != nil {
+				t.Fatalf("parseDotenv() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseDotenv() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDotenv_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		raw  string
+	}{
+		{name: "no equal sign", raw: "FOO"},
+		{name: "invalid name", raw: "FOO-BAR=1"},
+		{name: "name starting with digit", raw: "1FOO=1"},
+		{name: "unterminated quote", raw: "FOO=1\nBAR=\"baz\n"},
+		{name: "text after quoted value", raw: "FOO='bar' baz"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if _, err := parseDotenv([]byte(tt.raw)); !errors.Is(err, ErrInvalidDotenv) {
+				t.Errorf("parseDotenv() error = %v, want %v", err, ErrInvalidDotenv)
+			}
+		})
+	}
+}
+
+func TestDotenvFormatter_Unmarshal(t *testing.T) {
+	t.Parallel()
+
+	var cfg TestConfig
+	if err := NewDotenvFormatter().Unmarshal([]byte("# dotenv\nexport INT=\"42\"\n"), &cfg); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if cfg.Int != 42 {
+		t.Errorf("Int = %d, want 42", cfg.Int)
+	}
+}
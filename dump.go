@@ -0,0 +1,53 @@
+package confgo
+
+import (
+	"fmt"
+	"io"
+)
+
+// Marshaler is implemented by formatters which can encode a configuration back into their format,
+// so that unmarshaling the encoded data produces the same configuration.
+type Marshaler interface {
+	// Marshal encodes the configuration object into raw data.
+	Marshal(v any) ([]byte, error)
+}
+
+var (
+	_ Marshaler = (*JSONFormatter)(nil)
+	_ Marshaler = (*JSONCFormatter)(nil)
+	_ Marshaler = (*YAMLFormatter)(nil)
+	_ Marshaler = (*EnvFormatter)(nil)
+)
+
+// Dump writes the current effective configuration, i.e. all layers merged with overrides applied,
+// in the format, so operators can see exactly what the application is running with.
+// The values of secret fields are written as is; use RedactedConfig to expose the configuration safely.
+func (cm *ConfigManager) Dump(w io.Writer, format Format) error {
+	marshaler, err := marshalerForFormat(format)
+	if err != nil {
+		return err
+	}
+	cfg := cm.Config()
+	if cfg == nil {
+		return ErrConfigNotLoaded
+	}
+	data, err := marshaler.Marshal(cfg)
+	if err != nil {
+		return fmt.Errorf("marshal config: %w", err)
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+func marshalerForFormat(format Format) (Marshaler, error) {
+	switch format {
+	case FormatJSON:
+		return NewJSONFormatter(), nil
+	case FormatYAML:
+		return NewYAMLFormatter(), nil
+	case FormatEnv:
+		return NewEnvFormatter(), nil
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownFormat, format)
+	}
+}
@@ -0,0 +1,92 @@
+package confgo
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testDumpDB struct {
+	Host string `json:"host" yaml:"host" env:"HOST"`
+	Port int    `json:"port" yaml:"port" env:"PORT"`
+}
+
+type testDumpConfig struct {
+	Name    string            `json:"name" yaml:"name" env:"NAME"`
+	Timeout time.Duration     `json:"timeout" yaml:"timeout" env:"TIMEOUT"`
+	Size    ByteSize          `json:"size" yaml:"size" env:"SIZE"`
+	Hosts   []string          `json:"hosts" yaml:"hosts" env:"HOSTS"`
+	Labels  map[string]string `json:"labels" yaml:"labels" env:"LABELS"`
+	Debug   *bool             `json:"debug" yaml:"debug" env:"DEBUG"`
+	DB      testDumpDB        `json:"db" yaml:"db" envPrefix:"DB_"`
+}
+
+func TestConfigManager_Dump(t *testing.T) {
+	t.Parallel()
+
+	debug := true
+	cfg := testDumpConfig{
+		Name:    "api",
+		Timeout: 5 * time.Second,
+		Size:    2 * 1024 * 1024,
+		Hosts:   []string{"a", "b"},
+		Labels:  map[string]string{"env": "dev", "team": "core"},
+		Debug:   &debug,
+		DB:      testDumpDB{Host: "db.local", Port: 5432},
+	}
+
+	tests := []struct {
+		format    Format
+		formatter Formatter
+		want      string
+	}{
+		{format: FormatJSON, formatter: NewJSONFormatter()},
+		{format: FormatYAML, formatter: NewYAMLFormatter()},
+		{
+			format:    FormatEnv,
+			formatter: NewEnvFormatter(),
+			want: "DB_HOST=db.local\nDB_PORT=5432\nDEBUG=true\nHOSTS=a,b\nLABELS=env:dev,team:core\n" +
+				"NAME=api\nSIZE=2MiB\nTIMEOUT=5s\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			t.Parallel()
+
+			cm, err := NewConfigManagerFor[testDumpConfig]()
+			if err != nil {
+				t.Fatalf("NewConfigManagerFor() error = %v", err)
+			}
+			var buf bytes.Buffer
+			if err := cm.Dump(&buf, tt.format); !errors.Is(err, ErrConfigNotLoaded) {
+				t.Errorf("Dump() before load error = %v, want %v", err, ErrConfigNotLoaded)
+			}
+			cm.current.Store(&configSnapshot{config: &cfg, version: 1})
+
+			if err := cm.Dump(&buf, tt.format); err != nil {
+				t.Fatalf("Dump() error = %v", err)
+			}
+			if tt.want != "" && buf.String() != tt.want {
+				t.Errorf("Dump() =\n%s\nwant\n%s", buf.String(), tt.want)
+			}
+			var got testDumpConfig
+			if err := tt.formatter.Unmarshal(buf.Bytes(), &got); err != nil {
+				t.Fatalf("Unmarshal() of dumped config error = %v\n%s", err, buf.String())
+			}
+			if !reflect.DeepEqual(got, cfg) {
+				t.Errorf("Unmarshal() of dumped config = %+v, want %+v", got, cfg)
+			}
+		})
+	}
+
+	cm, err := NewConfigManagerFor[testDumpConfig]()
+	if err != nil {
+		t.Fatalf("NewConfigManagerFor() error = %v", err)
+	}
+	cm.current.Store(&configSnapshot{config: &cfg, version: 1})
+	if err := cm.Dump(&bytes.Buffer{}, "toml"); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("Dump() error = %v, want %v", err, ErrUnknownFormat)
+	}
+}
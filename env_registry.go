@@ -0,0 +1,177 @@
+package confgo
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// envBinding is a config field bound to an environment variable.
+type envBinding struct {
+	typ  reflect.Type
+	path string
+}
+
+// envBindings returns dotted paths of the fields of the struct type bound to every env variable,
+// honoring "envPrefix" tags of nested structs. The second returned value lists env variables
+// in the order of their first appearance.
+func envBindings(typ reflect.Type) (map[string][]string, []string) {
+	fieldsByEnv := make(map[string][]string)
+	envs := make([]string, 0)
+	walkEnvFields(typ, defaultEnvNames, "", "", func(env, path string) {
+		if _, ok := fieldsByEnv[env]; !ok {
+			envs = append(envs, env)
+		}
+		fieldsByEnv[env] = append(fieldsByEnv[env], path)
+	})
+	return fieldsByEnv, envs
+}
+
+func walkEnvFields(typ reflect.Type, names envNames, pathPrefix, envPrefix string, fn func(env, path string)) {
+	walkEnvFieldsVisiting(typ, names, pathPrefix, envPrefix, make(map[reflect.Type]bool), fn)
+}
+
+// walkEnvFieldsVisiting is walkEnvFields skipping the struct types being visited,
+// so the fields of recursive types are walked at their first level only.
+func walkEnvFieldsVisiting(
+	typ reflect.Type,
+	names envNames,
+	pathPrefix, envPrefix string,
+	visiting map[reflect.Type]bool,
+	fn func(env, path string),
+) {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct || visiting[typ] {
+		return
+	}
+	visiting[typ] = true
+	defer delete(visiting, typ)
+	for i := range typ.NumField() {
+		sf := typ.Field(i)
+		key, ok := fieldKey(sf)
+		if !ok {
+			continue
+		}
+		path := joinPath(pathPrefix, key)
+		fieldType := sf.Type
+		for fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		if fieldType.Kind() == reflect.Struct && !isLeafStruct(fieldType) {
+			walkEnvFieldsVisiting(fieldType, names, path, envPrefix+names.prefix(sf, key), visiting, fn)
+			continue
+		}
+		if env := names.name(sf, key); env != "" {
+			fn(envPrefix+env, path)
+		}
+	}
+}
+
+// envNames defines how fields are bound to env variables: by the names in their tags
+// and, if naming is set, the fields without the tags by the names derived from their keys.
+// If separator is set, the nested structs without "envPrefix" tags are prefixed with their
+// derived names followed by the separator.
+type envNames struct {
+	tag       string
+	naming    EnvNaming
+	separator string
+}
+
+var defaultEnvNames = envNames{tag: "env", naming: nil, separator: ""}
+
+// name returns the name of the env variable of the field with the key, or "" if the field is not bound.
+func (n envNames) name(sf reflect.StructField, key string) string {
+	if env, _, _ := strings.Cut(sf.Tag.Get(n.tag), ","); env != "" {
+		return env
+	}
+	if n.naming != nil {
+		return n.naming(key)
+	}
+	return ""
+}
+
+// prefix returns the prefix of the env variables of the fields of the nested struct field with the key.
+func (n envNames) prefix(sf reflect.StructField, key string) string {
+	if prefix, ok := sf.Tag.Lookup("envPrefix"); ok || n.separator == "" || n.naming == nil {
+		return prefix
+	}
+	return n.naming(key) + n.separator
+}
+
+// EnvRegistry tracks env variables bound by the config types of several managers running in one process.
+//
+// Two config types binding the same env variable to fields with different paths are usually a mistake:
+// changing the variable for one config silently changes the other one. Fields with the same path,
+// e.g. "db.host" of two services configs, are considered the same setting and are not reported.
+type EnvRegistry struct {
+	mu       sync.Mutex
+	bindings map[string][]envBinding
+}
+
+func NewEnvRegistry() *EnvRegistry {
+	return &EnvRegistry{
+		mu:       sync.Mutex{},
+		bindings: make(map[string][]envBinding),
+	}
+}
+
+// Register adds env variables bound by the type of cfg to the registry. If some of them are already bound
+// by another config type to a field with a different path, nothing is added and ErrEnvCollision is returned.
+// Registering the same type more than once is a no-op.
+func (r *EnvRegistry) Register(cfg any) error {
+	typ := reflect.TypeOf(cfg)
+	fieldsByEnv, envs := envBindings(typ)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	collisions := make([]string, 0)
+	for _, env := range envs {
+		for _, b := range r.bindings[env] {
+			if b.typ == typ {
+				continue
+			}
+			for _, path := range fieldsByEnv[env] {
+				if path != b.path {
+					collisions = append(collisions,
+						fmt.Sprintf("env %q is bound to %s.%s and %s.%s", env, b.typ, b.path, typ, path))
+				}
+			}
+		}
+	}
+	if len(collisions) > 0 {
+		return fmt.Errorf("%w: %s", ErrEnvCollision, strings.Join(collisions, "; "))
+	}
+
+	for _, env := range envs {
+		if r.registered(env, typ) {
+			continue
+		}
+		for _, path := range fieldsByEnv[env] {
+			r.bindings[env] = append(r.bindings[env], envBinding{typ: typ, path: path})
+		}
+	}
+	return nil
+}
+
+func (r *EnvRegistry) registered(env string, typ reflect.Type) bool {
+	for _, b := range r.bindings[env] {
+		if b.typ == typ {
+			return true
+		}
+	}
+	return false
+}
+
+// WithEnvRegistry registers the config type of the manager in the registry,
+// so that the manager fails to be created if its env variables collide with another config type.
+func WithEnvRegistry(r *EnvRegistry) Option {
+	return func(cm *ConfigManager) error {
+		if err := cm.validateConstructor(); err != nil {
+			return fmt.Errorf("validate constructor: %w", err)
+		}
+		return r.Register(cm.constructor())
+	}
+}
@@ -0,0 +1,107 @@
+package confgo
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type testEnvDBConfig struct {
+	Host string `json:"host" env:"HOST"`
+	Port int    `json:"port" env:"PORT"`
+}
+
+type testEnvServiceConfig struct {
+	Port int             `json:"port" env:"PORT"`
+	DB   testEnvDBConfig `json:"db" envPrefix:"DB_"`
+}
+
+type testEnvWorkerConfig struct {
+	DB      testEnvDBConfig `json:"db" envPrefix:"DB_"`
+	Workers int             `json:"workers" env:"WORKERS"`
+}
+
+type testEnvCollidingConfig struct {
+	ListenPort int `json:"listen_port" env:"PORT"`
+}
+
+func Test_envBindings(t *testing.T) {
+	t.Parallel()
+
+	fieldsByEnv, envs := envBindings(reflect.TypeFor[testEnvServiceConfig]())
+	wantFields := map[string][]string{
+		"PORT":    {"port"},
+		"DB_HOST": {"db.host"},
+		"DB_PORT": {"db.port"},
+	}
+	if !reflect.DeepEqual(fieldsByEnv, wantFields) {
+		t.Errorf("envBindings() fields = %v, want %v", fieldsByEnv, wantFields)
+	}
+	if want := []string{"PORT", "DB_HOST", "DB_PORT"}; !reflect.DeepEqual(envs, want) {
+		t.Errorf("envBindings() envs = %v, want %v", envs, want)
+	}
+}
+
+func TestEnvRegistry_Register(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		configs []any
+		wantErr error
+	}{
+		{
+			name:    "single config",
+			configs: []any{&testEnvServiceConfig{}},
+			wantErr: nil,
+		},
+		{
+			name:    "same config type twice",
+			configs: []any{&testEnvServiceConfig{}, &testEnvServiceConfig{}},
+			wantErr: nil,
+		},
+		{
+			name:    "shared env bound to the same path",
+			configs: []any{&testEnvServiceConfig{}, &testEnvWorkerConfig{}},
+			wantErr: nil,
+		},
+		{
+			name:    "shared env bound to different paths",
+			configs: []any{&testEnvServiceConfig{}, &testEnvWorkerConfig{}, &testEnvCollidingConfig{}},
+			wantErr: ErrEnvCollision,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := NewEnvRegistry()
+			var err error
+			for _, cfg := range tt.configs {
+				if err = r.Register(cfg); err != nil {
+					break
+				}
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Register() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWithEnvRegistry(t *testing.T) {
+	t.Parallel()
+
+	r := NewEnvRegistry()
+	if _, err := NewConfigManagerFor[testEnvServiceConfig](WithEnvRegistry(r), WithEnv); err != nil {
+		t.Fatalf("NewConfigManagerFor() error = %v", err)
+	}
+	_, err := NewConfigManagerFor[testEnvCollidingConfig](WithEnvRegistry(r), WithEnv)
+	if !errors.Is(err, ErrEnvCollision) {
+		t.Fatalf("NewConfigManagerFor() error = %v, want %v", err, ErrEnvCollision)
+	}
+	// The rejected type is not registered.
+	if err := r.Register(&testEnvCollidingConfig{}); !errors.Is(err, ErrEnvCollision) {
+		t.Errorf("Register() error = %v, want %v", err, ErrEnvCollision)
+	}
+}
@@ -5,9 +5,40 @@ import "errors"
 var (
 	ErrSourceIsNil                     = errors.New("source is nil")
 	ErrFormatterIsNil                  = errors.New("formatter is nil")
+	ErrTransformerIsNil                = errors.New("transformer is nil")
 	ErrConstructorIsNil                = errors.New("constructor is nil")
 	ErrValidatorIsNil                  = errors.New("validator is nil")
 	ErrConstructorMustBePointer        = errors.New("constructor must be a pointer to a struct")
 	ErrConstructorMustReturnZeroStruct = errors.New("constructor must return zero (empty) struct")
 	ErrNoLoadersDefined                = errors.New("no loaders defined")
+	ErrConfigNotLoaded                 = errors.New("config is not loaded yet")
+	ErrNotRunning                      = errors.New("config manager is not running")
+	ErrUnexpectedStatus                = errors.New("unexpected response status")
+	ErrCertificateNotLoaded            = errors.New("certificate is not loaded")
+	ErrInvalidFieldPath                = errors.New("invalid field path")
+	ErrInvalidFieldValue               = errors.New("invalid field value")
+	ErrConfigTypeMismatch              = errors.New("config type mismatch")
+	ErrUnknownFact                     = errors.New("unknown fact")
+	ErrDuplicateLoader                 = errors.New("duplicate loader")
+	ErrLoaderNotFound                  = errors.New("loader not found")
+	ErrEnvCollision                    = errors.New("env variable collision")
+	ErrUnknownFormat                   = errors.New("unknown format")
+	ErrHandedOff                       = errors.New("config manager is handed off")
+	ErrInvalidHandOffTarget            = errors.New("invalid hand off target")
+	ErrInvalidRuntimeSetting           = errors.New("invalid runtime setting")
+	ErrInvalidDotenv                   = errors.New("invalid dotenv data")
+	ErrInvalidJSONC                    = errors.New("invalid jsonc data")
+	ErrUnknownGroup                    = errors.New("unknown field group")
+	ErrInvalidEnvExpansion             = errors.New("invalid env expansion")
+	ErrSecretResolverIsNil             = errors.New("secret resolver is nil")
+	ErrInvalidSecretRef                = errors.New("invalid secret reference")
+	ErrSecretNotFound                  = errors.New("secret not found")
+	ErrDuplicateExpvar                 = errors.New("expvar variable is already published")
+	ErrInvalidMergeTag                 = errors.New("invalid merge tag")
+	ErrPanic                           = errors.New("panic")
+	ErrInvalidQueryResult              = errors.New("invalid query result")
+	ErrInvalidPatch                    = errors.New("invalid merge patch")
+	ErrConfigFileNotFound              = errors.New("config file not found")
+	ErrInvalidInclude                  = errors.New("invalid include directive")
+	ErrIncludeCycle                    = errors.New("include cycle")
 )
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/TheVovchenskiy/confgo"
+)
+
+type ServerConfig struct {
+	Addr         string `json:"addr"`
+	ReadTimeout  string `json:"read_timeout"`
+	WriteTimeout string `json:"write_timeout"`
+}
+
+type Config struct {
+	Server ServerConfig `json:"server"`
+}
+
+func serverSettings(cfg any) confgo.HTTPServerSettings {
+	server := cfg.(*Config).Server
+	readTimeout, _ := time.ParseDuration(server.ReadTimeout)
+	writeTimeout, _ := time.ParseDuration(server.WriteTimeout)
+	return confgo.HTTPServerSettings{
+		Addr:         server.Addr,
+		ReadTimeout:  readTimeout,
+		WriteTimeout: writeTimeout,
+	}
+}
+
+func main() {
+	cm, err := confgo.NewConfigManagerFor[Config](confgo.WithDynamicJSONFile("examples/httpserver/config.json",
+		func() {
+			fmt.Printf("Config has been updated!\n")
+		},
+		func(err error) {
+			fmt.Printf("Error while updating config: %v\n", err)
+		}))
+	if err != nil {
+		panic(err)
+	}
+	cm.MustStart()
+	defer cm.MustStop()
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprintf(w, "Hello from %s\n", cm.Config().(*Config).Server.Addr)
+	})
+	server := confgo.NewHTTPServer(cm, handler, serverSettings, func(err error) {
+		fmt.Printf("HTTP server error: %v\n", err)
+	})
+	if err := server.Start(); err != nil {
+		panic(err)
+	}
+	fmt.Printf("Listening on %s, change the address in config.json to move the server\n", server.Addr())
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	<-ctx.Done()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		fmt.Printf("Error while shutting down: %v\n", err)
+	}
+}
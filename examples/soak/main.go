@@ -0,0 +1,246 @@
+// Soak runs a config manager under continuous churn and checks its invariants, which makes it both
+// a reliability demonstration and a regression harness:
+//   - the config file is rewritten with an increasing sequence number, sometimes with invalid content;
+//   - the environment layer is flipped between values;
+//   - the file source fails at random;
+//   - readers and subscribers access the configuration concurrently.
+//
+// It checks that subscribers observe sequence numbers monotonically, that the last known good configuration
+// is retained after every failed reload, and that no goroutines are leaked. It exits with status 1 on the first
+// violated invariant.
+//
+// Usage: go run ./examples/soak -duration 1m
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/TheVovchenskiy/confgo"
+)
+
+const modeEnv = "SOAK_MODE"
+
+type Config struct {
+	Seq  int    `json:"seq"`
+	Mode string `json:"mode" env:"SOAK_MODE"`
+}
+
+var errInjected = errors.New("injected source failure")
+
+// flakySource fails to read with the given probability.
+type flakySource struct {
+	source      confgo.Source
+	failureRate float64
+	failed      atomic.Bool
+}
+
+func (s *flakySource) Read() ([]byte, error) {
+	if rand.Float64() < s.failureRate {
+		s.failed.Store(true)
+		return nil, errInjected
+	}
+	s.failed.Store(false)
+	return s.source.Read()
+}
+
+type stats struct {
+	reloads  int
+	failures int
+	reads    atomic.Int64
+}
+
+func main() {
+	duration := flag.Duration("duration", 30*time.Second, "how long to run")
+	readers := flag.Int("readers", 8, "number of concurrent readers")
+	failureRate := flag.Float64("failure-rate", 0.1, "probability of an injected source failure")
+	invalidRate := flag.Float64("invalid-rate", 0.1, "probability of writing an invalid config file")
+	flag.Parse()
+
+	if err := run(*duration, *readers, *failureRate, *invalidRate); err != nil {
+		fmt.Fprintf(os.Stderr, "FAIL: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println("PASS")
+}
+
+//nolint:cyclop,funlen
+func run(duration time.Duration, readers int, failureRate, invalidRate float64) error {
+	dir, err := os.MkdirTemp("", "confgo-soak")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "config.json")
+	if err := writeConfig(file, 0, false); err != nil {
+		return err
+	}
+	os.Setenv(modeEnv, "blue")
+	defer os.Unsetenv(modeEnv)
+
+	goroutinesBefore := runtime.NumGoroutine()
+
+	source := &flakySource{source: confgo.NewFileSource(file), failureRate: 0}
+	watcher := confgo.NewTriggerWatcher()
+	reloadErrs := make(chan error, 1)
+	cm, err := confgo.NewConfigManagerFor[Config](
+		func(cm *confgo.ConfigManager) error {
+			cm.AddLoader(confgo.Loader{
+				Source:          source,
+				Formatter:       confgo.NewJSONFormatter(),
+				Watcher:         watcher,
+				OnUpdateSuccess: func() { reloadErrs <- nil },
+				OnUpdateError:   func(err error) { reloadErrs <- err },
+			})
+			return nil
+		},
+		confgo.WithEnv,
+	)
+	if err != nil {
+		return err
+	}
+	if err := cm.Start(); err != nil {
+		return err
+	}
+	source.failureRate = failureRate
+
+	// violations collects invariant violations found by concurrent checkers.
+	violations := make(chan error, 1)
+	violate := func(err error) {
+		select {
+		case violations <- err:
+		default:
+		}
+	}
+
+	lastSeq := -1
+	var seqMu sync.Mutex
+	unsubscribe := cm.Subscribe(func(_, newCfg any) {
+		seqMu.Lock()
+		defer seqMu.Unlock()
+		seq := newCfg.(*Config).Seq
+		if seq < lastSeq {
+			violate(fmt.Errorf("subscriber observed sequence %d after %d", seq, lastSeq))
+		}
+		lastSeq = seq
+	})
+	changes, unsubscribeChan := cm.SubscribeChan(confgo.ChannelBuffer(4))
+
+	ctx, cancel := context.WithTimeout(context.Background(), duration)
+	defer cancel()
+	var st stats
+	var wg sync.WaitGroup
+	for range readers {
+		wg.Go(func() {
+			for ctx.Err() == nil {
+				if cfg, ok := cm.Config().(*Config); !ok || cfg == nil {
+					violate(errors.New("reader observed no configuration"))
+				}
+				st.reads.Add(1)
+			}
+		})
+	}
+	wg.Go(func() {
+		for range changes {
+			// Drained until the channel is closed, slow on purpose to exercise the overflow policy.
+			time.Sleep(time.Millisecond)
+		}
+	})
+
+	good := *cm.Config().(*Config)
+	report := time.NewTicker(5 * time.Second)
+	defer report.Stop()
+	for seq := 1; ctx.Err() == nil; seq++ {
+		select {
+		case err := <-violations:
+			return err
+		case <-report.C:
+			s := cm.Stats()
+			fmt.Printf("reloads: %d, failures: %d, reads: %d, channel delivered: %d, dropped: %d\n",
+				st.reloads, st.failures, st.reads.Load(), s.ChannelDelivered, s.ChannelDropped)
+		default:
+		}
+
+		invalid := rand.Float64() < invalidRate
+		if err := writeConfig(file, seq, invalid); err != nil {
+			return err
+		}
+		mode := []string{"blue", "green"}[seq%2]
+		os.Setenv(modeEnv, mode)
+
+		watcher.Trigger()
+		reloadErr := <-reloadErrs
+		st.reloads++
+		got := *cm.Config().(*Config)
+		switch {
+		case reloadErr != nil:
+			st.failures++
+			if !invalid && !source.failed.Load() {
+				return fmt.Errorf("unexpected reload error: %w", reloadErr)
+			}
+			if got != good {
+				return fmt.Errorf("last known good config %+v is not retained after failure, got %+v", good, got)
+			}
+		case invalid || source.failed.Load():
+			return fmt.Errorf("reload of an invalid config succeeded: %+v", got)
+		default:
+			want := Config{Seq: seq, Mode: mode}
+			if got != want {
+				return fmt.Errorf("config after reload = %+v, want %+v", got, want)
+			}
+			good = got
+		}
+	}
+
+	cancel()
+	unsubscribe()
+	unsubscribeChan()
+	wg.Wait()
+	if err := cm.Stop(); err != nil {
+		return err
+	}
+	select {
+	case err := <-violations:
+		return err
+	default:
+	}
+	if s := cm.Stats(); s.Subscribers != 0 || s.ChannelSubscribers != 0 {
+		return fmt.Errorf("subscriptions leaked: %+v", s)
+	}
+	// Give stopped goroutines a moment to exit.
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > goroutinesBefore && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := runtime.NumGoroutine(); n > goroutinesBefore {
+		return fmt.Errorf("goroutines leaked: %d before start, %d after stop", goroutinesBefore, n)
+	}
+	fmt.Printf("reloads: %d, failures: %d, reads: %d\n", st.reloads, st.failures, st.reads.Load())
+	return nil
+}
+
+// writeConfig atomically replaces the config file, so the manager never reads it half-written.
+func writeConfig(file string, seq int, invalid bool) error {
+	data, err := json.Marshal(map[string]any{"seq": seq})
+	if err != nil {
+		return err
+	}
+	if invalid {
+		data = data[:len(data)-1]
+	}
+	tmp := file + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, file)
+}
@@ -0,0 +1,110 @@
+package confgo
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"time"
+)
+
+const (
+	execTimeout = 30 * time.Second
+	// execWaitDelay limits waiting for the output of the processes started by a killed command.
+	execWaitDelay = time.Second
+	// execStderrLimit is the maximum number of bytes of stderr included in errors.
+	execStderrLimit = 1024
+)
+
+// ExecSourceOption configures ExecSource.
+type ExecSourceOption func(es *ExecSource)
+
+// ExecTimeout sets how long the command may run before it is killed. Zero disables the timeout.
+// Defaults to 30 seconds.
+func ExecTimeout(timeout time.Duration) ExecSourceOption {
+	return func(es *ExecSource) {
+		es.timeout = timeout
+	}
+}
+
+// ExecEnv sets the environment of the command in the "KEY=value" form instead of the environment
+// of the process, e.g. append(os.Environ(), "PROFILE=prod") to extend it.
+func ExecEnv(env ...string) ExecSourceOption {
+	return func(es *ExecSource) {
+		es.env = append(make([]string, 0, len(env)), env...)
+	}
+}
+
+// ExecDir sets the working directory of the command. Defaults to the working directory of the process.
+func ExecDir(dir string) ExecSourceOption {
+	return func(es *ExecSource) {
+		es.dir = dir
+	}
+}
+
+var (
+	_ Source        = (*ExecSource)(nil)
+	_ SourceContext = (*ExecSource)(nil)
+)
+
+// ExecSource is a configuration source that runs a command and reads its stdout, e.g. an external
+// secret fetcher or a config generator. The command is run on every read and fails the read
+// if it exits with a non-zero status, the error includes the beginning of its stderr.
+type ExecSource struct {
+	name    string
+	args    []string
+	timeout time.Duration
+	env     []string
+	dir     string
+}
+
+// NewExecSource creates a source running the command name with args, e.g.
+// NewExecSource("vault-fetch", []string{"--format=json", "myapp"}).
+func NewExecSource(name string, args []string, opts ...ExecSourceOption) *ExecSource {
+	es := &ExecSource{
+		name:    name,
+		args:    append(make([]string, 0, len(args)), args...),
+		timeout: execTimeout,
+		env:     nil,
+		dir:     "",
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(es)
+		}
+	}
+	return es
+}
+
+func (es *ExecSource) Read() ([]byte, error) {
+	return es.ReadContext(context.Background())
+}
+
+// ReadContext runs the command until it exits or ctx is done and returns its stdout.
+func (es *ExecSource) ReadContext(ctx context.Context) ([]byte, error) {
+	if es.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, es.timeout)
+		defer cancel()
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, es.name, es.args...)
+	cmd.Env = es.env
+	cmd.Dir = es.dir
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	cmd.WaitDelay = execWaitDelay
+	if err := cmd.Run(); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			err = fmt.Errorf("%w: %w", ctxErr, err)
+		}
+		if msg := bytes.TrimSpace(stderr.Bytes()); len(msg) > 0 {
+			if len(msg) > execStderrLimit {
+				msg = msg[:execStderrLimit]
+			}
+			return nil, fmt.Errorf("run command %q: %w: %s", es.name, err, msg)
+		}
+		return nil, fmt.Errorf("run command %q: %w", es.name, err)
+	}
+	return stdout.Bytes(), nil
+}
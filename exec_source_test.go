@@ -0,0 +1,102 @@
+package confgo
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExecSource_Read(t *testing.T) {
+	t.Parallel()
+
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not available")
+	}
+	tests := []struct {
+		name    string
+		script  string
+		opts    []ExecSourceOption
+		want    string
+		wantErr string
+	}{
+		{
+			name:   "stdout",
+			script: `echo '{"int": 1}'; echo warning >&2`,
+			want:   "{\"int\": 1}\n",
+		},
+		{
+			name:   "env",
+			script: `echo "$PROFILE"`,
+			opts:   []ExecSourceOption{ExecEnv("PROFILE=prod")},
+			want:   "prod\n",
+		},
+		{
+			name:   "dir",
+			script: `pwd`,
+			opts:   []ExecSourceOption{ExecDir("/")},
+			want:   "/\n",
+		},
+		{
+			name:    "failure",
+			script:  `echo out; echo 'no such secret' >&2; exit 3`,
+			wantErr: "exit status 3: no such secret",
+		},
+		{
+			name:    "timeout",
+			script:  `sleep 5`,
+			opts:    []ExecSourceOption{ExecTimeout(50 * time.Millisecond)},
+			wantErr: context.DeadlineExceeded.Error(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := NewExecSource("sh", []string{"-c", tt.script}, tt.opts...).Read()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Read() error = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Read() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Read() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigManager_WithExec(t *testing.T) {
+	t.Parallel()
+
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not available")
+	}
+	cm, err := NewConfigManager(testConfigConstructor,
+		WithExec("sh", []string{"-c", `echo '{"int": 1}'`}, NewJSONFormatter()),
+	)
+	if err != nil {
+		t.Fatalf("NewConfigManager() error = %v", err)
+	}
+	if err := cm.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer cm.MustStop()
+
+	if got, want := cm.Config(), (&TestConfig{Int: 1}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Config() = %+v, want %+v", got, want)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := NewExecSource("sh", []string{"-c", "true"}).ReadContext(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("ReadContext() error = %v, want %v", err, context.Canceled)
+	}
+}
@@ -0,0 +1,64 @@
+package confgo
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// envVarName matches the names of variables which may be expanded.
+var envVarName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// expandEnv replaces "${VAR}" in data with the value of the variable VAR and "${VAR:-default}"
+// with the value of VAR or default if VAR is unset or empty. Unset variables without a default are replaced
+// with an empty string, and "$${" is replaced with a literal "${". Other "$" characters are kept as is,
+// so values like passwords containing "$" need no escaping.
+//
+// The substitution is textual and happens before unmarshaling, so a variable may provide a value of any type,
+// e.g. `"port": ${PORT}` in JSON, but values containing quotes must be quoted by the variable itself.
+func expandEnv(data []byte, lookup func(string) (string, bool)) ([]byte, error) {
+	if !bytes.Contains(data, []byte("${")) {
+		return data, nil
+	}
+	res := make([]byte, 0, len(data))
+	line := 1
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		if c == '\n' {
+			line++
+		}
+		if c != '$' || i+1 >= len(data) {
+			res = append(res, c)
+			continue
+		}
+		if bytes.HasPrefix(data[i+1:], []byte("${")) {
+			res = append(res, "${"...)
+			i += 2
+			continue
+		}
+		if data[i+1] != '{' {
+			res = append(res, c)
+			continue
+		}
+		end := bytes.IndexByte(data[i+2:], '}')
+		if end < 0 {
+			return nil, fmt.Errorf("%w: unterminated variable at line %d", ErrInvalidEnvExpansion, line)
+		}
+		expr := string(data[i+2 : i+2+end])
+		name, def, hasDefault := expr, "", false
+		if before, after, ok := strings.Cut(expr, ":-"); ok {
+			name, def, hasDefault = before, after, true
+		}
+		if !envVarName.MatchString(name) {
+			return nil, fmt.Errorf("%w: invalid variable name %q at line %d", ErrInvalidEnvExpansion, name, line)
+		}
+		value, ok := lookup(name)
+		if hasDefault && (!ok || value == "") {
+			value = def
+		}
+		res = append(res, value...)
+		i += 2 + end
+	}
+	return res, nil
+}
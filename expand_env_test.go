@@ -0,0 +1,92 @@
+package confgo
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func Test_expandEnv(t *testing.T) {
+	t.Parallel()
+
+	lookup := func(name string) (string, bool) {
+		value, ok := map[string]string{"HOST": "db.local", "PORT": "5432", "EMPTY": ""}[name]
+		return value, ok
+	}
+	tests := []struct {
+		name    string
+		data    string
+		want    string
+		wantErr error
+	}{
+		{name: "no variables", data: `{"host": "localhost"}`, want: `{"host": "localhost"}`},
+		{name: "variable", data: `{"host": "${HOST}", "port": ${PORT}}`, want: `{"host": "db.local", "port": 5432}`},
+		{name: "unset", data: `host: "${MISSING}"`, want: `host: ""`},
+		{name: "default of unset", data: `host: ${MISSING:-localhost}`, want: `host: localhost`},
+		{name: "default of empty", data: `host: ${EMPTY:-localhost}`, want: `host: localhost`},
+		{name: "default of set", data: `host: ${HOST:-localhost}`, want: `host: db.local`},
+		{name: "empty default", data: `host: ${MISSING:-}`, want: `host: `},
+		{name: "escaped", data: `template: $${HOST}`, want: `template: ${HOST}`},
+		{name: "plain dollar", data: `password: pa$$w0rd$`, want: `password: pa$$w0rd$`},
+		{name: "unterminated", data: "a: 1\nhost: ${HOST", wantErr: ErrInvalidEnvExpansion},
+		{name: "invalid name", data: `host: ${1HOST}`, wantErr: ErrInvalidEnvExpansion},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := expandEnv([]byte(tt.data), lookup)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expandEnv() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && string(got) != tt.want {
+				t.Errorf("expandEnv() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatters_ExpandEnv(t *testing.T) {
+	t.Setenv("CONFGO_TEST_HOST", "db.local")
+	t.Setenv("CONFGO_TEST_PORT", "5432")
+
+	type config struct {
+		Host string `json:"host" yaml:"host"`
+		Port int    `json:"port" yaml:"port"`
+		User string `json:"user" yaml:"user"`
+	}
+	want := config{Host: "db.local", Port: 5432, User: "admin"}
+
+	tests := []struct {
+		name      string
+		formatter Formatter
+		data      string
+	}{
+		{
+			name:      "json",
+			formatter: NewJSONFormatter(JSONExpandEnv),
+			data:      `{"host": "${CONFGO_TEST_HOST}", "port": ${CONFGO_TEST_PORT}, "user": "${CONFGO_TEST_USER:-admin}"}`,
+		},
+		{
+			name:      "jsonc",
+			formatter: NewJSONCFormatter(JSONExpandEnv),
+			data:      `{"host": "${CONFGO_TEST_HOST}", /* port */ "port": ${CONFGO_TEST_PORT}, "user": "${CONFGO_TEST_USER:-admin}",}`,
+		},
+		{
+			name:      "yaml",
+			formatter: NewYAMLFormatter(YAMLExpandEnv),
+			data:      "host: ${CONFGO_TEST_HOST}\nport: ${CONFGO_TEST_PORT}\nuser: ${CONFGO_TEST_USER:-admin}\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got config
+			if err := tt.formatter.Unmarshal([]byte(tt.data), &got); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Unmarshal() = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package confgo
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+)
+
+// FieldExplanation describes the effective value of a config field and where it comes from.
+type FieldExplanation struct {
+	// Path is the dotted path of the field, e.g. "db.host".
+	Path string `json:"path"`
+	// Value is the effective value of the field, "[REDACTED]" for secret fields.
+	// Nil sections are reported as fields with nil values.
+	Value any `json:"value"`
+	// Source is the source of the value as reported by Provenance, empty if no source has set the field.
+	Source string `json:"source"`
+	// Default is true if the value is a default one.
+	Default bool `json:"default"`
+	// Overridden is true if the value has been set at runtime with SetConfig or SetOverride.
+	Overridden bool `json:"overridden"`
+	// Actor and Reason are the change metadata passed to SetConfig or SetOverride with the overridden value.
+	Actor  string `json:"actor,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// Explanation is a report of every leaf field of a configuration in the order of declaration.
+// It is encoded as a JSON array and its String method returns a table, so it may be attached to support tickets.
+type Explanation []FieldExplanation
+
+// Explain returns the report of every leaf field of the current configuration: its value, the loader
+// which has supplied it and whether it is a default or overridden one, along with who has overridden it and why.
+// It returns nil if no configuration is loaded.
+func (cm *ConfigManager) Explain() Explanation {
+	if next := cm.handedOff.Load(); next != nil {
+		return next.Explain()
+	}
+	cm.mu.RLock()
+	cfg, _ := cm.loadCurrent()
+	provenance, provenanceMeta := cm.provenance, cm.provenanceMeta
+	cm.mu.RUnlock()
+	if cfg == nil {
+		return nil
+	}
+	explanation := make(Explanation, 0)
+	explainFields(reflect.ValueOf(cfg).Elem(), "", false, func(path string, value any) {
+		source := sourceOf(provenance, path)
+		var meta ChangeMeta
+		if source == ProvenanceOverride {
+			meta = sourceOf(provenanceMeta, path)
+		}
+		explanation = append(explanation, FieldExplanation{
+			Path:       path,
+			Value:      value,
+			Source:     source,
+			Default:    source == ProvenanceDefault,
+			Overridden: source == ProvenanceOverride,
+			Actor:      meta.Actor,
+			Reason:     meta.Reason,
+		})
+	})
+	return explanation
+}
+
+func (e Explanation) String() string {
+	var sb strings.Builder
+	w := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "PATH\tVALUE\tSOURCE\tDEFAULT\tOVERRIDDEN\tACTOR\tREASON")
+	for _, f := range e {
+		_, _ = fmt.Fprintf(w, "%s\t%v\t%s\t%t\t%t\t%s\t%s\n",
+			f.Path, f.Value, orDash(f.Source), f.Default, f.Overridden, orDash(f.Actor), orDash(f.Reason))
+	}
+	_ = w.Flush()
+	return sb.String()
+}
+
+// orDash returns s or "-" if it is empty, so empty cells of the table stay visible.
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// explainFields calls fn for every leaf field of the struct value v, recursing into nested structs.
+// Nil sections are reported as leaf fields, so recursive types are walked only as deep as they are set.
+func explainFields(v reflect.Value, prefix string, secret bool, fn func(path string, value any)) {
+	for i := range v.NumField() {
+		sf := v.Type().Field(i)
+		key, ok := fieldKey(sf)
+		if !ok {
+			continue
+		}
+		path := joinPath(prefix, key)
+		fieldSecret := secret || isSecretField(sf)
+		field := v.Field(i)
+		for field.Kind() == reflect.Ptr && !field.IsNil() {
+			field = field.Elem()
+		}
+		if field.Kind() == reflect.Struct && !isLeafStruct(field.Type()) {
+			explainFields(field, path, fieldSecret, fn)
+			continue
+		}
+		if fieldSecret {
+			fn(path, redactedValue)
+			continue
+		}
+		fn(path, valueInterface(field))
+	}
+}
+
+// sourceOf returns the source of the field at the path or of the closest section containing it.
+func sourceOf[V any](provenance map[string]V, path string) V {
+	for {
+		if source, ok := provenance[path]; ok {
+			return source
+		}
+		i := strings.LastIndex(path, ".")
+		if i < 0 {
+			var zero V
+			return zero
+		}
+		path = path[:i]
+	}
+}
@@ -0,0 +1,131 @@
+package confgo
+
+import (
+	"encoding/json"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestConfigManager_Explain(t *testing.T) {
+	t.Parallel()
+
+	type config struct {
+		Host     string           `json:"host" default:"localhost"`
+		Port     int              `json:"port" default:"8080"`
+		Password string           `json:"password" secret:"true"`
+		Inner    testInnerConfig  `json:"inner"`
+		Ptr      *testInnerConfig `json:"ptr"`
+	}
+	source := &fakeSource{data: []byte(`{"port": 9090, "password": "pass", "inner": {"int": 1}}`)}
+	cm, err := NewConfigManagerFor[config](func(cm *ConfigManager) error {
+		cm.AddLoader(Loader{Source: source, Formatter: NewJSONFormatter()})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("NewConfigManagerFor() error = %v", err)
+	}
+	if got := cm.Explain(); got != nil {
+		t.Errorf("Explain() before start = %v, want nil", got)
+	}
+	if err := cm.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer cm.MustStop()
+	if err := cm.SetOverride("inner.string", "set", ChangeMeta{Actor: "alice", Reason: "incident"}); err != nil {
+		t.Fatalf("SetOverride() error = %v", err)
+	}
+
+	loader := "source *confgo.fakeSource"
+	want := Explanation{
+		{Path: "host", Value: "localhost", Source: ProvenanceDefault, Default: true, Overridden: false},
+		{Path: "port", Value: 9090, Source: loader, Default: false, Overridden: false},
+		{Path: "password", Value: redactedValue, Source: loader, Default: false, Overridden: false},
+		{Path: "inner.int", Value: 1, Source: loader, Default: false, Overridden: false},
+		{
+			Path: "inner.string", Value: "set", Source: ProvenanceOverride, Default: false, Overridden: true,
+			Actor: "alice", Reason: "incident",
+		},
+		{Path: "ptr", Value: (*testInnerConfig)(nil), Source: "", Default: false, Overridden: false},
+	}
+	got := cm.Explain()
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Explain() = %+v, want %+v", got, want)
+	}
+
+	table := got.String()
+	for _, line := range []string{
+		"PATH          VALUE       SOURCE                     DEFAULT  OVERRIDDEN  ACTOR  REASON",
+		"host          localhost   default                    true     false       -      -",
+		"inner.string  set         override                   false    true        alice  incident",
+		"ptr           <nil>       -                          false    false       -      -",
+	} {
+		if !strings.Contains(table, line+"\n") {
+			t.Errorf("String() = \n%s\nwant line %q", table, line)
+		}
+	}
+
+	data, err := json.Marshal(got)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	if want := `{"path":"host","value":"localhost","source":"default","default":true,"overridden":false}`; !strings.Contains(string(data), want) {
+		t.Errorf("json.Marshal() = %s, want it to contain %s", data, want)
+	}
+}
+
+func TestConfigManager_Explain_ChangeMeta(t *testing.T) {
+	t.Parallel()
+
+	source := &fakeSource{data: []byte(`{"int": 1}`)}
+	cm, err := NewConfigManager(testConfigConstructor, func(cm *ConfigManager) error {
+		cm.AddLoader(Loader{Source: source, Formatter: NewJSONFormatter()})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("NewConfigManager() error = %v", err)
+	}
+	if err := cm.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer cm.MustStop()
+
+	admin := ChangeMeta{Actor: "admin", Reason: "rollout"}
+	if err := cm.SetConfig(&TestConfig{Int: 2, Inner: testInnerConfig{Int: 5, String: "admin"}, Slice: []string{"a"}}, admin); err != nil {
+		t.Fatalf("SetConfig() error = %v", err)
+	}
+	if err := cm.ApplyPatch([]byte(`{"slice": ["b"]}`)); err != nil {
+		t.Fatalf("ApplyPatch() error = %v", err)
+	}
+	if err := cm.SetOverride("inner.string", "set", ChangeMeta{Actor: "alice", Reason: "incident"}); err != nil {
+		t.Fatalf("SetOverride() error = %v", err)
+	}
+	if err := cm.SetOverride("inner.int", 3, ChangeMeta{Actor: "bob", Reason: "test"}); err != nil {
+		t.Fatalf("SetOverride() error = %v", err)
+	}
+	if err := cm.RemoveOverride("inner.int", ChangeMeta{}); err != nil {
+		t.Fatalf("RemoveOverride() error = %v", err)
+	}
+
+	want := map[string]ChangeMeta{
+		"int":          admin,
+		"inner.int":    admin,
+		"inner.string": {Actor: "alice", Reason: "incident"},
+		"slice":        {},
+	}
+	for _, f := range cm.Explain() {
+		wantMeta, ok := want[f.Path]
+		if !ok {
+			continue
+		}
+		if got := (ChangeMeta{Actor: f.Actor, Reason: f.Reason}); got != wantMeta {
+			t.Errorf("Explain() of %q change meta = %+v, want %+v", f.Path, got, wantMeta)
+		}
+		delete(want, f.Path)
+	}
+	if len(want) != 0 {
+		t.Errorf("Explain() misses fields %v", slices.Collect(maps.Keys(want)))
+	}
+}
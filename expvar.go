@@ -0,0 +1,41 @@
+package confgo
+
+import (
+	"expvar"
+	"fmt"
+	"time"
+)
+
+// expvarStatus is the value published by WithExpvar.
+type expvarStatus struct {
+	Version    uint64    `json:"version"`
+	Reloads    uint64    `json:"reloads"`
+	LastReload time.Time `json:"last_reload"`
+	LastError  string    `json:"last_error"`
+}
+
+// WithExpvar publishes the reload status of the manager via expvar under the name, so it is served
+// by the /debug/vars handler along with the other variables: the config version, the number of reloads,
+// the time and the error of the last reload. Publishing under an already used name is reported
+// with ErrDuplicateExpvar, since expvar variables cannot be unpublished.
+func WithExpvar(name string) Option {
+	return func(cm *ConfigManager) error {
+		if expvar.Get(name) != nil {
+			return fmt.Errorf("%w: %q", ErrDuplicateExpvar, name)
+		}
+		expvar.Publish(name, expvar.Func(func() any {
+			status := cm.ReloadStatus()
+			v := expvarStatus{
+				Version:    status.Version,
+				Reloads:    status.Reloads,
+				LastReload: status.LastReload,
+				LastError:  "",
+			}
+			if status.Err != nil {
+				v.LastError = status.Err.Error()
+			}
+			return v
+		}))
+		return nil
+	}
+}
@@ -0,0 +1,40 @@
+package confgo
+
+import (
+	"encoding/json"
+	"errors"
+	"expvar"
+	"testing"
+)
+
+func TestWithExpvar(t *testing.T) {
+	t.Parallel()
+
+	const name = "confgo_test_with_expvar"
+	source := &fakeSource{data: []byte(`{"int": 1}`)}
+	cm, err := NewConfigManager(testConfigConstructor, WithExpvar(name), func(cm *ConfigManager) error {
+		cm.AddLoader(Loader{Source: source, Formatter: NewJSONFormatter()})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("NewConfigManager() error = %v", err)
+	}
+	if err := cm.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer cm.MustStop()
+	source.err = errors.New("read error")
+	_ = cm.reload()
+
+	var got expvarStatus
+	if err := json.Unmarshal([]byte(expvar.Get(name).String()), &got); err != nil {
+		t.Fatalf("decode expvar error = %v", err)
+	}
+	if got.Version != 1 || got.Reloads != 2 || got.LastError == "" || got.LastReload.IsZero() {
+		t.Errorf("expvar %s = %+v, want version 1, 2 reloads and the last error", name, got)
+	}
+
+	if _, err := NewConfigManager(testConfigConstructor, WithExpvar(name)); !errors.Is(err, ErrDuplicateExpvar) {
+		t.Errorf("NewConfigManager() error = %v, want %v", err, ErrDuplicateExpvar)
+	}
+}
@@ -0,0 +1,284 @@
+package confgo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"os"
+	"path"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	factsTimeout        = 5 * time.Second
+	awsMetadataEndpoint = "http://169.254.169.254"
+	gcpMetadataEndpoint = "http://metadata.google.internal"
+	awsTokenTTLSeconds  = "60"
+	// factsSelectorsKey is the top-level key of a config document holding fact selectors.
+	factsSelectorsKey = "$when"
+)
+
+// FactsProvider provides facts about the node the application runs on, such as hostname or availability zone.
+type FactsProvider interface {
+	// Facts returns a set of named facts.
+	Facts(ctx context.Context) (map[string]string, error)
+}
+
+// FactsProviderFunc is an adapter to use ordinary functions as FactsProvider.
+type FactsProviderFunc func(ctx context.Context) (map[string]string, error)
+
+func (f FactsProviderFunc) Facts(ctx context.Context) (map[string]string, error) {
+	return f(ctx)
+}
+
+// StaticFacts returns a FactsProvider of the given facts.
+func StaticFacts(facts map[string]string) FactsProvider {
+	return FactsProviderFunc(func(context.Context) (map[string]string, error) {
+		return maps.Clone(facts), nil
+	})
+}
+
+// HostFacts returns a FactsProvider of "hostname", "os" and "arch" facts.
+func HostFacts() FactsProvider {
+	return FactsProviderFunc(func(context.Context) (map[string]string, error) {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return nil, fmt.Errorf("get hostname: %w", err)
+		}
+		return map[string]string{
+			"hostname": hostname,
+			"os":       runtime.GOOS,
+			"arch":     runtime.GOARCH,
+		}, nil
+	})
+}
+
+// AWSMetadataFacts returns a FactsProvider of "instance_id", "instance_type", "region" and "zone" facts
+// read from the EC2 instance metadata service (IMDSv2). Empty endpoint means the default one.
+func AWSMetadataFacts(endpoint string) FactsProvider {
+	if endpoint == "" {
+		endpoint = awsMetadataEndpoint
+	}
+	return FactsProviderFunc(func(ctx context.Context) (map[string]string, error) {
+		token, err := metadataRequest(ctx, http.MethodPut, endpoint+"/latest/api/token",
+			map[string]string{"X-Aws-Ec2-Metadata-Token-Ttl-Seconds": awsTokenTTLSeconds})
+		if err != nil {
+			return nil, fmt.Errorf("get aws metadata token: %w", err)
+		}
+		facts := make(map[string]string)
+		for name, p := range map[string]string{
+			"instance_id":   "instance-id",
+			"instance_type": "instance-type",
+			"region":        "placement/region",
+			"zone":          "placement/availability-zone",
+		} {
+			value, err := metadataRequest(ctx, http.MethodGet, endpoint+"/latest/meta-data/"+p,
+				map[string]string{"X-Aws-Ec2-Metadata-Token": token})
+			if err != nil {
+				return nil, fmt.Errorf("get aws metadata %q: %w", p, err)
+			}
+			facts[name] = value
+		}
+		return facts, nil
+	})
+}
+
+// GCPMetadataFacts returns a FactsProvider of "instance_id", "instance_type", "region" and "zone" facts
+// read from the GCE metadata server. Empty endpoint means the default one.
+func GCPMetadataFacts(endpoint string) FactsProvider {
+	if endpoint == "" {
+		endpoint = gcpMetadataEndpoint
+	}
+	return FactsProviderFunc(func(ctx context.Context) (map[string]string, error) {
+		facts := make(map[string]string)
+		for name, p := range map[string]string{
+			"instance_id":   "id",
+			"instance_type": "machine-type",
+			"zone":          "zone",
+		} {
+			value, err := metadataRequest(ctx, http.MethodGet, endpoint+"/computeMetadata/v1/instance/"+p,
+				map[string]string{"Metadata-Flavor": "Google"})
+			if err != nil {
+				return nil, fmt.Errorf("get gcp metadata %q: %w", p, err)
+			}
+			// Zone and machine type are returned as resource paths, e.g. "projects/1/zones/us-central1-a".
+			facts[name] = path.Base(value)
+		}
+		if zone := facts["zone"]; strings.Count(zone, "-") > 1 {
+			facts["region"] = zone[:strings.LastIndex(zone, "-")]
+		}
+		return facts, nil
+	})
+}
+
+func metadataRequest(ctx context.Context, method, url string, headers map[string]string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return "", err
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%w: %s", ErrUnexpectedStatus, resp.Status)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(body)), nil
+}
+
+// WithFacts adds facts providers to the manager. Facts are gathered once, before the initial load,
+// the providers added later override facts of the providers added earlier.
+//
+// Facts are available to every loader. "${fact:name}" placeholders in raw source data are replaced
+// with fact values. A top-level "$when" list of JSON and YAML documents holds selectors: the "set" section
+// of a selector is merged into the document if all facts of its "match" section match
+// (values may be path.Match patterns):
+//
+//	port: 8080
+//	$when:
+//	  - match: {zone: "us-east-1*"}
+//	    set: {port: 8081}
+func WithFacts(providers ...FactsProvider) Option {
+	return func(cm *ConfigManager) error {
+		cm.facts.providers = append(cm.facts.providers, providers...)
+		return nil
+	}
+}
+
+type facts struct {
+	providers []FactsProvider
+	once      sync.Once
+	values    map[string]string
+	err       error
+}
+
+func (f *facts) gather() (map[string]string, error) {
+	f.once.Do(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), factsTimeout)
+		defer cancel()
+		f.values = make(map[string]string)
+		for _, p := range f.providers {
+			values, err := p.Facts(ctx)
+			if err != nil {
+				f.err = fmt.Errorf("gather facts: %w", err)
+				return
+			}
+			maps.Copy(f.values, values)
+		}
+	})
+	return f.values, f.err
+}
+
+// Facts returns the facts gathered from the providers added with WithFacts.
+func (cm *ConfigManager) Facts() (map[string]string, error) {
+	if len(cm.facts.providers) == 0 {
+		return map[string]string{}, nil
+	}
+	values, err := cm.facts.gather()
+	return maps.Clone(values), err
+}
+
+var factPlaceholderPattern = regexp.MustCompile(`\$\{fact:([A-Za-z0-9_.-]+)\}`)
+
+// interpolateFacts replaces "${fact:name}" placeholders in data with fact values.
+func interpolateFacts(data []byte, facts map[string]string) ([]byte, error) {
+	var err error
+	res := factPlaceholderPattern.ReplaceAllFunc(data, func(match []byte) []byte {
+		name := string(factPlaceholderPattern.FindSubmatch(match)[1])
+		value, ok := facts[name]
+		if !ok {
+			err = fmt.Errorf("%w: %q", ErrUnknownFact, name)
+			return match
+		}
+		return []byte(value)
+	})
+	return res, err
+}
+
+type factsSelector struct {
+	Match map[string]string `yaml:"match"`
+	Set   map[string]any    `yaml:"set"`
+}
+
+func (s factsSelector) matches(facts map[string]string) bool {
+	for name, pattern := range s.Match {
+		value, ok := facts[name]
+		if !ok {
+			return false
+		}
+		if matched, err := path.Match(pattern, value); err != nil || !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// unmarshalWithFacts unmarshals data into v applying fact selectors of JSON and YAML documents.
+func unmarshalWithFacts(formatter Formatter, data []byte, v any, facts map[string]string) error {
+	var doc map[string]any
+	if yaml.Unmarshal(data, &doc) != nil {
+		return formatter.Unmarshal(data, v)
+	}
+	rawSelectors, ok := doc[factsSelectorsKey]
+	if !ok {
+		return formatter.Unmarshal(data, v)
+	}
+	delete(doc, factsSelectorsKey)
+
+	selectorsData, err := yaml.Marshal(rawSelectors)
+	if err != nil {
+		return fmt.Errorf("marshal fact selectors: %w", err)
+	}
+	var selectors []factsSelector
+	if err := yaml.Unmarshal(selectorsData, &selectors); err != nil {
+		return fmt.Errorf("parse fact selectors: %w", err)
+	}
+
+	// JSON is valid YAML, so the layers encoded as JSON are understood by both JSON and YAML formatters.
+	layers := []map[string]any{doc}
+	for _, s := range selectors {
+		if s.matches(facts) {
+			layers = append(layers, s.Set)
+		}
+	}
+	for _, layer := range layers {
+		layerData, err := json.Marshal(layer)
+		if err != nil {
+			return fmt.Errorf("marshal document: %w", err)
+		}
+		if err := formatter.Unmarshal(layerData, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// unmarshalFacts interpolates facts into data and unmarshals it into v applying fact selectors.
+func (cm *ConfigManager) unmarshalFacts(formatter Formatter, data []byte, v any) error {
+	facts, err := cm.facts.gather()
+	if err != nil {
+		return err
+	}
+	data, err = interpolateFacts(data, facts)
+	if err != nil {
+		return err
+	}
+	return unmarshalWithFacts(formatter, data, v, facts)
+}
@@ -0,0 +1,203 @@
+package confgo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestAWSMetadataFacts(t *testing.T) {
+	t.Parallel()
+
+	values := map[string]string{
+		"/latest/meta-data/instance-id":                 "i-123",
+		"/latest/meta-data/instance-type":               "m5.large",
+		"/latest/meta-data/placement/region":            "us-east-1",
+		"/latest/meta-data/placement/availability-zone": "us-east-1a",
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut && r.URL.Path == "/latest/api/token" {
+			_, _ = w.Write([]byte("token"))
+			return
+		}
+		value, ok := values[r.URL.Path]
+		if !ok || r.Header.Get("X-Aws-Ec2-Metadata-Token") != "token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(value + "\n"))
+	}))
+	t.Cleanup(server.Close)
+
+	got, err := AWSMetadataFacts(server.URL).Facts(t.Context())
+	if err != nil {
+		t.Fatalf("Facts() error = %v", err)
+	}
+	want := map[string]string{
+		"instance_id":   "i-123",
+		"instance_type": "m5.large",
+		"region":        "us-east-1",
+		"zone":          "us-east-1a",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Facts() = %v, want %v", got, want)
+	}
+}
+
+func TestGCPMetadataFacts(t *testing.T) {
+	t.Parallel()
+
+	values := map[string]string{
+		"/computeMetadata/v1/instance/id":           "42",
+		"/computeMetadata/v1/instance/machine-type": "projects/1/machineTypes/e2-small",
+		"/computeMetadata/v1/instance/zone":         "projects/1/zones/us-central1-a",
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		value, ok := values[r.URL.Path]
+		if !ok || r.Header.Get("Metadata-Flavor") != "Google" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_, _ = w.Write([]byte(value))
+	}))
+	t.Cleanup(server.Close)
+
+	got, err := GCPMetadataFacts(server.URL).Facts(t.Context())
+	if err != nil {
+		t.Fatalf("Facts() error = %v", err)
+	}
+	want := map[string]string{
+		"instance_id":   "42",
+		"instance_type": "e2-small",
+		"region":        "us-central1",
+		"zone":          "us-central1-a",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Facts() = %v, want %v", got, want)
+	}
+}
+
+func Test_interpolateFacts(t *testing.T) {
+	t.Parallel()
+
+	facts := map[string]string{"zone": "eu-1a", "host.name": "node-1"}
+	tests := []struct {
+		name    string
+		data    string
+		want    string
+		wantErr error
+	}{
+		{name: "no placeholders", data: `{"a": "${HOME}"}`, want: `{"a": "${HOME}"}`},
+		{name: "placeholders", data: `{"a": "${fact:zone}/${fact:host.name}"}`, want: `{"a": "eu-1a/node-1"}`},
+		{name: "unknown fact", data: `{"a": "${fact:missing}"}`, wantErr: ErrUnknownFact},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := interpolateFacts([]byte(tt.data), facts)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("interpolateFacts() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && string(got) != tt.want {
+				t.Errorf("interpolateFacts() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithFacts(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		file    string
+		option  func(file string) Option
+		content string
+		want    *TestConfig
+	}{
+		{
+			name:   "yaml selectors",
+			file:   "config.yaml",
+			option: func(file string) Option { return WithYAMLFile(file) },
+			content: `
+int: 1
+inner:
+  string: ${fact:hostname}
+$when:
+  - match: {zone: "us-east-*"}
+    set: {int: 2, slice: [east]}
+  - match: {zone: "eu-*"}
+    set: {int: 3}
+  - match: {zone: "us-east-1a", hostname: node-1}
+    set: {inner: {int: 4}}
+`,
+			want: &TestConfig{Int: 2, Inner: testInnerConfig{Int: 4, String: "node-1"}, Slice: []string{"east"}},
+		},
+		{
+			name:    "json selectors",
+			file:    "config.json",
+			option:  func(file string) Option { return WithJSONFile(file) },
+			content: `{"int": 1, "$when": [{"match": {"zone": "eu-*"}, "set": {"int": 3}}, {"match": {"unknown": "*"}, "set": {"int": 5}}]}`,
+			want:    &TestConfig{Int: 1},
+		},
+		{
+			name:    "no selectors",
+			file:    "config.json",
+			option:  func(file string) Option { return WithJSONFile(file) },
+			content: `{"int": 1, "map": {"zone": "${fact:zone}"}}`,
+			want:    &TestConfig{Int: 1, Map: map[string]string{"zone": "us-east-1a"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			file := filepath.Join(t.TempDir(), tt.file)
+			if err := writeFile(file, tt.content); err != nil {
+				t.Fatalf("failed to write config: %v", err)
+			}
+			cm, err := NewConfigManagerFor[TestConfig](
+				WithFacts(StaticFacts(map[string]string{"zone": "us-east-1a", "hostname": "other"})),
+				WithFacts(StaticFacts(map[string]string{"hostname": "node-1"})),
+				tt.option(file),
+			)
+			if err != nil {
+				t.Fatalf("NewConfigManagerFor() error = %v", err)
+			}
+			if err := cm.Start(); err != nil {
+				t.Fatalf("Start() error = %v", err)
+			}
+			defer cm.MustStop()
+
+			if got := cm.Config(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Config() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithFacts_ProviderError(t *testing.T) {
+	t.Parallel()
+
+	errProvider := FactsProviderFunc(func(_ context.Context) (map[string]string, error) {
+		return nil, errors.New("metadata service is unavailable")
+	})
+	cm := newTestConfigManager(testConfigManagerFields{
+		constructor: testConfigConstructor,
+		loaders:     []Loader{{Source: &fakeSource{data: []byte("{}")}, Formatter: NewJSONFormatter()}},
+	})
+	if err := WithFacts(errProvider)(cm); err != nil {
+		t.Fatalf("WithFacts() error = %v", err)
+	}
+	if err := cm.reload(); err == nil {
+		t.Fatalf("reload() error = nil, want error")
+	}
+	if _, err := cm.Facts(); err == nil {
+		t.Fatalf("Facts() error = nil, want error")
+	}
+}
@@ -0,0 +1,250 @@
+package confgo
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// fieldKey returns the key used for the struct field in dotted field paths.
+// It prefers the json tag name, then the yaml tag name and falls back to the field name.
+// The second returned value is false if the field must be skipped.
+func fieldKey(sf reflect.StructField) (string, bool) {
+	if !sf.IsExported() {
+		return "", false
+	}
+	for _, tagName := range []string{"json", "yaml"} {
+		tag, ok := sf.Tag.Lookup(tagName)
+		if !ok {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if name == "-" {
+			return "", false
+		}
+		if name != "" {
+			return name, true
+		}
+	}
+	return sf.Name, true
+}
+
+func joinPath(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+// walkStructFields calls fn for every leaf field of the struct type, recursing into nested structs.
+func walkStructFields(typ reflect.Type, prefix string, fn func(path string, sf reflect.StructField)) {
+	walkStructFieldsVisiting(typ, prefix, make(map[reflect.Type]bool), fn)
+}
+
+// walkStructFieldsVisiting is walkStructFields skipping the struct types being visited,
+// so the fields of recursive types are walked at their first level only.
+func walkStructFieldsVisiting(
+	typ reflect.Type,
+	prefix string,
+	visiting map[reflect.Type]bool,
+	fn func(path string, sf reflect.StructField),
+) {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct || visiting[typ] {
+		return
+	}
+	visiting[typ] = true
+	defer delete(visiting, typ)
+	for i := range typ.NumField() {
+		sf := typ.Field(i)
+		key, ok := fieldKey(sf)
+		if !ok {
+			continue
+		}
+		path := joinPath(prefix, key)
+		fieldType := sf.Type
+		for fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		if fieldType.Kind() == reflect.Struct && !isLeafStruct(fieldType) {
+			walkStructFieldsVisiting(fieldType, path, visiting, fn)
+			continue
+		}
+		fn(path, sf)
+	}
+}
+
+// isLeafStruct reports whether the struct type is a value type, such as time.Time, rather than a config section.
+func isLeafStruct(typ reflect.Type) bool {
+	for _, iface := range []reflect.Type{
+		reflect.TypeFor[interface{ UnmarshalText(text []byte) error }](),
+		reflect.TypeFor[interface{ UnmarshalJSON(data []byte) error }](),
+	} {
+		if typ.Implements(iface) || reflect.PointerTo(typ).Implements(iface) {
+			return true
+		}
+	}
+	return false
+}
+
+// nonZeroPaths returns dotted paths of the leaf fields of cfg which hold non-zero values.
+func nonZeroPaths(cfg any) []string {
+	paths := make([]string, 0)
+	collectNonZeroPaths("", reflect.ValueOf(cfg), &paths)
+	return paths
+}
+
+func collectNonZeroPaths(path string, val reflect.Value, paths *[]string) {
+	for val.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			return
+		}
+		val = val.Elem()
+	}
+	if val.Kind() != reflect.Struct || isLeafStruct(val.Type()) {
+		if !val.IsZero() {
+			*paths = append(*paths, path)
+		}
+		return
+	}
+	for i := range val.NumField() {
+		key, ok := fieldKey(val.Type().Field(i))
+		if !ok {
+			continue
+		}
+		collectNonZeroPaths(joinPath(path, key), val.Field(i), paths)
+	}
+}
+
+// populateSections allocates nil pointers to config sections of the struct value v, recursively,
+// and applies the default tags of the allocated sections.
+// Sections of a type already being populated are left nil, so recursive types are populated only once.
+func populateSections(v reflect.Value, prefix string, populating map[reflect.Type]bool) error {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct || isLeafStruct(v.Type()) || populating[v.Type()] {
+		return nil
+	}
+	populating[v.Type()] = true
+	defer delete(populating, v.Type())
+	for i := range v.NumField() {
+		key, ok := fieldKey(v.Type().Field(i))
+		if !ok {
+			continue
+		}
+		path := joinPath(prefix, key)
+		field := v.Field(i)
+		if field.Kind() == reflect.Ptr && field.IsNil() {
+			elem := field.Type().Elem()
+			if elem.Kind() != reflect.Struct || isLeafStruct(elem) || populating[elem] {
+				continue
+			}
+			field.Set(reflect.New(elem))
+			if err := applyDefaultTags(field, path); err != nil {
+				return err
+			}
+		}
+		if err := populateSections(field, path, populating); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fieldByPath returns the field of the struct value v addressed by the dotted path.
+// If alloc is true, nil pointers on the way are allocated, otherwise an invalid value is returned for them.
+func fieldByPath(v reflect.Value, path string, alloc bool) (reflect.Value, error) {
+	if path == "" {
+		return reflect.Value{}, fmt.Errorf("%w: empty path", ErrInvalidFieldPath)
+	}
+	cur := v
+	for _, key := range strings.Split(path, ".") {
+		for cur.Kind() == reflect.Ptr {
+			if cur.IsNil() {
+				if !alloc {
+					return reflect.Value{}, nil
+				}
+				cur.Set(reflect.New(cur.Type().Elem()))
+			}
+			cur = cur.Elem()
+		}
+		if cur.Kind() != reflect.Struct {
+			return reflect.Value{}, fmt.Errorf("%w: %q: %s is not a struct", ErrInvalidFieldPath, path, cur.Type())
+		}
+		next, ok := structFieldByKey(cur, key)
+		if !ok {
+			return reflect.Value{}, fmt.Errorf("%w: %q: unknown field %q", ErrInvalidFieldPath, path, key)
+		}
+		cur = next
+	}
+	return cur, nil
+}
+
+func structFieldByKey(v reflect.Value, key string) (reflect.Value, bool) {
+	for i := range v.NumField() {
+		if k, ok := fieldKey(v.Type().Field(i)); ok && k == key {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// assignValue sets a deep copy of value into the field, so the field shares no slices or maps with value.
+// Nil value resets the field to its zero value.
+// Values of different types are converted only between numeric kinds or between string kinds.
+func assignValue(field reflect.Value, value any) error {
+	if value == nil {
+		field.SetZero()
+		return nil
+	}
+	val := reflect.ValueOf(value)
+	switch {
+	case val.Type().AssignableTo(field.Type()):
+		field.Set(deepCopy(val))
+	case isNumericKind(val.Kind()) && isNumericKind(field.Kind()),
+		val.Kind() == reflect.String && field.Kind() == reflect.String:
+		converted := val.Convert(field.Type())
+		// The round trip keeps the value of a negative number converted to an unsigned one and back,
+		// and of an unsigned one converted to a negative number and back, so the signs are compared too.
+		if !converted.Convert(val.Type()).Equal(val) || isNegative(val) != isNegative(converted) {
+			return fmt.Errorf("%w: %v overflows %s", ErrInvalidFieldValue, value, field.Type())
+		}
+		field.Set(converted)
+	case field.Kind() == reflect.Ptr && val.Type().AssignableTo(field.Type().Elem()):
+		ptr := reflect.New(field.Type().Elem())
+		ptr.Elem().Set(deepCopy(val))
+		field.Set(ptr)
+	default:
+		return fmt.Errorf("%w: cannot use %s as %s", ErrInvalidFieldValue, val.Type(), field.Type())
+	}
+	return nil
+}
+
+// isNegative reports whether v is a negative number, v must be of a numeric or string kind.
+func isNegative(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() < 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() < 0
+	default:
+		return false
+	}
+}
+
+func isNumericKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
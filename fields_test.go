@@ -0,0 +1,74 @@
+package confgo
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_fieldKey(t *testing.T) {
+	t.Parallel()
+
+	type sample struct {
+		JSON     int `json:"json_name,omitempty"`
+		YAML     int `yaml:"yaml_name"`
+		Both     int `json:"json_both" yaml:"yaml_both"`
+		Skipped  int `json:"-"`
+		Untagged int
+	}
+
+	want := map[string]string{
+		"JSON":     "json_name",
+		"YAML":     "yaml_name",
+		"Both":     "json_both",
+		"Untagged": "Untagged",
+	}
+	got := make(map[string]string)
+	typ := reflect.TypeFor[sample]()
+	for i := range typ.NumField() {
+		if key, ok := fieldKey(typ.Field(i)); ok {
+			got[typ.Field(i).Name] = key
+		}
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("fieldKey() = %v, want %v", got, want)
+	}
+}
+
+func TestConfigManager_WithPopulatedSections(t *testing.T) {
+	t.Parallel()
+
+	type node struct {
+		Name string `json:"name"`
+		Next *node  `json:"next"`
+	}
+	type config struct {
+		Inner    *testInnerConfig `json:"inner"`
+		Set      *testInnerConfig `json:"set"`
+		Node     *node            `json:"node"`
+		IntPtr   *int             `json:"int_ptr"`
+		Duration *Duration        `json:"duration"`
+	}
+
+	cm, err := NewConfigManagerFor[config](WithPopulatedSections, func(cm *ConfigManager) error {
+		cm.AddLoader(Loader{Source: &fakeSource{data: []byte(`{"set": {"int": 1}}`)}, Formatter: NewJSONFormatter()})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("NewConfigManagerFor() error = %v", err)
+	}
+	if err := cm.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer cm.MustStop()
+
+	want := &config{
+		Inner:    &testInnerConfig{},
+		Set:      &testInnerConfig{Int: 1},
+		Node:     &node{},
+		IntPtr:   nil,
+		Duration: nil,
+	}
+	if got := cm.Config(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Config() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,16 @@
+//go:build unix
+
+package confgo
+
+import (
+	"io/fs"
+	"syscall"
+)
+
+// fileInode returns the inode number of the file or 0 if it is unknown.
+func fileInode(info fs.FileInfo) uint64 {
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		return uint64(stat.Ino) //nolint:unconvert // Ino is not uint64 on every platform.
+	}
+	return 0
+}
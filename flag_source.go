@@ -0,0 +1,238 @@
+package confgo
+
+import (
+	"encoding"
+	"errors"
+	"flag"
+	"fmt"
+	"maps"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// FlagSourceOption configures FlagSource.
+type FlagSourceOption func(fs *FlagSource)
+
+// FlagPaths maps the names of flags to the dotted paths of the fields they set, e.g. {"port": "server.port"},
+// for flags whose names differ from the paths.
+func FlagPaths(paths map[string]string) FlagSourceOption {
+	return func(fs *FlagSource) {
+		maps.Copy(fs.paths, paths)
+	}
+}
+
+// FlagDecodeHooks adds decode hooks used to parse flag values of their types.
+func FlagDecodeHooks(hooks ...DecodeHook) FlagSourceOption {
+	return func(fs *FlagSource) {
+		fs.hooks = newDecodeHooks(hooks)
+	}
+}
+
+var (
+	_ Source           = (*FlagSource)(nil)
+	_ Formatter        = (*FlagSource)(nil)
+	_ PresenceReporter = (*FlagSource)(nil)
+)
+
+// FlagSource is both a Source and a Formatter of the layer of command-line flags of a flag.FlagSet.
+// Flags are named after the dotted paths of the fields they set, e.g. --server.port=8080, unless mapped
+// otherwise with FlagPaths, and flags naming no field, e.g. --config, are ignored. Only the flags set
+// on the command line are applied, so the defaults of the flags do not override the lower layers,
+// and with WithExplicitValues the set flags override them even with zero values, e.g. --debug=false.
+//
+// Values of flags implementing flag.Getter, e.g. the ones defined by FlagSet.Int, are assigned as is
+// if their types match the fields, other values are parsed from their string representation as default tags are.
+//
+// The data read by the source is only a fingerprint of the set flags used to detect changes,
+// so the loader must use the source as its formatter and must have no transformers, see WithFlagSet.
+type FlagSource struct {
+	flags *flag.FlagSet
+	paths map[string]string
+	hooks decodeHooks
+}
+
+// NewFlagSource creates a source of the flags of the flag set, which is expected to be parsed before
+// the configuration is loaded.
+func NewFlagSource(flags *flag.FlagSet, opts ...FlagSourceOption) *FlagSource {
+	fs := &FlagSource{
+		flags: flags,
+		paths: make(map[string]string),
+		hooks: nil,
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(fs)
+		}
+	}
+	return fs
+}
+
+func (fs *FlagSource) Read() ([]byte, error) {
+	var b strings.Builder
+	fs.flags.Visit(func(f *flag.Flag) {
+		fmt.Fprintf(&b, "%s=%q\n", f.Name, f.Value.String())
+	})
+	return []byte(b.String()), nil
+}
+
+// Unmarshal sets the values of the set flags into the config v, ignoring data.
+func (fs *FlagSource) Unmarshal(_ []byte, v any) error {
+	hooks := fs.hooks.withDefaults()
+	return fs.visit(reflect.ValueOf(v), func(field reflect.Value, f *flag.Flag, path string) error {
+		if getter, ok := f.Value.(flag.Getter); ok {
+			if err := assignValue(field, getter.Get()); err == nil {
+				return nil
+			}
+		}
+		value, err := parseDefault(field.Type(), f.Value.String(), hooks)
+		if err != nil {
+			return fmt.Errorf("flag %q of field %q: %w", f.Name, path, err)
+		}
+		field.Set(value)
+		return nil
+	})
+}
+
+// Present returns the paths of the fields of v set by the flags, ignoring data.
+func (fs *FlagSource) Present(_ []byte, v any) ([]string, error) {
+	paths := make([]string, 0)
+	cfg := reflect.New(reflect.TypeOf(v).Elem())
+	err := fs.visit(cfg, func(_ reflect.Value, _ *flag.Flag, path string) error {
+		paths = append(paths, path)
+		return nil
+	})
+	return paths, err
+}
+
+// visit calls fn for the fields of v set by the flags set on the command line in lexical order of flag names.
+func (fs *FlagSource) visit(v reflect.Value, fn func(field reflect.Value, f *flag.Flag, path string) error) error {
+	var err error
+	fs.flags.Visit(func(f *flag.Flag) {
+		if err != nil {
+			return
+		}
+		path, mapped := fs.paths[f.Name]
+		if !mapped {
+			path = f.Name
+		}
+		field, fieldErr := fieldByPath(v, path, true)
+		if fieldErr != nil {
+			if mapped || !errors.Is(fieldErr, ErrInvalidFieldPath) {
+				err = fmt.Errorf("flag %q: %w", f.Name, fieldErr)
+			}
+			return
+		}
+		err = fn(field, f, path)
+	})
+	return err
+}
+
+// DefineFlags defines a flag on flags for every leaf field of the config cfg, a pointer to a config struct,
+// so the flags never drift from the struct. Flags are named after the dotted field paths, e.g. "server.port",
+// have the defaults of the default tags and the usage of the usage tags, e.g.
+//
+//	Port int `json:"port" default:"8080" usage:"port to listen on"`
+//
+// Values are parsed the way default tags are, and the flags of bool fields may be set without a value.
+// Fields of types which cannot be parsed from a string, e.g. interfaces, are skipped.
+// The defaults are only shown in the usage, the default tags are applied by the manager itself.
+func DefineFlags(flags *flag.FlagSet, cfg any) error {
+	typ := reflect.TypeOf(cfg)
+	if typ == nil || typ.Kind() != reflect.Ptr || typ.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("%w: %T is not a pointer to a struct", ErrConfigTypeMismatch, cfg)
+	}
+	hooks := decodeHooks(nil).withDefaults()
+	var err error
+	walkStructFields(typ, "", func(path string, sf reflect.StructField) {
+		if err != nil || !isFlagType(sf.Type, hooks) {
+			return
+		}
+		def := sf.Tag.Get("default")
+		if def != "" {
+			if _, parseErr := parseDefault(sf.Type, def, hooks); parseErr != nil {
+				err = fmt.Errorf("default of field %q: %w", path, parseErr)
+				return
+			}
+		}
+		flags.Var(&fieldFlag{typ: sf.Type, hooks: hooks, raw: def, value: nil}, path, sf.Tag.Get("usage"))
+	})
+	return err
+}
+
+// WithFlags adds a Loader layer with FlagSource of the flags defined by DefineFlags for the config
+// and parsed from args, e.g. os.Args[1:]. Parse errors are returned as is, e.g. flag.ErrHelp for -h.
+func WithFlags(args []string) Option {
+	return func(cm *ConfigManager) error {
+		flags := flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+		if err := DefineFlags(flags, cm.constructor()); err != nil {
+			return err
+		}
+		if err := flags.Parse(args); err != nil {
+			return err
+		}
+		return WithFlagSet(flags)(cm)
+	}
+}
+
+// isFlagType reports whether values of the type can be parsed from a string by parseDefault.
+func isFlagType(typ reflect.Type, hooks decodeHooks) bool {
+	if _, ok := hooks[typ]; ok {
+		return true
+	}
+	if reflect.PointerTo(typ).Implements(reflect.TypeFor[encoding.TextUnmarshaler]()) {
+		return true
+	}
+	switch typ.Kind() {
+	case reflect.String, reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	case reflect.Ptr, reflect.Slice:
+		return isFlagType(typ.Elem(), hooks)
+	case reflect.Map:
+		return isFlagType(typ.Key(), hooks) && isFlagType(typ.Elem(), hooks)
+	default:
+		return false
+	}
+}
+
+var _ flag.Getter = (*fieldFlag)(nil)
+
+// fieldFlag is the flag of a config field defined by DefineFlags.
+type fieldFlag struct {
+	typ   reflect.Type
+	hooks decodeHooks
+	raw   string
+	// value is the parsed value of the set flag, nil until the flag is set.
+	value any
+}
+
+func (ff *fieldFlag) String() string {
+	return ff.raw
+}
+
+func (ff *fieldFlag) Set(raw string) error {
+	value, err := parseDefault(ff.typ, raw, ff.hooks)
+	if err != nil {
+		return err
+	}
+	ff.raw, ff.value = raw, value.Interface()
+	return nil
+}
+
+func (ff *fieldFlag) Get() any {
+	return ff.value
+}
+
+// IsBoolFlag makes the flag package accept the flags of bool fields without a value, e.g. --debug.
+func (ff *fieldFlag) IsBoolFlag() bool {
+	if ff.typ == nil {
+		return false
+	}
+	typ := ff.typ
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	return typ.Kind() == reflect.Bool
+}
@@ -0,0 +1,163 @@
+package confgo
+
+import (
+	"errors"
+	"flag"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFlagSource(t *testing.T) {
+	t.Parallel()
+
+	type server struct {
+		Port    int           `json:"port"`
+		Debug   bool          `json:"debug"`
+		Timeout time.Duration `json:"timeout"`
+		Hosts   []string      `json:"hosts"`
+	}
+	type config struct {
+		Server server  `json:"server"`
+		Ratio  float64 `json:"ratio"`
+	}
+
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags.Int("server.port", 80, "")
+	flags.Bool("server.debug", true, "")
+	flags.String("timeout", "", "")
+	flags.String("server.hosts", "", "")
+	flags.Int("ratio", 0, "")
+	flags.String("config", "config.json", "")
+	err := flags.Parse([]string{
+		"--server.port=8080", "--server.debug=false", "--timeout=5s", "--server.hosts=a, b", "--config=other.json",
+	})
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	cm, err := NewConfigManagerFor[config](
+		WithLoader(Loader{
+			Source:    &fakeSource{data: []byte(`{"server": {"port": 1, "debug": true}, "ratio": 0.5}`)},
+			Formatter: NewJSONFormatter(),
+		}),
+		WithFlagSet(flags, FlagPaths(map[string]string{"timeout": "server.timeout"})),
+		WithExplicitValues,
+	)
+	if err != nil {
+		t.Fatalf("NewConfigManagerFor() error = %v", err)
+	}
+	if err := cm.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer cm.MustStop()
+
+	// The default of the unset ratio flag does not override the file.
+	want := &config{
+		Server: server{Port: 8080, Debug: false, Timeout: 5 * time.Second, Hosts: []string{"a", "b"}},
+		Ratio:  0.5,
+	}
+	if got := cm.Config(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Config() = %+v, want %+v", got, want)
+	}
+}
+
+func TestFlagSource_Errors(t *testing.T) {
+	t.Parallel()
+
+	var cfg TestConfig
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags.String("int", "", "")
+	if err := flags.Parse([]string{"--int=abc"}); err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if err := NewFlagSource(flags).Unmarshal(nil, &cfg); !errors.Is(err, ErrInvalidFieldValue) {
+		t.Errorf("Unmarshal() error = %v, want ErrInvalidFieldValue", err)
+	}
+
+	flags = flag.NewFlagSet("test", flag.ContinueOnError)
+	flags.String("port", "", "")
+	if err := flags.Parse([]string{"--port=1"}); err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	s := NewFlagSource(flags, FlagPaths(map[string]string{"port": "server.port"}))
+	if err := s.Unmarshal(nil, &cfg); !errors.Is(err, ErrInvalidFieldPath) {
+		t.Errorf("Unmarshal() of a mapped flag error = %v, want ErrInvalidFieldPath", err)
+	}
+}
+
+func TestDefineFlags(t *testing.T) {
+	t.Parallel()
+
+	type server struct {
+		Port  int    `json:"port" default:"8080" usage:"port to listen on"`
+		Debug bool   `json:"debug" usage:"enable debug endpoints"`
+		Host  string `yaml:"host"`
+	}
+	type config struct {
+		Server  *server           `json:"server"`
+		Timeout time.Duration     `json:"timeout" default:"1s"`
+		Labels  map[string]string `json:"labels"`
+		Hook    func()            `json:"-"`
+		Any     any               `json:"any"`
+	}
+
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	if err := DefineFlags(flags, &config{}); err != nil {
+		t.Fatalf("DefineFlags() error = %v", err)
+	}
+	var names []string
+	flags.VisitAll(func(f *flag.Flag) { names = append(names, f.Name) })
+	if want := []string{"labels", "server.debug", "server.host", "server.port", "timeout"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("defined flags = %v, want %v", names, want)
+	}
+	if f := flags.Lookup("server.port"); f.DefValue != "8080" || f.Usage != "port to listen on" {
+		t.Errorf("server.port flag default = %q, usage = %q", f.DefValue, f.Usage)
+	}
+
+	if err := flags.Parse([]string{"--server.debug", "--timeout=2s", "--labels=a:1"}); err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	var got config
+	if err := NewFlagSource(flags).Unmarshal(nil, &got); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	want := config{Server: &server{Debug: true}, Timeout: 2 * time.Second, Labels: map[string]string{"a": "1"}}
+	if got.Hook != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("Unmarshal() = %+v, want %+v", got, want)
+	}
+
+	if err := flags.Set("server.port", "abc"); !errors.Is(err, ErrInvalidFieldValue) {
+		t.Errorf("Set() error = %v, want ErrInvalidFieldValue", err)
+	}
+	type invalid struct {
+		Port int `json:"port" default:"abc"`
+	}
+	if err := DefineFlags(flag.NewFlagSet("test", flag.ContinueOnError), &invalid{}); !errors.Is(err, ErrInvalidFieldValue) {
+		t.Errorf("DefineFlags() of an invalid default error = %v, want ErrInvalidFieldValue", err)
+	}
+}
+
+func TestWithFlags(t *testing.T) {
+	t.Parallel()
+
+	cm, err := NewConfigManager(testConfigConstructor,
+		WithLoader(Loader{Source: &fakeSource{data: []byte(`{"int": 1, "slice": ["a"]}`)}, Formatter: NewJSONFormatter()}),
+		WithFlags([]string{"--int=2", "--inner_ptr.string=b"}),
+	)
+	if err != nil {
+		t.Fatalf("NewConfigManager() error = %v", err)
+	}
+	if err := cm.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer cm.MustStop()
+	want := &TestConfig{Int: 2, InnerPtr: &testInnerConfig{String: "b"}, Slice: []string{"a"}}
+	if got := cm.Config(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Config() = %+v, want %+v", got, want)
+	}
+
+	if _, err := NewConfigManager(testConfigConstructor, WithFlags([]string{"--unknown=1"})); err == nil {
+		t.Error("NewConfigManager() with an unknown flag error = nil")
+	}
+}
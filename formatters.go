@@ -2,7 +2,17 @@ package confgo
 
 import (
 	"bytes"
+	"encoding"
 	"encoding/json"
+	"fmt"
+	"maps"
+	"net/url"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
 
 	"github.com/caarlos0/env/v11"
 	"gopkg.in/yaml.v3"
@@ -12,13 +22,101 @@ const pairLen = 2
 
 var _ Formatter = (*EnvFormatter)(nil)
 
+// EnvFormatterOption option that configures env formatter.
+type EnvFormatterOption func(ef *EnvFormatter)
+
+// EnvDecodeHooks adds decode hooks used to parse values of their types instead of the env package parsers.
+func EnvDecodeHooks(hooks ...DecodeHook) EnvFormatterOption {
+	return func(ef *EnvFormatter) {
+		ef.hooks = newDecodeHooks(hooks)
+	}
+}
+
+// EnvTagName makes the formatter bind fields to env variables by the tag with the name instead of "env".
+func EnvTagName(name string) EnvFormatterOption {
+	return func(ef *EnvFormatter) {
+		ef.names.tag = name
+	}
+}
+
+// EnvNamingStrategy makes the formatter bind the fields without env tags to the env variables named by naming,
+// e.g. UpperSnakeCase, so fields need not be tagged twice:
+//
+//	ReadTimeout time.Duration `json:"readTimeout"` // READ_TIMEOUT
+//
+// Values of such fields are parsed the way default tags are.
+func EnvNamingStrategy(naming EnvNaming) EnvFormatterOption {
+	return func(ef *EnvFormatter) {
+		ef.names.naming = naming
+	}
+}
+
+// EnvNestedSeparator makes the formatter bind the fields of the nested structs without "envPrefix" tags
+// to the env variables prefixed with the names of the structs followed by the separator, so nested configs
+// need no env tags at all. Names are derived by the naming strategy, UpperSnakeCase unless set by
+// EnvNamingStrategy, e.g. with the "__" separator and EnvPrefix("APP_") of the source:
+//
+//	APP_SERVER__PORT=8080 // Server.Port
+//	APP_DB__POOL__MAX_CONNS=10 // DB.Pool.MaxConns
+//
+// The prefixes apply to the fields with env tags in such structs as well.
+func EnvNestedSeparator(separator string) EnvFormatterOption {
+	return func(ef *EnvFormatter) {
+		ef.names.separator = separator
+	}
+}
+
+// EnvNaming derives the name of the env variable of a field from the key of the field,
+// i.e. the name of its json or yaml tag or its Go name.
+type EnvNaming func(key string) string
+
+// UpperSnakeCase is the EnvNaming converting keys in camel, kebab or snake case to upper snake case,
+// e.g. "readTimeout", "read-timeout" and "ReadTimeout" to "READ_TIMEOUT" and "HTTPServer" to "HTTP_SERVER".
+func UpperSnakeCase(key string) string {
+	runes := []rune(key)
+	out := make([]rune, 0, len(runes)+len(runes)/2)
+	// sep reports whether a separator may be written, i.e. there is a word before it not ended by one.
+	sep := false
+	for i, r := range runes {
+		if r == '-' || r == '_' || r == '.' || r == ' ' {
+			if sep {
+				out = append(out, '_')
+				sep = false
+			}
+			continue
+		}
+		if sep && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			acronymEnd := unicode.IsUpper(prev) && i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || acronymEnd {
+				out = append(out, '_')
+			}
+		}
+		out = append(out, unicode.ToUpper(r))
+		sep = true
+	}
+	return strings.TrimSuffix(string(out), "_")
+}
+
 // EnvFormatter is a formatter that parses environment variable-style key-value pairs
 // and converts them into structured data. It supports the standard format of KEY=VALUE
 // pairs, one per line, and handles parsing of such data into Go structs via the env package.
-type EnvFormatter struct{}
+type EnvFormatter struct {
+	hooks decodeHooks
+	names envNames
+}
 
-func NewEnvFormatter() *EnvFormatter {
-	return &EnvFormatter{}
+func NewEnvFormatter(opts ...EnvFormatterOption) *EnvFormatter {
+	envF := &EnvFormatter{hooks: nil, names: defaultEnvNames}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(envF)
+		}
+	}
+	if envF.names.separator != "" && envF.names.naming == nil {
+		envF.names.naming = UpperSnakeCase
+	}
+	return envF
 }
 
 func (ef *EnvFormatter) parseRawIntoMap(raw []byte) map[string]string {
@@ -40,12 +138,137 @@ func (ef *EnvFormatter) parseRawIntoMap(raw []byte) map[string]string {
 }
 
 func (ef *EnvFormatter) Unmarshal(data []byte, v any) error {
+	vars := ef.parseRawIntoMap(data)
 	// At some point we may want to make our own implementation of env parser
 	// in order to reduce dependencies count
-	return env.ParseWithOptions(v, env.Options{
-		Environment: ef.parseRawIntoMap(data),
+	err := env.ParseWithOptions(v, env.Options{
+		Environment: vars,
+		TagName:     ef.names.tag,
 		// Prefix: // Do we need to support this?
+		FuncMap: ef.hooks.envParsers(),
+	})
+	if err != nil || ef.names.naming == nil {
+		return err
+	}
+	return ef.unmarshalNamed(vars, reflect.ValueOf(v))
+}
+
+// unmarshalNamed sets the fields bound to the variables by the naming strategy and the nested separator,
+// which the env package knows nothing about. The fields it binds to the same variables are skipped.
+func (ef *EnvFormatter) unmarshalNamed(vars map[string]string, v reflect.Value) error {
+	tagged := make(map[string]string)
+	walkEnvFields(v.Type(), envNames{tag: ef.names.tag, naming: nil, separator: ""}, "", "", func(envName, path string) {
+		tagged[path] = envName
 	})
+	hooks := ef.hooks.withDefaults()
+	var walkErr error
+	walkEnvFields(v.Type(), ef.names, "", "", func(envName, path string) {
+		raw, ok := vars[envName]
+		if walkErr != nil || tagged[path] == envName || !ok {
+			return
+		}
+		field, err := fieldByPath(v, path, true)
+		if err != nil {
+			walkErr = err
+			return
+		}
+		value, err := parseDefault(field.Type(), raw, hooks)
+		if err != nil {
+			walkErr = fmt.Errorf("env %q: %w", envName, err)
+			return
+		}
+		field.Set(value)
+	})
+	return walkErr
+}
+
+// Marshal encodes the fields of the struct v bound to env variables by "env" tags, or the ones set by
+// EnvTagName and EnvNamingStrategy, as KEY=VALUE lines sorted by key, honoring "envPrefix" tags of nested structs. Fields under nil pointers are skipped.
+// Slices are encoded as comma-separated items and maps as comma-separated key:value pairs.
+func (ef *EnvFormatter) Marshal(v any) ([]byte, error) {
+	val := reflect.ValueOf(v)
+	values := make(map[string]string)
+	var walkErr error
+	walkEnvFields(val.Type(), ef.names, "", "", func(envName, path string) {
+		if walkErr != nil {
+			return
+		}
+		field, err := fieldByPath(val, path, false)
+		if err != nil {
+			walkErr = err
+			return
+		}
+		if !field.IsValid() || (field.Kind() == reflect.Ptr && field.IsNil()) {
+			return
+		}
+		value, err := formatEnvValue(field)
+		if err != nil {
+			walkErr = fmt.Errorf("env %q: %w", envName, err)
+			return
+		}
+		values[envName] = value
+	})
+	if walkErr != nil {
+		return nil, walkErr
+	}
+
+	var buf bytes.Buffer
+	for _, key := range slices.Sorted(maps.Keys(values)) {
+		buf.WriteString(key + "=" + values[key] + "\n")
+	}
+	return buf.Bytes(), nil
+}
+
+// formatEnvValue formats the value the way the env package parses it back.
+func formatEnvValue(v reflect.Value) (string, error) {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return "", nil
+		}
+		v = v.Elem()
+	}
+	// The value is copied, so methods with pointer receivers can be called on it.
+	ptr := reflect.New(v.Type())
+	ptr.Elem().Set(v)
+	switch m := ptr.Interface().(type) {
+	case encoding.TextMarshaler:
+		text, err := m.MarshalText()
+		return string(text), err
+	case *time.Duration:
+		return m.String(), nil
+	case *url.URL:
+		return m.String(), nil
+	}
+
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		items := make([]string, v.Len())
+		for i := range v.Len() {
+			item, err := formatEnvValue(v.Index(i))
+			if err != nil {
+				return "", err
+			}
+			items[i] = item
+		}
+		return strings.Join(items, ","), nil
+	case reflect.Map:
+		pairs := make([]string, 0, v.Len())
+		for iter := v.MapRange(); iter.Next(); {
+			key, err := formatEnvValue(iter.Key())
+			if err != nil {
+				return "", err
+			}
+			value, err := formatEnvValue(iter.Value())
+			if err != nil {
+				return "", err
+			}
+			pairs = append(pairs, key+":"+value)
+		}
+		slices.Sort(pairs)
+		return strings.Join(pairs, ","), nil
+	default:
+		return fmt.Sprint(v.Interface()), nil
+	}
 }
 
 // JSONFormatterOption option that configures json decoder.
@@ -58,6 +281,19 @@ func JSONDisallowUnknownFields(jf *JSONFormatter) {
 	jf.decoderTweaks = append(jf.decoderTweaks, func(decoder *json.Decoder) { decoder.DisallowUnknownFields() })
 }
 
+// JSONDecodeHooks adds decode hooks used to decode string values of their types, replacing the default ones.
+func JSONDecodeHooks(hooks ...DecodeHook) JSONFormatterOption {
+	return func(jf *JSONFormatter) {
+		jf.hooks = newDecodeHooks(hooks)
+	}
+}
+
+// JSONExpandEnv makes the formatter expand "${VAR}" and "${VAR:-default}" in the data
+// with the values of environment variables before decoding.
+func JSONExpandEnv(jf *JSONFormatter) {
+	jf.expandEnv = true
+}
+
 // UseNumber causes the json.Decoder to unmarshal a number into an interface
 // value as a json.Number instead of as a float64.
 // func UseNumber(jf *JSONFormatter) {
@@ -69,6 +305,8 @@ var _ Formatter = (*JSONFormatter)(nil)
 // JSONFormatter is a simple json formatter used to parse raw json data via the standard json package.
 type JSONFormatter struct {
 	decoderTweaks []func(*json.Decoder)
+	hooks         decodeHooks
+	expandEnv     bool
 }
 
 func NewJSONFormatter(opts ...JSONFormatterOption) *JSONFormatter {
@@ -82,6 +320,43 @@ func NewJSONFormatter(opts ...JSONFormatterOption) *JSONFormatter {
 }
 
 func (jf *JSONFormatter) Unmarshal(data []byte, v any) error {
+	if jf.expandEnv {
+		var err error
+		if data, err = expandEnv(data, os.LookupEnv); err != nil {
+			return err
+		}
+	}
+	hooks := jf.hooks.withDefaults()
+	if !hooks.usedBy(reflect.TypeOf(v), make(map[reflect.Type]bool)) {
+		return jf.decode(data, v)
+	}
+
+	var doc any
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		// The decoder reports the error along with the configured tweaks.
+		return jf.decode(data, v)
+	}
+	hooked := make([]hookedValue, 0)
+	root := jsonHookDoc{get: func() any { return doc }, set: func(val any) { doc = val }}
+	if err := hooks.extractHooked(root, reflect.TypeOf(v), jsonHookKeys, nil, &hooked); err != nil {
+		return err
+	}
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	if err := jf.decode(data, v); err != nil {
+		return err
+	}
+	for _, h := range hooked {
+		setHooked(reflect.ValueOf(v), h.path, h.value)
+	}
+	return nil
+}
+
+func (jf *JSONFormatter) decode(data []byte, v any) error {
 	dec := json.NewDecoder(bytes.NewReader(data))
 	for _, tweak := range jf.decoderTweaks {
 		tweak(dec)
@@ -89,6 +364,18 @@ func (jf *JSONFormatter) Unmarshal(data []byte, v any) error {
 	return dec.Decode(v)
 }
 
+// Marshal encodes v as JSON indented with two spaces.
+func (jf *JSONFormatter) Marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", strings.Repeat(" ", canonicalIndent))
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
 // YAMLFormatterOption option that configures json decoder.
 type YAMLFormatterOption func(jf *YAMLFormatter)
 
@@ -99,10 +386,25 @@ func YAMLDisallowUnknownFields(jf *YAMLFormatter) {
 	jf.decoderTweaks = append(jf.decoderTweaks, func(decoder *yaml.Decoder) { decoder.KnownFields(true) })
 }
 
+// YAMLDecodeHooks adds decode hooks used to decode string values of their types, replacing the default ones.
+func YAMLDecodeHooks(hooks ...DecodeHook) YAMLFormatterOption {
+	return func(yf *YAMLFormatter) {
+		yf.hooks = newDecodeHooks(hooks)
+	}
+}
+
+// YAMLExpandEnv makes the formatter expand "${VAR}" and "${VAR:-default}" in the data
+// with the values of environment variables before decoding.
+func YAMLExpandEnv(yf *YAMLFormatter) {
+	yf.expandEnv = true
+}
+
 var _ Formatter = (*YAMLFormatter)(nil)
 
 type YAMLFormatter struct {
 	decoderTweaks []func(*yaml.Decoder)
+	hooks         decodeHooks
+	expandEnv     bool
 }
 
 func NewYAMLFormatter(opts ...YAMLFormatterOption) *YAMLFormatter {
@@ -116,9 +418,57 @@ func NewYAMLFormatter(opts ...YAMLFormatterOption) *YAMLFormatter {
 }
 
 func (yf *YAMLFormatter) Unmarshal(data []byte, v any) error {
+	if yf.expandEnv {
+		var err error
+		if data, err = expandEnv(data, os.LookupEnv); err != nil {
+			return err
+		}
+	}
+	hooks := yf.hooks.withDefaults()
+	if !hooks.usedBy(reflect.TypeOf(v), make(map[reflect.Type]bool)) {
+		return yf.decode(data, v)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil || doc.Kind == 0 {
+		// The decoder reports the error or handles empty data along with the configured tweaks.
+		return yf.decode(data, v)
+	}
+	hooked := make([]hookedValue, 0)
+	if err := hooks.extractHooked(newYAMLHookDoc(&doc, false), reflect.TypeOf(v), yamlHookKeys, nil, &hooked); err != nil {
+		return err
+	}
+	data, err := yaml.Marshal(&doc)
+	if err != nil {
+		return err
+	}
+	if err := yf.decode(data, v); err != nil {
+		return err
+	}
+	for _, h := range hooked {
+		setHooked(reflect.ValueOf(v), h.path, h.value)
+	}
+	return nil
+}
+
+func (yf *YAMLFormatter) decode(data []byte, v any) error {
 	dec := yaml.NewDecoder(bytes.NewReader(data))
 	for _, tweak := range yf.decoderTweaks {
 		tweak(dec)
 	}
 	return dec.Decode(v)
 }
+
+// Marshal encodes v as YAML indented with two spaces.
+func (yf *YAMLFormatter) Marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(canonicalIndent)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
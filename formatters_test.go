@@ -1,8 +1,10 @@
 package confgo
 
 import (
+	"errors"
 	"reflect"
 	"testing"
+	"time"
 )
 
 func TestEnvFormatter_parseRawIntoMap(t *testing.T) {
@@ -78,6 +80,112 @@ func TestEnvFormatter_parseRawIntoMap(t *testing.T) {
 	}
 }
 
+func TestUpperSnakeCase(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"port":          "PORT",
+		"readTimeout":   "READ_TIMEOUT",
+		"ReadTimeout":   "READ_TIMEOUT",
+		"read-timeout":  "READ_TIMEOUT",
+		"read_timeout":  "READ_TIMEOUT",
+		"HTTPServer":    "HTTP_SERVER",
+		"maxConns2":     "MAX_CONNS2",
+		"tls.cert--key": "TLS_CERT_KEY",
+		"_private_":     "PRIVATE",
+	}
+	for key, want := range tests {
+		if got := UpperSnakeCase(key); got != want {
+			t.Errorf("UpperSnakeCase(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestEnvFormatter_NamingStrategy(t *testing.T) {
+	t.Parallel()
+
+	type inner struct {
+		MaxConns int `json:"maxConns"`
+	}
+	type config struct {
+		ReadTimeout time.Duration     `json:"readTimeout"`
+		Host        string            `json:"host"        config:"HOST" env:"SERVER_HOST"`
+		Hosts       []string          `yaml:"hosts"`
+		Labels      map[string]string `json:"labels"`
+		Skipped     string            `json:"-"`
+		Inner       inner             `json:"inner"                     envPrefix:"INNER_"`
+	}
+	ef := NewEnvFormatter(EnvNamingStrategy(UpperSnakeCase), EnvTagName("config"))
+	data := []byte("READ_TIMEOUT=5s\nHOST=b\nSERVER_HOST=c\nHOSTS=x,y\nLABELS=a:1\nSKIPPED=z\nINNER_MAX_CONNS=3\n")
+	var got config
+	if err := ef.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	want := config{
+		ReadTimeout: 5 * time.Second,
+		Host:        "b",
+		Hosts:       []string{"x", "y"},
+		Labels:      map[string]string{"a": "1"},
+		Skipped:     "",
+		Inner:       inner{MaxConns: 3},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Unmarshal() = %+v, want %+v", got, want)
+	}
+
+	out, err := ef.Marshal(&want)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if wantOut := "HOST=b\nHOSTS=x,y\nINNER_MAX_CONNS=3\nLABELS=a:1\nREAD_TIMEOUT=5s\n"; string(out) != wantOut {
+		t.Errorf("Marshal() = %q, want %q", out, wantOut)
+	}
+
+	if err := ef.Unmarshal([]byte("READ_TIMEOUT=soon"), &got); !errors.Is(err, ErrInvalidFieldValue) {
+		t.Errorf("Unmarshal() of an invalid value error = %v, want ErrInvalidFieldValue", err)
+	}
+}
+
+func TestEnvFormatter_NestedSeparator(t *testing.T) {
+	t.Parallel()
+
+	type pool struct {
+		MaxConns int `json:"maxConns"`
+	}
+	type db struct {
+		Host string `env:"HOST"`
+		Pool *pool  `json:"pool"`
+	}
+	type config struct {
+		Server struct {
+			Port int `json:"port"`
+		} `json:"server"`
+		DB     db `json:"db"`
+		Legacy db `json:"legacy" envPrefix:"OLD_"`
+	}
+	ef := NewEnvFormatter(EnvNestedSeparator("__"))
+	data := []byte("DB__HOST=a\nDB__POOL__MAX_CONNS=10\nOLD_HOST=b\nOLD_POOL__MAX_CONNS=2\nSERVER__PORT=8080\n")
+	var got config
+	if err := ef.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	var want config
+	want.Server.Port = 8080
+	want.DB = db{Host: "a", Pool: &pool{MaxConns: 10}}
+	want.Legacy = db{Host: "b", Pool: &pool{MaxConns: 2}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Unmarshal() = %+v, want %+v", got, want)
+	}
+
+	out, err := ef.Marshal(&want)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if string(out) != string(data) {
+		t.Errorf("Marshal() = %q, want %q", out, data)
+	}
+}
+
 func TestJSONFormatter_Unmarshal(t *testing.T) {
 	type args struct {
 		data []byte
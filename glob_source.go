@@ -0,0 +1,130 @@
+package confgo
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+var (
+	_ Source    = (*GlobSource)(nil)
+	_ Formatter = (*GlobSource)(nil)
+	_ Watcher   = (*GlobWatcher)(nil)
+)
+
+// GlobSource is both a Source and a Formatter of the files matching a glob pattern, e.g. "configs/*.yaml",
+// as accepted by filepath.Glob. The pattern is expanded on every read and the matches are merged
+// as sequential layers in lexical order, as DirSource does with the files of a directory.
+// Matches with extensions WithFile does not support are ignored, no matches is an empty layer.
+type GlobSource struct {
+	pattern string
+}
+
+func NewGlobSource(pattern string) *GlobSource {
+	return &GlobSource{pattern: pattern}
+}
+
+// files returns the paths of the config files matching the pattern in lexical order.
+func (gs *GlobSource) files() ([]string, error) {
+	matches, err := filepath.Glob(gs.pattern)
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(matches, func(path string) bool {
+		_, err := formatterForFile(path)
+		return err != nil
+	}), nil
+}
+
+// Read reads the config files matching the pattern.
+func (gs *GlobSource) Read() ([]byte, error) {
+	paths, err := gs.files()
+	if err != nil {
+		return nil, err
+	}
+	return readFileLayers("", paths)
+}
+
+// Unmarshal parses the files read by Read and merges them into v in order.
+func (gs *GlobSource) Unmarshal(data []byte, v any) error {
+	return unmarshalFileLayers(data, v)
+}
+
+// GlobWatcher is a watcher that expands the pattern of GlobSource periodically and reports a change
+// whenever a match is added or removed, or the modification time or the size of a match changes.
+type GlobWatcher struct {
+	source   *GlobSource
+	interval time.Duration
+	stop     chan struct{}
+	last     []globMatch
+	// initialized is set after the first check.
+	initialized bool
+}
+
+// globMatch is the state of a file matching the pattern.
+type globMatch struct {
+	path    string
+	modTime time.Time
+	size    int64
+}
+
+func NewGlobWatcher(source *GlobSource) *GlobWatcher {
+	return &GlobWatcher{
+		source:      source,
+		interval:    pollInterval,
+		stop:        make(chan struct{}),
+		last:        nil,
+		initialized: false,
+	}
+}
+
+func (gw *GlobWatcher) Watch(callback func()) {
+	go func() {
+		for {
+			select {
+			case <-gw.stop:
+				return
+			case <-time.After(gw.interval):
+				matches, err := gw.source.matches()
+				if err != nil {
+					continue
+				}
+				if !gw.initialized {
+					gw.initialized = true
+					gw.last = matches
+				} else if !slices.EqualFunc(matches, gw.last, globMatch.equal) {
+					gw.last = matches
+					callback()
+				}
+			}
+		}
+	}()
+}
+
+func (gw *GlobWatcher) Stop() error {
+	close(gw.stop)
+	return nil
+}
+
+// matches returns the states of the config files matching the pattern in lexical order.
+// Files removed while being checked are skipped.
+func (gs *GlobSource) matches() ([]globMatch, error) {
+	paths, err := gs.files()
+	if err != nil {
+		return nil, err
+	}
+	matches := make([]globMatch, 0, len(paths))
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		matches = append(matches, globMatch{path: path, modTime: info.ModTime(), size: info.Size()})
+	}
+	return matches, nil
+}
+
+func (m globMatch) equal(other globMatch) bool {
+	return m.path == other.path && m.modTime.Equal(other.modTime) && m.size == other.size
+}
@@ -0,0 +1,70 @@
+package confgo
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestGlobSource(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	write := func(name, data string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("a.json", `{"int": 1, "slice": ["a"]}`)
+	write("b.json", `{"int": 2}`)
+	write("c.yaml", "int: 3\n")
+
+	updated := make(chan struct{}, 1)
+	s := NewGlobSource(filepath.Join(dir, "*.json"))
+	watcher := NewGlobWatcher(s)
+	watcher.interval = 10 * time.Millisecond
+	cm, err := NewConfigManager(testConfigConstructor, WithLoader(Loader{
+		Source:          s,
+		Formatter:       s,
+		Watcher:         watcher,
+		OnUpdateSuccess: func() { updated <- struct{}{} },
+	}))
+	if err != nil {
+		t.Fatalf("NewConfigManager() error = %v", err)
+	}
+	if err := cm.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer cm.MustStop()
+
+	if got, want := cm.Config(), (&TestConfig{Int: 2, Slice: []string{"a"}}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Config() = %+v, want %+v", got, want)
+	}
+
+	// Let the watcher take the initial matches before adding one.
+	time.Sleep(50 * time.Millisecond)
+	write("c.json", `{"int": 4}`)
+	select {
+	case <-updated:
+	case <-time.After(5 * time.Second):
+		t.Fatal("GlobWatcher did not report the added match")
+	}
+	if got := cm.Config().(*TestConfig).Int; got != 4 {
+		t.Errorf("Config().Int = %d, want 4", got)
+	}
+
+	if err := os.Remove(filepath.Join(dir, "c.json")); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-updated:
+	case <-time.After(5 * time.Second):
+		t.Fatal("GlobWatcher did not report the removed match")
+	}
+	if got := cm.Config().(*TestConfig).Int; got != 2 {
+		t.Errorf("Config().Int = %d, want 2", got)
+	}
+}
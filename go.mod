@@ -3,7 +3,7 @@ module github.com/TheVovchenskiy/confgo
 go 1.25.0
 
 require (
-	dario.cat/mergo v1.0.2
 	github.com/caarlos0/env/v11 v11.3.1
+	golang.org/x/time v0.15.0
 	gopkg.in/yaml.v3 v3.0.1
 )
@@ -0,0 +1,190 @@
+package confgo
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	reconnectBaseDelay = 100 * time.Millisecond
+	reconnectMaxDelay  = 30 * time.Second
+)
+
+// GRPCConfig is a configuration returned or pushed by the ConfigService of proto/confgo/v1/config_service.proto.
+type GRPCConfig struct {
+	Data    []byte
+	Version string
+}
+
+// GRPCConfigClient is a client of the ConfigService of proto/confgo/v1/config_service.proto.
+// The module does not depend on gRPC, so the client generated from the contract is wrapped
+// by a small adapter, e.g.:
+//
+//	type configClient struct{ c confgov1.ConfigServiceClient }
+//
+//	func (a configClient) GetConfig(ctx context.Context, name string) (confgo.GRPCConfig, error) {
+//		resp, err := a.c.GetConfig(ctx, &confgov1.GetConfigRequest{Name: name})
+//		if err != nil {
+//			return confgo.GRPCConfig{}, err
+//		}
+//		return confgo.GRPCConfig{Data: resp.GetData(), Version: resp.GetVersion()}, nil
+//	}
+//
+//	func (a configClient) WatchConfig(ctx context.Context, name, version string) (confgo.GRPCConfigStream, error) {
+//		stream, err := a.c.WatchConfig(ctx, &confgov1.WatchConfigRequest{Name: name, Version: version})
+//		if err != nil {
+//			return nil, err
+//		}
+//		return configStream{stream}, nil // Recv converts *confgov1.Config the same way.
+//	}
+type GRPCConfigClient interface {
+	// GetConfig returns the current configuration with the name.
+	GetConfig(ctx context.Context, name string) (GRPCConfig, error)
+	// WatchConfig opens the stream of the configurations with the name, starting after the version.
+	WatchConfig(ctx context.Context, name, version string) (GRPCConfigStream, error)
+}
+
+// GRPCConfigStream is a server stream of configurations opened by GRPCConfigClient.WatchConfig.
+type GRPCConfigStream interface {
+	// Recv blocks until the next configuration is pushed or the stream fails.
+	Recv() (GRPCConfig, error)
+}
+
+var (
+	_ Source        = (*GRPCSource)(nil)
+	_ SourceContext = (*GRPCSource)(nil)
+	_ Watcher       = (*GRPCWatcher)(nil)
+)
+
+// GRPCSource is a configuration source that fetches the configuration from a central config service.
+// The configuration pushed to GRPCWatcher is read without fetching it again.
+type GRPCSource struct {
+	client GRPCConfigClient
+	name   string
+
+	mu sync.Mutex
+	// pushed is the configuration pushed since the last read, nil if none.
+	pushed *GRPCConfig
+	// version is the version of the last read configuration.
+	version string
+}
+
+// NewGRPCSource creates a source fetching the configuration with the name, e.g. the name of the service.
+func NewGRPCSource(client GRPCConfigClient, name string) *GRPCSource {
+	return &GRPCSource{
+		client:  client,
+		name:    name,
+		mu:      sync.Mutex{},
+		pushed:  nil,
+		version: "",
+	}
+}
+
+func (gs *GRPCSource) Read() ([]byte, error) {
+	return gs.ReadContext(context.Background())
+}
+
+// ReadContext returns the pushed configuration, if any, or fetches the current one until ctx is done.
+func (gs *GRPCSource) ReadContext(ctx context.Context) ([]byte, error) {
+	gs.mu.Lock()
+	pushed := gs.pushed
+	gs.pushed = nil
+	gs.mu.Unlock()
+	if pushed != nil {
+		return pushed.Data, nil
+	}
+	cfg, err := gs.client.GetConfig(ctx, gs.name)
+	if err != nil {
+		return nil, err
+	}
+	gs.mu.Lock()
+	gs.version = cfg.Version
+	gs.mu.Unlock()
+	return cfg.Data, nil
+}
+
+// push stores the configuration pushed by the service and reports whether its version is new.
+func (gs *GRPCSource) push(cfg GRPCConfig) bool {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	if cfg.Version != "" && cfg.Version == gs.version {
+		return false
+	}
+	gs.pushed = &cfg
+	gs.version = cfg.Version
+	return true
+}
+
+func (gs *GRPCSource) lastVersion() string {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	return gs.version
+}
+
+// GRPCWatcher subscribes to the configurations pushed by the config service of GRPCSource
+// and calls the callback for every new version. A failed stream is reopened with exponential backoff.
+type GRPCWatcher struct {
+	source  *GRPCSource
+	backoff func(attempt int) time.Duration
+	stop    chan struct{}
+}
+
+func NewGRPCWatcher(source *GRPCSource) *GRPCWatcher {
+	return &GRPCWatcher{
+		source:  source,
+		backoff: ExponentialBackoff(reconnectBaseDelay, reconnectMaxDelay),
+		stop:    make(chan struct{}),
+	}
+}
+
+func (gw *GRPCWatcher) Watch(callback func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-gw.stop
+		cancel()
+	}()
+	go func() {
+		reconnect(ctx, gw.backoff, func() bool {
+			// The stream is canceled by Stop.
+			stream, err := gw.source.client.WatchConfig(ctx, gw.source.name, gw.source.lastVersion())
+			if err != nil {
+				return false
+			}
+			received := false
+			for {
+				cfg, err := stream.Recv()
+				if err != nil {
+					return received
+				}
+				received = true
+				if gw.source.push(cfg) {
+					callback()
+				}
+			}
+		})
+	}()
+}
+
+// Stop closes the stream.
+func (gw *GRPCWatcher) Stop() error {
+	close(gw.stop)
+	return nil
+}
+
+// reconnect calls connect until ctx is done, waiting between the calls for the backoff delay,
+// which is reset if connect reports that the connection has received anything.
+func reconnect(ctx context.Context, backoff func(attempt int) time.Duration, connect func() (received bool)) {
+	for attempt := 1; ctx.Err() == nil; attempt++ {
+		if connect() {
+			attempt = 1
+		}
+		timer := time.NewTimer(backoff(attempt))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		}
+	}
+}
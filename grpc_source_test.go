@@ -0,0 +1,113 @@
+package confgo
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeGRPCClient struct {
+	mu       sync.Mutex
+	current  GRPCConfig
+	gets     int
+	versions []string
+	streams  chan chan GRPCConfig
+}
+
+func (c *fakeGRPCClient) GetConfig(context.Context, string) (GRPCConfig, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.gets++
+	return c.current, nil
+}
+
+func (c *fakeGRPCClient) WatchConfig(ctx context.Context, _, version string) (GRPCConfigStream, error) {
+	c.mu.Lock()
+	c.versions = append(c.versions, version)
+	c.mu.Unlock()
+	select {
+	case ch := <-c.streams:
+		return fakeGRPCStream{ctx: ctx, ch: ch}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+type fakeGRPCStream struct {
+	ctx context.Context //nolint:containedctx // The stream is bound to the context like gRPC streams.
+	ch  chan GRPCConfig
+}
+
+func (s fakeGRPCStream) Recv() (GRPCConfig, error) {
+	select {
+	case cfg, ok := <-s.ch:
+		if !ok {
+			return GRPCConfig{}, errors.New("stream closed")
+		}
+		return cfg, nil
+	case <-s.ctx.Done():
+		return GRPCConfig{}, s.ctx.Err()
+	}
+}
+
+func TestGRPCSource(t *testing.T) {
+	t.Parallel()
+
+	client := &fakeGRPCClient{
+		current: GRPCConfig{Data: []byte(`{"int": 1}`), Version: "1"},
+		streams: make(chan chan GRPCConfig),
+	}
+	s := NewGRPCSource(client, "app")
+	w := NewGRPCWatcher(s)
+	w.backoff = func(int) time.Duration { return time.Millisecond }
+	updated := make(chan struct{}, 1)
+	cm, err := NewConfigManager(testConfigConstructor, WithLoader(Loader{
+		Source:          s,
+		Formatter:       NewJSONFormatter(),
+		Watcher:         w,
+		OnUpdateSuccess: func() { updated <- struct{}{} },
+	}))
+	if err != nil {
+		t.Fatalf("NewConfigManager() error = %v", err)
+	}
+	if err := cm.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer cm.MustStop()
+
+	push := func(stream chan GRPCConfig, cfg GRPCConfig, want int) {
+		t.Helper()
+		stream <- cfg
+		select {
+		case <-updated:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("GRPCWatcher did not report version %q", cfg.Version)
+		}
+		if got := cm.Config().(*TestConfig).Int; got != want {
+			t.Errorf("Config().Int = %d, want %d", got, want)
+		}
+	}
+
+	stream := make(chan GRPCConfig)
+	client.streams <- stream
+	// The current version is skipped.
+	stream <- GRPCConfig{Data: []byte(`{"int": 1}`), Version: "1"}
+	push(stream, GRPCConfig{Data: []byte(`{"int": 2}`), Version: "2"}, 2)
+
+	// The failed stream is reopened after the last received version.
+	close(stream)
+	stream = make(chan GRPCConfig)
+	client.streams <- stream
+	push(stream, GRPCConfig{Data: []byte(`{"int": 3}`), Version: "3"}, 3)
+
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	if client.gets != 1 {
+		t.Errorf("GetConfig called %d times, want 1", client.gets)
+	}
+	if len(client.versions) < 2 || client.versions[0] != "1" || client.versions[1] != "2" {
+		t.Errorf("WatchConfig versions = %q, want [1 2 ...]", client.versions)
+	}
+}
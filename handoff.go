@@ -0,0 +1,110 @@
+package confgo
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+)
+
+// HandOff replaces cm with next, e.g. a manager constructed with a different set of loaders
+// after a bootstrap reload changed the deployment topology, without a gap for long-lived consumers:
+//   - the runtime overrides set by SetOverride and SetConfig and the audit log are copied to next,
+//     if next is constructed for the same config type and has none of its own;
+//   - the generation of next continues from the one of cm, so ConfigWithVersion reports the handoff as a change;
+//   - next is started if it is not running yet, cm is left intact if it fails to start;
+//   - the watchers of cm are stopped;
+//   - the subscriptions of cm, including channel ones, are moved to next and called once
+//     with the last configuration of cm and the current configuration of next.
+//
+// After the handoff Config, Subscribe, SubscribeChan and Stats of cm are served by next,
+// so references to cm held by consumers keep working. A manager can be handed off only once.
+func (cm *ConfigManager) HandOff(next *ConfigManager) error {
+	if next == nil || next == cm || next.handedOff.Load() != nil {
+		return ErrInvalidHandOffTarget
+	}
+	if cm.handedOff.Load() != nil {
+		return ErrHandedOff
+	}
+
+	cm.copyRuntimeState(next)
+	cm.mu.RLock()
+	version := cm.reloadStatus.Version
+	cm.mu.RUnlock()
+	next.mu.Lock()
+	next.reloadStatus.Version = max(next.reloadStatus.Version, version)
+	next.mu.Unlock()
+	if next.isRunning.Load() {
+		if err := next.reload(); err != nil {
+			return fmt.Errorf("reload next config manager: %w", err)
+		}
+	} else if err := next.Start(); err != nil {
+		return fmt.Errorf("start next config manager: %w", err)
+	}
+	stopErr := cm.Stop()
+
+	cm.subMu.Lock()
+	if !cm.handedOff.CompareAndSwap(nil, next) {
+		cm.subMu.Unlock()
+		return errors.Join(ErrHandedOff, stopErr)
+	}
+	moved := cm.subscribers
+	cm.subscribers = nil
+	chanSubscribers := cm.chanSubscribers.Swap(0)
+	cm.subMu.Unlock()
+
+	next.subMu.Lock()
+	next.subscribers = append(moved, next.subscribers...)
+	next.subMu.Unlock()
+	next.chanSubscribers.Add(chanSubscribers)
+	next.chanDelivered.Add(cm.chanDelivered.Swap(0))
+	next.chanDropped.Add(cm.chanDropped.Swap(0))
+
+	last, _ := cm.loadCurrent()
+	next.notify(moved, last, next.Config())
+
+	if stopErr != nil {
+		return fmt.Errorf("stop handed off config manager: %w", stopErr)
+	}
+	return nil
+}
+
+// active returns the manager which serves the configuration of cm, following its handoffs.
+func (cm *ConfigManager) active() *ConfigManager {
+	for next := cm.handedOff.Load(); next != nil; next = cm.handedOff.Load() {
+		cm = next
+	}
+	return cm
+}
+
+// copyRuntimeState copies the runtime overrides and the audit log of cm to next.
+func (cm *ConfigManager) copyRuntimeState(next *ConfigManager) {
+	if cm.constructor == nil || next.constructor == nil ||
+		reflect.TypeOf(cm.constructor()) != reflect.TypeOf(next.constructor()) {
+		return
+	}
+
+	cm.overridesMu.Lock()
+	overrides, overrideMeta := maps.Clone(cm.overrides), maps.Clone(cm.overrideMeta)
+	adminConfig, adminConfigMeta := cm.adminConfig, cm.adminConfigMeta
+	patches := cm.patches
+	cm.overridesMu.Unlock()
+	next.overridesMu.Lock()
+	if len(next.overrides) == 0 && next.adminConfig == nil && len(next.patches) == 0 {
+		next.overrides, next.overrideMeta = overrides, overrideMeta
+		next.adminConfig, next.adminConfigMeta = adminConfig, adminConfigMeta
+		next.patches = patches
+	}
+	next.overridesMu.Unlock()
+
+	cm.auditMu.Lock()
+	audit := slices.Clone(cm.audit)
+	cm.auditMu.Unlock()
+	next.auditMu.Lock()
+	defer next.auditMu.Unlock()
+	if len(next.audit) == 0 && next.auditSize > 0 {
+		audit = audit[max(len(audit)-next.auditSize, 0):]
+		next.audit = audit
+	}
+}
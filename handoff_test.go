@@ -0,0 +1,106 @@
+package confgo
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestConfigManager_HandOff(t *testing.T) {
+	t.Parallel()
+
+	cm := newTestOverridesManager(t, TestConfig{Int: 1, Inner: testInnerConfig{String: "old"}})
+	if err := cm.SetOverride("int", 10, ChangeMeta{Actor: "alice"}); err != nil {
+		t.Fatalf("SetOverride() error = %v", err)
+	}
+
+	type call struct {
+		oldCfg any
+		newCfg any
+	}
+	var calls []call
+	cm.Subscribe(func(oldCfg, newCfg any) {
+		calls = append(calls, call{oldCfg: oldCfg, newCfg: newCfg})
+	})
+	ch, unsubscribeChan := cm.SubscribeChan()
+
+	formatter := &fakeFormatter{data: TestConfig{Int: 2, Inner: testInnerConfig{String: "new"}}}
+	next, err := NewConfigManager(testConfigConstructor, func(cm *ConfigManager) error {
+		cm.AddLoader(Loader{Source: &fakeSource{data: []byte("test")}, Formatter: formatter})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("NewConfigManager() error = %v", err)
+	}
+	t.Cleanup(next.MustStop)
+
+	versionBefore := cm.Version()
+	if err := cm.HandOff(next); err != nil {
+		t.Fatalf("HandOff() error = %v", err)
+	}
+	if got := cm.Version(); got != versionBefore+1 {
+		t.Errorf("Version() after handoff = %d, want %d", got, versionBefore+1)
+	}
+	if err := cm.HandOff(next); !errors.Is(err, ErrHandedOff) {
+		t.Errorf("HandOff() again error = %v, want %v", err, ErrHandedOff)
+	}
+
+	oldCfg := &TestConfig{Int: 10, Inner: testInnerConfig{String: "old"}}
+	newCfg := &TestConfig{Int: 10, Inner: testInnerConfig{String: "new"}}
+	if got := cm.Config(); !reflect.DeepEqual(got, newCfg) {
+		t.Errorf("Config() = %v, want %v", got, newCfg)
+	}
+	if got := next.FieldAudit("int"); len(got) != 1 || got[0].Actor != "alice" {
+		t.Errorf("next.FieldAudit() = %v, want the record of alice", got)
+	}
+
+	formatter.data = TestConfig{Int: 3}
+	if err := next.reload(); err != nil {
+		t.Fatalf("reload() error = %v", err)
+	}
+	wantCalls := []call{
+		{oldCfg: oldCfg, newCfg: newCfg},
+		{oldCfg: newCfg, newCfg: &TestConfig{Int: 10}},
+	}
+	if !reflect.DeepEqual(calls, wantCalls) {
+		t.Errorf("subscriber calls = %v, want %v", calls, wantCalls)
+	}
+	if got := cm.Stats(); got.Subscribers != 2 || got.ChannelSubscribers != 1 {
+		t.Errorf("Stats() = %+v, want 2 subscribers and 1 channel subscriber", got)
+	}
+
+	unsubscribeChan()
+	var gotInts []int
+	for change := range ch {
+		gotInts = append(gotInts, change.New.(*TestConfig).Int)
+	}
+	if want := []int{10, 10}; !reflect.DeepEqual(gotInts, want) {
+		t.Errorf("received configs = %v, want %v", gotInts, want)
+	}
+	if got := next.Stats(); got.Subscribers != 1 || got.ChannelSubscribers != 0 {
+		t.Errorf("next.Stats() after unsubscribe = %+v, want 1 subscriber", got)
+	}
+}
+
+func TestConfigManager_HandOff_StartError(t *testing.T) {
+	t.Parallel()
+
+	cm := newTestOverridesManager(t, TestConfig{Int: 1})
+	next, err := NewConfigManager(testConfigConstructor)
+	if err != nil {
+		t.Fatalf("NewConfigManager() error = %v", err)
+	}
+
+	if err := cm.HandOff(next); !errors.Is(err, ErrNoLoadersDefined) {
+		t.Fatalf("HandOff() error = %v, want %v", err, ErrNoLoadersDefined)
+	}
+	if err := cm.HandOff(nil); !errors.Is(err, ErrInvalidHandOffTarget) {
+		t.Errorf("HandOff(nil) error = %v, want %v", err, ErrInvalidHandOffTarget)
+	}
+	if got := cm.Config(); !reflect.DeepEqual(got, &TestConfig{Int: 1}) {
+		t.Errorf("Config() = %v, want the config of the original manager", got)
+	}
+	if !cm.isRunning.Load() {
+		t.Error("original manager has been stopped by the failed handoff")
+	}
+}
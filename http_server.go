@@ -0,0 +1,312 @@
+package confgo
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const defaultShutdownTimeout = 30 * time.Second
+
+// HTTPServerSettings is a set of http.Server settings which can be changed at runtime.
+type HTTPServerSettings struct {
+	// Addr is a TCP address to listen on.
+	Addr              string
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	// CertFile and KeyFile enable TLS if both are set.
+	CertFile string
+	KeyFile  string
+	// ShutdownTimeout limits the time given to the replaced server to finish in-flight requests.
+	// Zero means 30 seconds.
+	ShutdownTimeout time.Duration
+}
+
+func (s HTTPServerSettings) tlsEnabled() bool {
+	return s.CertFile != "" && s.KeyFile != ""
+}
+
+// HTTPServerSettingsFunc extracts HTTPServerSettings from the configuration.
+type HTTPServerSettingsFunc func(cfg any) HTTPServerSettings
+
+// HTTPServer is an *http.Server that is rebuilt every time its settings change in the configuration.
+//
+// When settings change, a new *http.Server is started first and only then the old one is gracefully shut down,
+// so no connections are refused during the swap. If the address is unchanged, the listening socket is handed
+// over to the new server, otherwise a new socket is opened before the old one is closed.
+// TLS certificates are loaded on every rebuild, so certificate rotation only requires a change of settings.
+type HTTPServer struct {
+	cm          *ConfigManager
+	handler     http.Handler
+	settings    HTTPServerSettingsFunc
+	onError     CallbackErrFunc
+	mu          sync.Mutex
+	server      *http.Server
+	mux         *listenerMux
+	current     HTTPServerSettings
+	unsubscribe func()
+}
+
+// NewHTTPServer creates a new HTTPServer serving handler with the settings extracted from cm configuration.
+// onError is called if the server fails or cannot be rebuilt with new settings, it may be nil.
+func NewHTTPServer(
+	cm *ConfigManager,
+	handler http.Handler,
+	settings HTTPServerSettingsFunc,
+	onError CallbackErrFunc,
+) *HTTPServer {
+	return &HTTPServer{
+		cm:          cm,
+		handler:     handler,
+		settings:    settings,
+		onError:     onError,
+		mu:          sync.Mutex{},
+		server:      nil,
+		mux:         nil,
+		current:     HTTPServerSettings{},
+		unsubscribe: nil,
+	}
+}
+
+// Start starts serving with the settings of the current configuration and subscribes to its changes.
+// Config manager must be started beforehand.
+func (s *HTTPServer) Start() error {
+	cfg := s.cm.Config()
+	if cfg == nil {
+		return ErrConfigNotLoaded
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.server != nil {
+		return nil
+	}
+
+	settings := s.settings(cfg)
+	mux, err := newListenerMux(settings.Addr)
+	if err != nil {
+		return err
+	}
+	server, err := s.newServer(settings)
+	if err != nil {
+		_ = mux.Close()
+		return err
+	}
+	s.mux, s.server, s.current = mux, server, settings
+	s.serve(server, mux.listener(), settings)
+	s.unsubscribe = s.cm.Subscribe(func(_, newCfg any) {
+		s.apply(s.settings(newCfg))
+	})
+	return nil
+}
+
+// Shutdown stops watching configuration changes and gracefully shuts down the server.
+func (s *HTTPServer) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.server == nil {
+		return nil
+	}
+	s.unsubscribe()
+	err := s.server.Shutdown(ctx)
+	err = errors.Join(err, s.mux.Close())
+	s.server, s.mux = nil, nil
+	return err
+}
+
+// Addr returns the address the server is listening on or nil if it is not running.
+func (s *HTTPServer) Addr() net.Addr {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.mux == nil {
+		return nil
+	}
+	return s.mux.Addr()
+}
+
+func (s *HTTPServer) newServer(settings HTTPServerSettings) (*http.Server, error) {
+	server := &http.Server{
+		Handler:           s.handler,
+		ReadTimeout:       settings.ReadTimeout,
+		ReadHeaderTimeout: settings.ReadHeaderTimeout,
+		WriteTimeout:      settings.WriteTimeout,
+		IdleTimeout:       settings.IdleTimeout,
+	}
+	if settings.tlsEnabled() {
+		cert, err := tls.LoadX509KeyPair(settings.CertFile, settings.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load tls key pair: %w", err)
+		}
+		server.TLSConfig = &tls.Config{
+			Certificates: []tls.Certificate{cert},
+			MinVersion:   tls.VersionTLS12,
+		}
+	}
+	return server, nil
+}
+
+func (s *HTTPServer) serve(server *http.Server, l net.Listener, settings HTTPServerSettings) {
+	go func() {
+		var err error
+		if settings.tlsEnabled() {
+			err = server.ServeTLS(l, "", "")
+		} else {
+			err = server.Serve(l)
+		}
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.reportError(fmt.Errorf("serve http: %w", err))
+		}
+	}()
+}
+
+func (s *HTTPServer) reportError(err error) {
+	if s.onError != nil {
+		s.onError(err)
+	}
+}
+
+func (s *HTTPServer) apply(settings HTTPServerSettings) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.server == nil || settings == s.current {
+		return
+	}
+
+	mux, oldMux := s.mux, (*listenerMux)(nil)
+	if settings.Addr != s.current.Addr {
+		newMux, err := newListenerMux(settings.Addr)
+		if err != nil {
+			s.reportError(fmt.Errorf("rebuild http server: %w", err))
+			return
+		}
+		mux, oldMux = newMux, s.mux
+	}
+	server, err := s.newServer(settings)
+	if err != nil {
+		if oldMux != nil {
+			_ = mux.Close()
+		}
+		s.reportError(fmt.Errorf("rebuild http server: %w", err))
+		return
+	}
+
+	oldServer, oldSettings := s.server, s.current
+	s.server, s.mux, s.current = server, mux, settings
+	s.serve(server, mux.listener(), settings)
+
+	go func() {
+		timeout := oldSettings.ShutdownTimeout
+		if timeout == 0 {
+			timeout = defaultShutdownTimeout
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		if err := oldServer.Shutdown(ctx); err != nil {
+			s.reportError(fmt.Errorf("shutdown replaced http server: %w", err))
+		}
+		if oldMux != nil {
+			_ = oldMux.Close()
+		}
+	}()
+}
+
+// listenerMux accepts connections on a single socket and hands them to the listeners created by it,
+// so the socket can be passed from one server to another without being closed.
+type listenerMux struct {
+	base    net.Listener
+	conns   chan net.Conn
+	closing chan struct{}
+	done    chan struct{}
+	err     error
+}
+
+func newListenerMux(addr string) (*listenerMux, error) {
+	base, err := (&net.ListenConfig{}).Listen(context.Background(), "tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("listen %q: %w", addr, err)
+	}
+	mux := &listenerMux{
+		base:    base,
+		conns:   make(chan net.Conn),
+		closing: make(chan struct{}),
+		done:    make(chan struct{}),
+		err:     nil,
+	}
+	go mux.acceptLoop()
+	return mux, nil
+}
+
+func (m *listenerMux) acceptLoop() {
+	defer close(m.done)
+	for {
+		conn, err := m.base.Accept()
+		if err != nil {
+			m.err = err
+			return
+		}
+		select {
+		case m.conns <- conn:
+		case <-m.closing:
+			_ = conn.Close()
+			m.err = net.ErrClosed
+			return
+		}
+	}
+}
+
+func (m *listenerMux) listener() net.Listener {
+	return &muxListener{
+		mux:       m,
+		closed:    make(chan struct{}),
+		closeOnce: sync.Once{},
+	}
+}
+
+func (m *listenerMux) Addr() net.Addr {
+	return m.base.Addr()
+}
+
+func (m *listenerMux) Close() error {
+	close(m.closing)
+	err := m.base.Close()
+	<-m.done
+	return err
+}
+
+type muxListener struct {
+	mux       *listenerMux
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+func (l *muxListener) Accept() (net.Conn, error) {
+	select {
+	case <-l.closed:
+		return nil, net.ErrClosed
+	default:
+	}
+	select {
+	case conn := <-l.mux.conns:
+		return conn, nil
+	case <-l.closed:
+		return nil, net.ErrClosed
+	case <-l.mux.done:
+		return nil, l.mux.err
+	}
+}
+
+func (l *muxListener) Close() error {
+	l.closeOnce.Do(func() { close(l.closed) })
+	return nil
+}
+
+func (l *muxListener) Addr() net.Addr {
+	return l.mux.Addr()
+}
@@ -0,0 +1,181 @@
+package confgo
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type testHTTPConfig struct {
+	Addr        string `json:"addr"`
+	IdleTimeout int    `json:"idle_timeout"`
+}
+
+func testHTTPSettings(cfg any) HTTPServerSettings {
+	httpCfg, _ := cfg.(*testHTTPConfig)
+	return HTTPServerSettings{
+		Addr:            httpCfg.Addr,
+		IdleTimeout:     time.Duration(httpCfg.IdleTimeout) * time.Second,
+		ShutdownTimeout: time.Second,
+	}
+}
+
+func httpGet(t *testing.T, url string) string {
+	t.Helper()
+
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatalf("NewRequest() error = %v", err)
+	}
+	client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("GET %s error = %v", url, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("read body error = %v", err)
+	}
+	return string(body)
+}
+
+//nolint:cyclop
+func TestHTTPServer_RebuildOnChange(t *testing.T) {
+	t.Parallel()
+
+	file := filepath.Join(t.TempDir(), "config.json")
+	if err := updateJSONFile(file, map[string]any{"addr": "127.0.0.1:0", "idle_timeout": 1}); err != nil {
+		t.Fatalf("failed to setup json config: %v", err)
+	}
+
+	watcher := NewTriggerWatcher()
+	cm, err := NewConfigManagerFor[testHTTPConfig]()
+	if err != nil {
+		t.Fatalf("NewConfigManagerFor() error = %v", err)
+	}
+	cm.AddLoader(Loader{Source: NewFileSource(file), Formatter: NewJSONFormatter(), Watcher: watcher})
+	cm.MustStart()
+	defer cm.MustStop()
+
+	errs := make(chan error, 10)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, "ok")
+	})
+	server := NewHTTPServer(cm, handler, testHTTPSettings, func(err error) { errs <- err })
+	if err := server.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer func() {
+		if err := server.Shutdown(context.Background()); err != nil {
+			t.Errorf("Shutdown() error = %v", err)
+		}
+	}()
+
+	addr := server.Addr().String()
+	if got := httpGet(t, "http://"+addr); got != "ok" {
+		t.Fatalf("GET body = %q, want %q", got, "ok")
+	}
+
+	// Same address: the socket is handed over to the new server.
+	firstServer := server.server
+	if err := updateJSONFile(file, map[string]any{"addr": "127.0.0.1:0", "idle_timeout": 2}); err != nil {
+		t.Fatalf("failed to update json config: %v", err)
+	}
+	watcher.Trigger()
+	if server.server == firstServer {
+		t.Fatalf("server was not rebuilt")
+	}
+	if server.server.IdleTimeout != 2*time.Second {
+		t.Fatalf("IdleTimeout = %v, want %v", server.server.IdleTimeout, 2*time.Second)
+	}
+	if got := server.Addr().String(); got != addr {
+		t.Fatalf("Addr() = %q, want %q", got, addr)
+	}
+	if got := httpGet(t, "http://"+addr); got != "ok" {
+		t.Fatalf("GET body = %q, want %q", got, "ok")
+	}
+
+	// New address: a new socket is opened.
+	if err := updateJSONFile(file, map[string]any{"addr": "localhost:0", "idle_timeout": 2}); err != nil {
+		t.Fatalf("failed to update json config: %v", err)
+	}
+	watcher.Trigger()
+	newAddr := server.Addr().String()
+	if newAddr == addr {
+		t.Fatalf("Addr() was not changed")
+	}
+	if got := httpGet(t, "http://"+newAddr); got != "ok" {
+		t.Fatalf("GET body = %q, want %q", got, "ok")
+	}
+
+	select {
+	case err := <-errs:
+		t.Fatalf("unexpected error: %v", err)
+	default:
+	}
+}
+
+func TestHTTPServer_KeepsServingOnInvalidSettings(t *testing.T) {
+	t.Parallel()
+
+	file := filepath.Join(t.TempDir(), "config.json")
+	if err := updateJSONFile(file, map[string]any{"addr": "127.0.0.1:0"}); err != nil {
+		t.Fatalf("failed to setup json config: %v", err)
+	}
+
+	watcher := NewTriggerWatcher()
+	cm, err := NewConfigManagerFor[testHTTPConfig]()
+	if err != nil {
+		t.Fatalf("NewConfigManagerFor() error = %v", err)
+	}
+	cm.AddLoader(Loader{Source: NewFileSource(file), Formatter: NewJSONFormatter(), Watcher: watcher})
+	cm.MustStart()
+	defer cm.MustStop()
+
+	errs := make(chan error, 10)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, "ok")
+	})
+	server := NewHTTPServer(cm, handler, testHTTPSettings, func(err error) { errs <- err })
+	if err := server.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer func() {
+		if err := server.Shutdown(context.Background()); err != nil {
+			t.Errorf("Shutdown() error = %v", err)
+		}
+	}()
+	addr := server.Addr().String()
+
+	if err := updateJSONFile(file, map[string]any{"addr": "256.0.0.1:0"}); err != nil {
+		t.Fatalf("failed to update json config: %v", err)
+	}
+	watcher.Trigger()
+
+	select {
+	case <-errs:
+		// ok
+	default:
+		t.Fatalf("expected rebuild error")
+	}
+	if got := httpGet(t, "http://"+addr); got != "ok" {
+		t.Fatalf("GET body = %q, want %q", got, "ok")
+	}
+}
+
+func TestHTTPServer_StartBeforeLoad(t *testing.T) {
+	t.Parallel()
+
+	cm, err := NewConfigManagerFor[testHTTPConfig]()
+	if err != nil {
+		t.Fatalf("NewConfigManagerFor() error = %v", err)
+	}
+	server := NewHTTPServer(cm, http.NotFoundHandler(), testHTTPSettings, nil)
+	if err := server.Start(); err != ErrConfigNotLoaded {
+		t.Fatalf("Start() error = %v, want %v", err, ErrConfigNotLoaded)
+	}
+}
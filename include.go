@@ -0,0 +1,185 @@
+package confgo
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const defaultIncludeKey = "__include"
+
+var _ Transformer = (*IncludeTransformer)(nil)
+
+// IncludeTransformerOption option that configures include transformer.
+type IncludeTransformerOption func(it *IncludeTransformer)
+
+// IncludeKey sets the key of the include directive, "__include" by default, e.g. "$include".
+func IncludeKey(key string) IncludeTransformerOption {
+	return func(it *IncludeTransformer) {
+		it.key = key
+	}
+}
+
+// IncludeTransformer is a transformer that resolves include directives of JSON or YAML data,
+// so big configs can be split into modules:
+//
+//	__include: [server.yaml, db.yaml]
+//	server:
+//	  port: 8081
+//
+// The directive holds a path or a list of paths of JSON or YAML files relative to the directory of the including
+// file. It may appear in any object, and the included documents, which must be objects, are merged into that object
+// one after another with the keys of the object itself applied last, all as JSON merge patches, so null values
+// remove the included keys. Included files may include other files, and include cycles are reported
+// with ErrIncludeCycle. Data without include directives is returned unchanged.
+//
+// Included files are read on every transform, but changes to them do not trigger reloads by themselves.
+type IncludeTransformer struct {
+	file   string
+	format string
+	key    string
+}
+
+// NewIncludeTransformer creates an include transformer for the data of the file, which must have
+// the ".json", ".yaml" or ".yml" extension. The transformed data is encoded in the format of the file.
+func NewIncludeTransformer(file string, opts ...IncludeTransformerOption) (*IncludeTransformer, error) {
+	var format string
+	switch ext := filepath.Ext(file); strings.ToLower(ext) {
+	case ".json":
+		format = "json"
+	case ".yaml", ".yml":
+		format = "yaml"
+	default:
+		return nil, fmt.Errorf("%w: including file %q: unsupported extension %q", ErrUnknownFormat, file, ext)
+	}
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return nil, err
+	}
+	it := &IncludeTransformer{file: abs, format: format, key: defaultIncludeKey}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(it)
+		}
+	}
+	return it, nil
+}
+
+func (it *IncludeTransformer) Transform(data []byte) ([]byte, error) {
+	var doc any
+	// JSON is valid YAML, so both formats are parsed the same way. The formatter reports malformed data.
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return data, nil
+	}
+	found := false
+	doc, err := it.resolve(doc, []string{it.file}, &found)
+	if err != nil || !found {
+		return data, err
+	}
+	if it.format == "json" {
+		return json.Marshal(doc)
+	}
+	return yaml.Marshal(doc)
+}
+
+// resolve returns the value v of the file at the end of the include chain with the include directives
+// replaced by the included documents, setting found if there are any.
+func (it *IncludeTransformer) resolve(v any, chain []string, found *bool) (any, error) {
+	switch v := v.(type) {
+	case []any:
+		for i, item := range v {
+			resolved, err := it.resolve(item, chain, found)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = resolved
+		}
+		return v, nil
+	case map[string]any:
+		for key, value := range v {
+			if key == it.key {
+				continue
+			}
+			resolved, err := it.resolve(value, chain, found)
+			if err != nil {
+				return nil, err
+			}
+			v[key] = resolved
+		}
+		directive, ok := v[it.key]
+		if !ok {
+			return v, nil
+		}
+		*found = true
+		delete(v, it.key)
+		paths, err := it.includePaths(directive, chain[len(chain)-1])
+		if err != nil {
+			return nil, err
+		}
+		var merged any = map[string]any{}
+		for _, path := range paths {
+			included, err := it.include(path, chain, found)
+			if err != nil {
+				return nil, err
+			}
+			merged = mergePatch(merged, included)
+		}
+		return mergePatch(merged, v), nil
+	default:
+		return v, nil
+	}
+}
+
+// include returns the resolved document of the included file at the path.
+func (it *IncludeTransformer) include(path string, chain []string, found *bool) (any, error) {
+	if i := slices.Index(chain, path); i >= 0 {
+		return nil, fmt.Errorf("%w: %s", ErrIncludeCycle, strings.Join(append(chain[i:], path), " -> "))
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("include %q: %w", path, err)
+	}
+	var doc any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("include %q: %w", path, err)
+	}
+	if _, ok := doc.(map[string]any); !ok {
+		return nil, fmt.Errorf("%w: included file %q is not an object", ErrInvalidInclude, path)
+	}
+	return it.resolve(doc, append(slices.Clip(chain), path), found)
+}
+
+// includePaths returns the absolute paths of the files included by the directive of the file.
+func (it *IncludeTransformer) includePaths(directive any, file string) ([]string, error) {
+	var paths []string
+	switch d := directive.(type) {
+	case string:
+		paths = []string{d}
+	case []any:
+		for _, item := range d {
+			path, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("%w: %q of %q must hold paths", ErrInvalidInclude, it.key, file)
+			}
+			paths = append(paths, path)
+		}
+	default:
+		return nil, fmt.Errorf("%w: %q of %q must hold a path or a list of paths", ErrInvalidInclude, it.key, file)
+	}
+	for i, path := range paths {
+		if path == "" {
+			return nil, fmt.Errorf("%w: %q of %q holds an empty path", ErrInvalidInclude, it.key, file)
+		}
+		if filepath.IsAbs(path) {
+			paths[i] = filepath.Clean(path)
+		} else {
+			paths[i] = filepath.Join(filepath.Dir(file), path)
+		}
+	}
+	return paths, nil
+}
@@ -0,0 +1,116 @@
+package confgo
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestWithFileIncludes(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"config.yaml": "__include: [base.json, modules/inner.yaml]\nint: 1\nmap:\n  b: null\n" +
+			"innerptr:\n  __include: modules/ptr.yaml\n  string: own\n",
+		"base.json":           `{"int": 5, "slice": ["x"], "map": {"a": "1", "b": "2"}}`,
+		"modules/inner.yaml":  "__include: ../shared.yaml\ninner:\n  int: 2\n",
+		"modules/ptr.yaml":    "int: 3\nstring: included\n",
+		"shared.yaml":         "inner:\n  int: 7\n  string: shared\n",
+		"plain.json":          `{"int": 9}`,
+		"cycle.yaml":          "__include: modules/cycle.yaml\n",
+		"modules/cycle.yaml":  "__include: [../shared.yaml, ../cycle.yaml]\n",
+		"invalid.yaml":        "__include: {a: b}\n",
+		"scalar.yaml":         "__include: modules/scalar.yaml\n",
+		"modules/scalar.yaml": "just a string\n",
+		"custom.json":         `{"$include": "base.json", "int": 4}`,
+	}
+	if err := os.Mkdir(filepath.Join(dir, "modules"), 0o700); err != nil {
+		t.Fatalf("Mkdir() error = %v", err)
+	}
+	for file, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, file), []byte(data), 0o600); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		file    string
+		opts    []IncludeTransformerOption
+		want    *TestConfig
+		wantErr error
+	}{
+		{
+			name: "nested includes",
+			file: "config.yaml",
+			want: &TestConfig{
+				Int:      1,
+				Inner:    testInnerConfig{Int: 2, String: "shared"},
+				InnerPtr: &testInnerConfig{Int: 3, String: "own"},
+				Map:      map[string]string{"a": "1"},
+				Slice:    []string{"x"},
+			},
+		},
+		{
+			name: "no includes",
+			file: "plain.json",
+			want: &TestConfig{Int: 9},
+		},
+		{
+			name: "custom key",
+			file: "custom.json",
+			opts: []IncludeTransformerOption{IncludeKey("$include")},
+			want: &TestConfig{Int: 4, Map: map[string]string{"a": "1", "b": "2"}, Slice: []string{"x"}},
+		},
+		{
+			name:    "cycle",
+			file:    "cycle.yaml",
+			wantErr: ErrIncludeCycle,
+		},
+		{
+			name:    "invalid directive",
+			file:    "invalid.yaml",
+			wantErr: ErrInvalidInclude,
+		},
+		{
+			name:    "not an object",
+			file:    "scalar.yaml",
+			wantErr: ErrInvalidInclude,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cm, err := NewConfigManager(testConfigConstructor, WithFileIncludes(filepath.Join(dir, tt.file), tt.opts...))
+			if err != nil {
+				t.Fatalf("NewConfigManager() error = %v", err)
+			}
+			err = cm.Start()
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Start() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Start() error = %v", err)
+			}
+			defer cm.MustStop()
+			if got := cm.Config(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Config() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewIncludeTransformer(t *testing.T) {
+	t.Parallel()
+
+	if _, err := NewIncludeTransformer("config.toml"); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("NewIncludeTransformer() error = %v, want ErrUnknownFormat", err)
+	}
+}
@@ -0,0 +1,92 @@
+package confgo
+
+import (
+	"bytes"
+	"fmt"
+)
+
+var _ Formatter = (*JSONCFormatter)(nil)
+
+// JSONCFormatter is a formatter that parses JSON with comments (JSONC), commonly used in hand-edited
+// config files. It accepts "//" line comments, "/* */" block comments and trailing commas in objects
+// and arrays, strips them and decodes the rest as JSONFormatter does.
+//
+// Stripped comments are replaced with whitespace, so offsets in decoding errors point to the original data.
+type JSONCFormatter struct {
+	json *JSONFormatter
+}
+
+func NewJSONCFormatter(opts ...JSONFormatterOption) *JSONCFormatter {
+	return &JSONCFormatter{json: NewJSONFormatter(opts...)}
+}
+
+func (jf *JSONCFormatter) Unmarshal(data []byte, v any) error {
+	data, err := stripJSONC(data)
+	if err != nil {
+		return err
+	}
+	return jf.json.Unmarshal(data, v)
+}
+
+// Marshal encodes v as JSON indented with two spaces, which is valid JSONC.
+func (jf *JSONCFormatter) Marshal(v any) ([]byte, error) {
+	return jf.json.Marshal(v)
+}
+
+// stripJSONC replaces comments and trailing commas outside of strings with whitespace keeping line breaks.
+func stripJSONC(data []byte) ([]byte, error) {
+	res := bytes.Clone(data)
+	// lastComma is the index of the last comma not followed by a meaningful character yet.
+	lastComma := -1
+	for i := 0; i < len(res); i++ {
+		switch c := res[i]; {
+		case c == '"':
+			end, err := jsoncStringEnd(res, i)
+			if err != nil {
+				return nil, err
+			}
+			i = end
+			lastComma = -1
+		case c == '/' && i+1 < len(res) && res[i+1] == '/':
+			for ; i < len(res) && res[i] != '\n'; i++ {
+				res[i] = ' '
+			}
+		case c == '/' && i+1 < len(res) && res[i+1] == '*':
+			end := bytes.Index(res[i+2:], []byte("*/"))
+			if end < 0 {
+				return nil, fmt.Errorf("%w: unterminated block comment at offset %d", ErrInvalidJSONC, i)
+			}
+			end += i + 2 + len("*/")
+			for ; i < end; i++ {
+				if res[i] != '\n' {
+					res[i] = ' '
+				}
+			}
+			i--
+		case c == ',':
+			lastComma = i
+		case c == '}' || c == ']':
+			if lastComma >= 0 {
+				res[lastComma] = ' '
+			}
+			lastComma = -1
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+		default:
+			lastComma = -1
+		}
+	}
+	return res, nil
+}
+
+// jsoncStringEnd returns the index of the quote closing the string starting at start.
+func jsoncStringEnd(data []byte, start int) (int, error) {
+	for i := start + 1; i < len(data); i++ {
+		switch data[i] {
+		case '\\':
+			i++
+		case '"':
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: unterminated string at offset %d", ErrInvalidJSONC, start)
+}
@@ -0,0 +1,95 @@
+package confgo
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestStripJSONC(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		raw  string
+		want string
+	}{
+		{
+			name: "plain json",
+			raw:  `{"a": [1, 2], "b": {}}`,
+			want: `{"a": [1, 2], "b": {}}`,
+		},
+		{
+			name: "line comments",
+			raw:  "{\n  // comment\n  \"a\": 1 // trailing\n}",
+			want: "{\n            \n  \"a\": 1            \n}",
+		},
+		{
+			name: "block comments",
+			raw:  "{/* one\ntwo */\"a\": 1}",
+			want: "{      \n      \"a\": 1}",
+		},
+		{
+			name: "trailing commas",
+			raw:  "{\"a\": [1, 2,], \"b\": 3,\n}",
+			want: "{\"a\": [1, 2 ], \"b\": 3 \n}",
+		},
+		{
+			name: "trailing comma before comment",
+			raw:  "[1, // one\n]",
+			want: "[1        \n]",
+		},
+		{
+			name: "comment-like strings",
+			raw:  `{"url": "http://host/*x*/", "s": "\"//,]"}`,
+			want: `{"url": "http://host/*x*/", "s": "\"//,]"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := stripJSONC([]byte(tt.raw))
+			if err != nil {
+				t.Fatalf("stripJSONC() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("stripJSONC() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStripJSONC_Errors(t *testing.T) {
+	t.Parallel()
+
+	for _, raw := range []string{`{"a": 1 /* comment`, `{"a": "value}`} {
+		if _, err := stripJSONC([]byte(raw)); !errors.Is(err, ErrInvalidJSONC) {
+			t.Errorf("stripJSONC(%q) error = %v, want %v", raw, err, ErrInvalidJSONC)
+		}
+	}
+}
+
+func TestJSONCFormatter_Unmarshal(t *testing.T) {
+	t.Parallel()
+
+	data := []byte(`{
+		// The answer.
+		"int": 42,
+		"slice": ["a", "b",], /* trailing comma */
+	}`)
+	var cfg TestConfig
+	if err := NewJSONCFormatter().Unmarshal(data, &cfg); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	want := TestConfig{Int: 42, Slice: []string{"a", "b"}}
+	if !reflect.DeepEqual(cfg, want) {
+		t.Errorf("Unmarshal() = %v, want %v", cfg, want)
+	}
+
+	err := NewJSONCFormatter(JSONDisallowUnknownFields).Unmarshal([]byte(`{"unknown": 1, // comment
+	}`), &cfg)
+	if err == nil {
+		t.Errorf("Unmarshal() with unknown field error = nil, want error")
+	}
+}
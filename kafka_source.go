@@ -0,0 +1,201 @@
+package confgo
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sync"
+	"time"
+)
+
+// KafkaMessage is a message of a topic read by KafkaConsumer.
+type KafkaMessage struct {
+	Key   []byte
+	Value []byte
+	// Offset is the offset of the message in its partition.
+	Offset int64
+	// HighWaterMark is the offset of the next message to be written to the partition
+	// when the message is read.
+	HighWaterMark int64
+}
+
+// KafkaConsumer reads a single-partition topic from its earliest offset. The module does not depend
+// on a Kafka client, so the consumer of the client is wrapped by a small adapter, e.g. for kafka-go:
+//
+//	type kafkaConsumer struct{ r *kafka.Reader } // kafka.ReaderConfig{StartOffset: kafka.FirstOffset, ...}
+//
+//	func (c kafkaConsumer) ReadMessage(ctx context.Context) (confgo.KafkaMessage, error) {
+//		m, err := c.r.ReadMessage(ctx)
+//		if err != nil {
+//			return confgo.KafkaMessage{}, err
+//		}
+//		return confgo.KafkaMessage{Key: m.Key, Value: m.Value, Offset: m.Offset, HighWaterMark: m.HighWaterMark}, nil
+//	}
+type KafkaConsumer interface {
+	// ReadMessage blocks until the next message is read or ctx is done.
+	ReadMessage(ctx context.Context) (KafkaMessage, error)
+}
+
+// KafkaSourceOption configures KafkaSource.
+type KafkaSourceOption func(ks *KafkaSource)
+
+// KafkaKey makes the source use only the messages with the key, so that a topic may hold
+// the configurations of several services.
+func KafkaKey(key string) KafkaSourceOption {
+	return func(ks *KafkaSource) {
+		ks.key = []byte(key)
+	}
+}
+
+// KafkaBackoff sets the delay before reading again after the failed attempt, which starts from 1.
+// Defaults to the exponential backoff from 100 milliseconds to 30 seconds.
+func KafkaBackoff(backoff func(attempt int) time.Duration) KafkaSourceOption {
+	return func(ks *KafkaSource) {
+		ks.backoff = backoff
+	}
+}
+
+var (
+	_ Source        = (*KafkaSource)(nil)
+	_ SourceContext = (*KafkaSource)(nil)
+	_ Watcher       = (*KafkaWatcher)(nil)
+)
+
+// KafkaSource is a configuration source that consumes a compacted Kafka topic, where the latest
+// message is the current configuration. The topic is consumed in the background from the first read
+// until Close, and reads wait until the consumer has caught up with the topic, so the topic must not be empty.
+// A missing configuration, i.e. no message with the key or a tombstone, is reported as fs.ErrNotExist,
+// so the loader may be optional.
+type KafkaSource struct {
+	consumer KafkaConsumer
+	key      []byte
+	backoff  func(attempt int) time.Duration
+
+	start    sync.Once
+	stop     chan struct{}
+	stopOnce sync.Once
+	// caughtUp is closed once the consumer has read the message preceding the high water mark.
+	caughtUp chan struct{}
+
+	mu sync.Mutex
+	// data is the value of the latest message, nil if none or a tombstone.
+	data     []byte
+	ready    bool
+	callback func()
+}
+
+// NewKafkaSource creates a source consuming the topic with consumer.
+func NewKafkaSource(consumer KafkaConsumer, opts ...KafkaSourceOption) *KafkaSource {
+	ks := &KafkaSource{
+		consumer: consumer,
+		key:      nil,
+		backoff:  ExponentialBackoff(reconnectBaseDelay, reconnectMaxDelay),
+		start:    sync.Once{},
+		stop:     make(chan struct{}),
+		stopOnce: sync.Once{},
+		caughtUp: make(chan struct{}),
+		mu:       sync.Mutex{},
+		data:     nil,
+		ready:    false,
+		callback: nil,
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(ks)
+		}
+	}
+	return ks
+}
+
+func (ks *KafkaSource) Read() ([]byte, error) {
+	return ks.ReadContext(context.Background())
+}
+
+// ReadContext returns the value of the latest message, waiting until ctx is done for the consumer
+// to catch up with the topic.
+func (ks *KafkaSource) ReadContext(ctx context.Context) ([]byte, error) {
+	ks.consume()
+	select {
+	case <-ks.caughtUp:
+	case <-ks.stop:
+		return nil, errors.New("kafka topic: source is closed")
+	case <-ctx.Done():
+		return nil, fmt.Errorf("kafka topic: wait for config: %w", ctx.Err())
+	}
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	if ks.data == nil {
+		return nil, fmt.Errorf("kafka topic: %w", fs.ErrNotExist)
+	}
+	return bytes.Clone(ks.data), nil
+}
+
+// Close stops consuming the topic.
+func (ks *KafkaSource) Close() error {
+	ks.stopOnce.Do(func() { close(ks.stop) })
+	return nil
+}
+
+// consume starts consuming the topic, once.
+func (ks *KafkaSource) consume() {
+	ks.start.Do(func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			<-ks.stop
+			cancel()
+		}()
+		go reconnect(ctx, ks.backoff, func() bool {
+			for received := false; ; received = true {
+				msg, err := ks.consumer.ReadMessage(ctx)
+				if err != nil {
+					return received
+				}
+				ks.store(msg)
+			}
+		})
+	})
+}
+
+// store stores the message and calls the watcher callback if it has changed the configuration
+// after the consumer has caught up.
+func (ks *KafkaSource) store(msg KafkaMessage) {
+	ks.mu.Lock()
+	changed := false
+	if ks.key == nil || bytes.Equal(msg.Key, ks.key) {
+		changed = (ks.data == nil) != (msg.Value == nil) || !bytes.Equal(ks.data, msg.Value)
+		ks.data = bytes.Clone(msg.Value)
+	}
+	wasReady := ks.ready
+	if !ks.ready && msg.Offset+1 >= msg.HighWaterMark {
+		ks.ready = true
+		close(ks.caughtUp)
+	}
+	callback := ks.callback
+	ks.mu.Unlock()
+	if wasReady && changed && callback != nil {
+		callback()
+	}
+}
+
+// KafkaWatcher triggers reloads whenever a message changing the configuration of KafkaSource is consumed.
+// Stop closes the source.
+type KafkaWatcher struct {
+	source *KafkaSource
+}
+
+func NewKafkaWatcher(source *KafkaSource) *KafkaWatcher {
+	return &KafkaWatcher{source: source}
+}
+
+func (kw *KafkaWatcher) Watch(callback func()) {
+	kw.source.mu.Lock()
+	kw.source.callback = callback
+	kw.source.mu.Unlock()
+	kw.source.consume()
+}
+
+func (kw *KafkaWatcher) Stop() error {
+	return kw.source.Close()
+}
@@ -0,0 +1,94 @@
+package confgo
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type fakeKafkaConsumer struct {
+	messages chan KafkaMessage
+	fail     atomic.Bool
+}
+
+func (c *fakeKafkaConsumer) ReadMessage(ctx context.Context) (KafkaMessage, error) {
+	if c.fail.Swap(false) {
+		return KafkaMessage{}, errors.New("broker unavailable")
+	}
+	select {
+	case msg := <-c.messages:
+		return msg, nil
+	case <-ctx.Done():
+		return KafkaMessage{}, ctx.Err()
+	}
+}
+
+func TestKafkaSource(t *testing.T) {
+	t.Parallel()
+
+	consumer := &fakeKafkaConsumer{messages: make(chan KafkaMessage, 10)}
+	consumer.fail.Store(true)
+	consumer.messages <- KafkaMessage{Key: []byte("app"), Value: []byte(`{"int": 1}`), Offset: 0, HighWaterMark: 3}
+	consumer.messages <- KafkaMessage{Key: []byte("other"), Value: []byte(`{"int": 10}`), Offset: 1, HighWaterMark: 3}
+	consumer.messages <- KafkaMessage{Key: []byte("app"), Value: []byte(`{"int": 2}`), Offset: 2, HighWaterMark: 3}
+	s := NewKafkaSource(consumer, KafkaKey("app"), KafkaBackoff(func(int) time.Duration { return time.Millisecond }))
+	updated := make(chan struct{}, 1)
+	cm, err := NewConfigManager(testConfigConstructor, WithLoader(Loader{
+		Source:          s,
+		Formatter:       NewJSONFormatter(),
+		Watcher:         NewKafkaWatcher(s),
+		OnUpdateSuccess: func() { updated <- struct{}{} },
+	}))
+	if err != nil {
+		t.Fatalf("NewConfigManager() error = %v", err)
+	}
+	if err := cm.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer cm.MustStop()
+	if got := cm.Config().(*TestConfig).Int; got != 2 {
+		t.Fatalf("Config().Int = %d, want 2", got)
+	}
+
+	// Messages of other keys and the unchanged config do not trigger reloads.
+	consumer.messages <- KafkaMessage{Key: []byte("other"), Value: []byte(`{"int": 20}`), Offset: 3, HighWaterMark: 4}
+	consumer.messages <- KafkaMessage{Key: []byte("app"), Value: []byte(`{"int": 2}`), Offset: 4, HighWaterMark: 5}
+	consumer.messages <- KafkaMessage{Key: []byte("app"), Value: []byte(`{"int": 3}`), Offset: 5, HighWaterMark: 6}
+	select {
+	case <-updated:
+	case <-time.After(5 * time.Second):
+		t.Fatal("KafkaWatcher did not report the new config")
+	}
+	if got := cm.Config().(*TestConfig).Int; got != 3 {
+		t.Errorf("Config().Int = %d, want 3", got)
+	}
+	select {
+	case <-updated:
+		t.Error("unexpected reload")
+	default:
+	}
+}
+
+func TestKafkaSource_Tombstone(t *testing.T) {
+	t.Parallel()
+
+	consumer := &fakeKafkaConsumer{messages: make(chan KafkaMessage, 2)}
+	consumer.messages <- KafkaMessage{Key: []byte("app"), Value: []byte(`{"int": 1}`), Offset: 0, HighWaterMark: 2}
+	consumer.messages <- KafkaMessage{Key: []byte("app"), Value: nil, Offset: 1, HighWaterMark: 2}
+	s := NewKafkaSource(consumer)
+	defer s.Close()
+	if _, err := s.Read(); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Read() error = %v, want fs.ErrNotExist", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	empty := NewKafkaSource(&fakeKafkaConsumer{messages: make(chan KafkaMessage)})
+	defer empty.Close()
+	if _, err := empty.ReadContext(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("ReadContext() error = %v, want context.DeadlineExceeded", err)
+	}
+}
@@ -0,0 +1,112 @@
+package confgo
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// KeyNormalizer normalizes the keys of documents and config fields, so a document key matches the field
+// which key normalizes to the same string, see WithKeyNormalizer.
+type KeyNormalizer func(key string) string
+
+// NormalizeKeys is the KeyNormalizer matching keys case-insensitively regardless of dashes and underscores,
+// so "max-conns", "max_conns", "maxConns" and "MaxConns" match each other.
+func NormalizeKeys(key string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == '_' {
+			return -1
+		}
+		return r
+	}, strings.ToLower(key))
+}
+
+// normalizeKeys renames the keys of the JSON or YAML document data matching the fields of the config type typ
+// only after normalization to the keys of the fields, so the formatter decodes them. The data is returned
+// as is if no key is renamed, the normalizer is not set or the data is not a document, e.g. env variables.
+func (cm *ConfigManager) normalizeKeys(formatter Formatter, data []byte, typ reflect.Type) []byte {
+	if cm.keyNormalizer == nil {
+		return data
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil || doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 ||
+		doc.Content[0].Kind != yaml.MappingNode {
+		return data
+	}
+	keys := jsonHookKeys
+	_, isYAML := formatter.(*YAMLFormatter)
+	if isYAML {
+		keys = yamlHookKeys
+	}
+	if !renameKeys(doc.Content[0], typ, keys, cm.keyNormalizer) {
+		return data
+	}
+
+	// Other formatters get JSON which, unlike YAML, the JSON formatters understand.
+	if isYAML {
+		if renamed, err := yaml.Marshal(&doc); err == nil {
+			return renamed
+		}
+		return data
+	}
+	var v any
+	if err := doc.Decode(&v); err != nil {
+		return data
+	}
+	renamed, err := json.Marshal(v)
+	if err != nil {
+		return data
+	}
+	return renamed
+}
+
+// renameKeys renames the keys of the node decoded into a value of typ to the keys of the fields they match
+// after normalization, reporting whether any key is renamed.
+func renameKeys(node *yaml.Node, typ reflect.Type, keys hookKeys, normalize KeyNormalizer) bool {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	renamed := false
+	switch {
+	case node.Kind == yaml.SequenceNode && (typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array):
+		for _, item := range node.Content {
+			renamed = renameKeys(item, typ.Elem(), keys, normalize) || renamed
+		}
+	case node.Kind == yaml.MappingNode && typ.Kind() == reflect.Map:
+		for i := 1; i < len(node.Content); i += 2 {
+			renamed = renameKeys(node.Content[i], typ.Elem(), keys, normalize) || renamed
+		}
+	case node.Kind == yaml.MappingNode && typ.Kind() == reflect.Struct && !isLeafStruct(typ):
+		fieldKeys, _ := keys(typ)
+		normalized := make(map[string]string, len(fieldKeys))
+		for key := range fieldKeys {
+			normalized[normalize(key)] = key
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			keyNode := node.Content[i]
+			index, ok := fieldKeys[keyNode.Value]
+			if !ok {
+				var key string
+				if key, ok = normalized[normalize(keyNode.Value)]; ok {
+					keyNode.Value = key
+					index = fieldKeys[key]
+					renamed = true
+				}
+			}
+			if !ok {
+				continue
+			}
+			fieldType := typ
+			for _, idx := range index {
+				for fieldType.Kind() == reflect.Ptr {
+					fieldType = fieldType.Elem()
+				}
+				fieldType = fieldType.Field(idx).Type
+			}
+			renamed = renameKeys(node.Content[i+1], fieldType, keys, normalize) || renamed
+		}
+	}
+	return renamed
+}
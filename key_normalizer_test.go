@@ -0,0 +1,77 @@
+package confgo
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeKeys(t *testing.T) {
+	t.Parallel()
+
+	for _, key := range []string{"max-conns", "max_conns", "maxConns", "MaxConns", "MAX_CONNS"} {
+		if got := NormalizeKeys(key); got != "maxconns" {
+			t.Errorf("NormalizeKeys(%q) = %q, want %q", key, got, "maxconns")
+		}
+	}
+}
+
+func TestWithKeyNormalizer(t *testing.T) {
+	t.Parallel()
+
+	type pool struct {
+		MaxConns int `json:"max_conns" yaml:"max_conns"`
+	}
+	type config struct {
+		ReadTimeout string          `json:"read_timeout" yaml:"read_timeout"`
+		Pool        *pool           `json:"db_pool"      yaml:"db_pool"`
+		Pools       []pool          `json:"pools"        yaml:"pools"`
+		Named       map[string]pool `json:"named"        yaml:"named"`
+		Labels      map[string]int  `json:"labels"       yaml:"labels"`
+	}
+	want := &config{
+		ReadTimeout: "5s",
+		Pool:        &pool{MaxConns: 1},
+		Pools:       []pool{{MaxConns: 2}},
+		Named:       map[string]pool{"main-db": {MaxConns: 3}},
+		Labels:      map[string]int{"max-conns": 4},
+	}
+
+	tests := []struct {
+		name      string
+		formatter Formatter
+		data      string
+	}{
+		{
+			name:      "yaml",
+			formatter: NewYAMLFormatter(YAMLDisallowUnknownFields),
+			data: "read-timeout: 5s\ndb-pool:\n  max-conns: 1\npools:\n  - maxConns: 2\n" +
+				"named:\n  main-db:\n    MAX_CONNS: 3\nlabels:\n  max-conns: 4\n",
+		},
+		{
+			name:      "json",
+			formatter: NewJSONFormatter(JSONDisallowUnknownFields),
+			data: `{"readTimeout": "5s", "dbPool": {"max-conns": 1}, "pools": [{"max_conns": 2}],
+				"named": {"main-db": {"maxConns": 3}}, "labels": {"max-conns": 4}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cm, err := NewConfigManagerFor[config](
+				WithLoader(Loader{Source: &fakeSource{data: []byte(tt.data)}, Formatter: tt.formatter}),
+				WithKeyNormalizer(NormalizeKeys),
+			)
+			if err != nil {
+				t.Fatalf("NewConfigManagerFor() error = %v", err)
+			}
+			if err := cm.Start(); err != nil {
+				t.Fatalf("Start() error = %v", err)
+			}
+			defer cm.MustStop()
+			if got := cm.Config(); !reflect.DeepEqual(got, want) {
+				t.Errorf("Config() = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
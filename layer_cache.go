@@ -0,0 +1,90 @@
+package confgo
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// errConfigUnchanged is returned by a partial reload with WithSkipUnchanged if none of the re-read loaders
+// has changed its data.
+var errConfigUnchanged = errors.New("config data unchanged")
+
+// loadState accumulates the results of loading the layers of a reload.
+type loadState struct {
+	merged     any
+	degraded   []DegradedLayer
+	provenance map[string]string
+	// partial is true if only the loaders whose watchers have triggered and the loaders without watchers are read.
+	partial bool
+	// skipUnchanged is true if the read layers whose data has not changed are not parsed again
+	// and the reload is skipped if none of them has changed.
+	skipUnchanged bool
+	// changedLayers is the number of read layers whose data has changed since their last read.
+	changedLayers int
+}
+
+// cachedLayer is the result of the last read of a loader, reused by reloads triggered by the watchers of other loaders.
+type cachedLayer struct {
+	// parsed is the config unmarshaled from the data of the loader, it is never merged directly,
+	// so it shares no maps or slices with the loaded configurations.
+	parsed any
+	// present is the paths of the fields explicitly set by the data with WithExplicitValues.
+	present []string
+	// sum is the SHA-256 checksum of the raw data the layer has been parsed from.
+	sum [sha256.Size]byte
+	// skipErr is the error the loader has been skipped with, nil if the layer has been parsed.
+	skipErr error
+}
+
+// layerCache holds the cached layers by the ids of loaders.
+type layerCache struct {
+	mu     sync.Mutex
+	layers map[uint64]*cachedLayer
+}
+
+func (c *layerCache) get(id uint64) *cachedLayer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.layers[id]
+}
+
+// set caches the layer of the loader with the id, nil layer removes it from the cache.
+func (c *layerCache) set(id uint64, layer *cachedLayer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if layer == nil {
+		delete(c.layers, id)
+		return
+	}
+	if c.layers == nil {
+		c.layers = make(map[uint64]*cachedLayer)
+	}
+	c.layers[id] = layer
+}
+
+// mergeCachedLayer merges the layer of the loader i cached by its last read into the loaded config as loadLayer does.
+// It reports false if the layer is not cached, so the loader must be read.
+func (cm *ConfigManager) mergeCachedLayer(i int, l Loader, st *loadState) (bool, error) {
+	layer := cm.layerCache.get(l.id)
+	if layer == nil {
+		return false, nil
+	}
+	if layer.skipErr != nil {
+		st.degraded = append(st.degraded, DegradedLayer{
+			Loader: i,
+			Name:   l.Name,
+			Source: l.describe(),
+			Reason: DegradedReasonMissing,
+			Err:    layer.skipErr,
+		})
+		return true, nil
+	}
+	if err := cm.mergeLayer(st, deepCopy(reflect.ValueOf(layer.parsed)).Interface(), layer.present, l.describe()); err != nil {
+		return true, fmt.Errorf("%s: merge: %w", l.label(i), err)
+	}
+	cm.log().Debug("confgo: config loader reused", slogArgs(l.logAttrs(i))...)
+	return true, nil
+}
@@ -0,0 +1,171 @@
+package confgo
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestConfigManager_reloadLayers(t *testing.T) {
+	t.Parallel()
+
+	source1 := &fakeSource{data: []byte(`{"int": 1, "map": {"a": "1"}}`)}
+	source2 := &fakeSource{data: []byte(`{"map": {"b": "2"}}`)}
+	unwatched := &fakeSource{data: []byte(`{"slice": ["1"]}`)}
+	cm := newTestConfigManager(testConfigManagerFields{
+		constructor: testConfigConstructor,
+		loaders: []Loader{
+			{Source: source1, Formatter: NewJSONFormatter(), Watcher: NewTriggerWatcher()},
+			{Source: source2, Formatter: NewJSONFormatter(), Watcher: NewTriggerWatcher()},
+			{Source: unwatched, Formatter: NewJSONFormatter()},
+		},
+	})
+	if err := cm.reload(); err != nil {
+		t.Fatalf("reload() error = %v", err)
+	}
+	second := []uint64{cm.snapshotLoaders()[1].id}
+
+	// The first loader must not be read again, so its failure is not noticed,
+	// while the loader without a watcher is read on every reload.
+	source1.err = errors.New("test error")
+	source2.data = []byte(`{"map": {"c": "3"}}`)
+	unwatched.data = []byte(`{"slice": ["2"]}`)
+	if err := cm.reloadLayers(context.Background(), second); err != nil {
+		t.Fatalf("reloadLayers() error = %v", err)
+	}
+	want := &TestConfig{Int: 1, Map: map[string]string{"a": "1", "c": "3"}, Slice: []string{"2"}}
+	if got := cm.Config(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Config() = %+v, want %+v", got, want)
+	}
+
+	if err := cm.reload(); err == nil {
+		t.Errorf("reload() error = nil, want error")
+	}
+	// The failed layer is not cached any more, so it is read even if its loader has not triggered.
+	if err := cm.reloadLayers(context.Background(), second); err == nil {
+		t.Errorf("reloadLayers() error = nil, want error")
+	}
+	source1.err = nil
+	if err := cm.reloadLayers(context.Background(), second); err != nil {
+		t.Fatalf("reloadLayers() error = %v", err)
+	}
+	if got := cm.Config(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Config() = %+v, want %+v", got, want)
+	}
+}
+
+// gatedSource is a source whose reads block while it is blocked, reporting the blocked reads.
+type gatedSource struct {
+	mu      sync.Mutex
+	data    []byte
+	blocked chan struct{}
+	reading chan struct{}
+}
+
+func (s *gatedSource) Read() ([]byte, error) {
+	s.mu.Lock()
+	data, blocked := s.data, s.blocked
+	s.mu.Unlock()
+	if blocked != nil {
+		s.reading <- struct{}{}
+		<-blocked
+	}
+	return data, nil
+}
+
+func TestConfigManager_reloadLayersConcurrent(t *testing.T) {
+	t.Parallel()
+
+	first := &fakeSource{data: []byte(`{"slice": ["1"]}`)}
+	second := &gatedSource{data: []byte(`{"int": 1}`), blocked: nil, reading: make(chan struct{})}
+	cm := newTestConfigManager(testConfigManagerFields{
+		constructor: testConfigConstructor,
+		loaders: []Loader{
+			{Source: first, Formatter: NewJSONFormatter(), Watcher: NewTriggerWatcher()},
+			{Source: second, Formatter: NewJSONFormatter(), Watcher: NewTriggerWatcher()},
+		},
+	})
+	if err := cm.reload(); err != nil {
+		t.Fatalf("reload() error = %v", err)
+	}
+	loaders := cm.snapshotLoaders()
+
+	// The reload of the second loader merges the cached first layer and blocks reading the second one,
+	// while the first loader changes and triggers its own reload.
+	release := make(chan struct{})
+	second.mu.Lock()
+	second.data, second.blocked = []byte(`{"int": 2}`), release
+	second.mu.Unlock()
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		if err := cm.reloadLayers(context.Background(), []uint64{loaders[1].id}); err != nil {
+			t.Errorf("reloadLayers() error = %v", err)
+		}
+	}()
+	<-second.reading
+	second.mu.Lock()
+	second.blocked = nil
+	second.mu.Unlock()
+	first.data = []byte(`{"slice": ["2"]}`)
+	go func() {
+		defer wg.Done()
+		if err := cm.reloadLayers(context.Background(), []uint64{loaders[0].id}); err != nil {
+			t.Errorf("reloadLayers() error = %v", err)
+		}
+	}()
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	want := &TestConfig{Int: 2, Slice: []string{"2"}}
+	if got := cm.Config(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Config() = %+v, want %+v", got, want)
+	}
+}
+
+func TestConfigManager_WithSkipUnchanged(t *testing.T) {
+	t.Parallel()
+
+	watcher := NewTriggerWatcher()
+	source := &fakeSource{data: []byte(`{"int": 1}`)}
+	var updates int
+	cm, err := NewConfigManager(testConfigConstructor, WithSkipUnchanged, func(cm *ConfigManager) error {
+		cm.AddLoader(Loader{
+			Source:          source,
+			Formatter:       NewJSONFormatter(),
+			Watcher:         watcher,
+			OnUpdateSuccess: func() { updates++ },
+		})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("NewConfigManager() error = %v", err)
+	}
+	if err := cm.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer cm.MustStop()
+	var notified int
+	cm.Subscribe(func(_, _ any) { notified++ })
+
+	watcher.Trigger()
+	if updates != 0 || notified != 0 || cm.Version() != 1 {
+		t.Errorf("unchanged data: updates = %d, notified = %d, Version() = %d, want 0, 0, 1",
+			updates, notified, cm.Version())
+	}
+
+	source.data = []byte(`{"int": 2}`)
+	watcher.Trigger()
+	if updates != 1 || notified != 1 || cm.Version() != 2 {
+		t.Errorf("changed data: updates = %d, notified = %d, Version() = %d, want 1, 1, 2",
+			updates, notified, cm.Version())
+	}
+	if got := cm.Config().(*TestConfig).Int; got != 2 {
+		t.Errorf("Config().Int = %d, want 2", got)
+	}
+}
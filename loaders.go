@@ -0,0 +1,130 @@
+package confgo
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// snapshotLoaders returns a copy of the loaders, assigning ids to the loaders added without AddLoader.
+func (cm *ConfigManager) snapshotLoaders() []Loader {
+	cm.loadersMu.Lock()
+	defer cm.loadersMu.Unlock()
+	return cm.snapshotLoadersLocked()
+}
+
+// snapshotLoadersLocked is snapshotLoaders for callers holding cm.loadersMu.
+func (cm *ConfigManager) snapshotLoadersLocked() []Loader {
+	for i := range cm.loaders {
+		if cm.loaders[i].id == 0 {
+			cm.loaderSeq++
+			cm.loaders[i].id = cm.loaderSeq
+		}
+	}
+	return slices.Clone(cm.loaders)
+}
+
+// RemoveLoader removes the loader with the name. If the manager is running, the watcher of the loader is stopped
+// and the configuration is reloaded without its layer; the loader stays removed even if the reload fails.
+//
+// It returns an error wrapping ErrLoaderNotFound if there is no loader with the name or the name is empty.
+func (cm *ConfigManager) RemoveLoader(name string) error {
+	return cm.swapLoader(name, nil)
+}
+
+// ReplaceLoader replaces the loader with the name with l keeping its position, e.g. to change the path of a file
+// or rotate the credentials of a remote source without rebuilding the manager. l gets the name if it has none.
+// If the priority of l differs from the priority of the replaced loader, l is moved according to its priority.
+// If the manager is running, the watcher of the replaced loader is stopped, the watcher of l is started
+// and the configuration is reloaded; l stays in place even if the reload fails.
+//
+// It returns an error wrapping ErrLoaderNotFound if there is no loader with the name or the name is empty.
+func (cm *ConfigManager) ReplaceLoader(name string, l Loader) error {
+	if l.Name == "" {
+		l.Name = name
+	}
+	if err := l.validate(); err != nil {
+		return fmt.Errorf("%s: %w", l.label(-1), err)
+	}
+	return cm.swapLoader(name, &l)
+}
+
+// swapLoader replaces the loader with the name with replacement or removes it if replacement is nil.
+// Unnamed loaders cannot be addressed, so an empty name matches no loader.
+func (cm *ConfigManager) swapLoader(name string, replacement *Loader) error {
+	if name == "" {
+		return fmt.Errorf("%w: empty name", ErrLoaderNotFound)
+	}
+	cm.loadersMu.Lock()
+	i := slices.IndexFunc(cm.loaders, func(l Loader) bool { return l.Name == name })
+	if i < 0 {
+		cm.loadersMu.Unlock()
+		return fmt.Errorf("%w: %q", ErrLoaderNotFound, name)
+	}
+	if replacement != nil && replacement.Name != name &&
+		slices.ContainsFunc(cm.loaders, func(l Loader) bool { return l.Name == replacement.Name }) {
+		cm.loadersMu.Unlock()
+		return fmt.Errorf("%w: name %q is already used", ErrDuplicateLoader, replacement.Name)
+	}
+	replaced := cm.loaders[i]
+	loaders := slices.Clone(cm.loaders)
+	if replacement != nil {
+		cm.loaderSeq++
+		replacement.id = cm.loaderSeq
+	}
+	cm.mu.Lock()
+	if replacement != nil && replacement.Priority == replaced.Priority {
+		loaders[i] = *replacement
+	} else {
+		loaders = slices.Delete(loaders, i, i+1)
+		if replacement != nil {
+			loaders = insertLoader(loaders, *replacement)
+		}
+	}
+	cm.loaders = loaders
+	delete(cm.loaderStatuses, replaced.id)
+	cm.mu.Unlock()
+	cm.loadersMu.Unlock()
+	cm.layerCache.set(replaced.id, nil)
+
+	if !cm.isRunning.Load() {
+		return nil
+	}
+	errs := make([]error, 0)
+	if replaced.Watcher != nil {
+		if err := replaced.Watcher.Stop(); err != nil {
+			errs = append(errs, fmt.Errorf("stop watcher: %w", err))
+		}
+	}
+	if replacement != nil {
+		cm.watch(*replacement)
+	}
+	if err := cm.reload(); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// insertLoader inserts l into loaders after the loaders with the same or lower priority.
+func insertLoader(loaders []Loader, l Loader) []Loader {
+	i := len(loaders)
+	for i > 0 && loaders[i-1].Priority > l.Priority {
+		i--
+	}
+	return slices.Insert(loaders, i, l)
+}
+
+// MergeOrder returns the labels of the loaders in the order their layers are merged,
+// so every loader overrides the values of the loaders before it.
+// It helps to inspect the precedence when loaders are added by options from different packages.
+func (cm *ConfigManager) MergeOrder() []string {
+	if next := cm.handedOff.Load(); next != nil {
+		return next.MergeOrder()
+	}
+	loaders := cm.snapshotLoaders()
+	order := make([]string, 0, len(loaders))
+	for i, l := range loaders {
+		order = append(order, l.label(i))
+	}
+	return order
+}
@@ -0,0 +1,283 @@
+package confgo
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestConfigManager_RemoveLoader(t *testing.T) {
+	t.Parallel()
+
+	cm, err := NewConfigManager(testConfigConstructor, func(cm *ConfigManager) error {
+		cm.AddLoader(Loader{Name: "base", Source: &fakeSource{data: []byte(`{"int": 1}`)}, Formatter: NewJSONFormatter()})
+		cm.AddLoader(Loader{
+			Name:      "override",
+			Source:    &fakeSource{data: []byte(`{"int": 2}`)},
+			Formatter: NewJSONFormatter(),
+			Watcher:   NewTriggerWatcher(),
+		})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("NewConfigManager() error = %v", err)
+	}
+	if err := cm.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer cm.MustStop()
+
+	if err := cm.RemoveLoader("override"); err != nil {
+		t.Fatalf("RemoveLoader() error = %v", err)
+	}
+	if got := cm.Config().(*TestConfig).Int; got != 1 {
+		t.Errorf("Config().Int = %d, want 1", got)
+	}
+	if got := cm.Status().Loaders; len(got) != 1 || got[0].Name != "base" {
+		t.Errorf("Status().Loaders = %+v, want the base loader only", got)
+	}
+	if err := cm.RemoveLoader("override"); !errors.Is(err, ErrLoaderNotFound) {
+		t.Errorf("RemoveLoader() error = %v, want %v", err, ErrLoaderNotFound)
+	}
+}
+
+func TestConfigManager_RemoveLoader_EmptyName(t *testing.T) {
+	t.Parallel()
+
+	cm, err := NewConfigManager(testConfigConstructor, WithLoader(Loader{
+		Source:    &fakeSource{data: []byte(`{"int": 1}`)},
+		Formatter: NewJSONFormatter(),
+	}))
+	if err != nil {
+		t.Fatalf("NewConfigManager() error = %v", err)
+	}
+	if err := cm.RemoveLoader(""); !errors.Is(err, ErrLoaderNotFound) {
+		t.Errorf("RemoveLoader() error = %v, want %v", err, ErrLoaderNotFound)
+	}
+	replacement := Loader{Source: &fakeSource{data: []byte(`{"int": 2}`)}, Formatter: NewJSONFormatter()}
+	if err := cm.ReplaceLoader("", replacement); !errors.Is(err, ErrLoaderNotFound) {
+		t.Errorf("ReplaceLoader() error = %v, want %v", err, ErrLoaderNotFound)
+	}
+	if got := len(cm.snapshotLoaders()); got != 1 {
+		t.Errorf("len(loaders) = %d, want 1", got)
+	}
+}
+
+func TestConfigManager_RemoveLoader_DuringReload(t *testing.T) {
+	t.Parallel()
+
+	gated := &gatedSource{data: []byte(`{"int": 2}`), blocked: nil, reading: make(chan struct{})}
+	cm := newTestConfigManager(testConfigManagerFields{
+		constructor: testConfigConstructor,
+		loaders: []Loader{
+			{Name: "first", Source: &fakeSource{data: []byte(`{"int": 1}`)}, Formatter: NewJSONFormatter()},
+			{Name: "gated", Source: gated, Formatter: NewJSONFormatter()},
+			{Name: "last", Source: &fakeSource{data: []byte(`{"slice": ["a"]}`)}, Formatter: NewJSONFormatter()},
+		},
+	})
+	if err := cm.reload(); err != nil {
+		t.Fatalf("reload() error = %v", err)
+	}
+
+	// The first loader is removed while a reload is reading the layers, so the positions of the loaders shift
+	// under the reload.
+	release := make(chan struct{})
+	gated.mu.Lock()
+	gated.blocked = release
+	gated.mu.Unlock()
+	done := make(chan error, 1)
+	go func() {
+		done <- cm.reload()
+	}()
+	<-gated.reading
+	if err := cm.RemoveLoader("first"); err != nil {
+		t.Fatalf("RemoveLoader() error = %v", err)
+	}
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("reload() error = %v", err)
+	}
+
+	loaders := cm.Status().Loaders
+	if len(loaders) != 2 {
+		t.Fatalf("Status().Loaders = %+v, want 2 loaders", loaders)
+	}
+	for i, name := range []string{"gated", "last"} {
+		if loaders[i].Loader != i || loaders[i].Name != name || loaders[i].LastRead.IsZero() {
+			t.Errorf("Status().Loaders[%d] = %+v, want read loader %d %q", i, loaders[i], i, name)
+		}
+	}
+}
+
+func TestConfigManager_ReplaceLoader(t *testing.T) {
+	t.Parallel()
+
+	oldWatcher := NewTriggerWatcher()
+	var oldUpdates int
+	cm, err := NewConfigManager(testConfigConstructor, func(cm *ConfigManager) error {
+		cm.AddLoader(Loader{
+			Name:            "file",
+			Source:          &fakeSource{data: []byte(`{"int": 1, "slice": ["a"]}`)},
+			Formatter:       NewJSONFormatter(),
+			Watcher:         oldWatcher,
+			OnUpdateSuccess: func() { oldUpdates++ },
+		})
+		cm.AddLoader(Loader{Name: "env", Source: &fakeSource{data: []byte(`{"int": 2}`)}, Formatter: NewJSONFormatter()})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("NewConfigManager() error = %v", err)
+	}
+	if err := cm.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer cm.MustStop()
+
+	newSource := &fakeSource{data: []byte(`{"slice": ["b"]}`)}
+	newWatcher := NewTriggerWatcher()
+	var newUpdates int
+	if err := cm.ReplaceLoader("file", Loader{
+		Source:          newSource,
+		Formatter:       NewJSONFormatter(),
+		Watcher:         newWatcher,
+		OnUpdateSuccess: func() { newUpdates++ },
+	}); err != nil {
+		t.Fatalf("ReplaceLoader() error = %v", err)
+	}
+	want := &TestConfig{Int: 2, Slice: []string{"b"}}
+	if got := cm.Config(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Config() = %+v, want %+v", got, want)
+	}
+
+	newSource.data = []byte(`{"slice": ["c"]}`)
+	newWatcher.Trigger()
+	oldWatcher.Trigger()
+	want = &TestConfig{Int: 2, Slice: []string{"c"}}
+	if got := cm.Config(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Config() after trigger = %+v, want %+v", got, want)
+	}
+	if oldUpdates != 0 || newUpdates != 1 {
+		t.Errorf("updates of the replaced and new loaders = %d, %d, want 0, 1", oldUpdates, newUpdates)
+	}
+	if got := cm.Status().Loaders[0].Name; got != "file" {
+		t.Errorf("Status().Loaders[0].Name = %q, want %q", got, "file")
+	}
+
+	if err := cm.ReplaceLoader("file", Loader{Name: "env", Source: newSource, Formatter: NewJSONFormatter()}); !errors.Is(err, ErrDuplicateLoader) {
+		t.Errorf("ReplaceLoader() error = %v, want %v", err, ErrDuplicateLoader)
+	}
+	if err := cm.ReplaceLoader("file", Loader{Source: newSource}); !errors.Is(err, ErrFormatterIsNil) {
+		t.Errorf("ReplaceLoader() error = %v, want %v", err, ErrFormatterIsNil)
+	}
+	if err := cm.ReplaceLoader("unknown", Loader{Source: newSource, Formatter: NewJSONFormatter()}); !errors.Is(err, ErrLoaderNotFound) {
+		t.Errorf("ReplaceLoader() error = %v, want %v", err, ErrLoaderNotFound)
+	}
+}
+
+func TestConfigManager_AddLoader_Running(t *testing.T) {
+	t.Parallel()
+
+	cm, err := NewConfigManager(testConfigConstructor, func(cm *ConfigManager) error {
+		cm.AddLoader(Loader{Name: "base", Source: &fakeSource{data: []byte(`{"int": 1}`)}, Formatter: NewJSONFormatter()})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("NewConfigManager() error = %v", err)
+	}
+	if err := cm.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer cm.MustStop()
+
+	source := &fakeSource{data: []byte(`{"int": 2}`)}
+	watcher := NewTriggerWatcher()
+	var updates int
+	cm.AddLoader(Loader{
+		Name:            "override",
+		Source:          source,
+		Formatter:       NewJSONFormatter(),
+		Watcher:         watcher,
+		OnUpdateSuccess: func() { updates++ },
+	})
+	if got := cm.Config().(*TestConfig).Int; got != 2 {
+		t.Errorf("Config().Int = %d, want 2", got)
+	}
+	if updates != 1 {
+		t.Errorf("OnUpdateSuccess called %d times, want 1", updates)
+	}
+
+	source.data = []byte(`{"int": 3}`)
+	watcher.Trigger()
+	if got := cm.Config().(*TestConfig).Int; got != 3 {
+		t.Errorf("Config().Int after trigger = %d, want 3", got)
+	}
+
+	var addErr error
+	cm.AddLoader(Loader{
+		Name:          "override",
+		Source:        &fakeSource{data: []byte(`{"int": 4}`)},
+		Formatter:     NewJSONFormatter(),
+		OnUpdateError: func(err error) { addErr = err },
+	})
+	if !errors.Is(addErr, ErrDuplicateLoader) {
+		t.Errorf("OnUpdateError() error = %v, want %v", addErr, ErrDuplicateLoader)
+	}
+	if got := len(cm.Status().Loaders); got != 2 {
+		t.Errorf("len(Status().Loaders) = %d, want 2", got)
+	}
+}
+
+func TestConfigManager_LoaderPriority(t *testing.T) {
+	t.Parallel()
+
+	cm, err := NewConfigManager(testConfigConstructor, func(cm *ConfigManager) error {
+		cm.AddLoader(Loader{
+			Name:      "overrides",
+			Source:    &fakeSource{data: []byte(`{"int": 3}`)},
+			Formatter: NewJSONFormatter(),
+			Priority:  10,
+		})
+		cm.AddLoader(Loader{Name: "file", Source: &fakeSource{data: []byte(`{"int": 2}`)}, Formatter: NewJSONFormatter()})
+		cm.AddLoader(Loader{
+			Name:      "defaults",
+			Source:    &fakeSource{data: []byte(`{"int": 1, "slice": ["a"]}`)},
+			Formatter: NewJSONFormatter(),
+			Priority:  -10,
+		})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("NewConfigManager() error = %v", err)
+	}
+	if err := cm.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer cm.MustStop()
+
+	want := &TestConfig{Int: 3, Slice: []string{"a"}}
+	if got := cm.Config(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Config() = %+v, want %+v", got, want)
+	}
+	wantOrder := []string{
+		`loader "defaults" (source *confgo.fakeSource)`,
+		`loader "file" (source *confgo.fakeSource)`,
+		`loader "overrides" (source *confgo.fakeSource)`,
+	}
+	if got := cm.MergeOrder(); !reflect.DeepEqual(got, wantOrder) {
+		t.Errorf("MergeOrder() = %q, want %q", got, wantOrder)
+	}
+
+	if err := cm.ReplaceLoader("overrides", Loader{
+		Source:    &fakeSource{data: []byte(`{"int": 4}`)},
+		Formatter: NewJSONFormatter(),
+		Priority:  -20,
+	}); err != nil {
+		t.Fatalf("ReplaceLoader() error = %v", err)
+	}
+	if got := cm.Config().(*TestConfig).Int; got != 2 {
+		t.Errorf("Config().Int = %d, want 2", got)
+	}
+	if got := cm.Status().Loaders; len(got) != 3 || got[0].Name != "overrides" || got[0].Loader != 0 {
+		t.Errorf("Status().Loaders = %+v, want the overrides loader first", got)
+	}
+}
@@ -0,0 +1,156 @@
+package confgo
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// mergeConfigs merges the config src into the config dst of the same type.
+// The src may be either a pointer to the config struct or the struct itself.
+//
+// Values are merged recursively by the rules of their kinds:
+//   - structs are merged field by field, except for value types such as time.Time which are leaves;
+//   - maps are merged key by key, the values of struct and map types are merged recursively,
+//     other values of src override the values of dst, even zero ones;
+//   - non-empty slices of src replace the slices of dst, unless a "merge" tag or MergeAppendSlices sets otherwise;
+//   - nil pointers and interfaces of src are skipped, pointers to structs are merged recursively
+//     and other pointers replace the pointers of dst, even if they point to zero values;
+//   - other non-zero values of src override the values of dst.
+//
+// Values taken from src are deep copied, so dst shares no pointers, slices or maps with src.
+func mergeConfigs(dst, src any, o *mergeOptions) error {
+	dstVal, srcVal := reflect.ValueOf(dst), reflect.ValueOf(src)
+	if dstVal.Kind() != reflect.Ptr || dstVal.IsNil() || dstVal.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("%w: %T is not a pointer to a struct", ErrConfigTypeMismatch, dst)
+	}
+	if !srcVal.IsValid() {
+		return nil
+	}
+	if srcVal.Kind() == reflect.Ptr && srcVal.Type() == dstVal.Type() {
+		if srcVal.IsNil() {
+			return nil
+		}
+		srcVal = srcVal.Elem()
+	}
+	if srcVal.Type() != dstVal.Type().Elem() {
+		return fmt.Errorf("%w: cannot merge %s into %s", ErrConfigTypeMismatch, srcVal.Type(), dstVal.Type())
+	}
+	return o.mergeValue(dstVal.Elem(), srcVal, "")
+}
+
+// mergeValue merges the value src into the settable value dst at the dotted path.
+func (o *mergeOptions) mergeValue(dst, src reflect.Value, path string) error {
+	switch dst.Kind() {
+	case reflect.Struct:
+		if isLeafStruct(dst.Type()) {
+			if !src.IsZero() {
+				dst.Set(src)
+			}
+			return nil
+		}
+		for i := range dst.NumField() {
+			sf := dst.Type().Field(i)
+			if !sf.IsExported() {
+				continue
+			}
+			key, ok := fieldKey(sf)
+			if !ok {
+				key = sf.Name
+			}
+			if err := o.mergeField(dst.Field(i), src.Field(i), sf, joinPath(path, key)); err != nil {
+				return err
+			}
+		}
+	case reflect.Ptr:
+		if src.IsNil() {
+			return nil
+		}
+		if dst.IsNil() || !isMergeableStruct(dst.Type().Elem()) {
+			dst.Set(deepCopy(src))
+			return nil
+		}
+		return o.mergeValue(dst.Elem(), src.Elem(), path)
+	case reflect.Map:
+		return o.mergeMap(dst, src, path)
+	case reflect.Slice:
+		if src.Len() == 0 {
+			return nil
+		}
+		if o.appendSlices && dst.Len() > 0 {
+			dst.Set(reflect.AppendSlice(deepCopy(dst), deepCopy(src)))
+			return nil
+		}
+		dst.Set(deepCopy(src))
+	case reflect.Interface, reflect.Func, reflect.Chan:
+		if !src.IsNil() {
+			dst.Set(deepCopy(src))
+		}
+	default:
+		if !src.IsZero() {
+			dst.Set(deepCopy(src))
+		}
+	}
+	return nil
+}
+
+// mergeField merges the struct field by its registered function, by the strategy of its "merge" tag
+// or as any other value.
+func (o *mergeOptions) mergeField(dst, src reflect.Value, sf reflect.StructField, path string) error {
+	if fn := o.fieldFunc(path, sf.Type); fn != nil {
+		merged, err := fn(dst.Interface(), src.Interface())
+		if err == nil {
+			err = assignValue(dst, merged)
+		}
+		if err != nil {
+			return fmt.Errorf("merge field %q: %w", path, err)
+		}
+		return nil
+	}
+	if tag, ok := sf.Tag.Lookup(mergeTag); ok {
+		return o.mergeTagged(dst, src, sf.Type, tag, path)
+	}
+	return o.mergeValue(dst, src, path)
+}
+
+// mergeMap merges the map src into the map dst key by key.
+func (o *mergeOptions) mergeMap(dst, src reflect.Value, path string) error {
+	if src.Len() == 0 {
+		return nil
+	}
+	merged := reflect.MakeMapWithSize(dst.Type(), max(dst.Len(), src.Len()))
+	for iter := dst.MapRange(); iter.Next(); {
+		merged.SetMapIndex(iter.Key(), iter.Value())
+	}
+	elemType := dst.Type().Elem()
+	for iter := src.MapRange(); iter.Next(); {
+		key, value := iter.Key(), iter.Value()
+		current := merged.MapIndex(key)
+		if !current.IsValid() || !isMergeableMapValue(elemType) {
+			merged.SetMapIndex(key, deepCopy(value))
+			continue
+		}
+		// Map values are not addressable, so the value is copied, merged and put back.
+		elem := reflect.New(elemType).Elem()
+		elem.Set(deepCopy(current))
+		if err := o.mergeValue(elem, value, joinPath(path, fmt.Sprint(key.Interface()))); err != nil {
+			return err
+		}
+		merged.SetMapIndex(key, elem)
+	}
+	dst.Set(merged)
+	return nil
+}
+
+// isMergeableStruct reports whether the values of the type are merged field by field.
+func isMergeableStruct(typ reflect.Type) bool {
+	return typ.Kind() == reflect.Struct && !isLeafStruct(typ)
+}
+
+// isMergeableMapValue reports whether the map values of the type are merged recursively
+// rather than replaced.
+func isMergeableMapValue(typ reflect.Type) bool {
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	return typ.Kind() == reflect.Map || isMergeableStruct(typ)
+}
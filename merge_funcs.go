@@ -0,0 +1,43 @@
+package confgo
+
+import "reflect"
+
+// FieldMergeFunc merges the value src of a field of an upper layer into the value dst of the lower layers
+// and returns the merged value of the field. Returned nil resets the field to its zero value.
+type FieldMergeFunc func(dst, src any) (any, error)
+
+// MergeField makes Merge merge the field at the dotted path with fn instead of the default merging,
+// so a single tricky field may have its own semantics without implementing Merger for the whole config.
+// fn is called whenever the structs holding the field are set in both dst and src, even if the src value is zero.
+func MergeField(path string, fn FieldMergeFunc) MergeOption {
+	return func(o *mergeOptions) {
+		if o.fieldFuncs == nil {
+			o.fieldFuncs = make(map[string]FieldMergeFunc)
+		}
+		o.fieldFuncs[path] = fn
+	}
+}
+
+// MergeType makes Merge merge every field of type T with fn as MergeField does.
+// Functions registered for field paths take precedence over the ones registered for types.
+func MergeType[T any](fn func(dst, src T) (T, error)) MergeOption {
+	return func(o *mergeOptions) {
+		if o.typeFuncs == nil {
+			o.typeFuncs = make(map[reflect.Type]FieldMergeFunc)
+		}
+		o.typeFuncs[reflect.TypeFor[T]()] = func(dst, src any) (any, error) {
+			// Nil interfaces are passed as zero values.
+			typedDst, _ := dst.(T)
+			typedSrc, _ := src.(T)
+			return fn(typedDst, typedSrc)
+		}
+	}
+}
+
+// fieldFunc returns the function merging the field at the path of the type, nil if there is none.
+func (o *mergeOptions) fieldFunc(path string, typ reflect.Type) FieldMergeFunc {
+	if fn, ok := o.fieldFuncs[path]; ok {
+		return fn
+	}
+	return o.typeFuncs[typ]
+}
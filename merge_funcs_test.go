@@ -0,0 +1,62 @@
+package confgo
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMerge_FieldFuncs(t *testing.T) {
+	t.Parallel()
+
+	sum := func(dst, src any) (any, error) {
+		return dst.(int) + src.(int), nil
+	}
+	joinStrings := func(dst, src string) (string, error) {
+		return strings.Trim(dst+"+"+src, "+"), nil
+	}
+	dst := &TestConfig{Int: 1, Inner: testInnerConfig{Int: 1, String: "a"}, Slice: []string{"a"}}
+	src := &TestConfig{Int: 2, Inner: testInnerConfig{Int: 0, String: "b"}, Slice: []string{"b"}}
+	if err := Merge(dst, src, MergeField("int", sum), MergeField("inner.int", sum), MergeType(joinStrings)); err != nil {
+		t.Fatalf("Merge() error = %v", err)
+	}
+	want := &TestConfig{Int: 3, Inner: testInnerConfig{Int: 1, String: "a+b"}, Slice: []string{"b"}}
+	if !reflect.DeepEqual(dst, want) {
+		t.Errorf("Merge() = %+v, want %+v", dst, want)
+	}
+
+	errMerge := errors.New("merge error")
+	err := Merge(dst, src, MergeField("inner.int", func(_, _ any) (any, error) { return nil, errMerge }))
+	if !errors.Is(err, errMerge) {
+		t.Errorf("Merge() error = %v, want %v", err, errMerge)
+	}
+}
+
+func TestConfigManager_WithFieldMerge(t *testing.T) {
+	t.Parallel()
+
+	maxInt := func(dst, src any) (any, error) {
+		return max(dst.(int), src.(int)), nil
+	}
+	cm, err := NewConfigManager(testConfigConstructor, WithFieldMerge("int", maxInt), func(cm *ConfigManager) error {
+		cm.AddLoader(Loader{Source: &fakeSource{data: []byte(`{"int": 5}`)}, Formatter: NewJSONFormatter()})
+		cm.AddLoader(Loader{Source: &fakeSource{data: []byte(`{"int": 3}`)}, Formatter: NewJSONFormatter()})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("NewConfigManager() error = %v", err)
+	}
+	if err := cm.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer cm.MustStop()
+
+	if got := cm.Config().(*TestConfig).Int; got != 5 {
+		t.Errorf("Config().Int = %d, want 5", got)
+	}
+
+	if _, err := NewConfigManager(testConfigConstructor, WithFieldMerge("unknown", maxInt)); !errors.Is(err, ErrInvalidFieldPath) {
+		t.Errorf("NewConfigManager() error = %v, want %v", err, ErrInvalidFieldPath)
+	}
+}
@@ -0,0 +1,156 @@
+package confgo
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Merge strategies of slice fields set by the "merge" struct tag, e.g.:
+//
+//	Hosts []string `json:"hosts" merge:"append"`
+const (
+	// MergeReplace replaces the slice of a lower layer with a non-empty slice of an upper one. It is the default.
+	MergeReplace = "replace"
+	// MergeAppend appends the items of an upper layer to the items of the lower layers,
+	// so an override file may add extra items instead of repeating the whole list.
+	MergeAppend = "append"
+	// MergeUnique is like MergeAppend, but skips the items equal to the items already in the slice.
+	MergeUnique = "unique"
+	// MergeByKey merges slices of structs matching their items by the field named after "=", e.g. "byKey=name".
+	// Items of an upper layer are merged into the items of the lower layers with the same key,
+	// the items with new keys are appended, so a list of endpoints or rules may be amended item by item.
+	MergeByKey = "byKey"
+)
+
+const mergeTag = "merge"
+
+// mergeTagged merges the slice field of the type by the strategy of its "merge" tag.
+func (o *mergeOptions) mergeTagged(dst, src reflect.Value, typ reflect.Type, tag, path string) error {
+	if typ.Kind() != reflect.Slice {
+		return fmt.Errorf("%w: %q: merge tag on %s field", ErrInvalidMergeTag, path, typ)
+	}
+	strategy, key, _ := strings.Cut(tag, "=")
+	switch strategy {
+	case MergeReplace:
+		if src.Len() > 0 {
+			dst.Set(deepCopy(src))
+		}
+		return nil
+	case MergeAppend, MergeUnique:
+		mergeSlices(dst, src, strategy == MergeUnique)
+		return nil
+	case MergeByKey:
+		if err := checkMergeKey(typ.Elem(), key); err != nil {
+			return fmt.Errorf("%w: %q: %w", ErrInvalidMergeTag, path, err)
+		}
+		if err := o.mergeByKey(dst, src, key); err != nil {
+			return fmt.Errorf("merge field %q: %w", path, err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("%w: %q: unknown strategy %q", ErrInvalidMergeTag, path, strategy)
+	}
+}
+
+// checkMergeKey reports whether the items of the type may be matched by the field with the key.
+func checkMergeKey(typ reflect.Type, key string) error {
+	if key == "" {
+		return errors.New("no key field")
+	}
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return fmt.Errorf("items of %s are not structs", typ)
+	}
+	if _, ok := structFieldByKey(reflect.New(typ).Elem(), key); !ok {
+		return fmt.Errorf("unknown key field %q of %s", key, typ)
+	}
+	return nil
+}
+
+// mergeSlices appends the items of src to the items of dst, skipping the items already in the slice if unique is true.
+func mergeSlices(dst, src reflect.Value, unique bool) {
+	if src.Len() == 0 {
+		return
+	}
+	if dst.Len() == 0 {
+		dst.Set(deepCopy(src))
+		return
+	}
+	merged := reflect.MakeSlice(dst.Type(), 0, dst.Len()+src.Len())
+	for _, items := range []reflect.Value{dst, src} {
+		for i := range items.Len() {
+			item := items.Index(i)
+			if unique && containsItem(merged, item) {
+				continue
+			}
+			merged = reflect.Append(merged, deepCopy(item))
+		}
+	}
+	dst.Set(merged)
+}
+
+func containsItem(items, item reflect.Value) bool {
+	for i := range items.Len() {
+		if reflect.DeepEqual(items.Index(i).Interface(), item.Interface()) {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeByKey merges the items of src into the items of dst with the same key and appends the items with new keys.
+// Functions registered for field paths are not applied to the fields of the items.
+func (o *mergeOptions) mergeByKey(dst, src reflect.Value, key string) error {
+	if src.Len() == 0 {
+		return nil
+	}
+	itemOpts := *o
+	itemOpts.fieldFuncs = nil
+	merged := deepCopy(dst)
+	if merged.IsNil() {
+		merged = reflect.MakeSlice(dst.Type(), 0, src.Len())
+	}
+	for i := range src.Len() {
+		item := src.Index(i)
+		j := indexByKey(merged, item, key)
+		if j < 0 {
+			merged = reflect.Append(merged, deepCopy(item))
+			continue
+		}
+		if err := itemOpts.mergeValue(merged.Index(j), item, ""); err != nil {
+			return fmt.Errorf("item %d: %w", i, err)
+		}
+	}
+	dst.Set(merged)
+	return nil
+}
+
+// indexByKey returns the index of the item of items with the same key as item, -1 if there is none.
+// Nil items match nothing.
+func indexByKey(items, item reflect.Value, key string) int {
+	itemKey, ok := mergeKeyOf(item, key)
+	if !ok {
+		return -1
+	}
+	for i := range items.Len() {
+		if other, ok := mergeKeyOf(items.Index(i), key); ok && reflect.DeepEqual(itemKey, other) {
+			return i
+		}
+	}
+	return -1
+}
+
+func mergeKeyOf(item reflect.Value, key string) (any, bool) {
+	if item.Kind() == reflect.Ptr {
+		if item.IsNil() {
+			return nil, false
+		}
+		item = item.Elem()
+	}
+	field, _ := structFieldByKey(item, key)
+	return field.Interface(), true
+}
@@ -0,0 +1,151 @@
+package confgo
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type testMergeTagsInner struct {
+	Rules []string `json:"rules" merge:"unique"`
+}
+
+type testMergeTagsConfig struct {
+	Hosts    []string            `json:"hosts" merge:"append"`
+	Tags     []string            `json:"tags" merge:"unique"`
+	Ports    []int               `json:"ports" merge:"replace"`
+	Plain    []string            `json:"plain"`
+	Inner    testMergeTagsInner  `json:"inner"`
+	InnerPtr *testMergeTagsInner `json:"inner_ptr"`
+}
+
+func TestMerge_Tags(t *testing.T) {
+	t.Parallel()
+
+	dst := &testMergeTagsConfig{
+		Hosts:    []string{"a"},
+		Tags:     []string{"x", "y"},
+		Ports:    []int{80},
+		Plain:    []string{"a"},
+		Inner:    testMergeTagsInner{Rules: []string{"r1"}},
+		InnerPtr: &testMergeTagsInner{Rules: []string{"r1"}},
+	}
+	src := &testMergeTagsConfig{
+		Hosts:    []string{"b"},
+		Tags:     []string{"y", "z"},
+		Ports:    []int{443},
+		Plain:    []string{"b"},
+		Inner:    testMergeTagsInner{Rules: []string{"r1", "r2"}},
+		InnerPtr: &testMergeTagsInner{Rules: nil},
+	}
+	if err := Merge(dst, src); err != nil {
+		t.Fatalf("Merge() error = %v", err)
+	}
+	want := &testMergeTagsConfig{
+		Hosts:    []string{"a", "b"},
+		Tags:     []string{"x", "y", "z"},
+		Ports:    []int{443},
+		Plain:    []string{"b"},
+		Inner:    testMergeTagsInner{Rules: []string{"r1", "r2"}},
+		InnerPtr: &testMergeTagsInner{Rules: []string{"r1"}},
+	}
+	if !reflect.DeepEqual(dst, want) {
+		t.Errorf("Merge() = %+v, want %+v", dst, want)
+	}
+	if want := []string{"b"}; !reflect.DeepEqual(src.Hosts, want) {
+		t.Errorf("src.Hosts = %v, want %v", src.Hosts, want)
+	}
+}
+
+func TestMerge_InvalidTags(t *testing.T) {
+	t.Parallel()
+
+	type unknownStrategy struct {
+		Hosts []string `merge:"prepend"`
+	}
+	if err := Merge(&unknownStrategy{}, &unknownStrategy{}); !errors.Is(err, ErrInvalidMergeTag) {
+		t.Errorf("Merge() error = %v, want %v", err, ErrInvalidMergeTag)
+	}
+	type notSlice struct {
+		Host string `merge:"append"`
+	}
+	if err := Merge(&notSlice{}, &notSlice{}); !errors.Is(err, ErrInvalidMergeTag) {
+		t.Errorf("Merge() error = %v, want %v", err, ErrInvalidMergeTag)
+	}
+}
+
+type testMergeEndpoint struct {
+	Name    string   `json:"name"`
+	URL     string   `json:"url"`
+	Retries int      `json:"retries"`
+	Tags    []string `json:"tags" merge:"append"`
+}
+
+func TestMerge_ByKey(t *testing.T) {
+	t.Parallel()
+
+	type config struct {
+		Endpoints []testMergeEndpoint  `json:"endpoints" merge:"byKey=name"`
+		Pointers  []*testMergeEndpoint `json:"pointers" merge:"byKey=name"`
+	}
+	dst := &config{
+		Endpoints: []testMergeEndpoint{{Name: "a", URL: "http://a", Retries: 1, Tags: []string{"x"}}, {Name: "b", URL: "http://b"}},
+		Pointers:  []*testMergeEndpoint{{Name: "a", URL: "http://a"}, nil},
+	}
+	orig := dst.Endpoints
+	src := &config{
+		Endpoints: []testMergeEndpoint{{Name: "a", Retries: 3, Tags: []string{"y"}}, {Name: "c", URL: "http://c"}},
+		Pointers:  []*testMergeEndpoint{{Name: "a", Retries: 3}, {Name: "b", URL: "http://b"}},
+	}
+	if err := Merge(dst, src); err != nil {
+		t.Fatalf("Merge() error = %v", err)
+	}
+	want := &config{
+		Endpoints: []testMergeEndpoint{
+			{Name: "a", URL: "http://a", Retries: 3, Tags: []string{"x", "y"}},
+			{Name: "b", URL: "http://b"},
+			{Name: "c", URL: "http://c"},
+		},
+		Pointers: []*testMergeEndpoint{{Name: "a", URL: "http://a", Retries: 3}, nil, {Name: "b", URL: "http://b"}},
+	}
+	if !reflect.DeepEqual(dst, want) {
+		t.Errorf("Merge() = %+v, want %+v", dst, want)
+	}
+	if orig[0].Retries != 1 {
+		t.Errorf("Merge() modified the original items of dst")
+	}
+
+	type noKey struct {
+		Endpoints []testMergeEndpoint `merge:"byKey"`
+	}
+	if err := Merge(&noKey{}, &noKey{}); !errors.Is(err, ErrInvalidMergeTag) {
+		t.Errorf("Merge() without key error = %v, want %v", err, ErrInvalidMergeTag)
+	}
+	type unknownKey struct {
+		Endpoints []testMergeEndpoint `merge:"byKey=unknown"`
+	}
+	if err := Merge(&unknownKey{}, &unknownKey{}); !errors.Is(err, ErrInvalidMergeTag) {
+		t.Errorf("Merge() with unknown key error = %v, want %v", err, ErrInvalidMergeTag)
+	}
+}
+
+func TestConfigManager_MergeTags(t *testing.T) {
+	t.Parallel()
+
+	cm, err := NewConfigManagerFor[testMergeTagsConfig](func(cm *ConfigManager) error {
+		cm.AddLoader(Loader{Source: &fakeSource{data: []byte(`{"hosts": ["a"]}`)}, Formatter: NewJSONFormatter()})
+		cm.AddLoader(Loader{Source: &fakeSource{data: []byte(`{"hosts": ["b"]}`)}, Formatter: NewJSONFormatter()})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("NewConfigManagerFor() error = %v", err)
+	}
+	if err := cm.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer cm.MustStop()
+
+	if got, want := cm.Config().(*testMergeTagsConfig).Hosts, []string{"a", "b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Config().Hosts = %v, want %v", got, want)
+	}
+}
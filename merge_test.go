@@ -0,0 +1,80 @@
+package confgo
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testMergeConfig struct {
+	Timeout  time.Duration              `json:"timeout"`
+	Since    time.Time                  `json:"since"`
+	Debug    *bool                      `json:"debug"`
+	Services map[string]testInnerConfig `json:"services"`
+	Nested   map[string]map[string]int  `json:"nested"`
+	Inner    *testInnerConfig           `json:"inner"`
+}
+
+func TestMerge_Native(t *testing.T) {
+	t.Parallel()
+
+	since := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	dst := &testMergeConfig{
+		Timeout:  time.Second,
+		Since:    since,
+		Debug:    ptr(true),
+		Services: map[string]testInnerConfig{"a": {Int: 1, String: "a"}, "b": {Int: 2}},
+		Nested:   map[string]map[string]int{"x": {"one": 1}},
+	}
+	src := &testMergeConfig{
+		Debug:    ptr(false),
+		Services: map[string]testInnerConfig{"a": {Int: 10}, "c": {String: "c"}},
+		Nested:   map[string]map[string]int{"x": {"two": 2}, "y": {"zero": 0}},
+		Inner:    &testInnerConfig{Int: 3},
+	}
+	if err := Merge(dst, src); err != nil {
+		t.Fatalf("Merge() error = %v", err)
+	}
+	want := &testMergeConfig{
+		Timeout:  time.Second,
+		Since:    since,
+		Debug:    ptr(false),
+		Services: map[string]testInnerConfig{"a": {Int: 10, String: "a"}, "b": {Int: 2}, "c": {String: "c"}},
+		Nested:   map[string]map[string]int{"x": {"one": 1, "two": 2}, "y": {"zero": 0}},
+		Inner:    &testInnerConfig{Int: 3},
+	}
+	if !reflect.DeepEqual(dst, want) {
+		t.Errorf("Merge() = %+v, want %+v", dst, want)
+	}
+	if dst.Inner == src.Inner || dst.Debug == src.Debug {
+		t.Errorf("Merge() shares pointers with src")
+	}
+	dst.Nested["y"]["zero"] = 1
+	if src.Nested["y"]["zero"] != 0 {
+		t.Errorf("Merge() shares maps with src")
+	}
+}
+
+func TestMerge_TypeMismatch(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		dst  any
+		src  any
+	}{
+		{name: "nil dst", dst: (*TestConfig)(nil), src: &TestConfig{}},
+		{name: "dst not a pointer", dst: TestConfig{}, src: &TestConfig{}},
+		{name: "different types", dst: &TestConfig{}, src: &testMergeConfig{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if err := Merge(tt.dst, tt.src); !errors.Is(err, ErrConfigTypeMismatch) {
+				t.Errorf("Merge() error = %v, want %v", err, ErrConfigTypeMismatch)
+			}
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package confgo
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"sync"
+)
+
+// MQTTClient subscribes to the topics of an MQTT broker. The module does not depend on an MQTT client,
+// so the client is wrapped by a small adapter, e.g. for paho.mqtt.golang with auto reconnect
+// and a persistent session, so that the subscription survives reconnects:
+//
+//	type mqttClient struct{ c mqtt.Client }
+//
+//	func (a mqttClient) Subscribe(topic string, handler func(payload []byte)) error {
+//		token := a.c.Subscribe(topic, 1, func(_ mqtt.Client, m mqtt.Message) { handler(m.Payload()) })
+//		token.Wait()
+//		return token.Error()
+//	}
+//
+//	func (a mqttClient) Unsubscribe(topic string) error {
+//		token := a.c.Unsubscribe(topic)
+//		token.Wait()
+//		return token.Error()
+//	}
+type MQTTClient interface {
+	// Subscribe subscribes to the topic and calls the handler with the payload of every received message.
+	Subscribe(topic string, handler func(payload []byte)) error
+	// Unsubscribe unsubscribes from the topic.
+	Unsubscribe(topic string) error
+}
+
+var (
+	_ Source        = (*MQTTSource)(nil)
+	_ SourceContext = (*MQTTSource)(nil)
+	_ Watcher       = (*MQTTWatcher)(nil)
+)
+
+// MQTTSource is a configuration source that subscribes to a topic with the retained configuration,
+// e.g. "devices/thermostat/config", so that the broker delivers the current configuration on subscription
+// and pushes every new one. Reads wait for the first message, and an empty payload, which clears
+// the retained message, is reported as fs.ErrNotExist, so the loader may be optional.
+type MQTTSource struct {
+	client MQTTClient
+	topic  string
+	// received is closed once the first message is received.
+	received chan struct{}
+
+	mu         sync.Mutex
+	subscribed bool
+	data       []byte
+	callback   func()
+}
+
+// NewMQTTSource creates a source subscribing to the topic with client.
+func NewMQTTSource(client MQTTClient, topic string) *MQTTSource {
+	return &MQTTSource{
+		client:     client,
+		topic:      topic,
+		received:   make(chan struct{}),
+		mu:         sync.Mutex{},
+		subscribed: false,
+		data:       nil,
+		callback:   nil,
+	}
+}
+
+func (ms *MQTTSource) Read() ([]byte, error) {
+	return ms.ReadContext(context.Background())
+}
+
+// ReadContext returns the payload of the last received message, subscribing to the topic if needed
+// and waiting until ctx is done for the first message.
+func (ms *MQTTSource) ReadContext(ctx context.Context) ([]byte, error) {
+	if err := ms.subscribe(); err != nil {
+		return nil, err
+	}
+	select {
+	case <-ms.received:
+	case <-ctx.Done():
+		return nil, fmt.Errorf("mqtt topic %q: wait for config: %w", ms.topic, ctx.Err())
+	}
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	if len(ms.data) == 0 {
+		return nil, fmt.Errorf("mqtt topic %q: %w", ms.topic, fs.ErrNotExist)
+	}
+	return bytes.Clone(ms.data), nil
+}
+
+// Close unsubscribes from the topic.
+func (ms *MQTTSource) Close() error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	if !ms.subscribed {
+		return nil
+	}
+	if err := ms.client.Unsubscribe(ms.topic); err != nil {
+		return fmt.Errorf("unsubscribe from mqtt topic %q: %w", ms.topic, err)
+	}
+	ms.subscribed = false
+	return nil
+}
+
+// subscribe subscribes to the topic unless already subscribed.
+func (ms *MQTTSource) subscribe() error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	if ms.subscribed {
+		return nil
+	}
+	// The handler must not be called synchronously by Subscribe, as the lock is held.
+	if err := ms.client.Subscribe(ms.topic, ms.store); err != nil {
+		return fmt.Errorf("subscribe to mqtt topic %q: %w", ms.topic, err)
+	}
+	ms.subscribed = true
+	return nil
+}
+
+// store stores the payload and calls the watcher callback if it has changed.
+func (ms *MQTTSource) store(payload []byte) {
+	ms.mu.Lock()
+	first := ms.data == nil
+	changed := !first && !bytes.Equal(ms.data, payload)
+	if payload == nil {
+		payload = []byte{}
+	}
+	ms.data = bytes.Clone(payload)
+	callback := ms.callback
+	ms.mu.Unlock()
+	if first {
+		close(ms.received)
+	}
+	if changed && callback != nil {
+		callback()
+	}
+}
+
+// MQTTWatcher triggers reloads whenever a changed configuration is published to the topic of MQTTSource.
+// Stop unsubscribes from the topic.
+type MQTTWatcher struct {
+	source *MQTTSource
+}
+
+func NewMQTTWatcher(source *MQTTSource) *MQTTWatcher {
+	return &MQTTWatcher{source: source}
+}
+
+// Watch subscribes to the topic unless the source has subscribed to it by reading.
+// A failed subscription is retried by the next read.
+func (mw *MQTTWatcher) Watch(callback func()) {
+	mw.source.mu.Lock()
+	mw.source.callback = callback
+	mw.source.mu.Unlock()
+	_ = mw.source.subscribe()
+}
+
+func (mw *MQTTWatcher) Stop() error {
+	return mw.source.Close()
+}
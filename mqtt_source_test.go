@@ -0,0 +1,103 @@
+package confgo
+
+import (
+	"errors"
+	"io/fs"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeMQTTBroker delivers the retained message of a single topic like a broker.
+type fakeMQTTBroker struct {
+	mu       sync.Mutex
+	retained []byte
+	handler  func(payload []byte)
+	subs     int
+	unsubs   int
+}
+
+func (b *fakeMQTTBroker) Subscribe(_ string, handler func(payload []byte)) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handler = handler
+	b.subs++
+	if b.retained != nil {
+		go handler(b.retained)
+	}
+	return nil
+}
+
+func (b *fakeMQTTBroker) Unsubscribe(string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handler = nil
+	b.unsubs++
+	return nil
+}
+
+func (b *fakeMQTTBroker) publish(payload string) {
+	b.mu.Lock()
+	b.retained = []byte(payload)
+	handler := b.handler
+	b.mu.Unlock()
+	if handler != nil {
+		handler([]byte(payload))
+	}
+}
+
+func TestMQTTSource(t *testing.T) {
+	t.Parallel()
+
+	broker := &fakeMQTTBroker{retained: []byte(`{"int": 1}`)}
+	s := NewMQTTSource(broker, "devices/app/config")
+	updated := make(chan struct{}, 1)
+	cm, err := NewConfigManager(testConfigConstructor, WithLoader(Loader{
+		Source:          s,
+		Formatter:       NewJSONFormatter(),
+		Watcher:         NewMQTTWatcher(s),
+		OnUpdateSuccess: func() { updated <- struct{}{} },
+	}))
+	if err != nil {
+		t.Fatalf("NewConfigManager() error = %v", err)
+	}
+	if err := cm.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if got := cm.Config().(*TestConfig).Int; got != 1 {
+		t.Fatalf("Config().Int = %d, want 1", got)
+	}
+
+	// The unchanged config does not trigger a reload.
+	broker.publish(`{"int": 1}`)
+	broker.publish(`{"int": 2}`)
+	select {
+	case <-updated:
+	case <-time.After(5 * time.Second):
+		t.Fatal("MQTTWatcher did not report the new config")
+	}
+	if got := cm.Config().(*TestConfig).Int; got != 2 {
+		t.Errorf("Config().Int = %d, want 2", got)
+	}
+
+	cm.MustStop()
+	broker.mu.Lock()
+	defer broker.mu.Unlock()
+	if broker.subs != 1 || broker.unsubs != 1 {
+		t.Errorf("subscribed %d and unsubscribed %d times, want 1 and 1", broker.subs, broker.unsubs)
+	}
+}
+
+func TestMQTTSource_Cleared(t *testing.T) {
+	t.Parallel()
+
+	broker := &fakeMQTTBroker{retained: []byte{}}
+	s := NewMQTTSource(broker, "devices/app/config")
+	if _, err := s.Read(); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Read() error = %v, want fs.ErrNotExist", err)
+	}
+	broker.publish(`{"int": 1}`)
+	if data, err := s.Read(); err != nil || string(data) != `{"int": 1}` {
+		t.Errorf("Read() = %q, %v", data, err)
+	}
+}
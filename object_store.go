@@ -0,0 +1,316 @@
+package confgo
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	objectStoreRequestTimeout = 30 * time.Second
+	objectStorePollInterval   = 30 * time.Second
+	objectStoreDefaultRegion  = "us-east-1"
+	// emptyPayloadHash is the hex encoded SHA-256 of an empty request body.
+	emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	amzDateFormat    = "20060102T150405Z"
+)
+
+// ObjectStoreSourceOption configures ObjectStoreSource.
+type ObjectStoreSourceOption func(oss *ObjectStoreSource)
+
+// ObjectStoreCredentials sets the access keys the requests are signed with using AWS Signature Version 4,
+// e.g. the keys of AWS IAM, MinIO or GCS HMAC keys. The session token may be empty.
+// Without credentials the requests are anonymous, which suits public buckets.
+func ObjectStoreCredentials(accessKeyID, secretAccessKey, sessionToken string) ObjectStoreSourceOption {
+	return func(oss *ObjectStoreSource) {
+		oss.accessKeyID = accessKeyID
+		oss.secretAccessKey = secretAccessKey
+		oss.sessionToken = sessionToken
+	}
+}
+
+// ObjectStoreRegion sets the region of the bucket the requests are signed for. Defaults to "us-east-1".
+func ObjectStoreRegion(region string) ObjectStoreSourceOption {
+	return func(oss *ObjectStoreSource) {
+		oss.region = region
+	}
+}
+
+// ObjectStoreVirtualHosted addresses the bucket as a subdomain of the endpoint, e.g.
+// "https://bucket.s3.amazonaws.com/key", instead of a path, e.g. "https://s3.amazonaws.com/bucket/key".
+func ObjectStoreVirtualHosted(oss *ObjectStoreSource) {
+	oss.virtualHosted = true
+}
+
+// ObjectStoreHTTPClient sets the HTTP client used for requests to the storage.
+func ObjectStoreHTTPClient(client *http.Client) ObjectStoreSourceOption {
+	return func(oss *ObjectStoreSource) {
+		oss.client = client
+	}
+}
+
+var (
+	_ Source        = (*ObjectStoreSource)(nil)
+	_ SourceContext = (*ObjectStoreSource)(nil)
+)
+
+// ObjectStoreSource is a configuration source that reads an object from S3-compatible storage,
+// e.g. AWS S3, MinIO or Google Cloud Storage with its XML API. A missing object is reported
+// as fs.ErrNotExist, so the loader may be optional.
+type ObjectStoreSource struct {
+	endpoint        *url.URL
+	bucket          string
+	key             string
+	region          string
+	accessKeyID     string
+	secretAccessKey string
+	sessionToken    string
+	virtualHosted   bool
+	client          *http.Client
+	// now returns the time requests are signed at.
+	now func() time.Time
+
+	mu      sync.Mutex
+	version objectVersion
+}
+
+// objectVersion identifies the contents of an object.
+type objectVersion struct {
+	etag      string
+	versionID string
+}
+
+// NewObjectStoreSource creates a source reading the object with the key from the bucket of the storage
+// at endpoint, e.g. NewObjectStoreSource("https://s3.amazonaws.com", "configs", "myapp/config.yaml").
+// An invalid endpoint is reported by Read.
+func NewObjectStoreSource(endpoint, bucket, key string, opts ...ObjectStoreSourceOption) *ObjectStoreSource {
+	u, err := url.Parse(strings.TrimSuffix(endpoint, "/"))
+	if err != nil {
+		u = nil
+	}
+	oss := &ObjectStoreSource{
+		endpoint:        u,
+		bucket:          bucket,
+		key:             strings.TrimPrefix(key, "/"),
+		region:          objectStoreDefaultRegion,
+		accessKeyID:     "",
+		secretAccessKey: "",
+		sessionToken:    "",
+		virtualHosted:   false,
+		client:          &http.Client{Timeout: objectStoreRequestTimeout},
+		now:             time.Now,
+		mu:              sync.Mutex{},
+		version:         objectVersion{},
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(oss)
+		}
+	}
+	return oss
+}
+
+func (oss *ObjectStoreSource) Read() ([]byte, error) {
+	return oss.ReadContext(context.Background())
+}
+
+// ReadContext is the same as Read but aborts the request once ctx is done.
+func (oss *ObjectStoreSource) ReadContext(ctx context.Context) ([]byte, error) {
+	resp, err := oss.do(ctx, http.MethodGet)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read object %q: %w", oss.bucket+"/"+oss.key, err)
+	}
+	oss.mu.Lock()
+	oss.version = versionOf(resp)
+	oss.mu.Unlock()
+	return data, nil
+}
+
+// head returns the version of the object without reading its contents.
+func (oss *ObjectStoreSource) head(ctx context.Context) (objectVersion, error) {
+	resp, err := oss.do(ctx, http.MethodHead)
+	if err != nil {
+		return objectVersion{}, err
+	}
+	resp.Body.Close()
+	return versionOf(resp), nil
+}
+
+func versionOf(resp *http.Response) objectVersion {
+	return objectVersion{etag: resp.Header.Get("ETag"), versionID: resp.Header.Get("X-Amz-Version-Id")}
+}
+
+// do sends the request for the object and returns the successful response.
+func (oss *ObjectStoreSource) do(ctx context.Context, method string) (*http.Response, error) {
+	if oss.endpoint == nil {
+		return nil, fmt.Errorf("object %q: invalid endpoint", oss.bucket+"/"+oss.key)
+	}
+	u := *oss.endpoint
+	if oss.virtualHosted {
+		u.Host = oss.bucket + "." + u.Host
+		u.Path += "/" + oss.key
+	} else {
+		u.Path += "/" + oss.bucket + "/" + oss.key
+	}
+	// The path is escaped as Signature Version 4 requires, which Go keeps as a valid encoding of the path.
+	u.RawPath = escapeObjectPath(u.Path)
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if oss.accessKeyID != "" {
+		oss.sign(req, oss.now())
+	}
+	resp, err := oss.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<10))
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, fmt.Errorf("object %q: %w", oss.bucket+"/"+oss.key, fs.ErrNotExist)
+		}
+		return nil, fmt.Errorf("object %q: %w: %s: %s", oss.bucket+"/"+oss.key, ErrUnexpectedStatus, resp.Status,
+			strings.TrimSpace(string(msg)))
+	}
+	return resp, nil
+}
+
+// sign signs the request without a body with AWS Signature Version 4, covering the host
+// and all the headers of the request.
+func (oss *ObjectStoreSource) sign(req *http.Request, now time.Time) {
+	amzDate := now.UTC().Format(amzDateFormat)
+	scope := amzDate[:8] + "/" + oss.region + "/s3/aws4_request"
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", emptyPayloadHash)
+	if oss.sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", oss.sessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.Join(values, ",")
+	}
+	names := slices.Sorted(maps.Keys(headers))
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(headers[name]) + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.Query().Encode(),
+		canonicalHeaders.String(),
+		signedHeaders,
+		emptyPayloadHash,
+	}, "\n")
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := []byte("AWS4" + oss.secretAccessKey)
+	for _, part := range []string{amzDate[:8], oss.region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+oss.accessKeyID+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+signature)
+}
+
+// escapeObjectPath percent-encodes all the bytes of the path except the unreserved characters of RFC 3986 and slashes.
+func escapeObjectPath(path string) string {
+	var b strings.Builder
+	for i := range len(path) {
+		c := path[i]
+		if 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || strings.IndexByte("-_.~/", c) >= 0 {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// ObjectStoreWatcherOption configures ObjectStoreWatcher.
+type ObjectStoreWatcherOption func(ow *ObjectStoreWatcher)
+
+// ObjectStoreWatchInterval sets how often the object is checked. Defaults to 30 seconds.
+func ObjectStoreWatchInterval(interval time.Duration) ObjectStoreWatcherOption {
+	return func(ow *ObjectStoreWatcher) {
+		ow.interval = interval
+	}
+}
+
+var _ Watcher = (*ObjectStoreWatcher)(nil)
+
+// ObjectStoreWatcher periodically requests the metadata of the object of ObjectStoreSource
+// and calls the callback if its ETag or version differs from the ones of the last read.
+type ObjectStoreWatcher struct {
+	source   *ObjectStoreSource
+	interval time.Duration
+	stop     chan struct{}
+}
+
+func NewObjectStoreWatcher(source *ObjectStoreSource, opts ...ObjectStoreWatcherOption) *ObjectStoreWatcher {
+	ow := &ObjectStoreWatcher{
+		source:   source,
+		interval: objectStorePollInterval,
+		stop:     make(chan struct{}),
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(ow)
+		}
+	}
+	return ow
+}
+
+func (ow *ObjectStoreWatcher) Watch(callback func()) {
+	go func() {
+		for {
+			select {
+			case <-ow.stop:
+				return
+			case <-time.After(ow.interval):
+				version, err := ow.source.head(context.Background())
+				if err != nil {
+					continue
+				}
+				ow.source.mu.Lock()
+				last := ow.source.version
+				ow.source.mu.Unlock()
+				if version != last {
+					callback()
+				}
+			}
+		}
+	}()
+}
+
+func (ow *ObjectStoreWatcher) Stop() error {
+	close(ow.stop)
+	return nil
+}
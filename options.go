@@ -1,5 +1,17 @@
 package confgo
 
+import (
+	"database/sql"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+)
+
 // WithValidator adds a custom validator which will be called on each config load.
 func WithValidator(v ValidateFunc) Option {
 	return func(cm *ConfigManager) error {
@@ -8,10 +20,241 @@ func WithValidator(v ValidateFunc) Option {
 	}
 }
 
+// WithConfigValidator adds a custom validator which will be called with the candidate config on each config load,
+// before the candidate replaces the current config. Such validators are called after all the other ones.
+func WithConfigValidator(v ConfigValidateFunc) Option {
+	return func(cm *ConfigManager) error {
+		cm.configValidators = append(cm.configValidators, v)
+		return nil
+	}
+}
+
+// WithTypedValidator is the same as WithConfigValidator but passes the candidate config as *T.
+// A config of another type is reported with ErrConfigTypeMismatch.
+func WithTypedValidator[T any](v func(cfg *T) error) Option {
+	if v == nil {
+		return WithConfigValidator(nil)
+	}
+	return WithConfigValidator(func(cfg any) error {
+		typed, ok := cfg.(*T)
+		if !ok {
+			return fmt.Errorf("%w: got %T, want %T", ErrConfigTypeMismatch, cfg, typed)
+		}
+		return v(typed)
+	})
+}
+
 // WithNamedValidator adds a custom named validator which will be called on each config load.
+// Named validators are called in the order of registration, every one of them is called even if
+// the previous ones fail, and their results are available via ConfigManager.ValidationReport.
+// Adding a validator with an already registered name replaces it keeping its position.
 func WithNamedValidator(name string, v ValidateFunc) Option {
 	return func(cm *ConfigManager) error {
-		cm.namedValidators[name] = v
+		cm.namedValidators.set(name, v)
+		return nil
+	}
+}
+
+// WithStructTagValidation makes the manager validate every loaded config with v, e.g. validator.New()
+// of github.com/go-playground/validator/v10, so fields may be validated declaratively with `validate:"..."` tags.
+// The struct tags are validated after Validate method of Validator and before the custom validators.
+func WithStructTagValidation(v StructValidator) Option {
+	return func(cm *ConfigManager) error {
+		if v == nil {
+			return fmt.Errorf("struct validator: %w", ErrValidatorIsNil)
+		}
+		cm.structValidator = v
+		return nil
+	}
+}
+
+// WithPopulatedSections makes the manager allocate every optional config section, i.e. a pointer to a nested struct,
+// which is absent from all layers, so consumers may access its fields without nil checks.
+// Allocated sections hold the values of their default tags or zero values and are visible to validators.
+func WithPopulatedSections(cm *ConfigManager) error {
+	cm.populateSections = true
+	return nil
+}
+
+// WithDefaults seeds every config reload with the non-zero fields of cfg, which must be a struct
+// or a pointer to a struct of the config type. Config layers override the defaults field by field.
+// The defaults take precedence over SetDefaults method of Defaulter and default tags.
+func WithDefaults(cfg any) Option {
+	return func(cm *ConfigManager) error {
+		v := reflect.ValueOf(cfg)
+		if v.Kind() == reflect.Struct {
+			ptr := reflect.New(v.Type())
+			ptr.Elem().Set(v)
+			v = ptr
+		}
+		if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+			return fmt.Errorf("defaults: %w: got %T", ErrConfigTypeMismatch, cfg)
+		}
+		cm.defaults = deepCopy(v).Interface()
+		return nil
+	}
+}
+
+// WithSecretResolver makes the manager resolve string values of the loaded config which are references
+// of the scheme, e.g. "vault://secret/db#password" for the "vault" scheme, with the resolver.
+// References are resolved after all layers are merged and overrides are applied, before validation.
+// Registering a resolver for an already registered scheme replaces it.
+func WithSecretResolver(scheme string, r SecretResolver) Option {
+	return func(cm *ConfigManager) error {
+		if r == nil {
+			return fmt.Errorf("scheme %q: %w", scheme, ErrSecretResolverIsNil)
+		}
+		cm.secretResolvers[strings.ToLower(scheme)] = r
+		return nil
+	}
+}
+
+// WithLogger makes the manager emit structured events to the logger: start and stop, the read and merge
+// of every loader with its source and durations, validation and the swap of the configuration.
+// Failures are logged with Warn and Error levels, the swaps with Info level and the rest with Debug level.
+// The manager is silent by default or if the logger is nil.
+func WithLogger(logger *slog.Logger) Option {
+	return func(cm *ConfigManager) error {
+		cm.logger = logger
+		return nil
+	}
+}
+
+// WithMaxStaleness makes Status report the manager as not ready once its reloads have been failing
+// for longer than d, so readiness probes stop routing traffic to an instance serving an outdated configuration.
+// Zero or negative d does not limit the staleness, which is the default.
+func WithMaxStaleness(d time.Duration) Option {
+	return func(cm *ConfigManager) error {
+		cm.maxStaleness = d
+		return nil
+	}
+}
+
+// WithDebounce makes the manager coalesce the watcher triggers into a single reload which happens once
+// no watcher has triggered for d, e.g. when an editor or a Kubernetes volume update produces a burst of events.
+// The update callbacks of every loader whose watcher has triggered are called with the result of the reload.
+// Zero or negative d reloads on every trigger, which is the default.
+func WithDebounce(d time.Duration) Option {
+	return func(cm *ConfigManager) error {
+		cm.debounceWindow = d
+		return nil
+	}
+}
+
+// WithSkipUnchanged makes the manager skip the reloads triggered by watchers if the raw data read from
+// the triggered loaders is the same as on their last read, e.g. when only the modification time of a file
+// has changed, so neither subscribers nor OnUpdateSuccess callbacks are notified of spurious updates.
+// The data is compared by SHA-256 checksums before transformers are applied, so changes made by transformers
+// or formatters, e.g. of environment variables expanded into the data, are not noticed until the data changes.
+func WithSkipUnchanged(cm *ConfigManager) error {
+	cm.skipUnchanged = true
+	return nil
+}
+
+// WithMergeOptions makes the manager merge config layers with opts, e.g. MergeAppendSlices to append slices
+// of upper layers instead of replacing them. Layers of configs implementing Merger are merged
+// by their Merge method, unless opts include MergeIgnoreMerger.
+func WithMergeOptions(opts ...MergeOption) Option {
+	return func(cm *ConfigManager) error {
+		cm.mergeOptions = append(cm.mergeOptions, opts...)
+		return nil
+	}
+}
+
+// WithFieldMerge makes the manager merge the field at the dotted path with fn instead of the default merging,
+// see MergeField. It returns an error wrapping ErrInvalidFieldPath if the config has no such field.
+func WithFieldMerge(path string, fn FieldMergeFunc) Option {
+	return func(cm *ConfigManager) error {
+		if cm.constructor == nil {
+			return ErrConstructorIsNil
+		}
+		if _, err := fieldByPath(reflect.ValueOf(cm.constructor()), path, true); err != nil {
+			return err
+		}
+		cm.mergeOptions = append(cm.mergeOptions, MergeField(path, fn))
+		return nil
+	}
+}
+
+// WithTypeMerge makes the manager merge every field of type T with fn instead of the default merging,
+// see MergeType.
+func WithTypeMerge[T any](fn func(dst, src T) (T, error)) Option {
+	return func(cm *ConfigManager) error {
+		cm.mergeOptions = append(cm.mergeOptions, MergeType(fn))
+		return nil
+	}
+}
+
+// WithExplicitValues makes the fields explicitly set by a layer override the lower layers even with zero values,
+// e.g. a port set to 0 or a flag set to false, and null values reset the fields of the lower layers.
+// By default merging skips zero values, since they cannot be told from the missing ones.
+// The set fields are reported by formatters implementing PresenceReporter, the layers of other formatters
+// are merged as usual.
+func WithExplicitValues(cm *ConfigManager) error {
+	cm.explicitValues = true
+	return nil
+}
+
+// WithKeyNormalizer makes the manager match the keys of JSON and YAML documents to the fields which keys
+// normalize to the same string, e.g. with NormalizeKeys the "max-conns" key of a YAML file sets the field
+// tagged json:"max_conns". Keys matching fields as is are left untouched. Use strings.ToLower to match keys
+// case-insensitively only.
+func WithKeyNormalizer(normalize KeyNormalizer) Option {
+	return func(cm *ConfigManager) error {
+		cm.keyNormalizer = normalize
+		return nil
+	}
+}
+
+// WithImmutableSnapshots makes Config and ConfigWithVersion return a deep copy of the current configuration,
+// so a consumer modifying the returned struct cannot corrupt the configuration shared with other consumers.
+// Every read copies the whole configuration, which costs allocations on hot paths.
+// Configurations passed to subscribers are still shared and must not be modified.
+func WithImmutableSnapshots(cm *ConfigManager) error {
+	cm.copyOnRead = true
+	return nil
+}
+
+// WithLoader adds the Loader layer, e.g. with a custom Source, Formatter or Watcher, as AddLoader does.
+func WithLoader(l Loader) Option {
+	return func(cm *ConfigManager) error {
+		cm.AddLoader(l)
+		return nil
+	}
+}
+
+// WithLoaders adds the Loader layers in order, as WithLoader does.
+func WithLoaders(loaders ...Loader) Option {
+	return func(cm *ConfigManager) error {
+		for _, l := range loaders {
+			cm.AddLoader(l)
+		}
+		return nil
+	}
+}
+
+// WithMap adds a Loader layer with MapSource used both as the source and the formatter
+// to set the programmatically computed values of the map into the config.
+func WithMap(values map[string]any) Option {
+	return func(cm *ConfigManager) error {
+		s := NewMapSource(values)
+		cm.AddLoader(Loader{
+			Source:    s,
+			Formatter: s,
+		})
+		return nil
+	}
+}
+
+// WithStruct adds a Loader layer with StructSource used both as the source and the formatter
+// to merge the programmatically computed config struct cfg or a pointer to it.
+func WithStruct(cfg any) Option {
+	return func(cm *ConfigManager) error {
+		s := NewStructSource(cfg)
+		cm.AddLoader(Loader{
+			Source:    s,
+			Formatter: s,
+		})
 		return nil
 	}
 }
@@ -25,6 +268,314 @@ func WithEnv(cm *ConfigManager) error {
 	return nil
 }
 
+// WithFlagSet adds a Loader layer with FlagSource of the parsed flag set. Add it after the loaders of files
+// and env variables, so the flags set on the command line override their values, e.g.:
+//
+//	flags := flag.NewFlagSet("myapp", flag.ExitOnError)
+//	flags.Int("server.port", 8080, "port to listen on")
+//	flags.Parse(os.Args[1:])
+//	cm, err := confgo.NewConfigManagerFor[Config](confgo.WithYAMLFile("config.yaml"), confgo.WithEnv,
+//		confgo.WithFlagSet(flags))
+func WithFlagSet(flags *flag.FlagSet, opts ...FlagSourceOption) Option {
+	return func(cm *ConfigManager) error {
+		s := NewFlagSource(flags, opts...)
+		cm.AddLoader(Loader{
+			Source:    s,
+			Formatter: s,
+		})
+		return nil
+	}
+}
+
+// WithEnvPrefix adds a Loader layer with EnvSource reading only the variables with the prefix, e.g. "MYAPP_",
+// and EnvFormatter matching them to env tags without the prefix, to parse config data from.
+func WithEnvPrefix(prefix string) Option {
+	return func(cm *ConfigManager) error {
+		cm.AddLoader(Loader{
+			Source:    NewEnvSource(EnvPrefix(prefix)),
+			Formatter: NewEnvFormatter(),
+		})
+		return nil
+	}
+}
+
+// WithDotenvFile adds a Loader layer with FileSource and DotenvFormatter to parse config data from.
+func WithDotenvFile(file string) Option {
+	return func(cm *ConfigManager) error {
+		cm.AddLoader(Loader{
+			Source:    NewFileSource(file),
+			Formatter: NewDotenvFormatter(),
+		})
+		return nil
+	}
+}
+
+// WithDevMode enables development mode optimized for the edit-save-observe loop:
+//   - every file loader is watched and polled at a sub-second interval;
+//   - every file loader is followed by an optional "*.local.*" override loader
+//     (e.g. "config.local.json" for "config.json"), which is skipped while the file does not exist;
+//   - changed fields are logged to stderr on every reload.
+//
+// The option affects loaders added both before and after it.
+//
+// Files are polled rather than watched for filesystem events, e.g. with inotify: events are not delivered
+// for files on network and VM shared mounts, such as Docker volumes on macOS, and the atomic saves
+// of many editors replace the watched file, dropping its watch. Polling every 250ms also needs
+// no third-party dependency and still picks up a save well within a second.
+func WithDevMode(cm *ConfigManager) error {
+	if cm.devMode {
+		return nil
+	}
+	loaders := cm.loaders
+	cm.devMode = true
+	cm.loaders = make([]Loader, 0, len(loaders))
+	for _, l := range loaders {
+		cm.AddLoader(l)
+	}
+	return nil
+}
+
+// WithFile adds a Loader layer with FileSource and the Formatter picked by the file extension to parse config data from:
+// JSONFormatter for ".json", JSONCFormatter for ".jsonc", YAMLFormatter for ".yaml" and ".yml"
+// and DotenvFormatter for ".env". Other extensions are reported with ErrUnknownFormat.
+//
+// TOML files are not supported: the package has no TOML formatter, since parsing TOML requires a third-party
+// decoder the module does not depend on. Load them with WithLoader and a Formatter wrapping a TOML decoder instead.
+func WithFile(file string) Option {
+	return func(cm *ConfigManager) error {
+		formatter, err := formatterForFile(file)
+		if err != nil {
+			return err
+		}
+		cm.AddLoader(Loader{
+			Source:    NewFileSource(file),
+			Formatter: formatter,
+		})
+		return nil
+	}
+}
+
+// WithDir adds a Loader layer with DirSource used both as the source and the formatter
+// to parse config data from the files of the directory, e.g. "/etc/app/conf.d", merged in lexical order.
+func WithDir(dir string) Option {
+	return func(cm *ConfigManager) error {
+		s := NewDirSource(dir)
+		cm.AddLoader(Loader{
+			Source:    s,
+			Formatter: s,
+		})
+		return nil
+	}
+}
+
+// WithDynamicDir adds a Loader layer with DirSource as WithDir does and ModTimeWatcher with callbacks
+// to dynamically update config data when files are added to, removed from or changed in the directory.
+func WithDynamicDir(dir string, onUpdateSuccess CallbackFunc, onUpdateError CallbackErrFunc) Option {
+	return func(cm *ConfigManager) error {
+		s := NewDirSource(dir)
+		cm.AddLoader(Loader{
+			Source:          s,
+			Formatter:       s,
+			Watcher:         NewModTimeWatcher(s),
+			OnUpdateSuccess: onUpdateSuccess,
+			OnUpdateError:   onUpdateError,
+		})
+		return nil
+	}
+}
+
+// WithGlob adds a Loader layer with GlobSource used both as the source and the formatter
+// to parse config data from the files matching the pattern, e.g. "configs/*.yaml", merged in lexical order.
+func WithGlob(pattern string) Option {
+	return func(cm *ConfigManager) error {
+		s := NewGlobSource(pattern)
+		cm.AddLoader(Loader{
+			Source:    s,
+			Formatter: s,
+		})
+		return nil
+	}
+}
+
+// WithDynamicGlob adds a Loader layer with GlobSource as WithGlob does and GlobWatcher with callbacks
+// to dynamically update config data when matches are added, removed or changed.
+func WithDynamicGlob(pattern string, onUpdateSuccess CallbackFunc, onUpdateError CallbackErrFunc) Option {
+	return func(cm *ConfigManager) error {
+		s := NewGlobSource(pattern)
+		cm.AddLoader(Loader{
+			Source:          s,
+			Formatter:       s,
+			Watcher:         NewGlobWatcher(s),
+			OnUpdateSuccess: onUpdateSuccess,
+			OnUpdateError:   onUpdateError,
+		})
+		return nil
+	}
+}
+
+// WithExec adds a Loader layer with ExecSource and the formatter to parse the stdout of the command
+// name with args as config data.
+func WithExec(name string, args []string, formatter Formatter, opts ...ExecSourceOption) Option {
+	return func(cm *ConfigManager) error {
+		cm.AddLoader(Loader{
+			Source:    NewExecSource(name, args, opts...),
+			Formatter: formatter,
+		})
+		return nil
+	}
+}
+
+// WithSQL adds a Loader layer with SQLSource and JSONFormatter to parse config data queried from db.
+// To reload the config when it changes in the database, add the loader with NewSQLWatcher using WithLoader:
+//
+//	s := confgo.NewSQLSource(db, "SELECT key, value FROM app_config")
+//	w := confgo.NewSQLWatcher(s, "SELECT max(updated_at) FROM app_config")
+//	confgo.WithLoader(confgo.Loader{Source: s, Formatter: confgo.NewJSONFormatter(), Watcher: w})
+func WithSQL(db *sql.DB, query string, opts ...SQLSourceOption) Option {
+	return func(cm *ConfigManager) error {
+		cm.AddLoader(Loader{
+			Source:    NewSQLSource(db, query, opts...),
+			Formatter: NewJSONFormatter(),
+		})
+		return nil
+	}
+}
+
+// WithObjectStore adds a Loader layer with ObjectStoreSource, the Formatter picked by the key extension
+// as WithFile does and ObjectStoreWatcher with callbacks to parse and dynamically update config data
+// from the object of S3-compatible storage.
+func WithObjectStore(
+	endpoint, bucket, key string,
+	onUpdateSuccess CallbackFunc,
+	onUpdateError CallbackErrFunc,
+	objectStoreSourceOptions ...ObjectStoreSourceOption,
+) Option {
+	return func(cm *ConfigManager) error {
+		formatter, err := formatterForFile(key)
+		if err != nil {
+			return err
+		}
+		s := NewObjectStoreSource(endpoint, bucket, key, objectStoreSourceOptions...)
+		cm.AddLoader(Loader{
+			Source:          s,
+			Formatter:       formatter,
+			Watcher:         NewObjectStoreWatcher(s),
+			OnUpdateSuccess: onUpdateSuccess,
+			OnUpdateError:   onUpdateError,
+		})
+		return nil
+	}
+}
+
+// WithFSFile adds a Loader layer with FSSource and the Formatter picked by the file extension as WithFile does
+// to parse config data from the file of fsys, e.g. of embed.FS with default configs compiled into the binary:
+//
+//	//go:embed defaults.yaml
+//	var defaults embed.FS
+//
+//	cm, err := confgo.NewConfigManagerFor[Config](
+//		confgo.WithFSFile(defaults, "defaults.yaml"),
+//		confgo.WithFile("/etc/app/config.yaml"),
+//		confgo.WithEnv,
+//	)
+func WithFSFile(fsys fs.FS, file string) Option {
+	return func(cm *ConfigManager) error {
+		formatter, err := formatterForFile(file)
+		if err != nil {
+			return err
+		}
+		cm.AddLoader(Loader{
+			Source:    NewFSSource(fsys, file),
+			Formatter: formatter,
+		})
+		return nil
+	}
+}
+
+// WithSOPSFile adds a Loader layer with FileSource, SOPSTransformer and the Formatter picked by the file extension
+// as WithFile does to parse config data encrypted with SOPS from. Only ".json", ".yaml" and ".yml" files are supported.
+func WithSOPSFile(file string, opts ...SOPSTransformerOption) Option {
+	return func(cm *ConfigManager) error {
+		format, err := sopsFormat(file)
+		if err != nil {
+			return err
+		}
+		transformer, err := NewSOPSTransformer(format, opts...)
+		if err != nil {
+			return err
+		}
+		formatter, err := formatterForFile(file)
+		if err != nil {
+			return err
+		}
+		cm.AddLoader(Loader{
+			Source:       NewFileSource(file),
+			Transformers: []Transformer{transformer},
+			Formatter:    formatter,
+		})
+		return nil
+	}
+}
+
+// WithFileIncludes adds a Loader layer with FileSource, IncludeTransformer and the Formatter picked
+// by the file extension as WithFile does to parse config data split into several files from.
+// Only ".json", ".yaml" and ".yml" files are supported.
+func WithFileIncludes(file string, opts ...IncludeTransformerOption) Option {
+	return func(cm *ConfigManager) error {
+		transformer, err := NewIncludeTransformer(file, opts...)
+		if err != nil {
+			return err
+		}
+		formatter, err := formatterForFile(file)
+		if err != nil {
+			return err
+		}
+		cm.AddLoader(Loader{
+			Source:       NewFileSource(file),
+			Transformers: []Transformer{transformer},
+			Formatter:    formatter,
+		})
+		return nil
+	}
+}
+
+// WithDynamicFile adds a Loader layer with FileSource, the Formatter picked by the file extension as WithFile does
+// and ModTimeWatcher with callbacks to parse and dynamically update config data from.
+func WithDynamicFile(file string, onUpdateSuccess CallbackFunc, onUpdateError CallbackErrFunc) Option {
+	return func(cm *ConfigManager) error {
+		formatter, err := formatterForFile(file)
+		if err != nil {
+			return err
+		}
+		s := NewFileSource(file)
+		cm.AddLoader(Loader{
+			Source:          s,
+			Formatter:       formatter,
+			Watcher:         NewModTimeWatcher(s),
+			OnUpdateSuccess: onUpdateSuccess,
+			OnUpdateError:   onUpdateError,
+		})
+		return nil
+	}
+}
+
+// formatterForFile returns the Formatter of the file picked by its extension. There is no TOML formatter,
+// so ".toml" files are reported with ErrUnknownFormat as any other unknown extension.
+func formatterForFile(file string) (Formatter, error) {
+	switch ext := strings.ToLower(filepath.Ext(file)); ext {
+	case ".json":
+		return NewJSONFormatter(), nil
+	case ".jsonc":
+		return NewJSONCFormatter(), nil
+	case ".yaml", ".yml":
+		return NewYAMLFormatter(), nil
+	case ".env":
+		return NewDotenvFormatter(), nil
+	default:
+		return nil, fmt.Errorf("%w: file %q: no formatter for extension %q", ErrUnknownFormat, file, ext)
+	}
+}
+
 // WithJSONFile adds a Loader layer with FileSource and JSONFormatter to parse config data from.
 func WithJSONFile(file string, jsonFormatterOptions ...JSONFormatterOption) Option {
 	return func(cm *ConfigManager) error {
@@ -57,6 +608,18 @@ func WithDynamicJSONFile(
 	}
 }
 
+// WithJSONCFile adds a Loader layer with FileSource and JSONCFormatter to parse config data
+// with comments and trailing commas from.
+func WithJSONCFile(file string, jsonFormatterOptions ...JSONFormatterOption) Option {
+	return func(cm *ConfigManager) error {
+		cm.AddLoader(Loader{
+			Source:    NewFileSource(file),
+			Formatter: NewJSONCFormatter(jsonFormatterOptions...),
+		})
+		return nil
+	}
+}
+
 // WithYAMLFile adds a Loader layer with FileSource and YAMLFormatter to parse config data from.
 func WithYAMLFile(file string, yamlFormatterOptions ...YAMLFormatterOption) Option {
 	return func(cm *ConfigManager) error {
@@ -88,3 +651,25 @@ func WithDynamicYAMLFile(
 		return nil
 	}
 }
+
+// WithVaultSecret adds a Loader layer with VaultSource, JSONFormatter and VaultWatcher with callbacks
+// to parse and dynamically update config data from a HashiCorp Vault KV v2 secret.
+func WithVaultSecret(
+	addr, mount, path string,
+	auth VaultAuth,
+	onUpdateSuccess CallbackFunc,
+	onUpdateError CallbackErrFunc,
+	vaultSourceOptions ...VaultSourceOption,
+) Option {
+	return func(cm *ConfigManager) error {
+		s := NewVaultSource(addr, mount, path, auth, vaultSourceOptions...)
+		cm.AddLoader(Loader{
+			Source:          s,
+			Formatter:       NewJSONFormatter(),
+			Watcher:         NewVaultWatcher(s),
+			OnUpdateSuccess: onUpdateSuccess,
+			OnUpdateError:   onUpdateError,
+		})
+		return nil
+	}
+}
@@ -0,0 +1,224 @@
+package confgo
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"time"
+)
+
+// SetOverride sets the field at the dotted path (e.g. "server.port") to value in the in-memory override layer,
+// which has the highest priority and survives reloads of all other loaders.
+// If the manager is running, the configuration is reloaded, and the override is discarded if the reload fails.
+// The change is recorded in the audit log along with meta, with the values of secret fields redacted.
+func (cm *ConfigManager) SetOverride(path string, value any, meta ChangeMeta) error {
+	probe := cm.constructor()
+	field, err := fieldByPath(reflect.ValueOf(probe), path, true)
+	if err != nil {
+		return err
+	}
+	if err := assignValue(field, value); err != nil {
+		return fmt.Errorf("field %q: %w", path, err)
+	}
+
+	// The caller may keep changing its slices and maps, so the override holds a copy.
+	if value != nil {
+		value = deepCopy(reflect.ValueOf(value)).Interface()
+	}
+	oldValue := cm.fieldValue(path)
+	cm.overridesMu.Lock()
+	prev, had := cm.overrides[path]
+	prevMeta := cm.overrideMeta[path]
+	if cm.overrides == nil {
+		cm.overrides = make(map[string]any)
+	}
+	if cm.overrideMeta == nil {
+		cm.overrideMeta = make(map[string]ChangeMeta)
+	}
+	cm.overrides[path] = value
+	cm.overrideMeta[path] = meta
+	cm.overridesMu.Unlock()
+
+	if err := cm.reloadRuntimeChange(func() { cm.restoreOverride(path, prev, prevMeta, had) }); err != nil {
+		return err
+	}
+	secret := isSecretPath(reflect.TypeOf(probe), path)
+	cm.recordAudit(AuditRecord{
+		Time:     time.Now(),
+		Action:   AuditSetOverride,
+		Path:     path,
+		OldValue: auditValue(oldValue, secret),
+		NewValue: auditValue(value, secret),
+		Actor:    meta.Actor,
+		Reason:   meta.Reason,
+	})
+	return nil
+}
+
+// RemoveOverride removes the override of the field at the dotted path set by SetOverride.
+// If the manager is running, the configuration is reloaded, and the override is restored if the reload fails.
+// The change is recorded in the audit log along with meta.
+func (cm *ConfigManager) RemoveOverride(path string, meta ChangeMeta) error {
+	cm.overridesMu.Lock()
+	prev, had := cm.overrides[path]
+	prevMeta := cm.overrideMeta[path]
+	delete(cm.overrides, path)
+	delete(cm.overrideMeta, path)
+	cm.overridesMu.Unlock()
+	if !had {
+		return nil
+	}
+
+	if err := cm.reloadRuntimeChange(func() { cm.restoreOverride(path, prev, prevMeta, had) }); err != nil {
+		return err
+	}
+	secret := isSecretPath(reflect.TypeOf(cm.constructor()), path)
+	cm.recordAudit(AuditRecord{
+		Time:     time.Now(),
+		Action:   AuditRemoveOverride,
+		Path:     path,
+		OldValue: auditValue(prev, secret),
+		NewValue: auditValue(cm.fieldValue(path), secret),
+		Actor:    meta.Actor,
+		Reason:   meta.Reason,
+	})
+	return nil
+}
+
+// Set sets the field at the dotted path to value in the in-memory override layer, e.g. cm.Set("server.port", 8081).
+// It is SetOverride without the change metadata, for tests and tools which do not need to attribute changes.
+func (cm *ConfigManager) Set(path string, value any) error {
+	return cm.SetOverride(path, value, ChangeMeta{})
+}
+
+// Unset removes the override of the field at the dotted path set by Set or SetOverride.
+// It is RemoveOverride without the change metadata.
+func (cm *ConfigManager) Unset(path string) error {
+	return cm.RemoveOverride(path, ChangeMeta{})
+}
+
+// SetConfig merges cfg over the configuration produced by the loaders, like a loader with the highest priority
+// placed below the overrides set by SetOverride. Passing nil removes the previously set config.
+// cfg must be of the same type as the one returned by the manager constructor.
+// If the manager is running, the configuration is reloaded, and the previous config is restored if the reload fails.
+// Every changed field is recorded in the audit log along with meta, with the values of secret fields redacted.
+func (cm *ConfigManager) SetConfig(cfg any, meta ChangeMeta) error {
+	if cfg != nil && reflect.TypeOf(cfg) != reflect.TypeOf(cm.constructor()) {
+		return fmt.Errorf("%w: got %T, want %T", ErrConfigTypeMismatch, cfg, cm.constructor())
+	}
+
+	oldCfg := cm.Config()
+	cm.overridesMu.Lock()
+	prev, prevMeta := cm.adminConfig, cm.adminConfigMeta
+	cm.adminConfig, cm.adminConfigMeta = cfg, meta
+	cm.overridesMu.Unlock()
+
+	if err := cm.reloadRuntimeChange(func() {
+		cm.overridesMu.Lock()
+		cm.adminConfig, cm.adminConfigMeta = prev, prevMeta
+		cm.overridesMu.Unlock()
+	}); err != nil {
+		return err
+	}
+	cm.recordChanges(AuditSetConfig, oldCfg, prev, cfg, meta)
+	return nil
+}
+
+func (cm *ConfigManager) restoreOverride(path string, prev any, prevMeta ChangeMeta, had bool) {
+	cm.overridesMu.Lock()
+	defer cm.overridesMu.Unlock()
+	if had {
+		cm.overrides[path] = prev
+		cm.overrideMeta[path] = prevMeta
+	} else {
+		delete(cm.overrides, path)
+		delete(cm.overrideMeta, path)
+	}
+}
+
+// reloadRuntimeChange reloads the configuration with a runtime change if the manager is running.
+// If the reload fails, rollback discards the change, and as the served configuration has not changed,
+// the error and the staleness of the reload status are restored unless another reload has finished meanwhile,
+// so a rejected change does not make the manager report itself stale.
+func (cm *ConfigManager) reloadRuntimeChange(rollback func()) error {
+	if !cm.isRunning.Load() {
+		return nil
+	}
+	cm.mu.RLock()
+	prev := cm.reloadStatus
+	cm.mu.RUnlock()
+	err := cm.reload()
+	if err == nil {
+		return nil
+	}
+	rollback()
+	cm.mu.Lock()
+	if cm.reloadStatus.Reloads == prev.Reloads+1 {
+		cm.reloadStatus.Err, cm.reloadStatus.StaleSince = prev.Err, prev.StaleSince
+	}
+	cm.mu.Unlock()
+	return err
+}
+
+// fieldValue returns the value of the field at the dotted path in the current configuration or nil.
+func (cm *ConfigManager) fieldValue(path string) any {
+	return valueByPath(cm.Config(), path)
+}
+
+// valueByPath returns the value of the field at the dotted path in cfg or nil.
+func valueByPath(cfg any, path string) any {
+	if cfg == nil {
+		return nil
+	}
+	field, err := fieldByPath(reflect.ValueOf(cfg), path, false)
+	if err != nil || !field.IsValid() {
+		return nil
+	}
+	return field.Interface()
+}
+
+// applyOverrides merges the config set by SetConfig, applies the patches applied by ApplyPatch and then
+// sets the fields set by SetOverride, recording them in provenance and their change metadata in provenanceMeta.
+func (cm *ConfigManager) applyOverrides(
+	merged any,
+	provenance map[string]string,
+	provenanceMeta map[string]ChangeMeta,
+) error {
+	cm.overridesMu.Lock()
+	adminConfig, adminConfigMeta := cm.adminConfig, cm.adminConfigMeta
+	patches := cm.patches
+	overrides := maps.Clone(cm.overrides)
+	overrideMeta := maps.Clone(cm.overrideMeta)
+	cm.overridesMu.Unlock()
+
+	if adminConfig != nil {
+		if err := cm.merge(merged, adminConfig); err != nil {
+			return fmt.Errorf("merge config set at runtime: %w", err)
+		}
+		recordProvenance(provenance, adminConfig, ProvenanceOverride)
+		recordProvenance(provenanceMeta, adminConfig, adminConfigMeta)
+	}
+	for i, patch := range patches {
+		paths := make([]string, 0, len(patch))
+		if err := applyMergePatch(reflect.ValueOf(merged), patch, "", &paths); err != nil {
+			return fmt.Errorf("patch #%d: %w", i, err)
+		}
+		for _, path := range paths {
+			setProvenance(provenance, path, ProvenanceOverride)
+			setProvenance(provenanceMeta, path, ChangeMeta{})
+		}
+	}
+	for _, path := range slices.Sorted(maps.Keys(overrides)) {
+		field, err := fieldByPath(reflect.ValueOf(merged), path, true)
+		if err != nil {
+			return err
+		}
+		if err := assignValue(field, overrides[path]); err != nil {
+			return fmt.Errorf("field %q: %w", path, err)
+		}
+		setProvenance(provenance, path, ProvenanceOverride)
+		setProvenance(provenanceMeta, path, overrideMeta[path])
+	}
+	return nil
+}
@@ -0,0 +1,264 @@
+package confgo
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func newTestOverridesManager(t *testing.T, data TestConfig, opts ...Option) *ConfigManager {
+	t.Helper()
+
+	opts = append(opts, func(cm *ConfigManager) error {
+		cm.AddLoader(Loader{Source: &fakeSource{data: []byte("test")}, Formatter: &fakeFormatter{data: data}})
+		return nil
+	})
+	cm, err := NewConfigManager(testConfigConstructor, opts...)
+	if err != nil {
+		t.Fatalf("NewConfigManager() error = %v", err)
+	}
+	if err := cm.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	t.Cleanup(cm.MustStop)
+	return cm
+}
+
+func TestConfigManager_SetOverride(t *testing.T) {
+	t.Parallel()
+
+	cm := newTestOverridesManager(t, TestConfig{Int: 1, Inner: testInnerConfig{String: "file"}},
+		WithValidator(func() error { return nil }))
+	meta := ChangeMeta{Actor: "alice", Reason: "incident"}
+
+	if err := cm.SetOverride("int", 2, meta); err != nil {
+		t.Fatalf("SetOverride() error = %v", err)
+	}
+	if err := cm.SetOverride("inner_ptr.string", "allocated", meta); err != nil {
+		t.Fatalf("SetOverride() error = %v", err)
+	}
+	want := &TestConfig{
+		Int:      2,
+		Inner:    testInnerConfig{String: "file"},
+		InnerPtr: &testInnerConfig{String: "allocated"},
+	}
+	if got := cm.Config(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Config() = %v, want %v", got, want)
+	}
+
+	if err := cm.RemoveOverride("int", ChangeMeta{Actor: "bob"}); err != nil {
+		t.Fatalf("RemoveOverride() error = %v", err)
+	}
+	if got := cm.Config().(*TestConfig).Int; got != 1 {
+		t.Fatalf("Config().Int = %d, want 1", got)
+	}
+
+	wantAudit := []AuditRecord{
+		{Action: AuditSetOverride, Path: "int", OldValue: 1, NewValue: 2, Actor: "alice", Reason: "incident"},
+		{Action: AuditRemoveOverride, Path: "int", OldValue: 2, NewValue: 1, Actor: "bob"},
+	}
+	gotAudit := cm.FieldAudit("int")
+	for i := range gotAudit {
+		if gotAudit[i].Time.IsZero() {
+			t.Errorf("audit record #%d has zero time", i)
+		}
+		gotAudit[i].Time = wantAudit[i].Time
+	}
+	if !reflect.DeepEqual(gotAudit, wantAudit) {
+		t.Errorf("FieldAudit() = %v, want %v", gotAudit, wantAudit)
+	}
+	if got := len(cm.AuditLog()); got != 3 {
+		t.Errorf("len(AuditLog()) = %d, want 3", got)
+	}
+}
+
+func TestConfigManager_Set(t *testing.T) {
+	t.Parallel()
+
+	cm := newTestOverridesManager(t, TestConfig{Int: 1, Map: map[string]string{"a": "file"}})
+	if err := cm.Set("int", 8081); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if err := cm.Set("map", map[string]string{"b": "override"}); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if err := cm.reload(); err != nil {
+		t.Fatalf("reload() error = %v", err)
+	}
+	want := &TestConfig{Int: 8081, Map: map[string]string{"b": "override"}}
+	if got := cm.Config(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Config() = %v, want %v", got, want)
+	}
+
+	if err := cm.Unset("int"); err != nil {
+		t.Fatalf("Unset() error = %v", err)
+	}
+	if err := cm.Unset("slice"); err != nil {
+		t.Fatalf("Unset() of a field without override error = %v", err)
+	}
+	if got := cm.Config().(*TestConfig).Int; got != 1 {
+		t.Fatalf("Config().Int = %d, want 1", got)
+	}
+}
+
+func TestConfigManager_SetOverride_Errors(t *testing.T) {
+	t.Parallel()
+
+	cm := newTestOverridesManager(t, TestConfig{Int: 1})
+
+	tests := []struct {
+		name    string
+		path    string
+		value   any
+		wantErr error
+	}{
+		{name: "unknown field", path: "unknown", value: 1, wantErr: ErrInvalidFieldPath},
+		{name: "not a struct", path: "int.value", value: 1, wantErr: ErrInvalidFieldPath},
+		{name: "wrong type", path: "int", value: "1", wantErr: ErrInvalidFieldValue},
+		{name: "lossy conversion", path: "int", value: 1.5, wantErr: ErrInvalidFieldValue},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if err := cm.SetOverride(tt.path, tt.value, ChangeMeta{}); !errors.Is(err, tt.wantErr) {
+				t.Errorf("SetOverride() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfigManager_SetOverride_Unsigned(t *testing.T) {
+	t.Parallel()
+
+	type config struct {
+		Uint  uint  `json:"uint"`
+		Uint8 uint8 `json:"uint8"`
+		Int   int   `json:"int"`
+	}
+	cm, err := NewConfigManagerFor[config]()
+	if err != nil {
+		t.Fatalf("NewConfigManagerFor() error = %v", err)
+	}
+	tests := []struct {
+		path    string
+		value   any
+		wantErr error
+	}{
+		{path: "uint", value: -1, wantErr: ErrInvalidFieldValue},
+		{path: "uint", value: -1.0, wantErr: ErrInvalidFieldValue},
+		{path: "uint8", value: int8(-1), wantErr: ErrInvalidFieldValue},
+		{path: "uint8", value: 256, wantErr: ErrInvalidFieldValue},
+		{path: "int", value: uint64(math.MaxUint64), wantErr: ErrInvalidFieldValue},
+		{path: "uint", value: 1, wantErr: nil},
+		{path: "int", value: uint(1), wantErr: nil},
+	}
+	for _, tt := range tests {
+		if err := cm.SetOverride(tt.path, tt.value, ChangeMeta{}); !errors.Is(err, tt.wantErr) {
+			t.Errorf("SetOverride(%q, %T(%v)) error = %v, want %v", tt.path, tt.value, tt.value, err, tt.wantErr)
+		}
+	}
+}
+
+func TestConfigManager_SetOverride_CopiesValue(t *testing.T) {
+	t.Parallel()
+
+	cm := newTestOverridesManager(t, TestConfig{})
+	slice, m := []string{"a"}, map[string]string{"k": "v"}
+	if err := cm.SetOverride("slice", slice, ChangeMeta{}); err != nil {
+		t.Fatalf("SetOverride() error = %v", err)
+	}
+	if err := cm.SetOverride("map", m, ChangeMeta{}); err != nil {
+		t.Fatalf("SetOverride() error = %v", err)
+	}
+	slice[0], m["k"] = "b", "changed"
+	cm.Config().(*TestConfig).Slice[0] = "c"
+	if err := cm.reload(); err != nil {
+		t.Fatalf("reload() error = %v", err)
+	}
+
+	cfg := cm.Config().(*TestConfig)
+	if want := []string{"a"}; !reflect.DeepEqual(cfg.Slice, want) {
+		t.Errorf("Config().Slice = %v, want %v", cfg.Slice, want)
+	}
+	if want := map[string]string{"k": "v"}; !reflect.DeepEqual(cfg.Map, want) {
+		t.Errorf("Config().Map = %v, want %v", cfg.Map, want)
+	}
+}
+
+func TestConfigManager_SetOverride_DiscardedOnValidationError(t *testing.T) {
+	t.Parallel()
+
+	errTest := errors.New("test validation error")
+	cm, err := NewConfigManager(testConfigConstructor,
+		WithLoader(Loader{Source: &fakeSource{data: []byte(`{"int": 1}`)}, Formatter: NewJSONFormatter()}),
+		WithTypedValidator(func(cfg *TestConfig) error {
+			if cfg.Int == 123 {
+				return errTest
+			}
+			return nil
+		}),
+		WithMaxStaleness(time.Nanosecond),
+	)
+	if err != nil {
+		t.Fatalf("NewConfigManager() error = %v", err)
+	}
+	cm.MustStart()
+	defer cm.MustStop()
+
+	if err := cm.SetOverride("int", 123, ChangeMeta{}); !errors.Is(err, errTest) {
+		t.Fatalf("SetOverride() error = %v, want %v", err, errTest)
+	}
+	if got := cm.Config().(*TestConfig).Int; got != 1 {
+		t.Fatalf("Config().Int = %d, want 1", got)
+	}
+	// The served configuration has not changed, so the rejected override does not make it stale.
+	if status := cm.Status(); status.Err != nil || status.Staleness != 0 || !status.Healthy() {
+		t.Errorf("Status() = {Err: %v, Staleness: %v, Healthy: %t}, want healthy",
+			status.Err, status.Staleness, status.Healthy())
+	}
+	if err := cm.reload(); err != nil {
+		t.Fatalf("reload() error = %v, override must have been discarded", err)
+	}
+	if got := len(cm.AuditLog()); got != 0 {
+		t.Fatalf("len(AuditLog()) = %d, want 0", got)
+	}
+}
+
+func TestConfigManager_SetConfig(t *testing.T) {
+	t.Parallel()
+
+	cm := newTestOverridesManager(t, TestConfig{Int: 1, Slice: []string{"a"}})
+	meta := ChangeMeta{Actor: "admin", Reason: "rollout"}
+
+	if err := cm.SetConfig(&TestConfigAsMerger{}, meta); !errors.Is(err, ErrConfigTypeMismatch) {
+		t.Fatalf("SetConfig() error = %v, want %v", err, ErrConfigTypeMismatch)
+	}
+	if err := cm.SetConfig(&TestConfig{Int: 5, Map: map[string]string{"k": "v"}}, meta); err != nil {
+		t.Fatalf("SetConfig() error = %v", err)
+	}
+	want := &TestConfig{Int: 5, Slice: []string{"a"}, Map: map[string]string{"k": "v"}}
+	if got := cm.Config(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Config() = %v, want %v", got, want)
+	}
+
+	gotPaths := make([]string, 0)
+	for _, r := range cm.AuditLog() {
+		if r.Action != AuditSetConfig || r.Actor != "admin" || r.Reason != "rollout" {
+			t.Errorf("unexpected audit record %v", r)
+		}
+		gotPaths = append(gotPaths, r.Path)
+	}
+	if wantPaths := []string{"int", "map"}; !reflect.DeepEqual(gotPaths, wantPaths) {
+		t.Errorf("audited paths = %v, want %v", gotPaths, wantPaths)
+	}
+
+	if err := cm.SetConfig(nil, meta); err != nil {
+		t.Fatalf("SetConfig(nil) error = %v", err)
+	}
+	if got := cm.Config().(*TestConfig).Int; got != 1 {
+		t.Fatalf("Config().Int = %d, want 1", got)
+	}
+}
@@ -0,0 +1,47 @@
+package confgo
+
+import (
+	"fmt"
+	"runtime/debug"
+)
+
+// recoverPanic recovers a panic of user code run in the reload path, e.g. of a formatter, a validator
+// or an update callback, and stores it into errp as an error wrapping ErrPanic, so the panic is reported
+// like any other reload error instead of killing the watcher goroutine or the process.
+// It must be deferred directly.
+func (cm *ConfigManager) recoverPanic(errp *error, where string) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	cm.log().Error("confgo: recovered panic", "in", where, "panic", r, "stack", string(debug.Stack()))
+	cm.mu.Lock()
+	cm.reloadStatus.Panics++
+	cm.mu.Unlock()
+	if err, ok := r.(error); ok {
+		*errp = fmt.Errorf("%w in %s: %w", ErrPanic, where, err)
+		return
+	}
+	*errp = fmt.Errorf("%w in %s: %v", ErrPanic, where, r)
+}
+
+// runCallback calls the callback, returning its panic as an error.
+func (cm *ConfigManager) runCallback(where string, fn func()) (err error) {
+	defer cm.recoverPanic(&err, where)
+	fn()
+	return nil
+}
+
+// reportUpdate calls the update callback of the loader with the result of the reload. A panic of OnUpdateSuccess
+// is reported to OnUpdateError, a panic of OnUpdateError is only logged and counted in the status.
+func (cm *ConfigManager) reportUpdate(l Loader, err error) {
+	if err == nil && l.OnUpdateSuccess != nil {
+		err = cm.runCallback("OnUpdateSuccess", l.OnUpdateSuccess)
+		if err == nil {
+			return
+		}
+	}
+	if err != nil && l.OnUpdateError != nil {
+		_ = cm.runCallback("OnUpdateError", func() { l.OnUpdateError(err) })
+	}
+}
@@ -0,0 +1,83 @@
+package confgo
+
+import (
+	"errors"
+	"testing"
+)
+
+type panicFormatter struct{}
+
+func (panicFormatter) Unmarshal([]byte, any) error {
+	panic("boom")
+}
+
+func TestConfigManager_RecoverPanics(t *testing.T) {
+	t.Parallel()
+
+	source := &fakeSource{data: []byte(`{"int": 1}`)}
+	watcher := NewTriggerWatcher()
+	var updateErrs []error
+	cm, err := NewConfigManager(testConfigConstructor, func(cm *ConfigManager) error {
+		cm.AddLoader(Loader{
+			Source:          source,
+			Formatter:       NewJSONFormatter(),
+			Watcher:         watcher,
+			OnUpdateSuccess: func() { panic("callback") },
+			OnUpdateError:   func(err error) { updateErrs = append(updateErrs, err) },
+		})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("NewConfigManager() error = %v", err)
+	}
+	if err := cm.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer cm.MustStop()
+
+	watcher.Trigger()
+	if len(updateErrs) != 1 || !errors.Is(updateErrs[0], ErrPanic) {
+		t.Fatalf("OnUpdateError() errors = %v, want one %v", updateErrs, ErrPanic)
+	}
+	if got := cm.Config().(*TestConfig).Int; got != 1 {
+		t.Errorf("Config().Int = %d, want 1", got)
+	}
+
+	cm.AddLoader(Loader{Name: "broken", Source: &fakeSource{data: nil}, Formatter: panicFormatter{}})
+	status := cm.Status()
+	if !errors.Is(status.Err, ErrPanic) {
+		t.Errorf("Status().Err = %v, want %v", status.Err, ErrPanic)
+	}
+	if !errors.Is(status.Loaders[1].Err, ErrPanic) {
+		t.Errorf("Status().Loaders[1].Err = %v, want %v", status.Loaders[1].Err, ErrPanic)
+	}
+	if status.Panics != 2 {
+		t.Errorf("Status().Panics = %d, want 2", status.Panics)
+	}
+}
+
+func TestConfigManager_RecoverSubscriberPanic(t *testing.T) {
+	t.Parallel()
+
+	cm, err := NewConfigManager(testConfigConstructor, func(cm *ConfigManager) error {
+		cm.AddLoader(Loader{Source: &fakeSource{data: []byte(`{"int": 1}`)}, Formatter: NewJSONFormatter()})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("NewConfigManager() error = %v", err)
+	}
+	cm.Subscribe(func(_, _ any) { panic(errors.New("subscriber")) })
+	var notified []*TestConfig
+	cm.Subscribe(func(_, newCfg any) { notified = append(notified, newCfg.(*TestConfig)) })
+	if err := cm.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer cm.MustStop()
+
+	if status := cm.Status(); status.Err != nil || status.Panics != 1 {
+		t.Errorf("Status() = {Err: %v, Panics: %d}, want {Err: nil, Panics: 1}", status.Err, status.Panics)
+	}
+	if len(notified) != 1 || notified[0].Int != 1 {
+		t.Errorf("second subscriber notified with %+v, want one config with Int 1", notified)
+	}
+}
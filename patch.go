@@ -0,0 +1,177 @@
+package confgo
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"time"
+)
+
+// ApplyPatch applies the JSON merge patch of RFC 7386 to the configuration, e.g. {"log": {"level": "debug"}}
+// or {"server": {"timeout": null}} which resets the timeout to its zero value. Keys of the patch are the JSON keys
+// of the config fields. Patches are kept in the in-memory layer placed over the config set by SetConfig
+// and below the overrides set by SetOverride, so they survive reloads of all loaders until ClearPatch.
+// Patches applied one after another are applied in the same order on every reload.
+// If the manager is running, the configuration is reloaded, and the patch is discarded if the reload fails.
+// Every changed field is recorded in the audit log, with the values of secret fields redacted.
+func (cm *ConfigManager) ApplyPatch(patch []byte) error {
+	var doc map[string]any
+	if err := json.Unmarshal(patch, &doc); err != nil || doc == nil {
+		return fmt.Errorf("%w: must be a JSON object", ErrInvalidPatch)
+	}
+	if err := applyMergePatch(reflect.ValueOf(cm.constructor()), doc, "", new([]string)); err != nil {
+		return err
+	}
+
+	oldCfg := cm.Config()
+	cm.overridesMu.Lock()
+	cm.patches = append(slices.Clip(cm.patches), doc)
+	cm.overridesMu.Unlock()
+
+	if err := cm.reloadRuntimeChange(func() {
+		// Other patches may have been applied meanwhile, so the patch is removed by identity.
+		cm.overridesMu.Lock()
+		defer cm.overridesMu.Unlock()
+		cm.patches = slices.DeleteFunc(slices.Clone(cm.patches), func(p map[string]any) bool {
+			return reflect.ValueOf(p).UnsafePointer() == reflect.ValueOf(doc).UnsafePointer()
+		})
+	}); err != nil {
+		return err
+	}
+	redacted, _ := json.Marshal(redactPatch(reflect.TypeOf(cm.constructor()), doc))
+	cm.recordChanges(AuditApplyPatch, oldCfg, nil, string(redacted), ChangeMeta{})
+	return nil
+}
+
+// ClearPatch removes the patch layer, i.e. all the patches applied by ApplyPatch. If the manager is running,
+// the configuration is reloaded, and the patches are restored if the reload fails.
+// Every changed field is recorded in the audit log.
+func (cm *ConfigManager) ClearPatch() error {
+	oldCfg := cm.Config()
+	cm.overridesMu.Lock()
+	prev := cm.patches
+	cm.patches = nil
+	cm.overridesMu.Unlock()
+	if len(prev) == 0 {
+		return nil
+	}
+
+	if err := cm.reloadRuntimeChange(func() {
+		// The patches applied meanwhile are kept over the restored ones.
+		cm.overridesMu.Lock()
+		cm.patches = append(slices.Clip(prev), cm.patches...)
+		cm.overridesMu.Unlock()
+	}); err != nil {
+		return err
+	}
+	cm.recordChanges(AuditClearPatch, oldCfg, nil, nil, ChangeMeta{})
+	return nil
+}
+
+// recordChanges records the fields changed since oldCfg in the audit log. If there is no configuration
+// to compare with, a single record of the change from oldValue to newValue is recorded instead.
+func (cm *ConfigManager) recordChanges(action AuditAction, oldCfg, oldValue, newValue any, meta ChangeMeta) {
+	now := time.Now()
+	newCfg := cm.Config()
+	if oldCfg == nil || newCfg == nil {
+		cm.recordAudit(AuditRecord{
+			Time:     now,
+			Action:   action,
+			Path:     "",
+			OldValue: auditValue(oldValue, false),
+			NewValue: auditValue(newValue, false),
+			Actor:    meta.Actor,
+			Reason:   meta.Reason,
+		})
+		return
+	}
+	changes := diffConfigs(oldCfg, newCfg)
+	records := make([]AuditRecord, 0, len(changes))
+	for _, c := range changes {
+		records = append(records, AuditRecord{
+			Time:     now,
+			Action:   action,
+			Path:     c.path,
+			OldValue: auditValue(c.oldValue, c.secret),
+			NewValue: auditValue(c.newValue, c.secret),
+			Actor:    meta.Actor,
+			Reason:   meta.Reason,
+		})
+	}
+	cm.recordAudit(records...)
+}
+
+// applyMergePatch applies the patch to the struct v, appending the dotted paths of the patched fields to paths.
+// Nested objects are applied field by field to nested structs, while other fields are replaced with the result
+// of patching their JSON representation.
+func applyMergePatch(v reflect.Value, patch map[string]any, prefix string, paths *[]string) error {
+	for _, key := range slices.Sorted(maps.Keys(patch)) {
+		path := joinPath(prefix, key)
+		value := patch[key]
+		field, err := fieldByPath(v, key, value != nil)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidPatch, err)
+		}
+		if value == nil {
+			if field.IsValid() {
+				field.Set(reflect.Zero(field.Type()))
+			}
+			*paths = append(*paths, path)
+			continue
+		}
+		typ := field.Type()
+		for typ.Kind() == reflect.Ptr {
+			typ = typ.Elem()
+		}
+		if obj, ok := value.(map[string]any); ok && typ.Kind() == reflect.Struct && !isLeafStruct(typ) {
+			if err := applyMergePatch(field, obj, path, paths); err != nil {
+				return err
+			}
+			continue
+		}
+		*paths = append(*paths, path)
+
+		current, err := json.Marshal(field.Interface())
+		if err != nil {
+			return fmt.Errorf("%w: field %q: %w", ErrInvalidPatch, path, err)
+		}
+		var doc any
+		if err := json.Unmarshal(current, &doc); err != nil {
+			return fmt.Errorf("%w: field %q: %w", ErrInvalidPatch, path, err)
+		}
+		patched, err := json.Marshal(mergePatch(doc, value))
+		if err != nil {
+			return fmt.Errorf("%w: field %q: %w", ErrInvalidPatch, path, err)
+		}
+		result := reflect.New(field.Type())
+		if err := json.Unmarshal(patched, result.Interface()); err != nil {
+			return fmt.Errorf("%w: field %q: %w", ErrInvalidPatch, path, err)
+		}
+		field.Set(result.Elem())
+	}
+	return nil
+}
+
+// mergePatch returns the JSON document target patched with patch as defined by RFC 7386. It does not modify its arguments.
+func mergePatch(target, patch any) any {
+	obj, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	result, ok := target.(map[string]any)
+	if ok {
+		result = maps.Clone(result)
+	} else {
+		result = make(map[string]any, len(obj))
+	}
+	for key, value := range obj {
+		if value == nil {
+			delete(result, key)
+		} else {
+			result[key] = mergePatch(result[key], value)
+		}
+	}
+	return result
+}
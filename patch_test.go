@@ -0,0 +1,149 @@
+package confgo
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestMergePatch(t *testing.T) {
+	t.Parallel()
+
+	// The examples of RFC 7386, appendix A.
+	tests := []struct {
+		target, patch, want any
+	}{
+		{map[string]any{"a": "b"}, map[string]any{"a": "c"}, map[string]any{"a": "c"}},
+		{map[string]any{"a": "b"}, map[string]any{"b": "c"}, map[string]any{"a": "b", "b": "c"}},
+		{map[string]any{"a": "b"}, map[string]any{"a": nil}, map[string]any{}},
+		{map[string]any{"a": "b", "b": "c"}, map[string]any{"a": nil}, map[string]any{"b": "c"}},
+		{map[string]any{"a": []any{"b"}}, map[string]any{"a": "c"}, map[string]any{"a": "c"}},
+		{map[string]any{"a": "c"}, map[string]any{"a": []any{"b"}}, map[string]any{"a": []any{"b"}}},
+		{
+			map[string]any{"a": map[string]any{"b": "c"}},
+			map[string]any{"a": map[string]any{"b": "d", "c": nil}},
+			map[string]any{"a": map[string]any{"b": "d"}},
+		},
+		{map[string]any{"a": []any{map[string]any{"b": "c"}}}, map[string]any{"a": []any{1.0}}, map[string]any{"a": []any{1.0}}},
+		{[]any{"a", "b"}, []any{"c", "d"}, []any{"c", "d"}},
+		{map[string]any{"a": "b"}, []any{"c"}, []any{"c"}},
+		{map[string]any{"a": "foo"}, nil, nil},
+		{map[string]any{"a": "foo"}, "bar", "bar"},
+		{map[string]any{"e": nil}, map[string]any{"a": 1.0}, map[string]any{"e": nil, "a": 1.0}},
+		{[]any{1.0, 2.0}, map[string]any{"a": "b", "c": nil}, map[string]any{"a": "b"}},
+		{map[string]any{}, map[string]any{"a": map[string]any{"bb": map[string]any{"ccc": nil}}},
+			map[string]any{"a": map[string]any{"bb": map[string]any{}}}},
+	}
+	for _, tt := range tests {
+		if got := mergePatch(tt.target, tt.patch); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("mergePatch(%v, %v) = %v, want %v", tt.target, tt.patch, got, tt.want)
+		}
+	}
+}
+
+func TestConfigManager_ApplyPatch(t *testing.T) {
+	t.Parallel()
+
+	source := &fakeSource{data: []byte(`{"int": 1, "inner": {"int": 2, "string": "a"}, "map": {"a": "1", "b": "2"}}`)}
+	cm, err := NewConfigManager(testConfigConstructor,
+		WithLoader(Loader{Source: source, Formatter: NewJSONFormatter()}),
+		WithConfigValidator(func(cfg any) error {
+			if cfg.(*TestConfig).Int < 0 {
+				return errors.New("negative int")
+			}
+			return nil
+		}),
+	)
+	if err != nil {
+		t.Fatalf("NewConfigManager() error = %v", err)
+	}
+
+	for _, patch := range []string{`[1]`, `null`, `{"unknown": 1}`, `{"int": "a"}`} {
+		if err := cm.ApplyPatch([]byte(patch)); !errors.Is(err, ErrInvalidPatch) {
+			t.Errorf("ApplyPatch(%s) error = %v, want ErrInvalidPatch", patch, err)
+		}
+	}
+	if err := cm.ApplyPatch([]byte(`{"inner": {"string": "b"}, "map": {"a": null, "c": "3"}}`)); err != nil {
+		t.Fatalf("ApplyPatch() error = %v", err)
+	}
+	if err := cm.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer cm.MustStop()
+	if err := cm.ApplyPatch([]byte(`{"int": 5, "inner": {"int": null}, "inner_ptr": {"int": 6}}`)); err != nil {
+		t.Fatalf("ApplyPatch() error = %v", err)
+	}
+	if err := cm.ApplyPatch([]byte(`{"int": -1}`)); err == nil {
+		t.Error("ApplyPatch() of an invalid config error = nil")
+	}
+
+	// The patches survive reloads of the loaders.
+	source.data = []byte(`{"int": 1, "inner": {"int": 2, "string": "a"}, "map": {"a": "1", "b": "2"}, "slice": ["x"]}`)
+	if err := cm.reload(); err != nil {
+		t.Fatalf("reload() error = %v", err)
+	}
+	want := &TestConfig{
+		Int:      5,
+		Inner:    testInnerConfig{Int: 0, String: "b"},
+		InnerPtr: &testInnerConfig{Int: 6},
+		Map:      map[string]string{"b": "2", "c": "3"},
+		Slice:    []string{"x"},
+	}
+	if got := cm.Config(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Config() = %+v, want %+v", got, want)
+	}
+	provenance := cm.Provenance()
+	for _, path := range []string{"int", "inner.string", "inner_ptr.int", "map"} {
+		if provenance[path] != ProvenanceOverride {
+			t.Errorf("Provenance()[%q] = %q, want %q", path, provenance[path], ProvenanceOverride)
+		}
+	}
+
+	if err := cm.ClearPatch(); err != nil {
+		t.Fatalf("ClearPatch() error = %v", err)
+	}
+	if got := cm.Config().(*TestConfig); got.Int != 1 || got.Inner.String != "a" || got.InnerPtr != nil {
+		t.Errorf("Config() after ClearPatch() = %+v, want the loaded config", got)
+	}
+	audit := cm.AuditLog()
+	if len(audit) == 0 || audit[len(audit)-1].Action != AuditClearPatch {
+		t.Errorf("AuditLog() = %+v, want the last record of %q", audit, AuditClearPatch)
+	}
+}
+
+func TestConfigManager_ApplyPatch_RollbackKeepsConcurrentPatches(t *testing.T) {
+	t.Parallel()
+
+	var cm *ConfigManager
+	cm, err := NewConfigManager(testConfigConstructor,
+		WithLoader(Loader{Source: &fakeSource{data: []byte(`{"int": 1}`)}, Formatter: NewJSONFormatter()}),
+		WithConfigValidator(func(cfg any) error {
+			if cfg.(*TestConfig).Int < 0 {
+				// Another caller applies its patch while the invalid one is being validated.
+				cm.overridesMu.Lock()
+				cm.patches = append(cm.patches, map[string]any{"slice": []any{"concurrent"}})
+				cm.overridesMu.Unlock()
+				return errors.New("negative int")
+			}
+			return nil
+		}),
+	)
+	if err != nil {
+		t.Fatalf("NewConfigManager() error = %v", err)
+	}
+	if err := cm.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer cm.MustStop()
+
+	if err := cm.ApplyPatch([]byte(`{"int": -1}`)); err == nil {
+		t.Fatal("ApplyPatch() of an invalid config error = nil")
+	}
+	if err := cm.reload(); err != nil {
+		t.Fatalf("reload() error = %v", err)
+	}
+	want := &TestConfig{Int: 1, Slice: []string{"concurrent"}}
+	if got := cm.Config(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Config() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,111 @@
+package confgo
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// MergeOption configures Merge.
+type MergeOption func(o *mergeOptions)
+
+type mergeOptions struct {
+	ignoreMerger bool
+	appendSlices bool
+	fieldFuncs   map[string]FieldMergeFunc
+	typeFuncs    map[reflect.Type]FieldMergeFunc
+}
+
+// MergeIgnoreMerger makes Merge merge structs recursively even if dst implements Merger.
+// It lets a Merge method delegate to the default merging for the fields it does not handle itself.
+func MergeIgnoreMerger(o *mergeOptions) {
+	o.ignoreMerger = true
+}
+
+// MergeAppendSlices makes Merge append the items of non-empty src slices to the items of dst slices
+// instead of replacing them, except for the slice fields with the "merge" tag, which are merged by its strategy.
+func MergeAppendSlices(o *mergeOptions) {
+	o.appendSlices = true
+}
+
+// Merge merges src into dst the same way the manager merges config layers:
+// via the Merge method if dst implements Merger, otherwise recursively with non-zero src values
+// overriding dst values, except for the fields merged by functions registered with MergeField and MergeType,
+// and slice fields with the "merge" tag merged by its strategy, see MergeAppend and MergeUnique.
+// Nested structs, maps and pointers are merged recursively and nil src pointers leave dst values intact.
+// dst must be a pointer to a struct and src must be of the same type.
+func Merge(dst, src any, opts ...MergeOption) error {
+	o := mergeOptions{ignoreMerger: false, appendSlices: false, fieldFuncs: nil, typeFuncs: nil}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&o)
+		}
+	}
+
+	if m, ok := dst.(Merger); ok && !o.ignoreMerger {
+		if err := m.Merge(src); err != nil {
+			return err
+		}
+		return nil
+	}
+	return mergeConfigs(dst, src, &o)
+}
+
+// Validate validates cfg the same way the manager validates a loaded configuration:
+// via the Validate method if cfg implements Validator, then with the struct tag validator sv if it is not nil,
+// see WithStructTagValidation, and then with the config validators in order, see WithConfigValidator.
+// Every validator is called even if the previous ones fail, and all their errors are returned joined.
+func Validate(cfg any, sv StructValidator, validators ...ConfigValidateFunc) error {
+	errs := validateConfig(cfg, sv)
+	if err := runConfigValidators(cfg, validators); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// validateConfig runs Validate method of Validator and the struct tag validator sv if it is not nil
+// and returns their errors.
+func validateConfig(cfg any, sv StructValidator) []error {
+	errs := make([]error, 0)
+	if v, ok := cfg.(Validator); ok {
+		if err := v.Validate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if sv != nil {
+		if err := sv.Struct(cfg); err != nil {
+			errs = append(errs, fmt.Errorf("validate struct tags: %w", err))
+		}
+	}
+	return errs
+}
+
+// runConfigValidators calls every config validator with cfg in order and returns all their errors joined.
+func runConfigValidators(cfg any, validators []ConfigValidateFunc) error {
+	errs := make([]error, 0)
+	for i, v := range validators {
+		if v == nil {
+			errs = append(errs, fmt.Errorf("config validator %d: %w", i, ErrValidatorIsNil))
+			continue
+		}
+		if err := v(cfg); err != nil {
+			errs = append(errs, fmt.Errorf("config validator %d: %w", i, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// runValidators calls every validator in order and returns all their errors joined.
+func runValidators(validators []ValidateFunc) error {
+	errs := make([]error, 0)
+	for i, v := range validators {
+		if v == nil {
+			errs = append(errs, fmt.Errorf("validator %d: %w", i, ErrValidatorIsNil))
+			continue
+		}
+		if err := v(); err != nil {
+			errs = append(errs, fmt.Errorf("validator %d: %w", i, err))
+		}
+	}
+	return errors.Join(errs...)
+}
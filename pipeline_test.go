@@ -0,0 +1,101 @@
+package confgo
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	t.Parallel()
+
+	dst := &TestConfig{Int: 1, Inner: testInnerConfig{String: "dst"}, Slice: []string{"a"}}
+	src := &TestConfig{Inner: testInnerConfig{Int: 2}, Slice: []string{"b"}}
+	if err := Merge(dst, src); err != nil {
+		t.Fatalf("Merge() error = %v", err)
+	}
+	want := &TestConfig{Int: 1, Inner: testInnerConfig{Int: 2, String: "dst"}, Slice: []string{"b"}}
+	if !reflect.DeepEqual(dst, want) {
+		t.Errorf("Merge() = %+v, want %+v", dst, want)
+	}
+
+	merger := &TestConfigAsMerger{TestConfig: TestConfig{Int: 1}}
+	if err := Merge(merger, &TestConfigAsMerger{TestConfig: TestConfig{Int: 5}}); err != nil {
+		t.Fatalf("Merge() with Merger error = %v", err)
+	}
+	if merger.Int != 6 {
+		t.Errorf("Merge() with Merger Int = %d, want 6", merger.Int)
+	}
+	if err := Merge(merger, &TestConfigAsMerger{TestConfig: TestConfig{Int: 5}}, MergeIgnoreMerger); err != nil {
+		t.Fatalf("Merge() ignoring Merger error = %v", err)
+	}
+	if merger.Int != 5 {
+		t.Errorf("Merge() ignoring Merger Int = %d, want 5", merger.Int)
+	}
+}
+
+func TestConfigManager_WithMergeOptions(t *testing.T) {
+	t.Parallel()
+
+	cm, err := NewConfigManager(testConfigConstructor, WithMergeOptions(MergeAppendSlices), func(cm *ConfigManager) error {
+		cm.AddLoader(Loader{Source: &fakeSource{data: []byte(`{"int": 1, "slice": ["a"]}`)}, Formatter: NewJSONFormatter()})
+		cm.AddLoader(Loader{Source: &fakeSource{data: []byte(`{"int": 2, "slice": ["b"]}`)}, Formatter: NewJSONFormatter()})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("NewConfigManager() error = %v", err)
+	}
+	if err := cm.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer cm.MustStop()
+
+	want := &TestConfig{Int: 2, Slice: []string{"a", "b"}}
+	if got := cm.Config(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Config() = %+v, want %+v", got, want)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	errTest := errors.New("test error")
+	var calls []int
+	validator := func(i int, err error) ConfigValidateFunc {
+		return func(cfg any) error {
+			if cfg.(*TestConfig).Int != 1 {
+				return errors.New("unexpected config")
+			}
+			calls = append(calls, i)
+			return err
+		}
+	}
+
+	cfg := &TestConfig{Int: 1}
+	if err := Validate(cfg, nil, validator(0, nil), validator(1, errTest), validator(2, nil)); !errors.Is(err, errTest) {
+		t.Errorf("Validate() error = %v, want %v", err, errTest)
+	}
+	if want := []int{0, 1, 2}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("called validators = %v, want %v", calls, want)
+	}
+	if err := Validate(&TestConfigAsValidator{TestConfig: TestConfig{Int: 123}}, nil); err == nil {
+		t.Errorf("Validate() of invalid Validator error = nil, want error")
+	}
+	if err := Validate(cfg, nil, nil); !errors.Is(err, ErrValidatorIsNil) {
+		t.Errorf("Validate() with nil validator error = %v, want %v", err, ErrValidatorIsNil)
+	}
+	if err := Validate(&TestConfigAsValidator{}, nil); err != nil {
+		t.Errorf("Validate() error = %v, want nil", err)
+	}
+
+	type config struct {
+		Host string `json:"host" validate:"required"`
+	}
+	if err := Validate(&config{}, requiredValidator{}); err == nil || !strings.Contains(err.Error(), "Host is required") {
+		t.Errorf("Validate() with struct validator error = %v, want error about Host", err)
+	}
+	if err := Validate(&config{Host: "localhost"}, requiredValidator{}); err != nil {
+		t.Errorf("Validate() with struct validator error = %v, want nil", err)
+	}
+}
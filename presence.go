@@ -0,0 +1,216 @@
+package confgo
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// PresenceReporter is implemented by formatters which can report the fields explicitly set by the data.
+// With WithExplicitValues such fields override the lower layers even with zero values, e.g. a port set to 0
+// or a flag set to false, and null values reset the fields of the lower layers.
+type PresenceReporter interface {
+	// Present returns the dotted paths of the fields of the config v which are set by data.
+	// Paths of nested structs are reported only if the structs are set to null.
+	Present(data []byte, v any) ([]string, error)
+}
+
+var (
+	_ PresenceReporter = (*JSONFormatter)(nil)
+	_ PresenceReporter = (*JSONCFormatter)(nil)
+	_ PresenceReporter = (*YAMLFormatter)(nil)
+	_ PresenceReporter = (*EnvFormatter)(nil)
+	_ PresenceReporter = (*DotenvFormatter)(nil)
+)
+
+// Present returns the paths of the fields of v set by the json data.
+func (jf *JSONFormatter) Present(data []byte, v any) ([]string, error) {
+	if jf.expandEnv {
+		var err error
+		if data, err = expandEnv(data, os.LookupEnv); err != nil {
+			return nil, err
+		}
+	}
+	var doc any
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+	paths := make([]string, 0)
+	presentPaths(jsonHookDoc{get: func() any { return doc }, set: func(any) {}}, reflect.TypeOf(v), jsonHookKeys, "", &paths)
+	return paths, nil
+}
+
+// Present returns the paths of the fields of v set by the jsonc data.
+func (jf *JSONCFormatter) Present(data []byte, v any) ([]string, error) {
+	data, err := stripJSONC(data)
+	if err != nil {
+		return nil, err
+	}
+	return jf.json.Present(data, v)
+}
+
+// Present returns the paths of the fields of v set by the yaml data.
+func (yf *YAMLFormatter) Present(data []byte, v any) ([]string, error) {
+	if yf.expandEnv {
+		var err error
+		if data, err = expandEnv(data, os.LookupEnv); err != nil {
+			return nil, err
+		}
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	paths := make([]string, 0)
+	if doc.Kind != 0 {
+		presentPaths(newYAMLHookDoc(&doc, false), reflect.TypeOf(v), yamlHookKeys, "", &paths)
+	}
+	return paths, nil
+}
+
+// Present returns the paths of the fields of v bound to the env variables set by the data.
+func (ef *EnvFormatter) Present(data []byte, v any) ([]string, error) {
+	return presentEnvPaths(ef.parseRawIntoMap(data), reflect.TypeOf(v), ef.names), nil
+}
+
+// Present returns the paths of the fields of v bound to the env variables set by the dotenv data.
+func (df *DotenvFormatter) Present(data []byte, v any) ([]string, error) {
+	vars, err := parseDotenv(data)
+	if err != nil {
+		return nil, err
+	}
+	return presentEnvPaths(vars, reflect.TypeOf(v), defaultEnvNames), nil
+}
+
+func presentEnvPaths(vars map[string]string, typ reflect.Type, names envNames) []string {
+	paths := make([]string, 0)
+	walkEnvFields(typ, names, "", "", func(env, path string) {
+		if _, ok := vars[env]; ok {
+			paths = append(paths, path)
+		}
+	})
+	return paths
+}
+
+// presentPaths collects the paths of the fields of the struct type typ set by the document,
+// matching the document objects to the struct fields by keys.
+func presentPaths(doc hookDoc, typ reflect.Type, keys hookKeys, prefix string, paths *[]string) {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	fields := doc.fields()
+	if typ.Kind() != reflect.Struct || isLeafStruct(typ) || fields == nil {
+		if prefix != "" {
+			*paths = append(*paths, prefix)
+		}
+		return
+	}
+	fieldKeys, fold := keys(typ)
+	for key, child := range fields {
+		index, ok := fieldKeys[key]
+		if !ok && fold {
+			for k, i := range fieldKeys {
+				if strings.EqualFold(k, key) {
+					index, ok = i, true
+					break
+				}
+			}
+		}
+		if !ok {
+			continue
+		}
+		path, fieldType, ok := fieldPathByIndex(typ, index, prefix)
+		if !ok {
+			continue
+		}
+		presentPaths(child, fieldType, keys, path, paths)
+	}
+}
+
+// fieldPathByIndex returns the dotted path and the type of the struct field with the index sequence,
+// which may go through embedded structs. It reports false if some field on the way has no path.
+func fieldPathByIndex(typ reflect.Type, index []int, prefix string) (string, reflect.Type, bool) {
+	path := prefix
+	for i, idx := range index {
+		if i > 0 {
+			for typ.Kind() == reflect.Ptr {
+				typ = typ.Elem()
+			}
+		}
+		sf := typ.Field(idx)
+		key, ok := fieldKey(sf)
+		if !ok {
+			return "", nil, false
+		}
+		path = joinPath(path, key)
+		typ = sf.Type
+	}
+	return path, typ, true
+}
+
+// applyPresent sets the fields of dst at the paths to the values of src which the merge has skipped
+// as empty ones, so explicitly set zero values override the values of the lower layers.
+func applyPresent(dst, src any, paths []string) error {
+	for _, path := range paths {
+		from, err := fieldByPath(reflect.ValueOf(src), path, false)
+		if err != nil {
+			return err
+		}
+		if !from.IsValid() || !isEmptyValue(from) {
+			continue
+		}
+		to, err := fieldByPath(reflect.ValueOf(dst), path, true)
+		if err != nil {
+			return err
+		}
+		to.Set(deepCopy(from))
+	}
+	return nil
+}
+
+// isEmptyValue reports whether the value is skipped by merging.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	default:
+		return v.IsZero()
+	}
+}
+
+// present returns the paths of the fields of v set by the data with WithExplicitValues,
+// nil if the option is not set or the formatter does not implement PresenceReporter.
+func (cm *ConfigManager) present(formatter Formatter, data []byte, v any) ([]string, error) {
+	reporter, ok := formatter.(PresenceReporter)
+	if !cm.explicitValues || !ok {
+		return nil, nil
+	}
+	paths, err := reporter.Present(data, v)
+	if err != nil {
+		return nil, fmt.Errorf("find present fields: %w", err)
+	}
+	return paths, nil
+}
+
+// mergeLayer merges the parsed layer into the loaded config, overriding the fields at the present paths
+// even with zero values, and records the provenance of the fields set by the layer.
+func (cm *ConfigManager) mergeLayer(st *loadState, layer any, present []string, source string) error {
+	if err := cm.merge(st.merged, layer); err != nil {
+		return err
+	}
+	if err := applyPresent(st.merged, layer, present); err != nil {
+		return err
+	}
+	recordProvenance(st.provenance, layer, source)
+	for _, path := range present {
+		setProvenance(st.provenance, path, source)
+	}
+	return nil
+}
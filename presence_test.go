@@ -0,0 +1,120 @@
+package confgo
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestFormatters_Present(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		formatter PresenceReporter
+		data      string
+		want      []string
+	}{
+		{
+			name:      "json",
+			formatter: NewJSONFormatter(),
+			data:      `{"int": 0, "INNER": {"string": ""}, "inner_ptr": null, "map": {"a": "1"}, "unknown": 1}`,
+			want:      []string{"inner.string", "inner_ptr", "int", "map"},
+		},
+		{
+			name:      "jsonc",
+			formatter: NewJSONCFormatter(),
+			data:      "{\n// comment\n\"slice\": [],\n}",
+			want:      []string{"slice"},
+		},
+		{
+			name:      "yaml",
+			formatter: NewYAMLFormatter(),
+			data:      "int: 0\ninnerptr:\n  int: 0\nmap:\n",
+			want:      []string{"inner_ptr.int", "int", "map"},
+		},
+		{
+			name:      "yaml empty",
+			formatter: NewYAMLFormatter(),
+			data:      "",
+			want:      []string{},
+		},
+		{
+			name:      "env",
+			formatter: NewEnvFormatter(),
+			data:      "INT=0\nOTHER=1\n",
+			want:      []string{"int"},
+		},
+		{
+			name:      "dotenv",
+			formatter: NewDotenvFormatter(),
+			data:      "INT=\n",
+			want:      []string{"int"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := tt.formatter.Present([]byte(tt.data), &TestConfig{})
+			if err != nil {
+				t.Fatalf("Present() error = %v", err)
+			}
+			slices.Sort(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Present() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigManager_WithExplicitValues(t *testing.T) {
+	t.Parallel()
+
+	base := `{"int": 8080, "inner": {"int": 1, "string": "base"}, "inner_ptr": {"int": 2}, "slice": ["a"]}`
+	override := `{"int": 0, "inner": {"string": ""}, "inner_ptr": null, "slice": []}`
+	tests := []struct {
+		name string
+		opts []Option
+		want *TestConfig
+	}{
+		{
+			name: "default",
+			opts: nil,
+			want: &TestConfig{
+				Int:      8080,
+				Inner:    testInnerConfig{Int: 1, String: "base"},
+				InnerPtr: &testInnerConfig{Int: 2},
+				Slice:    []string{"a"},
+			},
+		},
+		{
+			name: "explicit values",
+			opts: []Option{WithExplicitValues},
+			want: &TestConfig{Inner: testInnerConfig{Int: 1}, Slice: []string{}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			opts := append(slices.Clone(tt.opts), func(cm *ConfigManager) error {
+				cm.AddLoader(Loader{Source: &fakeSource{data: []byte(base)}, Formatter: NewJSONFormatter()})
+				cm.AddLoader(Loader{Source: &fakeSource{data: []byte(override)}, Formatter: NewJSONFormatter()})
+				return nil
+			})
+			cm, err := NewConfigManager(testConfigConstructor, opts...)
+			if err != nil {
+				t.Fatalf("NewConfigManager() error = %v", err)
+			}
+			if err := cm.Start(); err != nil {
+				t.Fatalf("Start() error = %v", err)
+			}
+			defer cm.MustStop()
+
+			if got := cm.Config(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Config() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package confgo
+
+import (
+	"maps"
+	"strings"
+)
+
+// Sources of field values reported by Provenance besides the descriptions of loaders.
+const (
+	// ProvenanceDefault is the source of values set by SetDefaults method of Defaulter, default tags and WithDefaults.
+	ProvenanceDefault = "default"
+	// ProvenanceOverride is the source of values set at runtime with SetConfig, ApplyPatch and SetOverride.
+	ProvenanceOverride = "override"
+)
+
+// Provenance returns the source of the effective value of every field of the current configuration
+// which holds a non-zero value, keyed by the dotted field path, e.g. "db.host".
+// The source is the description of the last loader which has set the field, e.g. "env" or `file "config.json"`,
+// ProvenanceDefault or ProvenanceOverride.
+// Maps and slices are attributed as a whole to the last loader setting them, though their elements may be merged
+// from several loaders. It returns nil if no configuration is loaded.
+func (cm *ConfigManager) Provenance() map[string]string {
+	if next := cm.handedOff.Load(); next != nil {
+		return next.Provenance()
+	}
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+	return maps.Clone(cm.provenance)
+}
+
+// recordProvenance sets source as the source of the fields of cfg which hold non-zero values.
+// Besides sources, it records the change metadata of the fields set at runtime.
+func recordProvenance[V any](provenance map[string]V, cfg any, source V) {
+	for _, path := range nonZeroPaths(cfg) {
+		provenance[path] = source
+	}
+}
+
+// setProvenance sets source as the source of the field at the path, replacing the sources of the fields nested in it.
+func setProvenance[V any](provenance map[string]V, path string, source V) {
+	maps.DeleteFunc(provenance, func(p string, _ V) bool {
+		return strings.HasPrefix(p, path+".")
+	})
+	provenance[path] = source
+}
@@ -0,0 +1,57 @@
+package confgo
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestConfigManager_Provenance(t *testing.T) {
+	t.Setenv("INT", "2")
+
+	type config struct {
+		Int   int             `json:"int" env:"INT"`
+		Level string          `json:"level" default:"info"`
+		Inner testInnerConfig `json:"inner"`
+		Ptr   *testInnerConfig
+	}
+	path := filepath.Join(t.TempDir(), "config.json")
+	data := `{"int": 1, "inner": {"int": 1, "string": "file"}, "Ptr": {"int": 1}}`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	cm, err := NewConfigManagerFor[config](WithFile(path), WithEnv)
+	if err != nil {
+		t.Fatalf("NewConfigManagerFor() error = %v", err)
+	}
+	if got := cm.Provenance(); got != nil {
+		t.Errorf("Provenance() before start = %v, want nil", got)
+	}
+	if err := cm.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer cm.MustStop()
+
+	file := fmt.Sprintf("file %q", path)
+	want := map[string]string{
+		"int":          "env",
+		"level":        ProvenanceDefault,
+		"inner.int":    file,
+		"inner.string": file,
+		"Ptr.int":      file,
+	}
+	if got := cm.Provenance(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Provenance() = %v, want %v", got, want)
+	}
+
+	if err := cm.SetOverride("Ptr", &testInnerConfig{String: "s"}, ChangeMeta{}); err != nil {
+		t.Fatalf("SetOverride() error = %v", err)
+	}
+	delete(want, "Ptr.int")
+	want["Ptr"] = ProvenanceOverride
+	if got := cm.Provenance(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Provenance() after override = %v, want %v", got, want)
+	}
+}
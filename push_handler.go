@@ -0,0 +1,211 @@
+package confgo
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math"
+	"mime"
+	"net/http"
+	"sync"
+)
+
+const (
+	defaultPushLoaderName = "push"
+	defaultPushMaxBytes   = 1 << 20
+)
+
+// PushHandlerOption configures the handler returned by PushHandler.
+type PushHandlerOption func(ph *pushHandler)
+
+// PushAuth sets the function authorizing push requests, e.g. checking a bearer token or the client certificate.
+// A request is rejected with status 401 if auth returns an error. Without it every request is accepted,
+// so the handler must be protected otherwise.
+func PushAuth(auth func(r *http.Request) error) PushHandlerOption {
+	return func(ph *pushHandler) {
+		ph.auth = auth
+	}
+}
+
+// PushFormatter sets the formatter of the payloads whose Content-Type is neither JSON nor YAML.
+// Defaults to JSONFormatter.
+func PushFormatter(formatter Formatter) PushHandlerOption {
+	return func(ph *pushHandler) {
+		ph.formatter = formatter
+	}
+}
+
+// PushLoaderName sets the name of the loader staging the pushed configuration. Defaults to "push".
+func PushLoaderName(name string) PushHandlerOption {
+	return func(ph *pushHandler) {
+		ph.name = name
+	}
+}
+
+// PushPriority sets the priority of the loader staging the pushed configuration.
+// Defaults to the highest one, so the pushed configuration overrides the configuration of other loaders.
+func PushPriority(priority int) PushHandlerOption {
+	return func(ph *pushHandler) {
+		ph.priority = priority
+	}
+}
+
+// PushMaxBytes limits the size of the pushed payload, 1 MiB by default.
+func PushMaxBytes(n int64) PushHandlerOption {
+	return func(ph *pushHandler) {
+		ph.maxBytes = n
+	}
+}
+
+// pushHandler is the handler returned by PushHandler.
+type pushHandler struct {
+	cm        *ConfigManager
+	source    *pushSource
+	auth      func(r *http.Request) error
+	formatter Formatter
+	name      string
+	priority  int
+	maxBytes  int64
+	// mu serializes pushes, so a failed push restores the configuration staged before it.
+	mu sync.Mutex
+}
+
+// PushHandler returns an HTTP handler receiving the configuration pushed by a control plane instead of
+// pulling it from sources. The handler adds a loader staging the pushed configuration, which is skipped
+// until the first push, so PushHandler must be called once per manager unless loader names differ.
+//
+// On POST or PUT the request body is parsed according to its Content-Type, JSON or YAML, staged
+// as the layer of the loader and the configuration is reloaded. The handler responds like Handler,
+// with status 400 if the payload cannot be parsed, 422 if the reload fails, in which case the previously
+// staged configuration is restored, and 503 if the manager is not running. On DELETE the staged
+// configuration is removed and the configuration is reloaded without it.
+func (cm *ConfigManager) PushHandler(opts ...PushHandlerOption) http.Handler {
+	ph := &pushHandler{
+		cm:        cm,
+		source:    &pushSource{mu: sync.Mutex{}, data: nil, formatter: nil},
+		auth:      nil,
+		formatter: NewJSONFormatter(),
+		name:      defaultPushLoaderName,
+		priority:  math.MaxInt,
+		maxBytes:  defaultPushMaxBytes,
+		mu:        sync.Mutex{},
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(ph)
+		}
+	}
+	cm.AddLoader(Loader{
+		Name:          ph.name,
+		Source:        ph.source,
+		Formatter:     ph.source,
+		Priority:      ph.priority,
+		skipIfMissing: true,
+	})
+	return ph
+}
+
+func (ph *pushHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost, http.MethodPut, http.MethodDelete:
+	default:
+		w.Header().Set("Allow", "POST, PUT, DELETE")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	if ph.auth != nil {
+		if err := ph.auth(r); err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+	}
+	if !ph.cm.isRunning.Load() {
+		http.Error(w, ErrNotRunning.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	var data []byte
+	var formatter Formatter
+	if r.Method != http.MethodDelete {
+		var err error
+		if data, err = io.ReadAll(http.MaxBytesReader(w, r.Body, ph.maxBytes)); err != nil {
+			http.Error(w, fmt.Sprintf("read payload: %v", err), http.StatusBadRequest)
+			return
+		}
+		formatter = ph.formatterFor(r.Header.Get("Content-Type"))
+		if err := ph.cm.unmarshal(formatter, data, ph.cm.constructor()); err != nil {
+			http.Error(w, fmt.Sprintf("parse payload: %v", err), http.StatusBadRequest)
+			return
+		}
+	}
+
+	ph.mu.Lock()
+	defer ph.mu.Unlock()
+	prevData, prevFormatter := ph.source.stage(data, formatter)
+	if err := ph.cm.reload(); err != nil {
+		ph.source.stage(prevData, prevFormatter)
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	ph.cm.writeAdminResponse(w, http.StatusOK)
+}
+
+// formatterFor returns the formatter of the payload with the content type.
+func (ph *pushHandler) formatterFor(contentType string) Formatter {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ph.formatter
+	}
+	switch mediaType {
+	case "application/json":
+		return NewJSONFormatter()
+	case "application/yaml", "application/x-yaml", "text/yaml", "text/x-yaml":
+		return NewYAMLFormatter()
+	default:
+		return ph.formatter
+	}
+}
+
+var (
+	_ Source    = (*pushSource)(nil)
+	_ Formatter = (*pushSource)(nil)
+)
+
+// pushSource is the source and the formatter of the loader staging the pushed configuration.
+// It reports fs.ErrNotExist until a configuration is staged.
+type pushSource struct {
+	mu        sync.Mutex
+	data      []byte
+	formatter Formatter
+}
+
+// stage stages the data parsed by the formatter, or removes the staged data if formatter is nil,
+// and returns the previously staged data and formatter.
+func (ps *pushSource) stage(data []byte, formatter Formatter) ([]byte, Formatter) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	prevData, prevFormatter := ps.data, ps.formatter
+	ps.data, ps.formatter = data, formatter
+	return prevData, prevFormatter
+}
+
+func (ps *pushSource) Read() ([]byte, error) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	if ps.formatter == nil {
+		return nil, fmt.Errorf("pushed config: %w", fs.ErrNotExist)
+	}
+	return bytes.Clone(ps.data), nil
+}
+
+func (ps *pushSource) Unmarshal(data []byte, v any) error {
+	ps.mu.Lock()
+	formatter := ps.formatter
+	ps.mu.Unlock()
+	if formatter == nil {
+		return errors.New("pushed config: nothing is staged")
+	}
+	return formatter.Unmarshal(data, v)
+}
@@ -0,0 +1,96 @@
+package confgo
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestConfigManager_PushHandler(t *testing.T) {
+	t.Parallel()
+
+	type config struct {
+		Host string `json:"host" yaml:"host"`
+		Port int    `json:"port" yaml:"port"`
+	}
+	cm, err := NewConfigManagerFor[config](
+		WithLoader(Loader{Source: &fakeSource{data: []byte(`{"host": "a", "port": 80}`)}, Formatter: NewJSONFormatter()}),
+		WithTypedValidator(func(cfg *config) error {
+			if cfg.Port <= 0 {
+				return errors.New("invalid port")
+			}
+			return nil
+		}),
+	)
+	if err != nil {
+		t.Fatalf("NewConfigManagerFor() error = %v", err)
+	}
+	handler := cm.PushHandler(PushAuth(func(r *http.Request) error {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			return errors.New("invalid token")
+		}
+		return nil
+	}))
+
+	serve := func(method, contentType, body string) (int, adminResponse) {
+		t.Helper()
+		req := httptest.NewRequest(method, "/push", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer token")
+		req.Header.Set("Content-Type", contentType)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		var resp adminResponse
+		if rec.Code == http.StatusOK {
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatalf("decode response error = %v", err)
+			}
+		}
+		return rec.Code, resp
+	}
+
+	if code, _ := serve(http.MethodPost, "application/json", `{"host": "b"}`); code != http.StatusServiceUnavailable {
+		t.Errorf("POST before start status = %d, want %d", code, http.StatusServiceUnavailable)
+	}
+	if err := cm.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer cm.MustStop()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/push", strings.NewReader(`{"host": "b"}`)))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("POST without token status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+	if code, _ := serve(http.MethodGet, "", ""); code != http.StatusMethodNotAllowed {
+		t.Errorf("GET status = %d, want %d", code, http.StatusMethodNotAllowed)
+	}
+
+	code, resp := serve(http.MethodPost, "application/json", `{"host": "b"}`)
+	if code != http.StatusOK || resp.Config["host"] != "b" || resp.Config["port"] != float64(80) {
+		t.Errorf("POST = %d %+v, want 200 with host b and port 80", code, resp)
+	}
+	if code, _ := serve(http.MethodPut, "application/yaml; charset=utf-8", "host: c\nport: 81\n"); code != http.StatusOK {
+		t.Errorf("PUT YAML status = %d, want 200", code)
+	}
+
+	if code, _ := serve(http.MethodPost, "application/json", `{"host": `); code != http.StatusBadRequest {
+		t.Errorf("POST malformed status = %d, want %d", code, http.StatusBadRequest)
+	}
+	if code, _ := serve(http.MethodPost, "application/json", `{"port": -1}`); code != http.StatusUnprocessableEntity {
+		t.Errorf("POST invalid status = %d, want %d", code, http.StatusUnprocessableEntity)
+	}
+	// The previously pushed config is restored and survives reloads.
+	if err := cm.reload(); err != nil {
+		t.Fatalf("reload() error = %v", err)
+	}
+	if cfg := cm.Config().(*config); cfg.Host != "c" || cfg.Port != 81 {
+		t.Errorf("Config() = %+v, want host c and port 81", cfg)
+	}
+
+	if code, resp = serve(http.MethodDelete, "", ""); code != http.StatusOK || resp.Config["host"] != "a" {
+		t.Errorf("DELETE = %d %+v, want 200 with host a", code, resp)
+	}
+}
@@ -0,0 +1,179 @@
+package confgo
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// redactedValue replaces the values of secret fields.
+const redactedValue = "[REDACTED]"
+
+// isSecretField reports whether the field is tagged `secret:"true"`.
+func isSecretField(sf reflect.StructField) bool {
+	secret, err := strconv.ParseBool(sf.Tag.Get("secret"))
+	return err == nil && secret
+}
+
+// isSecretPath reports whether the field at the dotted path of the config type typ or one of its parents
+// is tagged `secret:"true"`. Unknown paths are not secret.
+func isSecretPath(typ reflect.Type, path string) bool {
+	for _, key := range strings.Split(path, ".") {
+		for typ.Kind() == reflect.Ptr {
+			typ = typ.Elem()
+		}
+		if typ.Kind() != reflect.Struct {
+			return false
+		}
+		sf, ok := structFieldTypeByKey(typ, key)
+		if !ok {
+			return false
+		}
+		if isSecretField(sf) {
+			return true
+		}
+		typ = sf.Type
+	}
+	return false
+}
+
+func structFieldTypeByKey(typ reflect.Type, key string) (reflect.StructField, bool) {
+	for i := range typ.NumField() {
+		if k, ok := fieldKey(typ.Field(i)); ok && k == key {
+			return typ.Field(i), true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// hasSecretFields reports whether values of typ may hold fields tagged `secret:"true"`, including nested ones.
+func hasSecretFields(typ reflect.Type, visiting map[reflect.Type]bool) bool {
+	switch typ.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+		return hasSecretFields(typ.Elem(), visiting)
+	case reflect.Struct:
+		if isLeafStruct(typ) || visiting[typ] {
+			return false
+		}
+		visiting[typ] = true
+		defer delete(visiting, typ)
+		for i := range typ.NumField() {
+			sf := typ.Field(i)
+			if _, ok := fieldKey(sf); ok && (isSecretField(sf) || hasSecretFields(sf.Type, visiting)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// redactPatch returns a copy of the JSON merge patch of the config type typ with the values of secret fields
+// replaced with "[REDACTED]". Values of fields which are not patched field by field are redacted as a whole
+// if they may hold secret fields.
+func redactPatch(typ reflect.Type, patch map[string]any) map[string]any {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	res := make(map[string]any, len(patch))
+	for key, value := range patch {
+		sf, ok := structFieldTypeByKey(typ, key)
+		if !ok || value == nil {
+			res[key] = value
+			continue
+		}
+		fieldType := sf.Type
+		for fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		obj, isObject := value.(map[string]any)
+		switch {
+		case isSecretField(sf):
+			res[key] = redactedValue
+		case isObject && fieldType.Kind() == reflect.Struct && !isLeafStruct(fieldType):
+			res[key] = redactPatch(fieldType, obj)
+		case hasSecretFields(fieldType, make(map[reflect.Type]bool)):
+			res[key] = redactedValue
+		default:
+			res[key] = value
+		}
+	}
+	return res
+}
+
+// Redacted is a representation of a config in which the values of the fields tagged `secret:"true"`,
+// including nested ones, are replaced with "[REDACTED]", so it may be safely logged or exposed.
+// Structs are represented as maps keyed by the same field keys as the config data,
+// and its String method returns the JSON encoding.
+type Redacted map[string]any
+
+// Redact returns the redacted representation of cfg, which must be a struct or a pointer to a struct.
+// Other values are represented as nil.
+func Redact(cfg any) Redacted {
+	r, _ := redactValue(reflect.ValueOf(cfg)).(map[string]any)
+	return r
+}
+
+// RedactedConfig returns the redacted representation of the current configuration.
+func (cm *ConfigManager) RedactedConfig() Redacted {
+	return Redact(cm.Config())
+}
+
+func (r Redacted) String() string {
+	data, err := json.Marshal(map[string]any(r))
+	if err != nil {
+		return fmt.Sprint(map[string]any(r))
+	}
+	return string(data)
+}
+
+func redactValue(v reflect.Value) any {
+	switch v.Kind() {
+	case reflect.Invalid:
+		return nil
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return redactValue(v.Elem())
+	case reflect.Struct:
+		if isLeafStruct(v.Type()) {
+			return valueInterface(v)
+		}
+		res := make(map[string]any)
+		for i := range v.NumField() {
+			sf := v.Type().Field(i)
+			key, ok := fieldKey(sf)
+			if !ok {
+				continue
+			}
+			if isSecretField(sf) {
+				res[key] = redactedValue
+				continue
+			}
+			res[key] = redactValue(v.Field(i))
+		}
+		return res
+	case reflect.Slice, reflect.Array:
+		if (v.Kind() == reflect.Slice && v.IsNil()) || v.Type().Elem().Kind() == reflect.Uint8 {
+			return valueInterface(v)
+		}
+		res := make([]any, v.Len())
+		for i := range v.Len() {
+			res[i] = redactValue(v.Index(i))
+		}
+		return res
+	case reflect.Map:
+		if v.IsNil() {
+			return valueInterface(v)
+		}
+		res := make(map[string]any, v.Len())
+		for iter := v.MapRange(); iter.Next(); {
+			res[fmt.Sprint(iter.Key())] = redactValue(iter.Value())
+		}
+		return res
+	default:
+		return valueInterface(v)
+	}
+}
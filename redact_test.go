@@ -0,0 +1,102 @@
+package confgo
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testRedactConfig struct {
+	Host     string            `json:"host"`
+	Password string            `json:"password" secret:"true"`
+	Timeout  Duration          `json:"timeout"`
+	Tokens   []testRedactToken `json:"tokens"`
+	Auth     *testRedactAuth   `json:"auth" secret:"true"`
+	Labels   map[string]string `json:"labels"`
+	Public   bool              `json:"public" secret:"false"`
+}
+
+type testRedactToken struct {
+	Name  string `json:"name"`
+	Value string `json:"value" secret:"true"`
+}
+
+type testRedactAuth struct {
+	User string `json:"user"`
+}
+
+func TestRedact(t *testing.T) {
+	t.Parallel()
+
+	cfg := &testRedactConfig{
+		Host:     "localhost",
+		Password: "p@ss",
+		Timeout:  Duration(time.Second),
+		Tokens:   []testRedactToken{{Name: "ci", Value: "t0ken"}},
+		Auth:     &testRedactAuth{User: "admin"},
+		Labels:   map[string]string{"env": "dev"},
+		Public:   true,
+	}
+	want := Redacted{
+		"host":     "localhost",
+		"password": redactedValue,
+		"timeout":  Duration(time.Second),
+		"tokens":   []any{map[string]any{"name": "ci", "value": redactedValue}},
+		"auth":     redactedValue,
+		"labels":   map[string]any{"env": "dev"},
+		"public":   true,
+	}
+	got := Redact(cfg)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Redact() = %v, want %v", got, want)
+	}
+	wantString := `{"auth":"[REDACTED]","host":"localhost","labels":{"env":"dev"},"password":"[REDACTED]",` +
+		`"public":true,"timeout":"1s","tokens":[{"name":"ci","value":"[REDACTED]"}]}`
+	if s := got.String(); s != wantString {
+		t.Errorf("Redact().String() = %s, want %s", s, wantString)
+	}
+	if cfg.Password != "p@ss" || cfg.Tokens[0].Value != "t0ken" {
+		t.Errorf("Redact() modified the config: %+v", cfg)
+	}
+	if got := Redact(42); got != nil {
+		t.Errorf("Redact(42) = %v, want nil", got)
+	}
+}
+
+func TestConfigManager_RedactedConfig(t *testing.T) {
+	t.Parallel()
+
+	source := &fakeSource{data: []byte(`{"host": "a", "password": "first"}`)}
+	cm, err := NewConfigManagerFor[testRedactConfig](func(cm *ConfigManager) error {
+		cm.AddLoader(Loader{Source: source, Formatter: NewJSONFormatter()})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("NewConfigManagerFor() error = %v", err)
+	}
+	out := &bytes.Buffer{}
+	cm.devMode = true
+	cm.devOut = out
+	if err := cm.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer cm.MustStop()
+
+	if got := cm.RedactedConfig(); got["host"] != "a" || got["password"] != redactedValue {
+		t.Errorf("RedactedConfig() = %v, want host a and redacted password", got)
+	}
+
+	source.data = []byte(`{"host": "b", "password": "second"}`)
+	if err := cm.reload(); err != nil {
+		t.Fatalf("reload() error = %v", err)
+	}
+	log := out.String()
+	if strings.Contains(log, "first") || strings.Contains(log, "second") {
+		t.Errorf("dev mode log leaks the secret:\n%s", log)
+	}
+	if !strings.Contains(log, "  password: [REDACTED]\n") || !strings.Contains(log, "  host: a -> b\n") {
+		t.Errorf("dev mode log =\n%s, want redacted password and host change", log)
+	}
+}
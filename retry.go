@@ -0,0 +1,68 @@
+package confgo
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"log/slog"
+	"time"
+)
+
+// RetryPolicy defines how reading the source of a loader is retried when it fails during a reload,
+// so transient failures of remote sources do not fail the whole reload.
+// The zero value does not retry.
+type RetryPolicy struct {
+	// Attempts is the maximum number of reads, including the first one. Values below 2 mean no retries.
+	Attempts int
+	// Backoff returns the delay before the retry following the failed attempt, which starts from 1.
+	// Nil Backoff retries immediately.
+	Backoff func(attempt int) time.Duration
+	// Retryable reports whether the read error is transient. Nil Retryable treats every error as transient
+	// except fs.ErrNotExist and the cancellation of the reload.
+	Retryable func(err error) bool
+}
+
+// ExponentialBackoff returns a Backoff doubling the delay after every attempt starting from base,
+// which never exceeds maxDelay.
+func ExponentialBackoff(base, maxDelay time.Duration) func(attempt int) time.Duration {
+	return func(attempt int) time.Duration {
+		delay := base
+		for i := 1; i < attempt && delay < maxDelay; i++ {
+			delay *= 2
+		}
+		return min(delay, maxDelay)
+	}
+}
+
+func (p RetryPolicy) retryable(err error) bool {
+	if p.Retryable != nil {
+		return p.Retryable(err)
+	}
+	return !errors.Is(err, fs.ErrNotExist) && !errors.Is(err, context.Canceled)
+}
+
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	if p.Backoff == nil {
+		return 0
+	}
+	return p.Backoff(attempt)
+}
+
+// readWithRetry reads the source of the loader retrying the failed reads according to its RetryPolicy.
+func (l *Loader) readWithRetry(ctx context.Context, log *slog.Logger) ([]byte, error) {
+	for attempt := 1; ; attempt++ {
+		data, err := l.read(ctx)
+		if err == nil || attempt >= l.Retry.Attempts || !l.Retry.retryable(err) {
+			return data, err
+		}
+		delay := l.Retry.backoff(attempt)
+		log.Warn("confgo: config loader read retried", "attempt", attempt, "delay", delay, "error", err)
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, errors.Join(err, ctx.Err())
+		}
+	}
+}
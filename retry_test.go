@@ -0,0 +1,89 @@
+package confgo
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// flakySource fails the first failures reads.
+type flakySource struct {
+	failures int
+	reads    int
+}
+
+func (s *flakySource) Read() ([]byte, error) {
+	s.reads++
+	if s.reads <= s.failures {
+		return nil, errors.New("test error")
+	}
+	return []byte(`{"int": 1}`), nil
+}
+
+func TestLoader_Retry(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		failures  int
+		policy    RetryPolicy
+		wantErr   bool
+		wantReads int
+	}{
+		{name: "no retries", failures: 1, policy: RetryPolicy{}, wantErr: true, wantReads: 1},
+		{
+			name:      "recovered",
+			failures:  2,
+			policy:    RetryPolicy{Attempts: 3, Backoff: ExponentialBackoff(time.Millisecond, time.Millisecond)},
+			wantErr:   false,
+			wantReads: 3,
+		},
+		{name: "attempts exhausted", failures: 3, policy: RetryPolicy{Attempts: 3}, wantErr: true, wantReads: 3},
+		{
+			name:      "not retryable",
+			failures:  1,
+			policy:    RetryPolicy{Attempts: 3, Retryable: func(error) bool { return false }},
+			wantErr:   true,
+			wantReads: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			source := &flakySource{failures: tt.failures}
+			cm := newTestConfigManager(testConfigManagerFields{
+				constructor: testConfigConstructor,
+				loaders:     []Loader{{Source: source, Formatter: NewJSONFormatter(), Retry: tt.policy}},
+			})
+			if err := cm.reload(); (err != nil) != tt.wantErr {
+				t.Errorf("reload() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if source.reads != tt.wantReads {
+				t.Errorf("reads = %d, want %d", source.reads, tt.wantReads)
+			}
+		})
+	}
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	t.Parallel()
+
+	backoff := ExponentialBackoff(100*time.Millisecond, time.Second)
+	got := make([]time.Duration, 0, 6)
+	for attempt := 1; attempt <= 6; attempt++ {
+		got = append(got, backoff(attempt))
+	}
+	want := []time.Duration{
+		100 * time.Millisecond,
+		200 * time.Millisecond,
+		400 * time.Millisecond,
+		800 * time.Millisecond,
+		time.Second,
+		time.Second,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ExponentialBackoff() delays = %v, want %v", got, want)
+	}
+}
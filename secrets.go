@@ -0,0 +1,210 @@
+package confgo
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// SecretResolver resolves secret references of a URI scheme, e.g. "file:///run/secrets/token",
+// into the secret values.
+type SecretResolver interface {
+	// Resolve returns the value of the secret referenced by ref.
+	Resolve(ref *url.URL) (string, error)
+}
+
+// SecretResolverFunc is a function implementing SecretResolver.
+type SecretResolverFunc func(ref *url.URL) (string, error)
+
+func (f SecretResolverFunc) Resolve(ref *url.URL) (string, error) {
+	return f(ref)
+}
+
+// FileSecretResolver returns a resolver of "file" references which reads the secret from the file,
+// e.g. "file:///run/secrets/token", trimming a single trailing line break.
+func FileSecretResolver() SecretResolver {
+	return SecretResolverFunc(func(ref *url.URL) (string, error) {
+		data, err := os.ReadFile(filepath.FromSlash(ref.Host + ref.Path))
+		if err != nil {
+			return "", err
+		}
+		value := strings.TrimSuffix(string(data), "\n")
+		return strings.TrimSuffix(value, "\r"), nil
+	})
+}
+
+// EnvSecretResolver returns a resolver of "env" references which reads the secret from the environment variable,
+// e.g. "env://DB_PASSWORD". An unset variable is reported with ErrSecretNotFound.
+func EnvSecretResolver() SecretResolver {
+	return SecretResolverFunc(func(ref *url.URL) (string, error) {
+		value, ok := os.LookupEnv(ref.Host)
+		if !ok {
+			return "", fmt.Errorf("%w: env %q is not set", ErrSecretNotFound, ref.Host)
+		}
+		return value, nil
+	})
+}
+
+var _ SecretResolver = (*VaultSecretResolver)(nil)
+
+// VaultSecretResolver resolves "vault" references to keys of HashiCorp Vault KV v2 secrets
+// written as "vault://<mount>/<path>#<key>", e.g. "vault://secret/myapp/db#password".
+// Non-string values are resolved to their JSON encoding.
+type VaultSecretResolver struct {
+	addr string
+	auth VaultAuth
+	opts []VaultSourceOption
+
+	mu      sync.Mutex
+	sources map[string]*VaultSource
+}
+
+// NewVaultSecretResolver creates a resolver reading secrets from Vault at addr
+// the same way as VaultSource created with the same arguments does.
+func NewVaultSecretResolver(addr string, auth VaultAuth, opts ...VaultSourceOption) *VaultSecretResolver {
+	return &VaultSecretResolver{
+		addr:    addr,
+		auth:    auth,
+		opts:    opts,
+		mu:      sync.Mutex{},
+		sources: make(map[string]*VaultSource),
+	}
+}
+
+func (vr *VaultSecretResolver) Resolve(ref *url.URL) (string, error) {
+	path := strings.Trim(ref.Path, "/")
+	if ref.Host == "" || path == "" || ref.Fragment == "" {
+		return "", fmt.Errorf("%w: vault reference must be formatted as vault://<mount>/<path>#<key>", ErrInvalidSecretRef)
+	}
+	data, err := vr.source(ref.Host, path).Read()
+	if err != nil {
+		return "", err
+	}
+	var secret map[string]json.RawMessage
+	if err := json.Unmarshal(data, &secret); err != nil {
+		return "", fmt.Errorf("decode vault secret: %w", err)
+	}
+	raw, ok := secret[ref.Fragment]
+	if !ok {
+		return "", fmt.Errorf("%w: key %q of vault secret %q", ErrSecretNotFound, ref.Fragment, ref.Host+"/"+path)
+	}
+	var value string
+	if json.Unmarshal(raw, &value) == nil {
+		return value, nil
+	}
+	return string(raw), nil
+}
+
+// source returns the source of the secret, reusing it along with its token for subsequent references.
+func (vr *VaultSecretResolver) source(mount, path string) *VaultSource {
+	vr.mu.Lock()
+	defer vr.mu.Unlock()
+	key := mount + "/" + path
+	vs, ok := vr.sources[key]
+	if !ok {
+		vs = NewVaultSource(vr.addr, mount, path, vr.auth, vr.opts...)
+		vr.sources[key] = vs
+	}
+	return vs
+}
+
+// resolveSecrets replaces the string values of the merged config which are references
+// of the registered schemes with the resolved secrets.
+func (cm *ConfigManager) resolveSecrets(merged any) error {
+	if len(cm.secretResolvers) == 0 {
+		return nil
+	}
+	return resolveSecretRefs(reflect.ValueOf(merged), "", cm.secretResolvers)
+}
+
+func resolveSecretRefs(v reflect.Value, path string, resolvers map[string]SecretResolver) error {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		if v.Kind() == reflect.Interface {
+			return resolveSecretInterface(v, path, resolvers)
+		}
+		return resolveSecretRefs(v.Elem(), path, resolvers)
+	case reflect.Struct:
+		if isLeafStruct(v.Type()) {
+			return nil
+		}
+		for i := range v.NumField() {
+			key, ok := fieldKey(v.Type().Field(i))
+			if !ok {
+				continue
+			}
+			if err := resolveSecretRefs(v.Field(i), joinPath(path, key), resolvers); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := range v.Len() {
+			if err := resolveSecretRefs(v.Index(i), joinPath(path, strconv.Itoa(i)), resolvers); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		for iter := v.MapRange(); iter.Next(); {
+			// Map values are not addressable, so they are resolved in a copy and set back.
+			elem := reflect.New(v.Type().Elem()).Elem()
+			elem.Set(iter.Value())
+			if err := resolveSecretRefs(elem, joinPath(path, fmt.Sprint(iter.Key())), resolvers); err != nil {
+				return err
+			}
+			v.SetMapIndex(iter.Key(), elem)
+		}
+	case reflect.String:
+		value, err := resolveSecretRef(v.String(), resolvers)
+		if err != nil {
+			return fmt.Errorf("field %q: %w", path, err)
+		}
+		if v.CanSet() {
+			v.SetString(value)
+		}
+	default:
+	}
+	return nil
+}
+
+// resolveSecretInterface resolves the value held by the interface v, e.g. a string of map[string]any.
+func resolveSecretInterface(v reflect.Value, path string, resolvers map[string]SecretResolver) error {
+	elem := reflect.New(v.Elem().Type()).Elem()
+	elem.Set(v.Elem())
+	if err := resolveSecretRefs(elem, path, resolvers); err != nil {
+		return err
+	}
+	if v.CanSet() {
+		v.Set(elem)
+	}
+	return nil
+}
+
+// resolveSecretRef resolves the value if it is a reference of a registered scheme and returns it as is otherwise.
+func resolveSecretRef(value string, resolvers map[string]SecretResolver) (string, error) {
+	scheme, _, ok := strings.Cut(value, "://")
+	if !ok {
+		return value, nil
+	}
+	resolver, ok := resolvers[strings.ToLower(scheme)]
+	if !ok {
+		return value, nil
+	}
+	ref, err := url.Parse(value)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidSecretRef, err)
+	}
+	resolved, err := resolver.Resolve(ref)
+	if err != nil {
+		return "", fmt.Errorf("resolve %s reference: %w", ref.Scheme, err)
+	}
+	return resolved, nil
+}
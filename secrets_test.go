@@ -0,0 +1,110 @@
+package confgo
+
+import (
+	"errors"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestConfigManager_WithSecretResolver(t *testing.T) {
+	t.Setenv("CONFGO_TEST_API_KEY", "api-key")
+
+	vault := newFakeVault(map[string]any{"password": "db-password", "port": 5432})
+	server := httptest.NewServer(vault)
+	defer server.Close()
+
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte("file-token\n"), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	type db struct {
+		Password string `json:"password"`
+		Port     string `json:"port"`
+	}
+	type config struct {
+		DB      *db               `json:"db"`
+		Token   string            `json:"token"`
+		Keys    []string          `json:"keys"`
+		Headers map[string]string `json:"headers"`
+		Extra   map[string]any    `json:"extra"`
+		URL     string            `json:"url"`
+	}
+	data := `{
+		"db": {"password": "vault://secret/app#password", "port": "vault://secret/app#port"},
+		"token": "file://` + filepath.ToSlash(tokenFile) + `",
+		"keys": ["env://CONFGO_TEST_API_KEY", "plain"],
+		"headers": {"X-Api-Key": "env://CONFGO_TEST_API_KEY"},
+		"extra": {"key": "env://CONFGO_TEST_API_KEY"},
+		"url": "https://example.com"
+	}`
+
+	cm, err := NewConfigManagerFor[config](
+		WithSecretResolver("vault", NewVaultSecretResolver(server.URL, VaultTokenAuth("root"))),
+		WithSecretResolver("file", FileSecretResolver()),
+		WithSecretResolver("env", EnvSecretResolver()),
+		func(cm *ConfigManager) error {
+			cm.AddLoader(Loader{Source: &fakeSource{data: []byte(data)}, Formatter: NewJSONFormatter()})
+			return nil
+		},
+	)
+	if err != nil {
+		t.Fatalf("NewConfigManagerFor() error = %v", err)
+	}
+	if err := cm.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer cm.MustStop()
+
+	want := &config{
+		DB:      &db{Password: "db-password", Port: "5432"},
+		Token:   "file-token",
+		Keys:    []string{"api-key", "plain"},
+		Headers: map[string]string{"X-Api-Key": "api-key"},
+		Extra:   map[string]any{"key": "api-key"},
+		URL:     "https://example.com",
+	}
+	if got := cm.Config(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Config() = %+v, want %+v", got, want)
+	}
+}
+
+func Test_resolveSecretRefs_Errors(t *testing.T) {
+	t.Parallel()
+
+	errResolve := errors.New("resolve error")
+	resolvers := map[string]SecretResolver{
+		"env": EnvSecretResolver(),
+		"failing": SecretResolverFunc(func(*url.URL) (string, error) {
+			return "", errResolve
+		}),
+		"vault": NewVaultSecretResolver("http://127.0.0.1:0", VaultTokenAuth("root")),
+	}
+	tests := []struct {
+		name    string
+		value   string
+		wantErr error
+	}{
+		{name: "resolver error", value: "failing://secret", wantErr: errResolve},
+		{name: "unset env", value: "env://CONFGO_TEST_UNSET_VARIABLE", wantErr: ErrSecretNotFound},
+		{name: "vault without key", value: "vault://secret/app", wantErr: ErrInvalidSecretRef},
+		{name: "invalid reference", value: "env://%zz", wantErr: ErrInvalidSecretRef},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := &struct {
+				Secret string `json:"secret"`
+			}{Secret: tt.value}
+			err := resolveSecretRefs(reflect.ValueOf(cfg), "", resolvers)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("resolveSecretRefs() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package confgo
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// SetValues collects the values of a repeatable command-line flag, e.g. --set, as a flag.Value:
+//
+//	var sets confgo.SetValues
+//	flag.Var(&sets, "set", "override a config field, e.g. --set server.port=8081")
+//	flag.Parse()
+//	cm, err := confgo.NewConfigManagerFor[Config](confgo.WithYAMLFile("config.yaml"), confgo.WithSetValues(sets...))
+type SetValues []string
+
+func (sv *SetValues) String() string {
+	return strings.Join(*sv, ",")
+}
+
+func (sv *SetValues) Set(value string) error {
+	*sv = append(*sv, value)
+	return nil
+}
+
+// WithSetValues sets the fields listed in Helm-style values, e.g. "server.port=8081" or "a.b=1,a.c=x",
+// in the in-memory override layer as SetOverride does, see ParseSetValues.
+func WithSetValues(values ...string) Option {
+	return func(cm *ConfigManager) error {
+		overrides, err := ParseSetValues(cm.constructor(), values...)
+		if err != nil {
+			return err
+		}
+		cm.overridesMu.Lock()
+		defer cm.overridesMu.Unlock()
+		for path, value := range overrides {
+			cm.overrides[path] = value
+		}
+		return nil
+	}
+}
+
+// ParseSetValues parses Helm-style values into the values of the fields of the config type of cfg,
+// a pointer to a config struct, keyed by dotted paths.
+// Every value holds comma-separated path=value pairs, e.g. "server.port=8081,server.host=localhost",
+// and the later pairs override the earlier ones. Values are converted to the types of the fields the way
+// default tags are, except that slices and maps are enclosed in braces, e.g. "hosts={a,b}" or "labels={env:prod}".
+// The value null resets the field to its zero value. Commas and backslashes in scalar values are escaped
+// with a backslash, while items of slices and maps cannot contain commas.
+func ParseSetValues(cfg any, values ...string) (map[string]any, error) {
+	typ := reflect.TypeOf(cfg)
+	if typ == nil || typ.Kind() != reflect.Ptr || typ.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w: %T is not a pointer to a struct", ErrConfigTypeMismatch, cfg)
+	}
+	probe := reflect.New(typ.Elem())
+	overrides := make(map[string]any)
+	hooks := decodeHooks(nil).withDefaults()
+	for _, value := range values {
+		for _, pair := range splitSetPairs(value) {
+			path, raw, ok := strings.Cut(pair, "=")
+			if !ok || path == "" {
+				return nil, fmt.Errorf("%w: %q must be formatted as path=value", ErrInvalidFieldValue, pair)
+			}
+			field, err := fieldByPath(probe, path, true)
+			if err != nil {
+				return nil, err
+			}
+			if raw == "null" {
+				overrides[path] = nil
+				continue
+			}
+			if kind := field.Kind(); kind == reflect.Slice || kind == reflect.Map {
+				if !strings.HasPrefix(raw, "{") || !strings.HasSuffix(raw, "}") {
+					return nil, fmt.Errorf("%w: field %q: %s must be enclosed in braces", ErrInvalidFieldValue, path, kind)
+				}
+				raw = raw[1 : len(raw)-1]
+			}
+			parsed, err := parseDefault(field.Type(), raw, hooks)
+			if err != nil {
+				return nil, fmt.Errorf("field %q: %w", path, err)
+			}
+			overrides[path] = parsed.Interface()
+		}
+	}
+	return overrides, nil
+}
+
+// splitSetPairs splits the value on the commas outside braces, unescaping the characters escaped outside braces.
+func splitSetPairs(value string) []string {
+	pairs := make([]string, 0, 1)
+	var cur strings.Builder
+	depth := 0
+	for i := 0; i < len(value); i++ {
+		switch c := value[i]; {
+		case c == '\\' && i+1 < len(value):
+			i++
+			if depth > 0 {
+				cur.WriteByte(c)
+			}
+			cur.WriteByte(value[i])
+		case c == '{':
+			depth++
+			cur.WriteByte(c)
+		case c == '}' && depth > 0:
+			depth--
+			cur.WriteByte(c)
+		case c == ',' && depth == 0:
+			pairs = append(pairs, cur.String())
+			cur.Reset()
+		default:
+			cur.WriteByte(c)
+		}
+	}
+	return append(pairs, cur.String())
+}
@@ -0,0 +1,78 @@
+package confgo
+
+import (
+	"errors"
+	"flag"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseSetValues(t *testing.T) {
+	t.Parallel()
+
+	type config struct {
+		Port    int               `json:"port"`
+		Name    string            `json:"name"`
+		Timeout time.Duration     `json:"timeout"`
+		Hosts   []string          `json:"hosts"`
+		Labels  map[string]string `json:"labels"`
+		Inner   *testInnerConfig  `json:"inner"`
+	}
+
+	got, err := ParseSetValues(&config{}, `port=8081,name=a\,b`, "hosts={x,y},labels={env:prod}",
+		"timeout=5s,inner.int=3", "port=8082", "inner.string=null")
+	if err != nil {
+		t.Fatalf("ParseSetValues() error = %v", err)
+	}
+	want := map[string]any{
+		"port":         8082,
+		"name":         "a,b",
+		"timeout":      5 * time.Second,
+		"hosts":        []string{"x", "y"},
+		"labels":       map[string]string{"env": "prod"},
+		"inner.int":    3,
+		"inner.string": nil,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseSetValues() = %#v, want %#v", got, want)
+	}
+
+	tests := []struct {
+		cfg     any
+		value   string
+		wantErr error
+	}{
+		{cfg: &config{}, value: "port", wantErr: ErrInvalidFieldValue},
+		{cfg: &config{}, value: "port=abc", wantErr: ErrInvalidFieldValue},
+		{cfg: &config{}, value: "hosts=x", wantErr: ErrInvalidFieldValue},
+		{cfg: &config{}, value: "unknown=1", wantErr: ErrInvalidFieldPath},
+		{cfg: config{}, value: "port=1", wantErr: ErrConfigTypeMismatch},
+	}
+	for _, tt := range tests {
+		if _, err := ParseSetValues(tt.cfg, tt.value); !errors.Is(err, tt.wantErr) {
+			t.Errorf("ParseSetValues(%q) error = %v, want %v", tt.value, err, tt.wantErr)
+		}
+	}
+}
+
+func TestWithSetValues(t *testing.T) {
+	t.Parallel()
+
+	var sets SetValues
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags.Var(&sets, "set", "")
+	if err := flags.Parse([]string{"--set", "int=2", "--set", "inner.string=b,slice={x}"}); err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	cm := newTestOverridesManager(t, TestConfig{Int: 1, Inner: testInnerConfig{Int: 1, String: "a"}},
+		WithSetValues(sets...))
+	want := &TestConfig{Int: 2, Inner: testInnerConfig{Int: 1, String: "b"}, Slice: []string{"x"}}
+	if got := cm.Config(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Config() = %+v, want %+v", got, want)
+	}
+	if provenance := cm.Provenance(); provenance["inner.string"] != ProvenanceOverride {
+		t.Errorf("Provenance()[inner.string] = %q, want %q", provenance["inner.string"], ProvenanceOverride)
+	}
+}
@@ -0,0 +1,93 @@
+package confgo
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const defaultSOPSBinary = "sops"
+
+var _ Transformer = (*SOPSTransformer)(nil)
+
+// SOPSTransformerOption option that configures sops transformer.
+type SOPSTransformerOption func(st *SOPSTransformer)
+
+// SOPSBinary sets the path of the sops executable, "sops" looked up in PATH by default.
+func SOPSBinary(path string) SOPSTransformerOption {
+	return func(st *SOPSTransformer) {
+		st.binary = path
+	}
+}
+
+// SOPSTransformer is a transformer that decrypts JSON or YAML data encrypted with SOPS
+// (https://github.com/getsops/sops), so secrets may be stored encrypted and still be loaded as plain config.
+//
+// Decryption is delegated to the sops executable, which resolves age, PGP and cloud KMS keys
+// the same way as on the command line, e.g. from SOPS_AGE_KEY_FILE. Data without SOPS metadata
+// is returned unchanged.
+type SOPSTransformer struct {
+	format string
+	binary string
+}
+
+// NewSOPSTransformer creates a sops transformer for data in the format, either "json" or "yaml".
+func NewSOPSTransformer(format string, opts ...SOPSTransformerOption) (*SOPSTransformer, error) {
+	if format != "json" && format != "yaml" {
+		return nil, fmt.Errorf("%w: sops data format %q", ErrUnknownFormat, format)
+	}
+	st := &SOPSTransformer{format: format, binary: defaultSOPSBinary}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(st)
+		}
+	}
+	return st, nil
+}
+
+func (st *SOPSTransformer) Transform(data []byte) ([]byte, error) {
+	if !isSOPSEncrypted(data) {
+		return data, nil
+	}
+	var stdout, stderr bytes.Buffer
+	//nolint:gosec // The binary is configured by the application, not by the config data.
+	cmd := exec.Command(st.binary, "--decrypt", "--input-type", st.format, "--output-type", st.format, "/dev/stdin")
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("sops decrypt: %w: %s", err, msg)
+		}
+		return nil, fmt.Errorf("sops decrypt: %w", err)
+	}
+	return stdout.Bytes(), nil
+}
+
+// isSOPSEncrypted reports whether the JSON or YAML data holds the top-level "sops" metadata key.
+func isSOPSEncrypted(data []byte) bool {
+	var doc map[string]any
+	// JSON is valid YAML, so both formats are checked the same way.
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return false
+	}
+	_, ok := doc["sops"]
+	return ok
+}
+
+// sopsFormat returns the sops data format of the file by its extension.
+func sopsFormat(file string) (string, error) {
+	ext := filepath.Ext(file)
+	switch strings.ToLower(ext) {
+	case ".json":
+		return "json", nil
+	case ".yaml", ".yml":
+		return "yaml", nil
+	default:
+		return "", fmt.Errorf("%w: sops file %q: unsupported extension %q", ErrUnknownFormat, file, ext)
+	}
+}
@@ -0,0 +1,116 @@
+package confgo
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+// fakeSOPS writes a shell script standing for the sops executable and returns its path.
+func fakeSOPS(t *testing.T, script string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake sops executable is a shell script")
+	}
+	path := filepath.Join(t.TempDir(), "sops")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0o700); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	return path
+}
+
+func TestSOPSTransformer_Transform(t *testing.T) {
+	t.Parallel()
+
+	// The fake sops checks the arguments and the metadata and "decrypts" the password.
+	decrypt := fakeSOPS(t, `[ "$*" = "--decrypt --input-type json --output-type json /dev/stdin" ] || exit 2
+grep -q '"sops"' || exit 3
+printf '{"password": "secret"}'
+`)
+	failing := fakeSOPS(t, "echo 'no key could decrypt the data' >&2\nexit 128\n")
+
+	tests := []struct {
+		name    string
+		binary  string
+		data    string
+		want    string
+		wantErr string
+	}{
+		{
+			name:   "encrypted",
+			binary: decrypt,
+			data:   `{"password": "ENC[AES256_GCM,data:...]", "sops": {"version": "3.9.0"}}`,
+			want:   `{"password": "secret"}`,
+		},
+		{
+			name:   "plain",
+			binary: failing,
+			data:   `{"password": "plain"}`,
+			want:   `{"password": "plain"}`,
+		},
+		{
+			name:    "decryption failure",
+			binary:  failing,
+			data:    `{"password": "ENC[AES256_GCM,data:...]", "sops": {}}`,
+			wantErr: "sops decrypt: exit status 128: no key could decrypt the data",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			st, err := NewSOPSTransformer("json", SOPSBinary(tt.binary))
+			if err != nil {
+				t.Fatalf("NewSOPSTransformer() error = %v", err)
+			}
+			got, err := st.Transform([]byte(tt.data))
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("Transform() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Transform() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Transform() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithSOPSFile(t *testing.T) {
+	t.Parallel()
+
+	decrypt := fakeSOPS(t, `[ "$*" = "--decrypt --input-type yaml --output-type yaml /dev/stdin" ] || exit 2
+printf 'password: secret\n'
+`)
+	file := filepath.Join(t.TempDir(), "secrets.yaml")
+	if err := os.WriteFile(file, []byte("password: ENC[...]\nsops:\n  version: 3.9.0\n"), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	type config struct {
+		Password string `yaml:"password"`
+	}
+	cm, err := NewConfigManagerFor[config](WithSOPSFile(file, SOPSBinary(decrypt)))
+	if err != nil {
+		t.Fatalf("NewConfigManagerFor() error = %v", err)
+	}
+	if err := cm.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer cm.MustStop()
+
+	if got, want := cm.Config(), (&config{Password: "secret"}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Config() = %+v, want %+v", got, want)
+	}
+
+	if _, err := NewConfigManagerFor[config](WithSOPSFile("secrets.env")); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("NewConfigManagerFor() error = %v, want %v", err, ErrUnknownFormat)
+	}
+}
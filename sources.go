@@ -1,8 +1,12 @@
 package confgo
 
 import (
+	"bytes"
+	"io"
+	iofs "io/fs"
 	"os"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -10,22 +14,52 @@ func stringsToBytes(s []string) []byte {
 	return []byte(strings.Join(s, "\n"))
 }
 
+// EnvSourceOption configures EnvSource.
+type EnvSourceOption func(es *EnvSource)
+
+// EnvPrefix makes the source read only the variables with the prefix, e.g. "MYAPP_", and strip it,
+// so the variable MYAPP_PORT matches the `env:"PORT"` tag and the variables of other apps are ignored.
+func EnvPrefix(prefix string) EnvSourceOption {
+	return func(es *EnvSource) {
+		es.prefix = prefix
+	}
+}
+
 var _ Source = (*EnvSource)(nil)
 
 // EnvSource is a configuration source that reads environment variables.
-type EnvSource struct{}
+type EnvSource struct {
+	prefix string
+}
 
-func NewEnvSource() *EnvSource {
-	return &EnvSource{}
+func NewEnvSource(opts ...EnvSourceOption) *EnvSource {
+	es := &EnvSource{prefix: ""}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(es)
+		}
+	}
+	return es
 }
 
 func (es *EnvSource) Read() ([]byte, error) {
-	return stringsToBytes(os.Environ()), nil
+	environ := os.Environ()
+	if es.prefix == "" {
+		return stringsToBytes(environ), nil
+	}
+	vars := make([]string, 0)
+	for _, kv := range environ {
+		if rest, ok := strings.CutPrefix(kv, es.prefix); ok {
+			vars = append(vars, rest)
+		}
+	}
+	return stringsToBytes(vars), nil
 }
 
 var (
-	_ Source   = (*FileSource)(nil)
-	_ ModTimer = (*FileSource)(nil)
+	_ Source     = (*FileSource)(nil)
+	_ ModTimer   = (*FileSource)(nil)
+	_ FileStater = (*FileSource)(nil)
 )
 
 // FileSource is a configuration source that reads from a file.
@@ -49,3 +83,112 @@ func (fs *FileSource) ModTime() (time.Time, error) {
 
 	return info.ModTime(), nil
 }
+
+func (fs *FileSource) Stat() (iofs.FileInfo, error) {
+	return os.Stat(fs.path)
+}
+
+var (
+	_ Source     = (*FSSource)(nil)
+	_ ModTimer   = (*FSSource)(nil)
+	_ FileStater = (*FSSource)(nil)
+)
+
+// FSSource is a configuration source that reads from a file of a file system, e.g. of embed.FS
+// with default configs compiled into the binary or of os.DirFS.
+type FSSource struct {
+	fsys iofs.FS
+	path string
+}
+
+// NewFSSource creates an FSSource reading the file at path of fsys. The path is slash-separated
+// and unrooted, as fs.ValidPath requires, e.g. "config/defaults.yaml".
+func NewFSSource(fsys iofs.FS, path string) *FSSource {
+	return &FSSource{fsys: fsys, path: path}
+}
+
+func (fs *FSSource) Read() ([]byte, error) {
+	return iofs.ReadFile(fs.fsys, fs.path)
+}
+
+// ModTime returns the modification time of the file, which is zero for files of embed.FS.
+func (fs *FSSource) ModTime() (time.Time, error) {
+	info, err := iofs.Stat(fs.fsys, fs.path)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return info.ModTime(), nil
+}
+
+func (fs *FSSource) Stat() (iofs.FileInfo, error) {
+	return iofs.Stat(fs.fsys, fs.path)
+}
+
+var (
+	_ Source = FuncSource(nil)
+	_ Source = (*BytesSource)(nil)
+	_ Source = (*StringSource)(nil)
+	_ Source = (*ReaderSource)(nil)
+)
+
+// FuncSource is a function implementing Source, e.g. a computed config, a test fixture or a legacy loader.
+// The function is called on every read.
+type FuncSource func() ([]byte, error)
+
+func (f FuncSource) Read() ([]byte, error) {
+	return f()
+}
+
+// BytesSource is a configuration source that reads the data given to it, e.g. a config embedded in the binary.
+type BytesSource struct {
+	data []byte
+}
+
+// NewBytesSource creates a BytesSource with a copy of data, so the caller may reuse the slice.
+func NewBytesSource(data []byte) *BytesSource {
+	return &BytesSource{data: bytes.Clone(data)}
+}
+
+func (bs *BytesSource) Read() ([]byte, error) {
+	return bytes.Clone(bs.data), nil
+}
+
+// StringSource is a configuration source that reads the string given to it.
+type StringSource struct {
+	data string
+}
+
+func NewStringSource(data string) *StringSource {
+	return &StringSource{data: data}
+}
+
+func (ss *StringSource) Read() ([]byte, error) {
+	return []byte(ss.data), nil
+}
+
+// ReaderSource is a configuration source that reads the reader given to it, e.g. a response body
+// or the output of another library. The reader is read to the end once, on the first read,
+// and the following reads of reloads return the same data or error.
+type ReaderSource struct {
+	mu   sync.Mutex
+	r    io.Reader
+	data []byte
+	err  error
+	read bool
+}
+
+func NewReaderSource(r io.Reader) *ReaderSource {
+	return &ReaderSource{mu: sync.Mutex{}, r: r, data: nil, err: nil, read: false}
+}
+
+func (rs *ReaderSource) Read() ([]byte, error) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	if !rs.read {
+		rs.data, rs.err = io.ReadAll(rs.r)
+		rs.read = true
+		rs.r = nil
+	}
+	return bytes.Clone(rs.data), rs.err
+}
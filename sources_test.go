@@ -1,8 +1,14 @@
 package confgo
 
 import (
+	"errors"
+	"io/fs"
 	"reflect"
+	"strings"
 	"testing"
+	"testing/fstest"
+	"testing/iotest"
+	"time"
 )
 
 func Test_stringsToBytes(t *testing.T) {
@@ -48,3 +54,115 @@ func Test_stringsToBytes(t *testing.T) {
 		})
 	}
 }
+
+func TestBytesSource_Read(t *testing.T) {
+	t.Parallel()
+
+	data := []byte(`{"int": 1}`)
+	s := NewBytesSource(data)
+	data[0] = '['
+	got, err := s.Read()
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if want := `{"int": 1}`; string(got) != want {
+		t.Errorf("Read() = %q, want %q", got, want)
+	}
+}
+
+func TestStringSource_Read(t *testing.T) {
+	t.Parallel()
+
+	got, err := NewStringSource(`{"int": 1}`).Read()
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if want := `{"int": 1}`; string(got) != want {
+		t.Errorf("Read() = %q, want %q", got, want)
+	}
+}
+
+func TestReaderSource_Read(t *testing.T) {
+	t.Parallel()
+
+	s := NewReaderSource(strings.NewReader(`{"int": 1}`))
+	for i := range 2 {
+		got, err := s.Read()
+		if err != nil {
+			t.Fatalf("Read() #%d error = %v", i, err)
+		}
+		if want := `{"int": 1}`; string(got) != want {
+			t.Errorf("Read() #%d = %q, want %q", i, got, want)
+		}
+	}
+
+	readErr := errors.New("read error")
+	s = NewReaderSource(iotest.ErrReader(readErr))
+	for i := range 2 {
+		if _, err := s.Read(); !errors.Is(err, readErr) {
+			t.Errorf("Read() #%d error = %v, want %v", i, err, readErr)
+		}
+	}
+}
+
+func TestFuncSource_Read(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	s := FuncSource(func() ([]byte, error) {
+		calls++
+		return []byte(`{"int": 1}`), nil
+	})
+	cm, err := NewConfigManager(testConfigConstructor, WithLoader(Loader{Source: s, Formatter: NewJSONFormatter()}))
+	if err != nil {
+		t.Fatalf("NewConfigManager() error = %v", err)
+	}
+	if err := cm.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer cm.MustStop()
+
+	if got := cm.Config().(*TestConfig).Int; got != 1 {
+		t.Errorf("Config().Int = %d, want 1", got)
+	}
+	if calls != 1 {
+		t.Errorf("FuncSource called %d times, want 1", calls)
+	}
+
+	l := Loader{Source: FuncSource(nil), Formatter: NewJSONFormatter()}
+	if err := l.validate(); !errors.Is(err, ErrSourceIsNil) {
+		t.Errorf("validate() error = %v, want %v", err, ErrSourceIsNil)
+	}
+}
+
+func TestFSSource(t *testing.T) {
+	t.Parallel()
+
+	modTime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	fsys := fstest.MapFS{
+		"config/defaults.json": {Data: []byte(`{"int": 1, "slice": ["a"]}`), ModTime: modTime},
+	}
+	s := NewFSSource(fsys, "config/defaults.json")
+	if got, err := s.ModTime(); err != nil || !got.Equal(modTime) {
+		t.Errorf("ModTime() = %v, %v, want %v", got, err, modTime)
+	}
+	if _, err := NewFSSource(fsys, "missing.json").Read(); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Read() error = %v, want %v", err, fs.ErrNotExist)
+	}
+
+	cm, err := NewConfigManager(testConfigConstructor,
+		WithFSFile(fsys, "config/defaults.json"),
+		WithMap(map[string]any{"int": 2}),
+	)
+	if err != nil {
+		t.Fatalf("NewConfigManager() error = %v", err)
+	}
+	if err := cm.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer cm.MustStop()
+
+	if got, want := cm.Config(), (&TestConfig{Int: 2, Slice: []string{"a"}}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Config() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,247 @@
+package confgo
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+const sqlQueryTimeout = 30 * time.Second
+
+// SQLSourceOption configures SQLSource.
+type SQLSourceOption func(ss *SQLSource)
+
+// SQLArgs sets the arguments of the placeholders of the query.
+func SQLArgs(args ...any) SQLSourceOption {
+	return func(ss *SQLSource) {
+		ss.args = args
+	}
+}
+
+var (
+	_ Source        = (*SQLSource)(nil)
+	_ SourceContext = (*SQLSource)(nil)
+)
+
+// SQLSource is a configuration source that reads config data from a database with a query.
+// Read returns the data encoded as JSON, so it is meant to be used with JSONFormatter.
+// The query must return either:
+//   - a single row with a single JSON column, which is the config data as is;
+//   - rows of key and value columns, e.g. "SELECT key, value FROM app_config", where keys are dotted field paths,
+//     e.g. "server.port", and values are parsed as JSON if valid and taken as strings otherwise.
+//     NULL values are JSON nulls and later rows override the earlier ones with the same key.
+type SQLSource struct {
+	db    *sql.DB
+	query string
+	args  []any
+}
+
+// NewSQLSource creates a source reading config data from db with the query.
+func NewSQLSource(db *sql.DB, query string, opts ...SQLSourceOption) *SQLSource {
+	ss := &SQLSource{
+		db:    db,
+		query: query,
+		args:  nil,
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(ss)
+		}
+	}
+	return ss
+}
+
+func (ss *SQLSource) Read() ([]byte, error) {
+	return ss.ReadContext(context.Background())
+}
+
+// ReadContext is the same as Read but aborts the query once ctx is done.
+func (ss *SQLSource) ReadContext(ctx context.Context) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, sqlQueryTimeout)
+	defer cancel()
+	rows, err := ss.db.QueryContext(ctx, ss.query, ss.args...)
+	if err != nil {
+		return nil, fmt.Errorf("query config: %w", err)
+	}
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("query config: %w", err)
+	}
+	var data []byte
+	switch len(columns) {
+	case 1:
+		data, err = scanJSONRow(rows)
+	case 2:
+		data, err = scanKeyValueRows(rows)
+	default:
+		err = fmt.Errorf("%w: got %d columns, want 1 or 2", ErrInvalidQueryResult, len(columns))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query config: %w", err)
+	}
+	return data, nil
+}
+
+func scanJSONRow(rows *sql.Rows) ([]byte, error) {
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: no rows", ErrInvalidQueryResult)
+	}
+	var data []byte
+	if err := rows.Scan(&data); err != nil {
+		return nil, err
+	}
+	if rows.Next() {
+		return nil, fmt.Errorf("%w: more than one row", ErrInvalidQueryResult)
+	}
+	return data, rows.Err()
+}
+
+func scanKeyValueRows(rows *sql.Rows) ([]byte, error) {
+	doc := make(map[string]any)
+	for rows.Next() {
+		var key string
+		var value sql.NullString
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, err
+		}
+		var v any
+		switch {
+		case !value.Valid:
+			v = nil
+		case json.Valid([]byte(value.String)):
+			v = json.RawMessage(value.String)
+		default:
+			v = value.String
+		}
+		if err := setDocValue(doc, key, v); err != nil {
+			return nil, err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return json.Marshal(doc)
+}
+
+// setDocValue sets the value at the dotted path of the document, creating the objects on the way.
+func setDocValue(doc map[string]any, path string, value any) error {
+	keys := strings.Split(path, ".")
+	for _, key := range keys[:len(keys)-1] {
+		next, ok := doc[key].(map[string]any)
+		if !ok {
+			if _, exists := doc[key]; exists {
+				return fmt.Errorf("%w: key %q conflicts with a value of %q", ErrInvalidQueryResult, path, key)
+			}
+			next = make(map[string]any)
+			doc[key] = next
+		}
+		doc = next
+	}
+	if _, ok := doc[keys[len(keys)-1]].(map[string]any); ok {
+		return fmt.Errorf("%w: key %q conflicts with its nested keys", ErrInvalidQueryResult, path)
+	}
+	doc[keys[len(keys)-1]] = value
+	return nil
+}
+
+// SQLWatcherOption configures SQLWatcher.
+type SQLWatcherOption func(sw *SQLWatcher)
+
+// SQLWatchArgs sets the arguments of the placeholders of the watch query.
+func SQLWatchArgs(args ...any) SQLWatcherOption {
+	return func(sw *SQLWatcher) {
+		sw.args = args
+	}
+}
+
+// SQLWatchInterval sets how often the watch query is run. Defaults to 3 seconds.
+func SQLWatchInterval(interval time.Duration) SQLWatcherOption {
+	return func(sw *SQLWatcher) {
+		sw.interval = interval
+	}
+}
+
+var _ Watcher = (*SQLWatcher)(nil)
+
+// SQLWatcher is a watcher that periodically runs a query returning a single value which changes
+// whenever the config does, e.g. "SELECT max(updated_at) FROM app_config", and calls the callback
+// if the value differs from the previous one.
+type SQLWatcher struct {
+	db       *sql.DB
+	query    string
+	args     []any
+	interval time.Duration
+	stop     chan struct{}
+	last     any
+	// initialized is set after the first successful query.
+	initialized bool
+}
+
+// NewSQLWatcher creates a watcher running the query against the database of the source.
+func NewSQLWatcher(source *SQLSource, query string, opts ...SQLWatcherOption) *SQLWatcher {
+	sw := &SQLWatcher{
+		db:          source.db,
+		query:       query,
+		args:        nil,
+		interval:    pollInterval,
+		stop:        make(chan struct{}),
+		last:        nil,
+		initialized: false,
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(sw)
+		}
+	}
+	return sw
+}
+
+func (sw *SQLWatcher) Watch(callback func()) {
+	go func() {
+		for {
+			select {
+			case <-sw.stop:
+				return
+			case <-time.After(sw.interval):
+				value, err := sw.check()
+				if err != nil {
+					continue
+				}
+				if !sw.initialized {
+					sw.initialized = true
+					sw.last = value
+				} else if !reflect.DeepEqual(value, sw.last) {
+					sw.last = value
+					callback()
+				}
+			}
+		}
+	}()
+}
+
+func (sw *SQLWatcher) Stop() error {
+	close(sw.stop)
+	return nil
+}
+
+func (sw *SQLWatcher) check() (any, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), sqlQueryTimeout)
+	defer cancel()
+	var value any
+	if err := sw.db.QueryRowContext(ctx, sw.query, sw.args...).Scan(&value); err != nil {
+		return nil, err
+	}
+	// Drivers may reuse the memory of []byte values.
+	if b, ok := value.([]byte); ok {
+		value = string(b)
+	}
+	return value, nil
+}
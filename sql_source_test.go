@@ -0,0 +1,197 @@
+package confgo
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeSQLResults maps queries to their results for the fake database/sql driver.
+type fakeSQLResults struct {
+	mu      sync.Mutex
+	results map[string]fakeSQLRows
+}
+
+func (r *fakeSQLResults) set(query string, rows fakeSQLRows) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.results[query] = rows
+}
+
+func (r *fakeSQLResults) Connect(context.Context) (driver.Conn, error) { return fakeSQLConn{r}, nil }
+func (r *fakeSQLResults) Driver() driver.Driver                        { return nil }
+
+type fakeSQLConn struct{ results *fakeSQLResults }
+
+func (c fakeSQLConn) Prepare(query string) (driver.Stmt, error) {
+	return fakeSQLStmt{c.results, query}, nil
+}
+func (c fakeSQLConn) Close() error              { return nil }
+func (c fakeSQLConn) Begin() (driver.Tx, error) { return nil, errors.New("not supported") }
+
+type fakeSQLStmt struct {
+	results *fakeSQLResults
+	query   string
+}
+
+func (s fakeSQLStmt) Close() error  { return nil }
+func (s fakeSQLStmt) NumInput() int { return -1 }
+func (s fakeSQLStmt) Exec([]driver.Value) (driver.Result, error) {
+	return nil, errors.New("not supported")
+}
+
+func (s fakeSQLStmt) Query([]driver.Value) (driver.Rows, error) {
+	s.results.mu.Lock()
+	defer s.results.mu.Unlock()
+	rows, ok := s.results.results[s.query]
+	if !ok {
+		return nil, errors.New("unknown query")
+	}
+	return &rows, nil
+}
+
+type fakeSQLRows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+func (r *fakeSQLRows) Columns() []string { return r.columns }
+func (r *fakeSQLRows) Close() error      { return nil }
+func (r *fakeSQLRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+func newFakeSQLDB(t *testing.T) (*sql.DB, *fakeSQLResults) {
+	t.Helper()
+	results := &fakeSQLResults{mu: sync.Mutex{}, results: make(map[string]fakeSQLRows)}
+	db := sql.OpenDB(results)
+	t.Cleanup(func() { _ = db.Close() })
+	return db, results
+}
+
+func TestSQLSource_Read(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		rows    fakeSQLRows
+		want    string
+		wantErr error
+	}{
+		{
+			name: "json column",
+			rows: fakeSQLRows{columns: []string{"data"}, values: [][]driver.Value{{[]byte(`{"int": 1}`)}}},
+			want: `{"int": 1}`,
+		},
+		{
+			name: "key value rows",
+			rows: fakeSQLRows{columns: []string{"key", "value"}, values: [][]driver.Value{
+				{"int", "1"},
+				{"inner.string", "str"},
+				{"inner.int", "2"},
+				{"slice", `["a"]`},
+				{"map", nil},
+				{"int", "3"},
+			}},
+			want: `{"inner":{"int":2,"string":"str"},"int":3,"map":null,"slice":["a"]}`,
+		},
+		{
+			name:    "no rows",
+			rows:    fakeSQLRows{columns: []string{"data"}, values: nil},
+			wantErr: ErrInvalidQueryResult,
+		},
+		{
+			name:    "too many columns",
+			rows:    fakeSQLRows{columns: []string{"a", "b", "c"}, values: nil},
+			wantErr: ErrInvalidQueryResult,
+		},
+		{
+			name: "conflicting keys",
+			rows: fakeSQLRows{columns: []string{"key", "value"}, values: [][]driver.Value{
+				{"inner", "1"},
+				{"inner.int", "2"},
+			}},
+			wantErr: ErrInvalidQueryResult,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			db, results := newFakeSQLDB(t)
+			results.set("SELECT config", tt.rows)
+			got, err := NewSQLSource(db, "SELECT config").Read()
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Read() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Read() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Read() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSQLWatcher(t *testing.T) {
+	t.Parallel()
+
+	db, results := newFakeSQLDB(t)
+	configRows := func(value string) fakeSQLRows {
+		return fakeSQLRows{columns: []string{"key", "value"}, values: [][]driver.Value{{"int", value}}}
+	}
+	updatedAt := func(at time.Time) fakeSQLRows {
+		return fakeSQLRows{columns: []string{"updated_at"}, values: [][]driver.Value{{at}}}
+	}
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	results.set("SELECT config", configRows("1"))
+	results.set("SELECT updated_at", updatedAt(start))
+
+	s := NewSQLSource(db, "SELECT config")
+	updated := make(chan struct{}, 1)
+	cm, err := NewConfigManager(testConfigConstructor, WithLoader(Loader{
+		Source:          s,
+		Formatter:       NewJSONFormatter(),
+		Watcher:         NewSQLWatcher(s, "SELECT updated_at", SQLWatchInterval(10*time.Millisecond)),
+		OnUpdateSuccess: func() { updated <- struct{}{} },
+	}))
+	if err != nil {
+		t.Fatalf("NewConfigManager() error = %v", err)
+	}
+	if err := cm.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer cm.MustStop()
+
+	if got, want := cm.Config(), (&TestConfig{Int: 1}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Config() = %+v, want %+v", got, want)
+	}
+
+	// Let the watcher take the initial value before the update.
+	time.Sleep(50 * time.Millisecond)
+	results.set("SELECT config", configRows("2"))
+	results.set("SELECT updated_at", updatedAt(start.Add(time.Second)))
+	select {
+	case <-updated:
+	case <-time.After(5 * time.Second):
+		t.Fatal("SQLWatcher did not report the update")
+	}
+	if got := cm.Config().(*TestConfig).Int; got != 2 {
+		t.Errorf("Config().Int = %d, want 2", got)
+	}
+}
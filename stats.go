@@ -0,0 +1,29 @@
+package confgo
+
+// Stats contains runtime statistics of the manager.
+type Stats struct {
+	// Subscribers is the number of active subscriptions, including channel ones.
+	Subscribers int `json:"subscribers"`
+	// ChannelSubscribers is the number of active channel subscriptions.
+	ChannelSubscribers int `json:"channel_subscribers"`
+	// ChannelDelivered is the number of changes put into subscription channels.
+	ChannelDelivered uint64 `json:"channel_delivered"`
+	// ChannelDropped is the number of changes discarded by subscription channels overflow policies.
+	ChannelDropped uint64 `json:"channel_dropped"`
+}
+
+// Stats returns the runtime statistics of the manager.
+func (cm *ConfigManager) Stats() Stats {
+	if next := cm.handedOff.Load(); next != nil {
+		return next.Stats()
+	}
+	cm.subMu.Lock()
+	subscribers := len(cm.subscribers)
+	cm.subMu.Unlock()
+	return Stats{
+		Subscribers:        subscribers,
+		ChannelSubscribers: int(cm.chanSubscribers.Load()),
+		ChannelDelivered:   cm.chanDelivered.Load(),
+		ChannelDropped:     cm.chanDropped.Load(),
+	}
+}
@@ -0,0 +1,184 @@
+package confgo
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+// LoaderStatus describes the result of the last read of a loader.
+type LoaderStatus struct {
+	// Loader is the index of the loader.
+	Loader int `json:"loader"`
+	// Name is the name of the loader, if any.
+	Name   string `json:"name,omitempty"`
+	Source string `json:"source"`
+	// LastRead is the time of the last attempt to load the layer, zero if it has not been attempted yet.
+	LastRead time.Time `json:"last_read"`
+	// Skipped is true if the layer has been skipped, e.g. because its optional file is missing.
+	Skipped bool `json:"skipped"`
+	// Err is the error of the last attempt, nil if it has succeeded.
+	Err error `json:"-"`
+}
+
+// Healthy reports whether the layer has been loaded by the last attempt.
+func (s LoaderStatus) Healthy() bool {
+	return !s.LastRead.IsZero() && !s.Skipped && s.Err == nil
+}
+
+// Status describes the state of the manager and its loaders.
+type Status struct {
+	Running bool `json:"running"`
+	// Generation is the number of successfully loaded configurations, zero if none is loaded yet.
+	Generation uint64 `json:"generation"`
+	// LastReload is the time of the last reload, successful or not.
+	LastReload time.Time `json:"last_reload"`
+	// LastSuccess is the time of the last successful reload.
+	LastSuccess time.Time `json:"last_success"`
+	// Staleness is how long the reloads have been failing, i.e. how long the last known good configuration
+	// has been served instead of the sources contents, zero if the last reload has succeeded.
+	Staleness time.Duration `json:"staleness"`
+	// MaxStaleness is the staleness set by WithMaxStaleness, zero if the staleness is not limited.
+	MaxStaleness time.Duration `json:"max_staleness"`
+	// Err is the error of the last reload, nil if it has succeeded.
+	// A panic of the reload is reported as an error wrapping ErrPanic.
+	Err error `json:"-"`
+	// Panics is the number of panics recovered in reloads, subscribers and update callbacks.
+	Panics uint64 `json:"panics"`
+	// Loaders are the statuses of the loaders in the order of loaders.
+	Loaders []LoaderStatus `json:"loaders"`
+}
+
+// Ready reports whether a configuration is loaded, i.e. the application may serve with it,
+// and it has not been stale for longer than MaxStaleness. It is suitable for readiness probes.
+func (s Status) Ready() bool {
+	return s.Generation > 0 && (s.MaxStaleness <= 0 || s.Staleness <= s.MaxStaleness)
+}
+
+// Healthy reports whether the manager is running and the last reload has succeeded.
+// It is suitable for liveness probes of applications that must not run with a stale configuration.
+func (s Status) Healthy() bool {
+	return s.Running && s.Ready() && s.Err == nil
+}
+
+// Status returns the state of the manager and the health of its loaders.
+func (cm *ConfigManager) Status() Status {
+	if next := cm.handedOff.Load(); next != nil {
+		return next.Status()
+	}
+	current := cm.snapshotLoaders()
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+	loaders := make([]LoaderStatus, 0, len(current))
+	for j, l := range current {
+		status, ok := cm.loaderStatuses[l.id]
+		if !ok {
+			status = LoaderStatus{Name: l.Name, Source: l.describe(), LastRead: time.Time{}, Skipped: false, Err: nil}
+		}
+		status.Loader = j
+		loaders = append(loaders, status)
+	}
+	return Status{
+		Running:      cm.isRunning.Load(),
+		Generation:   cm.reloadStatus.Version,
+		LastReload:   cm.reloadStatus.LastReload,
+		LastSuccess:  cm.reloadStatus.LastSuccess,
+		Staleness:    cm.reloadStatus.staleness(),
+		MaxStaleness: cm.maxStaleness,
+		Err:          cm.reloadStatus.Err,
+		Panics:       cm.reloadStatus.Panics,
+		Loaders:      loaders,
+	}
+}
+
+// LastError returns the error of the last reload, nil if it has succeeded. A failed reload keeps
+// the last known good configuration, so the error is the only sign of the sources being out of sync with it.
+func (cm *ConfigManager) LastError() error {
+	return cm.ReloadStatus().Err
+}
+
+// Staleness returns how long the reloads have been failing, i.e. how long the last known good configuration
+// has been served instead of the sources contents, zero if the last reload has succeeded.
+func (cm *ConfigManager) Staleness() time.Duration {
+	return cm.ReloadStatus().staleness()
+}
+
+func (s ReloadStatus) staleness() time.Duration {
+	if s.StaleSince.IsZero() {
+		return 0
+	}
+	return time.Since(s.StaleSince)
+}
+
+// recordLoaderStatus stores the result of the attempt to load the layer of the loader.
+// Statuses are keyed by the loader ids, so a reload racing with RemoveLoader, ReplaceLoader or AddLoader
+// never records the status of one loader against another, and the status of a removed loader is dropped.
+func (cm *ConfigManager) recordLoaderStatus(l Loader, skipped bool, err error) {
+	cm.loadersMu.Lock()
+	defer cm.loadersMu.Unlock()
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	if !slices.ContainsFunc(cm.loaders, func(loader Loader) bool { return loader.id == l.id }) {
+		return
+	}
+	if cm.loaderStatuses == nil {
+		cm.loaderStatuses = make(map[uint64]LoaderStatus)
+	}
+	cm.loaderStatuses[l.id] = LoaderStatus{
+		Loader:   0,
+		Name:     l.Name,
+		Source:   l.describe(),
+		LastRead: time.Now(),
+		Skipped:  skipped,
+		Err:      err,
+	}
+}
+
+// readySignal is closed once the first configuration is loaded. The zero value is ready to use.
+type readySignal struct {
+	mu     sync.Mutex
+	ch     chan struct{}
+	closed bool
+}
+
+func (s *readySignal) wait() <-chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ch == nil {
+		s.ch = make(chan struct{})
+	}
+	return s.ch
+}
+
+func (s *readySignal) signal() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	if s.ch == nil {
+		s.ch = make(chan struct{})
+	}
+	close(s.ch)
+	s.closed = true
+}
+
+// Ready returns a channel which is closed after the first successful load of the configuration,
+// so the configuration may be read once it is closed. It is useful when Start is called in a goroutine,
+// e.g. by a service runner, and other components must wait for the configuration.
+func (cm *ConfigManager) Ready() <-chan struct{} {
+	return cm.ready.wait()
+}
+
+// WaitForFirstLoad blocks until the first successful load of the configuration.
+// It returns an error wrapping the error of ctx if ctx is done first.
+func (cm *ConfigManager) WaitForFirstLoad(ctx context.Context) error {
+	select {
+	case <-cm.Ready():
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("wait for first load: %w", ctx.Err())
+	}
+}
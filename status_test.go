@@ -0,0 +1,154 @@
+package confgo
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"testing"
+	"time"
+)
+
+func TestConfigManager_Status(t *testing.T) {
+	t.Parallel()
+
+	base := &fakeSource{data: []byte(`{"int": 1}`)}
+	optional := &fakeSource{err: fs.ErrNotExist}
+	remote := &fakeSource{data: []byte(`{"int": 2}`)}
+	cm, err := NewConfigManager(testConfigConstructor, func(cm *ConfigManager) error {
+		cm.AddLoader(Loader{Source: base, Formatter: NewJSONFormatter()})
+		cm.AddLoader(Loader{Source: optional, Formatter: NewJSONFormatter(), skipIfMissing: true})
+		cm.AddLoader(Loader{Source: remote, Formatter: NewJSONFormatter()})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("NewConfigManager() error = %v", err)
+	}
+
+	status := cm.Status()
+	if status.Ready() || status.Healthy() || len(status.Loaders) != 3 || status.Loaders[0].Healthy() {
+		t.Errorf("Status() before start = %+v, want not ready and unhealthy with 3 loaders", status)
+	}
+
+	if err := cm.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer cm.MustStop()
+	status = cm.Status()
+	if !status.Ready() || !status.Healthy() || status.Generation != 1 || status.LastSuccess.IsZero() {
+		t.Errorf("Status() after start = %+v, want ready and healthy generation 1", status)
+	}
+	if !status.Loaders[0].Healthy() || !status.Loaders[1].Skipped || status.Loaders[1].Healthy() ||
+		!status.Loaders[2].Healthy() {
+		t.Errorf("Status().Loaders = %+v, want the optional loader skipped and the others healthy", status.Loaders)
+	}
+
+	remote.err = errors.New("connection refused")
+	if err := cm.reload(); err == nil {
+		t.Fatalf("reload() error = nil, want error")
+	}
+	lastSuccess := status.LastSuccess
+	status = cm.Status()
+	if !status.Ready() || status.Healthy() || !errors.Is(status.Err, remote.err) || status.Generation != 1 ||
+		status.LastSuccess != lastSuccess || status.LastReload.Before(lastSuccess) {
+		t.Errorf("Status() after failed reload = %+v, want ready, unhealthy, generation 1 and the error", status)
+	}
+	if !status.Loaders[0].Healthy() || !errors.Is(status.Loaders[2].Err, remote.err) {
+		t.Errorf("Status().Loaders = %+v, want the remote loader failed", status.Loaders)
+	}
+}
+
+func TestConfigManager_LastKnownGood(t *testing.T) {
+	t.Parallel()
+
+	source := &fakeSource{data: []byte(`{"int": 1}`)}
+	cm, err := NewConfigManager(testConfigConstructor, WithMaxStaleness(time.Hour), func(cm *ConfigManager) error {
+		cm.AddLoader(Loader{Source: source, Formatter: NewJSONFormatter()})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("NewConfigManager() error = %v", err)
+	}
+	if err := cm.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer cm.MustStop()
+	if cm.LastError() != nil || cm.Staleness() != 0 {
+		t.Errorf("LastError() = %v, Staleness() = %v, want nil and 0", cm.LastError(), cm.Staleness())
+	}
+
+	source.err = errors.New("read error")
+	_ = cm.reload()
+	staleSince := cm.ReloadStatus().StaleSince
+	_ = cm.reload()
+	if !errors.Is(cm.LastError(), source.err) {
+		t.Errorf("LastError() = %v, want %v", cm.LastError(), source.err)
+	}
+	if got := cm.ReloadStatus().StaleSince; got.IsZero() || !got.Equal(staleSince) {
+		t.Errorf("StaleSince = %v, want the time of the first failure %v", got, staleSince)
+	}
+	if got := cm.Config().(*TestConfig).Int; got != 1 {
+		t.Errorf("Config().Int = %d, want the last known good 1", got)
+	}
+	if status := cm.Status(); !status.Ready() || status.Staleness <= 0 {
+		t.Errorf("Status() = %+v, want ready with positive staleness", status)
+	}
+
+	// Pretend the reloads have been failing for longer than the max staleness.
+	cm.mu.Lock()
+	cm.reloadStatus.StaleSince = time.Now().Add(-2 * time.Hour)
+	cm.mu.Unlock()
+	if status := cm.Status(); status.Ready() {
+		t.Errorf("Status().Ready() = true, want false after staleness %v", status.Staleness)
+	}
+
+	source.err = nil
+	if err := cm.reload(); err != nil {
+		t.Fatalf("reload() error = %v", err)
+	}
+	if status := cm.Status(); !status.Ready() || status.Staleness != 0 || cm.LastError() != nil {
+		t.Errorf("Status() after recovery = %+v, want ready without staleness", status)
+	}
+}
+
+func TestConfigManager_Ready(t *testing.T) {
+	t.Parallel()
+
+	source := &fakeSource{data: []byte(`{"int": "invalid"}`)}
+	cm, err := NewConfigManager(testConfigConstructor, func(cm *ConfigManager) error {
+		cm.AddLoader(Loader{Source: source, Formatter: NewJSONFormatter()})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("NewConfigManager() error = %v", err)
+	}
+	ready := cm.Ready()
+
+	if err := cm.Start(); err == nil {
+		t.Fatalf("Start() error = nil, want error")
+	}
+	select {
+	case <-ready:
+		t.Fatalf("Ready() is closed after a failed load")
+	default:
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := cm.WaitForFirstLoad(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("WaitForFirstLoad() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	source.data = []byte(`{"int": 1}`)
+	started := make(chan error, 1)
+	go func() { started <- cm.Start() }()
+	if err := cm.WaitForFirstLoad(context.Background()); err != nil {
+		t.Fatalf("WaitForFirstLoad() error = %v", err)
+	}
+	if err := <-started; err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer cm.MustStop()
+	if got := cm.Config().(*TestConfig).Int; got != 1 {
+		t.Errorf("Config().Int = %d, want 1", got)
+	}
+	<-ready
+}
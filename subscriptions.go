@@ -0,0 +1,359 @@
+package confgo
+
+import (
+	"cmp"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// SubscriberFunc is a function called after a new configuration has been swapped in.
+// oldCfg is the previous configuration (nil on the initial load) and newCfg is the one returned by Config from now on.
+type SubscriberFunc func(oldCfg, newCfg any)
+
+// ChangeFunc is a function called with the configuration change after a new configuration has been swapped in.
+type ChangeFunc func(change ConfigChange)
+
+type subscriber struct {
+	fn       SubscriberFunc
+	onChange ChangeFunc
+}
+
+// Subscribe registers fn to be called after every successful config reload which swapped the configuration.
+// Subscribers are called synchronously in the order of subscription.
+// The returned function removes the subscription, it is safe to call it multiple times.
+func (cm *ConfigManager) Subscribe(fn SubscriberFunc) func() {
+	return cm.subscribe(&subscriber{fn: fn, onChange: nil})
+}
+
+// OnChange registers fn to be called, as Subscribe does, with the change including the paths of the changed fields,
+// so fn may react only to the parts of the configuration it cares about.
+// The returned function removes the subscription, it is safe to call it multiple times.
+func (cm *ConfigManager) OnChange(fn ChangeFunc) func() {
+	return cm.subscribe(&subscriber{fn: nil, onChange: fn})
+}
+
+func (cm *ConfigManager) subscribe(sub *subscriber) func() {
+	cm.subMu.Lock()
+	// The handoff is checked under the lock, so the subscription cannot be added after the subscribers are moved.
+	if next := cm.handedOff.Load(); next != nil {
+		cm.subMu.Unlock()
+		return next.subscribe(sub)
+	}
+	cm.subscribers = append(cm.subscribers, sub)
+	cm.subMu.Unlock()
+	return func() {
+		cm.unsubscribe(sub)
+	}
+}
+
+// unsubscribe removes the subscription from the manager and the managers it has been handed off to.
+func (cm *ConfigManager) unsubscribe(sub *subscriber) {
+	cm.subMu.Lock()
+	cm.subscribers = slices.DeleteFunc(cm.subscribers, func(s *subscriber) bool { return s == sub })
+	cm.subMu.Unlock()
+	if next := cm.handedOff.Load(); next != nil {
+		next.unsubscribe(sub)
+	}
+}
+
+// deliverSwap logs the diff of the swapped configuration in development mode and notifies the subscribers.
+// Concurrent reloads swap the configurations in version order but may finish in any order, so swaps are queued
+// and delivered in version order by the caller already delivering them, and a swap older than the last delivered
+// one is dropped. A reload started by a subscriber only queues its swap, so it does not wait for itself.
+func (cm *ConfigManager) deliverSwap(swap *configSwap) {
+	cm.notifyMu.Lock()
+	cm.pendingSwaps = append(cm.pendingSwaps, swap)
+	if cm.notifying {
+		cm.notifyMu.Unlock()
+		return
+	}
+	cm.notifying = true
+	for len(cm.pendingSwaps) > 0 {
+		swaps := cm.pendingSwaps
+		cm.pendingSwaps = nil
+		cm.notifyMu.Unlock()
+		slices.SortFunc(swaps, func(a, b *configSwap) int { return cmp.Compare(a.version, b.version) })
+		for _, s := range swaps {
+			if s.version <= cm.notifiedVersion {
+				continue
+			}
+			cm.notifiedVersion = s.version
+			if cm.devMode {
+				cm.logDiff(s.prev, s.next)
+			}
+			cm.notifySubscribers(s.prev, s.next)
+		}
+		cm.notifyMu.Lock()
+	}
+	cm.notifying = false
+	cm.notifyMu.Unlock()
+}
+
+func (cm *ConfigManager) notifySubscribers(oldCfg, newCfg any) {
+	cm.subMu.Lock()
+	subs := slices.Clone(cm.subscribers)
+	cm.subMu.Unlock()
+	cm.notify(subs, oldCfg, newCfg)
+}
+
+// notify calls the subscribers with the configuration change. The configuration is already swapped,
+// so a panic of a subscriber is only logged and counted, and the subscribers after it are still notified.
+func (cm *ConfigManager) notify(subs []*subscriber, oldCfg, newCfg any) {
+	var change *ConfigChange
+	for _, s := range subs {
+		switch {
+		case s.fn != nil:
+			_ = cm.runCallback("subscriber", func() { s.fn(oldCfg, newCfg) })
+		case s.onChange != nil:
+			// The diff is computed once and only if someone needs it.
+			if change == nil {
+				change = newConfigChange(oldCfg, newCfg)
+			}
+			_ = cm.runCallback("subscriber", func() { s.onChange(*change) })
+		}
+	}
+}
+
+const defaultChannelBuffer = 16
+
+// ConfigChange is a configuration change delivered to channel subscribers.
+type ConfigChange struct {
+	// Old is the previous configuration, nil on the initial load.
+	Old any
+	New any
+	// Paths are the dotted paths of the leaf fields which differ between Old and New, e.g. "server.port",
+	// in the order of declaration. It is nil on the initial load.
+	Paths []string
+}
+
+func newConfigChange(oldCfg, newCfg any) *ConfigChange {
+	change := &ConfigChange{Old: oldCfg, New: newCfg, Paths: nil}
+	if oldCfg == nil {
+		return change
+	}
+	changes := diffConfigs(oldCfg, newCfg)
+	change.Paths = make([]string, 0, len(changes))
+	for _, c := range changes {
+		change.Paths = append(change.Paths, c.path)
+	}
+	return change
+}
+
+// Changed reports whether the field at the dotted path, a field nested in it or a section containing it
+// has changed. Every field is reported as changed on the initial load.
+func (c ConfigChange) Changed(path string) bool {
+	return c.Old == nil || pathsOverlap([]string{path}, c.Paths)
+}
+
+// OverflowPolicy defines what a channel subscription does with a change when its buffer is full.
+type OverflowPolicy int
+
+const (
+	// OverflowDropOldest discards the oldest buffered change to make room for the new one,
+	// so the consumer always sees the latest configuration.
+	OverflowDropOldest OverflowPolicy = iota
+	// OverflowDropNewest discards the new change and keeps the buffered ones.
+	OverflowDropNewest
+	// OverflowBlock blocks the reload until the consumer receives a change or unsubscribes.
+	OverflowBlock
+)
+
+// ChannelOption configures a channel subscription.
+type ChannelOption func(cs *channelSubscription)
+
+// ChannelBuffer sets the buffer size of the channel, 16 by default. Non-positive sizes are replaced with 1.
+func ChannelBuffer(size int) ChannelOption {
+	return func(cs *channelSubscription) {
+		cs.buffer = max(size, 1)
+	}
+}
+
+// ChannelOverflow sets the policy applied when the channel buffer is full, OverflowDropOldest by default.
+func ChannelOverflow(policy OverflowPolicy) ChannelOption {
+	return func(cs *channelSubscription) {
+		cs.policy = policy
+	}
+}
+
+type channelSubscription struct {
+	cm     *ConfigManager
+	buffer int
+	policy OverflowPolicy
+	ch     chan ConfigChange
+	done   chan struct{}
+	// mu serializes deliveries and closing of ch.
+	mu     sync.Mutex
+	closed bool
+}
+
+// SubscribeChan returns a bounded channel receiving every configuration change, as Subscribe does.
+// When the consumer falls behind and the buffer is full, the overflow policy is applied and dropped changes
+// are counted in Stats, so a slow consumer never causes unbounded memory growth.
+// The returned function removes the subscription and closes the channel, it is safe to call it multiple times.
+func (cm *ConfigManager) SubscribeChan(opts ...ChannelOption) (<-chan ConfigChange, func()) {
+	cs := &channelSubscription{
+		cm:     cm,
+		buffer: defaultChannelBuffer,
+		policy: OverflowDropOldest,
+		ch:     nil,
+		done:   make(chan struct{}),
+		mu:     sync.Mutex{},
+		closed: false,
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(cs)
+		}
+	}
+	cs.ch = make(chan ConfigChange, cs.buffer)
+
+	cm.active().chanSubscribers.Add(1)
+	unsubscribe := cm.OnChange(cs.deliver)
+	var once sync.Once
+	return cs.ch, func() {
+		once.Do(func() {
+			unsubscribe()
+			close(cs.done)
+			cs.mu.Lock()
+			defer cs.mu.Unlock()
+			cs.closed = true
+			close(cs.ch)
+			cm.active().chanSubscribers.Add(-1)
+		})
+	}
+}
+
+func (cs *channelSubscription) deliver(change ConfigChange) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	if cs.closed {
+		return
+	}
+
+	switch cs.policy {
+	case OverflowBlock:
+		select {
+		case cs.ch <- change:
+			cs.cm.active().chanDelivered.Add(1)
+		case <-cs.done:
+			cs.cm.active().chanDropped.Add(1)
+		}
+	case OverflowDropNewest:
+		select {
+		case cs.ch <- change:
+			cs.cm.active().chanDelivered.Add(1)
+		default:
+			cs.cm.active().chanDropped.Add(1)
+		}
+	default:
+		for {
+			select {
+			case cs.ch <- change:
+				cs.cm.active().chanDelivered.Add(1)
+				return
+			default:
+			}
+			select {
+			case <-cs.ch:
+				cs.cm.active().chanDropped.Add(1)
+			default:
+			}
+		}
+	}
+}
+
+// SubscribeGroup registers fn to be called, as Subscribe does, only when the initial configuration is loaded
+// or a field of the group changes. Fields are added to groups with the comma-separated group tag,
+// e.g. `group:"network,tls"`; tagging a nested struct adds all of its fields to the group.
+//
+// It returns an error if no field of the config type belongs to the group.
+// The returned function removes the subscription, it is safe to call it multiple times.
+func (cm *ConfigManager) SubscribeGroup(group string, fn SubscriberFunc) (func(), error) {
+	if cm.constructor == nil {
+		return nil, ErrConstructorIsNil
+	}
+	paths := groupPaths(reflect.TypeOf(cm.constructor()), "", make(map[reflect.Type]bool))[group]
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownGroup, group)
+	}
+	return cm.OnChange(func(change ConfigChange) {
+		if change.Old == nil || pathsOverlap(paths, change.Paths) {
+			fn(change.Old, change.New)
+		}
+	}), nil
+}
+
+// FieldChangeFunc is a function called with the previous and the new value of a changed config field.
+// A value is nil if a section containing the field is nil.
+type FieldChangeFunc func(oldValue, newValue any)
+
+// OnFieldChange registers fn to be called, as Subscribe does, only when the value of the field at the dotted path,
+// e.g. "server.port", changes across reloads. It is not called on the initial load.
+//
+// It returns an error wrapping ErrInvalidFieldPath if the config type has no field at the path.
+// The returned function removes the subscription, it is safe to call it multiple times.
+func (cm *ConfigManager) OnFieldChange(path string, fn FieldChangeFunc) (func(), error) {
+	if cm.constructor == nil {
+		return nil, ErrConstructorIsNil
+	}
+	if _, err := fieldByPath(reflect.ValueOf(cm.constructor()), path, true); err != nil {
+		return nil, err
+	}
+	return cm.OnChange(func(change ConfigChange) {
+		if change.Old == nil || !pathsOverlap([]string{path}, change.Paths) {
+			return
+		}
+		oldValue, newValue := valueByPath(change.Old, path), valueByPath(change.New, path)
+		// A containing section may have changed without touching the field.
+		if !reflect.DeepEqual(oldValue, newValue) {
+			fn(oldValue, newValue)
+		}
+	}), nil
+}
+
+// groupPaths returns dotted paths of the fields of the struct type tagged with every group.
+// The types being visited are skipped, so the fields of recursive types are listed at their first level only.
+func groupPaths(typ reflect.Type, prefix string, visiting map[reflect.Type]bool) map[string][]string {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	res := make(map[string][]string)
+	if typ.Kind() != reflect.Struct || visiting[typ] {
+		return res
+	}
+	visiting[typ] = true
+	defer delete(visiting, typ)
+	for i := range typ.NumField() {
+		sf := typ.Field(i)
+		key, ok := fieldKey(sf)
+		if !ok {
+			continue
+		}
+		path := joinPath(prefix, key)
+		for _, group := range strings.Split(sf.Tag.Get("group"), ",") {
+			if group = strings.TrimSpace(group); group != "" {
+				res[group] = append(res[group], path)
+			}
+		}
+		for group, paths := range groupPaths(sf.Type, path, visiting) {
+			res[group] = append(res[group], paths...)
+		}
+	}
+	return res
+}
+
+// pathsOverlap reports whether any of the changed paths addresses a field at one of the paths,
+// a field nested in it or a section containing it.
+func pathsOverlap(paths, changed []string) bool {
+	for _, c := range changed {
+		for _, p := range paths {
+			if c == p || strings.HasPrefix(c, p+".") || strings.HasPrefix(p, c+".") {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,465 @@
+package confgo
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestConfigManager_Subscribe(t *testing.T) {
+	t.Parallel()
+
+	source := &fakeSource{data: []byte("test")}
+	formatter := &fakeFormatter{data: TestConfig{Int: 1}}
+	cm := newTestConfigManager(testConfigManagerFields{
+		constructor: testConfigConstructor,
+		loaders:     []Loader{{Source: source, Formatter: formatter}},
+	})
+
+	type call struct {
+		oldCfg any
+		newCfg any
+	}
+	var first, second []call
+	unsubscribe := cm.Subscribe(func(oldCfg, newCfg any) {
+		first = append(first, call{oldCfg: oldCfg, newCfg: newCfg})
+	})
+	cm.Subscribe(func(oldCfg, newCfg any) {
+		second = append(second, call{oldCfg: oldCfg, newCfg: newCfg})
+	})
+
+	if err := cm.reload(); err != nil {
+		t.Fatalf("reload() error = %v", err)
+	}
+	formatter.data = TestConfig{Int: 2}
+	if err := cm.reload(); err != nil {
+		t.Fatalf("reload() error = %v", err)
+	}
+	unsubscribe()
+	unsubscribe()
+	formatter.data = TestConfig{Int: 3}
+	if err := cm.reload(); err != nil {
+		t.Fatalf("reload() error = %v", err)
+	}
+	formatter.err = errors.New("test error")
+	if err := cm.reload(); err == nil {
+		t.Fatalf("reload() error = nil, want error")
+	}
+
+	wantFirst := []call{
+		{oldCfg: nil, newCfg: &TestConfig{Int: 1}},
+		{oldCfg: &TestConfig{Int: 1}, newCfg: &TestConfig{Int: 2}},
+	}
+	if !reflect.DeepEqual(first, wantFirst) {
+		t.Errorf("first subscriber calls = %v, want %v", first, wantFirst)
+	}
+	wantSecond := append(wantFirst, call{oldCfg: &TestConfig{Int: 2}, newCfg: &TestConfig{Int: 3}})
+	if !reflect.DeepEqual(second, wantSecond) {
+		t.Errorf("second subscriber calls = %v, want %v", second, wantSecond)
+	}
+}
+
+func TestConfigManager_Subscribe_Reload(t *testing.T) {
+	t.Parallel()
+
+	cm := newTestConfigManager(testConfigManagerFields{
+		constructor: testConfigConstructor,
+		loaders:     []Loader{{Source: &fakeSource{data: []byte(`{"int": 1}`)}, Formatter: NewJSONFormatter()}},
+	})
+	if err := cm.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer cm.MustStop()
+	// Subscribers are notified after the reload is done, so they may change the configuration themselves.
+	cm.Subscribe(func(_, newCfg any) {
+		if newCfg.(*TestConfig).Int == 2 {
+			if err := cm.Set("int", 3); err != nil {
+				t.Errorf("Set() error = %v", err)
+			}
+		}
+	})
+	done := make(chan error, 1)
+	go func() {
+		done <- cm.Set("int", 2)
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Set() error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Set() from a subscriber has not returned")
+	}
+	if got := cm.Config().(*TestConfig).Int; got != 3 {
+		t.Errorf("Config().Int = %d, want 3", got)
+	}
+}
+
+// countingSource returns a config with the int field incremented on every read.
+type countingSource struct {
+	mu    sync.Mutex
+	reads int
+}
+
+func (s *countingSource) Read() ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reads++
+	return []byte(fmt.Sprintf(`{"int": %d}`, s.reads)), nil
+}
+
+func TestConfigManager_Subscribe_ConcurrentReloads(t *testing.T) {
+	t.Parallel()
+
+	cm := newTestConfigManager(testConfigManagerFields{
+		constructor: testConfigConstructor,
+		loaders:     []Loader{{Source: &countingSource{mu: sync.Mutex{}, reads: 0}, Formatter: NewJSONFormatter()}},
+	})
+	// The subscriber is slow and guards its state itself, so concurrent or reordered notifications
+	// are caught by the checks below rather than by the race detector only.
+	var mu sync.Mutex
+	var notified []int
+	cm.Subscribe(func(_, newCfg any) {
+		time.Sleep(100 * time.Microsecond)
+		mu.Lock()
+		defer mu.Unlock()
+		notified = append(notified, newCfg.(*TestConfig).Int)
+	})
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 20 {
+				if err := cm.reload(); err != nil {
+					t.Errorf("reload() error = %v", err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if !slices.IsSorted(notified) || len(slices.Compact(slices.Clone(notified))) != len(notified) {
+		t.Errorf("subscriber notified with versions %v, want increasing versions", notified)
+	}
+	if last := notified[len(notified)-1]; last != cm.Config().(*TestConfig).Int {
+		t.Errorf("last notified version = %d, want %d", last, cm.Config().(*TestConfig).Int)
+	}
+}
+
+func TestConfigManager_OnChange(t *testing.T) {
+	t.Parallel()
+
+	formatter := &fakeFormatter{data: TestConfig{Int: 1}}
+	cm := newTestConfigManager(testConfigManagerFields{
+		constructor: testConfigConstructor,
+		loaders:     []Loader{{Source: &fakeSource{data: []byte("test")}, Formatter: formatter}},
+	})
+
+	var changes []ConfigChange
+	unsubscribe := cm.OnChange(func(change ConfigChange) {
+		changes = append(changes, change)
+	})
+	defer unsubscribe()
+
+	for _, data := range []TestConfig{
+		{Int: 1},
+		{Int: 1, Inner: testInnerConfig{String: "s"}, Slice: []string{"a"}},
+		{Int: 1, Inner: testInnerConfig{String: "s"}, Slice: []string{"a"}, InnerPtr: &testInnerConfig{Int: 2}},
+		{Int: 1, Inner: testInnerConfig{String: "s"}, Slice: []string{"a"}, InnerPtr: &testInnerConfig{Int: 2}},
+	} {
+		formatter.data = data
+		if err := cm.reload(); err != nil {
+			t.Fatalf("reload() error = %v", err)
+		}
+	}
+
+	gotPaths := make([][]string, 0, len(changes))
+	for _, change := range changes {
+		gotPaths = append(gotPaths, change.Paths)
+	}
+	wantPaths := [][]string{nil, {"inner.string", "slice"}, {"inner_ptr"}, {}}
+	if !reflect.DeepEqual(gotPaths, wantPaths) {
+		t.Fatalf("changed paths = %q, want %q", gotPaths, wantPaths)
+	}
+
+	tests := []struct {
+		change int
+		path   string
+		want   bool
+	}{
+		{change: 0, path: "int", want: true},
+		{change: 1, path: "inner", want: true},
+		{change: 1, path: "inner.string", want: true},
+		{change: 1, path: "inner.int", want: false},
+		{change: 1, path: "int", want: false},
+		{change: 2, path: "inner_ptr.int", want: true},
+		{change: 3, path: "int", want: false},
+	}
+	for _, tt := range tests {
+		if got := changes[tt.change].Changed(tt.path); got != tt.want {
+			t.Errorf("change %d: Changed(%q) = %v, want %v", tt.change, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestConfigManager_OnFieldChange(t *testing.T) {
+	t.Parallel()
+
+	formatter := &fakeFormatter{data: TestConfig{Int: 1}}
+	cm := newTestConfigManager(testConfigManagerFields{
+		constructor: testConfigConstructor,
+		loaders:     []Loader{{Source: &fakeSource{data: []byte("test")}, Formatter: formatter}},
+	})
+
+	type call struct {
+		oldValue any
+		newValue any
+	}
+	var intCalls, innerCalls []call
+	unsubscribe, err := cm.OnFieldChange("int", func(oldValue, newValue any) {
+		intCalls = append(intCalls, call{oldValue: oldValue, newValue: newValue})
+	})
+	if err != nil {
+		t.Fatalf("OnFieldChange() error = %v", err)
+	}
+	defer unsubscribe()
+	if _, err := cm.OnFieldChange("inner_ptr.string", func(oldValue, newValue any) {
+		innerCalls = append(innerCalls, call{oldValue: oldValue, newValue: newValue})
+	}); err != nil {
+		t.Fatalf("OnFieldChange() error = %v", err)
+	}
+	if _, err := cm.OnFieldChange("inner.unknown", func(_, _ any) {}); !errors.Is(err, ErrInvalidFieldPath) {
+		t.Errorf("OnFieldChange() error = %v, want %v", err, ErrInvalidFieldPath)
+	}
+
+	for _, data := range []TestConfig{
+		{Int: 1},
+		{Int: 2},
+		{Int: 2, Slice: []string{"a"}},
+		{Int: 2, InnerPtr: &testInnerConfig{Int: 1}},
+		{Int: 2, InnerPtr: &testInnerConfig{Int: 1, String: "s"}},
+		{Int: 3},
+	} {
+		formatter.data = data
+		if err := cm.reload(); err != nil {
+			t.Fatalf("reload() error = %v", err)
+		}
+	}
+
+	if want := []call{{oldValue: 1, newValue: 2}, {oldValue: 2, newValue: 3}}; !reflect.DeepEqual(intCalls, want) {
+		t.Errorf("int calls = %v, want %v", intCalls, want)
+	}
+	want := []call{{oldValue: nil, newValue: ""}, {oldValue: "", newValue: "s"}, {oldValue: "s", newValue: nil}}
+	if !reflect.DeepEqual(innerCalls, want) {
+		t.Errorf("inner_ptr.string calls = %v, want %v", innerCalls, want)
+	}
+}
+
+func TestConfigManager_SubscribeChan(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		policy        OverflowPolicy
+		wantInts      []int
+		wantDelivered uint64
+		wantDropped   uint64
+	}{
+		{
+			name:          "drop oldest",
+			policy:        OverflowDropOldest,
+			wantInts:      []int{3, 4},
+			wantDelivered: 4,
+			wantDropped:   2,
+		},
+		{
+			name:          "drop newest",
+			policy:        OverflowDropNewest,
+			wantInts:      []int{1, 2},
+			wantDelivered: 2,
+			wantDropped:   2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			formatter := &fakeFormatter{data: TestConfig{}}
+			cm := newTestConfigManager(testConfigManagerFields{
+				constructor: testConfigConstructor,
+				loaders:     []Loader{{Source: &fakeSource{data: []byte("test")}, Formatter: formatter}},
+			})
+			ch, unsubscribe := cm.SubscribeChan(ChannelBuffer(2), ChannelOverflow(tt.policy))
+			for i := 1; i <= 4; i++ {
+				formatter.data = TestConfig{Int: i}
+				if err := cm.reload(); err != nil {
+					t.Fatalf("reload() error = %v", err)
+				}
+			}
+
+			wantStats := Stats{
+				Subscribers:        1,
+				ChannelSubscribers: 1,
+				ChannelDelivered:   tt.wantDelivered,
+				ChannelDropped:     tt.wantDropped,
+			}
+			if got := cm.Stats(); got != wantStats {
+				t.Errorf("Stats() = %+v, want %+v", got, wantStats)
+			}
+
+			unsubscribe()
+			unsubscribe()
+			var gotInts []int
+			for change := range ch {
+				gotInts = append(gotInts, change.New.(*TestConfig).Int)
+				if change.Old != nil && !reflect.DeepEqual(change.Paths, []string{"int"}) {
+					t.Errorf("change.Paths = %q, want [\"int\"]", change.Paths)
+				}
+			}
+			if !reflect.DeepEqual(gotInts, tt.wantInts) {
+				t.Errorf("received configs = %v, want %v", gotInts, tt.wantInts)
+			}
+			if got := cm.Stats(); got.Subscribers != 0 || got.ChannelSubscribers != 0 {
+				t.Errorf("Stats() after unsubscribe = %+v, want no subscribers", got)
+			}
+		})
+	}
+}
+
+func TestConfigManager_SubscribeChan_Block(t *testing.T) {
+	t.Parallel()
+
+	formatter := &fakeFormatter{data: TestConfig{Int: 1}}
+	cm := newTestConfigManager(testConfigManagerFields{
+		constructor: testConfigConstructor,
+		loaders:     []Loader{{Source: &fakeSource{data: []byte("test")}, Formatter: formatter}},
+	})
+	ch, unsubscribe := cm.SubscribeChan(ChannelBuffer(1), ChannelOverflow(OverflowBlock))
+	if err := cm.reload(); err != nil {
+		t.Fatalf("reload() error = %v", err)
+	}
+
+	reloaded := make(chan struct{})
+	go func() {
+		defer close(reloaded)
+		_ = cm.reload()
+	}()
+	select {
+	case <-reloaded:
+		t.Fatal("reload() has not blocked on the full channel")
+	case <-time.After(50 * time.Millisecond):
+	}
+	<-ch
+	select {
+	case <-reloaded:
+	case <-time.After(time.Second):
+		t.Fatal("reload() is still blocked after the change has been received")
+	}
+
+	go func() {
+		_ = cm.reload()
+	}()
+	time.Sleep(50 * time.Millisecond)
+	unsubscribe()
+	if got := cm.Stats(); got.ChannelDelivered != 2 {
+		t.Errorf("Stats().ChannelDelivered = %d, want 2", got.ChannelDelivered)
+	}
+}
+
+type testGroupConfig struct {
+	Port    int `json:"port" group:"network"`
+	Timeout int `json:"timeout" group:"network, limits"`
+	Workers int `json:"workers"`
+	TLS     *struct {
+		Cert string `json:"cert"`
+	} `json:"tls" group:"network"`
+}
+
+func TestConfigManager_SubscribeGroup(t *testing.T) {
+	t.Parallel()
+
+	formatter := &fakeFormatter{data: testGroupConfig{Port: 80}}
+	cm := newTestConfigManager(testConfigManagerFields{
+		constructor: func() any { return new(testGroupConfig) },
+		loaders:     []Loader{{Source: &fakeSource{data: []byte("test")}, Formatter: formatter}},
+	})
+
+	var network, limits []*testGroupConfig
+	unsubscribe, err := cm.SubscribeGroup("network", func(_, newCfg any) {
+		network = append(network, newCfg.(*testGroupConfig))
+	})
+	if err != nil {
+		t.Fatalf("SubscribeGroup() error = %v", err)
+	}
+	defer unsubscribe()
+	if _, err := cm.SubscribeGroup("limits", func(_, newCfg any) {
+		limits = append(limits, newCfg.(*testGroupConfig))
+	}); err != nil {
+		t.Fatalf("SubscribeGroup() error = %v", err)
+	}
+	if _, err := cm.SubscribeGroup("unknown", func(_, _ any) {}); !errors.Is(err, ErrUnknownGroup) {
+		t.Errorf("SubscribeGroup() error = %v, want %v", err, ErrUnknownGroup)
+	}
+
+	for _, data := range []testGroupConfig{
+		{Port: 80},
+		{Port: 80, Workers: 4},
+		{Port: 8080, Workers: 4},
+		{Port: 8080, Workers: 4, TLS: &struct {
+			Cert string `json:"cert"`
+		}{Cert: "cert.pem"}},
+		{Port: 8080, Workers: 4, Timeout: 5},
+	} {
+		formatter.data = data
+		if err := cm.reload(); err != nil {
+			t.Fatalf("reload() error = %v", err)
+		}
+	}
+
+	gotPorts := make([]int, 0, len(network))
+	for _, cfg := range network {
+		gotPorts = append(gotPorts, cfg.Port)
+	}
+	// The initial load, the port change, the TLS section added and then removed along with the timeout change.
+	if want := []int{80, 8080, 8080, 8080}; !reflect.DeepEqual(gotPorts, want) {
+		t.Errorf("network group notified with ports %v, want %v", gotPorts, want)
+	}
+	if len(limits) != 2 || limits[1].Timeout != 5 {
+		t.Errorf("limits group notified with %v, want the initial load and the timeout change", limits)
+	}
+}
+
+func TestConfigManager_SubscribeGroup_RecursiveType(t *testing.T) {
+	t.Parallel()
+
+	type node struct {
+		Name string `json:"name" group:"names"`
+		Next *node  `json:"next"`
+	}
+	formatter := &fakeFormatter{data: node{Name: "a"}}
+	cm := newTestConfigManager(testConfigManagerFields{
+		constructor: func() any { return new(node) },
+		loaders:     []Loader{{Source: &fakeSource{data: []byte("test")}, Formatter: formatter}},
+	})
+	var notified int
+	unsubscribe, err := cm.SubscribeGroup("names", func(_, _ any) { notified++ })
+	if err != nil {
+		t.Fatalf("SubscribeGroup() error = %v", err)
+	}
+	defer unsubscribe()
+	for _, data := range []node{{Name: "a"}, {Name: "b"}, {Name: "b", Next: &node{Name: "c"}}} {
+		formatter.data = data
+		if err := cm.reload(); err != nil {
+			t.Fatalf("reload() error = %v", err)
+		}
+	}
+	if notified != 2 {
+		t.Errorf("names group notified %d times, want 2", notified)
+	}
+}
@@ -0,0 +1,38 @@
+package confgo
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+)
+
+var _ Transformer = (*TemplateTransformer)(nil)
+
+// TemplateTransformer is a transformer that renders raw config data as a text/template,
+// enabling computed values and conditionals in config files, e.g.
+//
+//	port: {{ if .Production }}443{{ else }}8080{{ end }}
+//
+// Referencing a missing key of a map data is reported as an error.
+type TemplateTransformer struct {
+	data  any
+	funcs template.FuncMap
+}
+
+// NewTemplateTransformer creates a template transformer executing templates with the data,
+// which may use the functions from funcs in addition to the predefined ones.
+func NewTemplateTransformer(data any, funcs template.FuncMap) *TemplateTransformer {
+	return &TemplateTransformer{data: data, funcs: funcs}
+}
+
+func (tt *TemplateTransformer) Transform(data []byte) ([]byte, error) {
+	tmpl, err := template.New("config").Funcs(tt.funcs).Option("missingkey=error").Parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("parse template: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, tt.data); err != nil {
+		return nil, fmt.Errorf("execute template: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,91 @@
+package confgo
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"text/template"
+)
+
+func TestTemplateTransformer_Transform(t *testing.T) {
+	t.Parallel()
+
+	funcs := template.FuncMap{"upper": strings.ToUpper}
+	tests := []struct {
+		name    string
+		data    any
+		tmpl    string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "values and conditionals",
+			data: map[string]any{"Production": true, "Name": "api"},
+			tmpl: "name: {{ upper .Name }}\nport: {{ if .Production }}443{{ else }}8080{{ end }}\n",
+			want: "name: API\nport: 443\n",
+		},
+		{
+			name: "struct data",
+			data: struct{ Replicas int }{Replicas: 3},
+			tmpl: `{"workers": {{ .Replicas }}}`,
+			want: `{"workers": 3}`,
+		},
+		{name: "missing key", data: map[string]any{}, tmpl: "name: {{ .Name }}", wantErr: true},
+		{name: "invalid template", data: nil, tmpl: "name: {{ .Name", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := NewTemplateTransformer(tt.data, funcs).Transform([]byte(tt.tmpl))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Transform() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Transform() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigManager_Transformers(t *testing.T) {
+	t.Parallel()
+
+	type config struct {
+		Host string `json:"host"`
+		Port int    `json:"port"`
+	}
+
+	cm, err := NewConfigManagerFor[config](func(cm *ConfigManager) error {
+		cm.AddLoader(Loader{
+			Source:       &fakeSource{data: []byte(`{"host": "{{ .Host }}", "port": {{ .Port }}}`)},
+			Transformers: []Transformer{NewTemplateTransformer(map[string]any{"Host": "db.local", "Port": 5432}, nil)},
+			Formatter:    NewJSONFormatter(),
+		})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("NewConfigManagerFor() error = %v", err)
+	}
+	if err := cm.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer cm.MustStop()
+
+	want := &config{Host: "db.local", Port: 5432}
+	if got := cm.Config(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Config() = %+v, want %+v", got, want)
+	}
+
+	cm, err = NewConfigManagerFor[config](func(cm *ConfigManager) error {
+		cm.AddLoader(Loader{Source: &fakeSource{}, Transformers: []Transformer{nil}, Formatter: NewJSONFormatter()})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("NewConfigManagerFor() error = %v", err)
+	}
+	if err := cm.Start(); !errors.Is(err, ErrTransformerIsNil) {
+		t.Errorf("Start() error = %v, want %v", err, ErrTransformerIsNil)
+	}
+}
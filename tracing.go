@@ -0,0 +1,62 @@
+package confgo
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Names of the spans started by the manager.
+const (
+	SpanReload    = "confgo.reload"
+	SpanLoader    = "confgo.loader"
+	SpanRead      = "confgo.read"
+	SpanUnmarshal = "confgo.unmarshal"
+	SpanMerge     = "confgo.merge"
+	SpanValidate  = "confgo.validate"
+)
+
+// EndSpanFunc ends a span, recording err if it is not nil.
+type EndSpanFunc func(err error)
+
+// ReloadTracer traces configuration reloads. Every reload is wrapped in a SpanReload span with a SpanLoader child
+// span per loader, which in turn has SpanRead, SpanUnmarshal and SpanMerge child spans, so slow remote sources
+// are visible in traces. Validation is traced with a SpanValidate span.
+//
+// Implementations usually adapt a tracing library, e.g. for OpenTelemetry:
+//
+//	type otelTracer struct{ tracer trace.Tracer }
+//
+//	func (t otelTracer) StartSpan(ctx context.Context, name string, attrs ...slog.Attr) (context.Context, confgo.EndSpanFunc) {
+//		ctx, span := t.tracer.Start(ctx, name)
+//		for _, a := range attrs {
+//			span.SetAttributes(attribute.String(a.Key, a.Value.String()))
+//		}
+//		return ctx, func(err error) {
+//			if err != nil {
+//				span.RecordError(err)
+//				span.SetStatus(codes.Error, err.Error())
+//			}
+//			span.End()
+//		}
+//	}
+type ReloadTracer interface {
+	// StartSpan starts a span with the name and attributes as a child of the span in ctx
+	// and returns the context holding the new span along with the function ending it.
+	StartSpan(ctx context.Context, name string, attrs ...slog.Attr) (context.Context, EndSpanFunc)
+}
+
+// WithTracer makes the manager trace every reload with the tracer.
+func WithTracer(tracer ReloadTracer) Option {
+	return func(cm *ConfigManager) error {
+		cm.tracer = tracer
+		return nil
+	}
+}
+
+// startSpan starts a span with the tracer set by WithTracer, if any.
+func (cm *ConfigManager) startSpan(ctx context.Context, name string, attrs ...slog.Attr) (context.Context, EndSpanFunc) {
+	if cm.tracer == nil {
+		return ctx, func(error) {}
+	}
+	return cm.tracer.StartSpan(ctx, name, attrs...)
+}
@@ -0,0 +1,76 @@
+package confgo
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type fakeSpanKey struct{}
+
+// fakeTracer records ended spans as "parent/name" paths with " error" appended to failed ones.
+type fakeTracer struct {
+	mu    sync.Mutex
+	spans []string
+}
+
+func (t *fakeTracer) StartSpan(ctx context.Context, name string, attrs ...slog.Attr) (context.Context, EndSpanFunc) {
+	path := name
+	if parent, ok := ctx.Value(fakeSpanKey{}).(string); ok {
+		path = parent + "/" + name
+	}
+	for _, a := range attrs {
+		if a.Key == "source" {
+			path += "[" + a.Value.String() + "]"
+		}
+	}
+	return context.WithValue(ctx, fakeSpanKey{}, path), func(err error) {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		if err != nil {
+			path += " error"
+		}
+		t.spans = append(t.spans, path)
+	}
+}
+
+func TestConfigManager_WithTracer(t *testing.T) {
+	t.Parallel()
+
+	tracer := &fakeTracer{}
+	source := &fakeSource{data: []byte(`{"int": 1}`)}
+	cm, err := NewConfigManager(testConfigConstructor, WithTracer(tracer), func(cm *ConfigManager) error {
+		cm.AddLoader(Loader{Source: source, Formatter: NewJSONFormatter()})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("NewConfigManager() error = %v", err)
+	}
+	if err := cm.reload(); err != nil {
+		t.Fatalf("reload() error = %v", err)
+	}
+	source.err = errors.New("read error")
+	if err := cm.reload(); err == nil {
+		t.Fatalf("reload() error = nil, want error")
+	}
+
+	loader := "confgo.reload/confgo.loader[source *confgo.fakeSource]"
+	want := []string{
+		loader + "/confgo.read",
+		loader + "/confgo.unmarshal",
+		loader + "/confgo.merge",
+		loader,
+		"confgo.reload/confgo.validate",
+		"confgo.reload",
+		loader + "/confgo.read error",
+		loader + " error",
+		"confgo.reload error",
+	}
+	if !reflect.DeepEqual(tracer.spans, want) {
+		t.Errorf("spans =\n%s\nwant\n%s", strings.Join(tracer.spans, "\n"), strings.Join(want, "\n"))
+	}
+}
@@ -0,0 +1,224 @@
+package confgo
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// Bind calls apply with the settings extracted from the current configuration
+// and then every time the extracted settings change.
+// If the manager has not loaded the configuration yet, apply is first called after the initial load.
+// The returned function stops tracking configuration changes.
+func Bind[S comparable](cm *ConfigManager, extract func(cfg any) S, apply func(settings S)) func() {
+	var (
+		mu      sync.Mutex
+		applied bool
+		last    S
+	)
+	update := func(cfg any) {
+		settings := extract(cfg)
+		mu.Lock()
+		defer mu.Unlock()
+		if applied && settings == last {
+			return
+		}
+		applied, last = true, settings
+		apply(settings)
+	}
+
+	unsubscribe := cm.Subscribe(func(_, newCfg any) {
+		update(newCfg)
+	})
+	if cfg := cm.Config(); cfg != nil {
+		update(cfg)
+	}
+	return unsubscribe
+}
+
+// RateLimitSettings is a set of rate.Limiter settings.
+type RateLimitSettings struct {
+	// Limit is the number of events per second. Zero allows no events, rate.Inf allows any number of events.
+	Limit rate.Limit
+	Burst int
+}
+
+// BindRateLimiter keeps limiter limit and burst in sync with the settings extracted from the configuration.
+// Settings are changed at the current time, so the tokens already accumulated by the limiter are kept.
+// The returned function stops tracking configuration changes.
+func BindRateLimiter(cm *ConfigManager, limiter *rate.Limiter, extract func(cfg any) RateLimitSettings) func() {
+	return Bind(cm, extract, func(settings RateLimitSettings) {
+		now := time.Now()
+		limiter.SetLimitAt(now, settings.Limit)
+		limiter.SetBurstAt(now, settings.Burst)
+	})
+}
+
+// CircuitBreakerSettings is a set of tunables common to circuit breaker implementations.
+// Use it with Reloadable to rebuild a breaker of any library when its settings change.
+type CircuitBreakerSettings struct {
+	// MaxRequests is the maximum number of requests allowed to pass through in the half-open state.
+	MaxRequests uint32
+	// Interval is the cyclic period of the closed state to clear the failure counts.
+	Interval time.Duration
+	// Timeout is the period of the open state after which the state becomes half-open.
+	Timeout time.Duration
+	// ConsecutiveFailures is the number of consecutive failures which trips the breaker.
+	ConsecutiveFailures uint32
+	// FailureRatio is the ratio of failed requests which trips the breaker when at least MinRequests were made.
+	FailureRatio float64
+	MinRequests  uint32
+}
+
+// Reloadable holds a value built from the settings extracted from the configuration
+// and builds it again every time the settings change.
+//
+// It suits components that cannot be reconfigured in place, e.g. circuit breakers of most libraries.
+// If the value cannot be built with new settings, the previous value is kept and onError is called.
+type Reloadable[S comparable, T any] struct {
+	value       atomic.Pointer[T]
+	unsubscribe func()
+}
+
+// NewReloadable creates a new Reloadable and builds the initial value from the current configuration.
+// Config manager must be started beforehand. onError may be nil.
+func NewReloadable[S comparable, T any](
+	cm *ConfigManager,
+	extract func(cfg any) S,
+	build func(settings S) (T, error),
+	onError CallbackErrFunc,
+) (*Reloadable[S, T], error) {
+	if cm.Config() == nil {
+		return nil, ErrConfigNotLoaded
+	}
+
+	r := &Reloadable[S, T]{
+		value:       atomic.Pointer[T]{},
+		unsubscribe: nil,
+	}
+	var initErr error
+	r.unsubscribe = Bind(cm, extract, func(settings S) {
+		value, err := build(settings)
+		if err != nil {
+			err = fmt.Errorf("build reloadable value: %w", err)
+			if r.value.Load() == nil {
+				initErr = err
+			} else if onError != nil {
+				onError(err)
+			}
+			return
+		}
+		r.value.Store(&value)
+	})
+	if initErr != nil {
+		r.unsubscribe()
+		return nil, initErr
+	}
+	return r, nil
+}
+
+// Get returns the value built from the latest settings.
+func (r *Reloadable[S, T]) Get() T {
+	return *r.value.Load()
+}
+
+// Stop stops tracking configuration changes. Get keeps returning the last built value.
+func (r *Reloadable[S, T]) Stop() {
+	r.unsubscribe()
+}
+
+// RuntimeSettings is a set of Go runtime tunables. Zero values leave the corresponding tunable at the value
+// it had when the settings were bound, so removing a setting from the configuration restores it.
+type RuntimeSettings struct {
+	// GOMAXPROCS is the maximum number of CPUs executing simultaneously, as runtime.GOMAXPROCS.
+	GOMAXPROCS int
+	// GCPercent is the garbage collection target percentage, as debug.SetGCPercent. Negative disables GC.
+	GCPercent int
+	// MemoryLimit is the soft memory limit in bytes, as debug.SetMemoryLimit.
+	MemoryLimit int64
+}
+
+// Validate checks that the settings are within the bounds accepted by the runtime.
+// It suits to be called from the config Validate method, so invalid settings are rejected on reload.
+func (s RuntimeSettings) Validate() error {
+	errs := make([]error, 0)
+	if s.GOMAXPROCS < 0 {
+		errs = append(errs, fmt.Errorf("%w: GOMAXPROCS %d is negative", ErrInvalidRuntimeSetting, s.GOMAXPROCS))
+	}
+	if s.MemoryLimit < 0 {
+		errs = append(errs, fmt.Errorf("%w: memory limit %d is negative", ErrInvalidRuntimeSetting, s.MemoryLimit))
+	}
+	return errors.Join(errs...)
+}
+
+// BindRuntime applies the Go runtime tunables extracted from the configuration every time they change.
+// Invalid settings are not applied, onError is called instead, it may be nil.
+// The returned function stops tracking configuration changes and restores the tunables
+// to the values they had before BindRuntime was called.
+//
+// Runtime tunables are process-wide, so bind them at most once per process.
+func BindRuntime(cm *ConfigManager, extract func(cfg any) RuntimeSettings, onError CallbackErrFunc) func() {
+	initial := currentRuntimeSettings()
+	unbind := Bind(cm, extract, func(settings RuntimeSettings) {
+		if err := settings.Validate(); err != nil {
+			if onError != nil {
+				onError(fmt.Errorf("apply runtime settings: %w", err))
+			}
+			return
+		}
+		applyRuntimeSettings(settings.withDefaults(initial))
+	})
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			unbind()
+			applyRuntimeSettings(initial)
+		})
+	}
+}
+
+// withDefaults returns the settings with zero values replaced by the values of defaults.
+func (s RuntimeSettings) withDefaults(defaults RuntimeSettings) RuntimeSettings {
+	if s.GOMAXPROCS == 0 {
+		s.GOMAXPROCS = defaults.GOMAXPROCS
+	}
+	if s.GCPercent == 0 {
+		s.GCPercent = defaults.GCPercent
+	}
+	if s.MemoryLimit == 0 {
+		s.MemoryLimit = defaults.MemoryLimit
+	}
+	return s
+}
+
+func currentRuntimeSettings() RuntimeSettings {
+	// debug.SetGCPercent has no getter, so the value is read by setting it and restoring right away.
+	gcPercent := debug.SetGCPercent(100)
+	debug.SetGCPercent(gcPercent)
+	return RuntimeSettings{
+		GOMAXPROCS: runtime.GOMAXPROCS(0),
+		GCPercent:  gcPercent,
+		// Negative input only reads the limit.
+		MemoryLimit: debug.SetMemoryLimit(-1),
+	}
+}
+
+func applyRuntimeSettings(s RuntimeSettings) {
+	if s.GOMAXPROCS > 0 && runtime.GOMAXPROCS(0) != s.GOMAXPROCS {
+		runtime.GOMAXPROCS(s.GOMAXPROCS)
+	}
+	if s.GCPercent < 0 {
+		s.GCPercent = -1
+	}
+	debug.SetGCPercent(s.GCPercent)
+	if s.MemoryLimit > 0 {
+		debug.SetMemoryLimit(s.MemoryLimit)
+	}
+}
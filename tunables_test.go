@@ -0,0 +1,173 @@
+package confgo
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"golang.org/x/time/rate"
+)
+
+func newTestTriggeredManager(t *testing.T, formatter *fakeFormatter) (*ConfigManager, *TriggerWatcher) {
+	t.Helper()
+
+	watcher := NewTriggerWatcher()
+	cm := newTestConfigManager(testConfigManagerFields{
+		constructor: testConfigConstructor,
+		loaders:     []Loader{{Source: &fakeSource{data: []byte("test")}, Formatter: formatter, Watcher: watcher}},
+	})
+	if err := cm.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	t.Cleanup(cm.MustStop)
+	return cm, watcher
+}
+
+func TestBind(t *testing.T) {
+	t.Parallel()
+
+	formatter := &fakeFormatter{data: TestConfig{Int: 1}}
+	cm, watcher := newTestTriggeredManager(t, formatter)
+
+	var applied []int
+	unbind := Bind(cm, func(cfg any) int { return cfg.(*TestConfig).Int }, func(settings int) {
+		applied = append(applied, settings)
+	})
+
+	formatter.data = TestConfig{Int: 1, Slice: []string{"unrelated"}}
+	watcher.Trigger()
+	formatter.data = TestConfig{Int: 2}
+	watcher.Trigger()
+	unbind()
+	formatter.data = TestConfig{Int: 3}
+	watcher.Trigger()
+
+	if want := []int{1, 2}; !reflect.DeepEqual(applied, want) {
+		t.Errorf("applied settings = %v, want %v", applied, want)
+	}
+}
+
+func TestBindRateLimiter(t *testing.T) {
+	t.Parallel()
+
+	formatter := &fakeFormatter{data: TestConfig{Int: 10}}
+	cm, watcher := newTestTriggeredManager(t, formatter)
+
+	limiter := rate.NewLimiter(rate.Inf, 0)
+	unbind := BindRateLimiter(cm, limiter, func(cfg any) RateLimitSettings {
+		n := cfg.(*TestConfig).Int
+		return RateLimitSettings{Limit: rate.Limit(n), Burst: n * 2}
+	})
+	defer unbind()
+
+	if limiter.Limit() != 10 || limiter.Burst() != 20 {
+		t.Fatalf("limit = %v, burst = %d, want 10 and 20", limiter.Limit(), limiter.Burst())
+	}
+	formatter.data = TestConfig{Int: 5}
+	watcher.Trigger()
+	if limiter.Limit() != 5 || limiter.Burst() != 10 {
+		t.Fatalf("limit = %v, burst = %d, want 5 and 10", limiter.Limit(), limiter.Burst())
+	}
+}
+
+type testBreaker struct {
+	settings CircuitBreakerSettings
+}
+
+func TestReloadable(t *testing.T) {
+	t.Parallel()
+
+	formatter := &fakeFormatter{data: TestConfig{Int: 3}}
+	cm, watcher := newTestTriggeredManager(t, formatter)
+
+	extract := func(cfg any) CircuitBreakerSettings {
+		return CircuitBreakerSettings{ConsecutiveFailures: uint32(cfg.(*TestConfig).Int)}
+	}
+	build := func(settings CircuitBreakerSettings) (*testBreaker, error) {
+		if settings.ConsecutiveFailures == 0 {
+			return nil, errors.New("consecutive failures must be positive")
+		}
+		return &testBreaker{settings: settings}, nil
+	}
+
+	errs := make(chan error, 10)
+	breaker, err := NewReloadable(cm, extract, build, func(err error) { errs <- err })
+	if err != nil {
+		t.Fatalf("NewReloadable() error = %v", err)
+	}
+	defer breaker.Stop()
+
+	first := breaker.Get()
+	if first.settings.ConsecutiveFailures != 3 {
+		t.Fatalf("ConsecutiveFailures = %d, want 3", first.settings.ConsecutiveFailures)
+	}
+
+	formatter.data = TestConfig{Int: 5}
+	watcher.Trigger()
+	if got := breaker.Get().settings.ConsecutiveFailures; got != 5 {
+		t.Fatalf("ConsecutiveFailures = %d, want 5", got)
+	}
+
+	formatter.data = TestConfig{Int: 0}
+	watcher.Trigger()
+	select {
+	case <-errs:
+		// ok
+	default:
+		t.Fatalf("expected build error")
+	}
+	if got := breaker.Get().settings.ConsecutiveFailures; got != 5 {
+		t.Fatalf("ConsecutiveFailures = %d, want 5", got)
+	}
+
+	if _, err := NewReloadable(cm, extract, build, nil); err == nil {
+		t.Fatalf("NewReloadable() with invalid initial settings error = nil, want error")
+	}
+}
+
+func TestBindRuntime(t *testing.T) {
+	// Runtime tunables are process-wide, so the test is not parallel.
+	initial := currentRuntimeSettings()
+
+	formatter := &fakeFormatter{data: TestConfig{Int: 50}}
+	cm, watcher := newTestTriggeredManager(t, formatter)
+
+	errs := make(chan error, 10)
+	unbind := BindRuntime(cm, func(cfg any) RuntimeSettings {
+		n := cfg.(*TestConfig).Int
+		return RuntimeSettings{GOMAXPROCS: 0, GCPercent: n, MemoryLimit: int64(n) << 20}
+	}, func(err error) { errs <- err })
+	defer unbind()
+
+	want := RuntimeSettings{GOMAXPROCS: initial.GOMAXPROCS, GCPercent: 50, MemoryLimit: 50 << 20}
+	if got := currentRuntimeSettings(); got != want {
+		t.Fatalf("runtime settings = %+v, want %+v", got, want)
+	}
+
+	formatter.data = TestConfig{Int: -1}
+	watcher.Trigger()
+	select {
+	case err := <-errs:
+		if !errors.Is(err, ErrInvalidRuntimeSetting) {
+			t.Errorf("error = %v, want %v", err, ErrInvalidRuntimeSetting)
+		}
+	default:
+		t.Fatalf("expected invalid runtime setting error")
+	}
+	if got := currentRuntimeSettings(); got != want {
+		t.Fatalf("runtime settings after invalid update = %+v, want %+v", got, want)
+	}
+
+	formatter.data = TestConfig{Int: 0}
+	watcher.Trigger()
+	if got := currentRuntimeSettings(); got != initial {
+		t.Fatalf("runtime settings with zero values = %+v, want %+v", got, initial)
+	}
+
+	formatter.data = TestConfig{Int: 70}
+	watcher.Trigger()
+	unbind()
+	if got := currentRuntimeSettings(); got != initial {
+		t.Errorf("runtime settings after unbind = %+v, want %+v", got, initial)
+	}
+}
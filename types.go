@@ -0,0 +1,367 @@
+package confgo
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	_ encoding.TextUnmarshaler = (*Duration)(nil)
+	_ json.Unmarshaler         = (*Duration)(nil)
+	_ encoding.TextUnmarshaler = (*TimeOfDay)(nil)
+	_ encoding.TextUnmarshaler = (*Schedule)(nil)
+	_ encoding.TextUnmarshaler = (*ByteSize)(nil)
+	_ json.Unmarshaler         = (*ByteSize)(nil)
+)
+
+// The config field types below are (un)marshalled as text, so they are understood by JSON, YAML and env formatters.
+
+// Duration is a time.Duration written as a string accepted by time.ParseDuration, e.g. "1m30s".
+// JSON numbers are taken as nanoseconds, as time.Duration is encoded by the json package.
+type Duration time.Duration
+
+// Std returns the duration as time.Duration.
+func (d Duration) Std() time.Duration {
+	return time.Duration(d)
+}
+
+func (d Duration) String() string {
+	return time.Duration(d).String()
+}
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	parsed, err := time.ParseDuration(strings.TrimSpace(string(text)))
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidFieldValue, err)
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	if bytes.HasPrefix(data, []byte(`"`)) {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		return d.UnmarshalText([]byte(s))
+	}
+	var ns int64
+	if err := json.Unmarshal(data, &ns); err != nil {
+		return fmt.Errorf("%w: duration must be a string or an integer number of nanoseconds", ErrInvalidFieldValue)
+	}
+	*d = Duration(ns)
+	return nil
+}
+
+// TimeOfDay is a wall clock time written as "15:04" or "15:04:05".
+type TimeOfDay struct {
+	Hour   int
+	Minute int
+	Second int
+}
+
+// Validate checks that the time of day is between 00:00:00 and 23:59:59.
+func (t TimeOfDay) Validate() error {
+	if t.Hour < 0 || t.Hour > 23 || t.Minute < 0 || t.Minute > 59 || t.Second < 0 || t.Second > 59 {
+		return fmt.Errorf("%w: time of day %02d:%02d:%02d is out of range", ErrInvalidFieldValue,
+			t.Hour, t.Minute, t.Second)
+	}
+	return nil
+}
+
+// On returns the time of day on the date of day in its location.
+func (t TimeOfDay) On(day time.Time) time.Time {
+	y, m, d := day.Date()
+	return time.Date(y, m, d, t.Hour, t.Minute, t.Second, 0, day.Location())
+}
+
+// sinceMidnight returns the duration since midnight, comparable across times of day.
+func (t TimeOfDay) sinceMidnight() time.Duration {
+	return time.Duration(t.Hour)*time.Hour + time.Duration(t.Minute)*time.Minute + time.Duration(t.Second)*time.Second
+}
+
+func timeOfDayOf(t time.Time) TimeOfDay {
+	return TimeOfDay{Hour: t.Hour(), Minute: t.Minute(), Second: t.Second()}
+}
+
+func (t TimeOfDay) String() string {
+	if t.Second == 0 {
+		return fmt.Sprintf("%02d:%02d", t.Hour, t.Minute)
+	}
+	return fmt.Sprintf("%02d:%02d:%02d", t.Hour, t.Minute, t.Second)
+}
+
+func (t TimeOfDay) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+func (t *TimeOfDay) UnmarshalText(text []byte) error {
+	parsed, err := parseTimeOfDay(strings.TrimSpace(string(text)))
+	if err != nil {
+		return err
+	}
+	*t = parsed
+	return nil
+}
+
+func parseTimeOfDay(s string) (TimeOfDay, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return TimeOfDay{}, fmt.Errorf("%w: time of day %q must be formatted as HH:MM or HH:MM:SS", ErrInvalidFieldValue, s)
+	}
+	values := make([]int, 3)
+	for i, p := range parts {
+		v, err := strconv.Atoi(p)
+		if err != nil || len(p) != 2 {
+			return TimeOfDay{}, fmt.Errorf("%w: time of day %q must be formatted as HH:MM or HH:MM:SS", ErrInvalidFieldValue, s)
+		}
+		values[i] = v
+	}
+	t := TimeOfDay{Hour: values[0], Minute: values[1], Second: values[2]}
+	return t, t.Validate()
+}
+
+// ScheduleWindow is a daily time window active on the given days of the week.
+type ScheduleWindow struct {
+	// Days are the days of the week the window starts on. Empty Days means every day.
+	Days  []time.Weekday
+	Start TimeOfDay
+	// End is exclusive. If End is not after Start, the window ends on the next day.
+	End TimeOfDay
+}
+
+func (w ScheduleWindow) startsOn(day time.Weekday) bool {
+	if len(w.Days) == 0 {
+		return true
+	}
+	for _, d := range w.Days {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
+
+// Contains reports whether t is within the window.
+func (w ScheduleWindow) Contains(t time.Time) bool {
+	tod := timeOfDayOf(t).sinceMidnight()
+	start, end := w.Start.sinceMidnight(), w.End.sinceMidnight()
+	if start < end {
+		return w.startsOn(t.Weekday()) && tod >= start && tod < end
+	}
+	return w.startsOn(t.Weekday()) && tod >= start ||
+		w.startsOn((t.Weekday()+6)%7) && tod < end
+}
+
+func (w ScheduleWindow) String() string {
+	window := w.Start.String() + "-" + w.End.String()
+	if len(w.Days) == 0 {
+		return window
+	}
+	days := make([]string, 0, len(w.Days))
+	for _, d := range w.Days {
+		days = append(days, strings.ToLower(d.String()[:3]))
+	}
+	return strings.Join(days, ",") + " " + window
+}
+
+// Schedule is a set of weekly time windows written as windows separated by ";", where every window is
+// an optional comma-separated list of days or day ranges followed by a time range,
+// e.g. "mon-fri 09:00-18:00; sat,sun 10:00-14:00" or "22:00-06:00" for every night.
+type Schedule []ScheduleWindow
+
+// Contains reports whether t is within any window of the schedule.
+func (s Schedule) Contains(t time.Time) bool {
+	for _, w := range s {
+		if w.Contains(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks the times of day and days of the week of every window.
+func (s Schedule) Validate() error {
+	errs := make([]error, 0)
+	for i, w := range s {
+		if err := w.Start.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("window #%d start: %w", i, err))
+		}
+		if err := w.End.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("window #%d end: %w", i, err))
+		}
+		for _, d := range w.Days {
+			if d < time.Sunday || d > time.Saturday {
+				errs = append(errs, fmt.Errorf("%w: window #%d: invalid day %d", ErrInvalidFieldValue, i, d))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s Schedule) String() string {
+	windows := make([]string, 0, len(s))
+	for _, w := range s {
+		windows = append(windows, w.String())
+	}
+	return strings.Join(windows, "; ")
+}
+
+func (s Schedule) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+func (s *Schedule) UnmarshalText(text []byte) error {
+	schedule := make(Schedule, 0)
+	for _, window := range strings.Split(string(text), ";") {
+		window = strings.TrimSpace(window)
+		if window == "" {
+			continue
+		}
+		w, err := parseScheduleWindow(window)
+		if err != nil {
+			return err
+		}
+		schedule = append(schedule, w)
+	}
+	*s = schedule
+	return nil
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+func parseScheduleWindow(s string) (ScheduleWindow, error) {
+	var w ScheduleWindow
+	timeRange := s
+	// The time range is the last token, days are given only if the window starts with a letter.
+	if i := strings.LastIndexAny(s, " \t"); i >= 0 && s[0] >= 'A' {
+		days := s[:i]
+		timeRange = s[i+1:]
+		for _, spec := range strings.Split(days, ",") {
+			from, to, isRange := strings.Cut(strings.ToLower(strings.TrimSpace(spec)), "-")
+			first, ok1 := weekdays[from]
+			last, ok2 := weekdays[to]
+			if !ok1 || isRange && !ok2 {
+				return ScheduleWindow{}, fmt.Errorf("%w: schedule window %q: invalid days %q", ErrInvalidFieldValue, s, spec)
+			}
+			if !isRange {
+				last = first
+			}
+			for d := first; ; d = (d + 1) % 7 {
+				w.Days = append(w.Days, d)
+				if d == last {
+					break
+				}
+			}
+		}
+	}
+
+	start, end, ok := strings.Cut(timeRange, "-")
+	if !ok {
+		return ScheduleWindow{}, fmt.Errorf("%w: schedule window %q: time range must be formatted as HH:MM-HH:MM",
+			ErrInvalidFieldValue, s)
+	}
+	var err error
+	if w.Start, err = parseTimeOfDay(strings.TrimSpace(start)); err != nil {
+		return ScheduleWindow{}, fmt.Errorf("schedule window %q: %w", s, err)
+	}
+	if w.End, err = parseTimeOfDay(strings.TrimSpace(end)); err != nil {
+		return ScheduleWindow{}, fmt.Errorf("schedule window %q: %w", s, err)
+	}
+	return w, nil
+}
+
+// ByteSize is a number of bytes written as an integer optionally followed by a decimal (kB, MB, GB, TB)
+// or binary (KiB, MiB, GiB, TiB) unit, e.g. "512", "64KiB" or "1.5GB". Units are case-insensitive.
+type ByteSize uint64
+
+var byteSizeUnits = []struct {
+	name string
+	size uint64
+}{
+	// Binary units go first, so that String finds them and "b" of the decimal units is not taken for bytes.
+	{"TiB", 1 << 40},
+	{"GiB", 1 << 30},
+	{"MiB", 1 << 20},
+	{"KiB", 1 << 10},
+	{"TB", 1e12},
+	{"GB", 1e9},
+	{"MB", 1e6},
+	{"kB", 1e3},
+	{"B", 1},
+}
+
+const binaryByteSizeUnits = 4
+
+// String returns the size in the largest binary unit which represents it exactly.
+func (b ByteSize) String() string {
+	for _, unit := range byteSizeUnits[:binaryByteSizeUnits] {
+		if b != 0 && uint64(b)%unit.size == 0 {
+			return strconv.FormatUint(uint64(b)/unit.size, 10) + unit.name
+		}
+	}
+	return strconv.FormatUint(uint64(b), 10)
+}
+
+func (b ByteSize) MarshalText() ([]byte, error) {
+	return []byte(b.String()), nil
+}
+
+func (b *ByteSize) UnmarshalText(text []byte) error {
+	s := strings.ToLower(strings.TrimSpace(string(text)))
+	multiplier := uint64(1)
+	for _, unit := range byteSizeUnits {
+		if number, ok := strings.CutSuffix(s, strings.ToLower(unit.name)); ok {
+			s, multiplier = strings.TrimSpace(number), unit.size
+			break
+		}
+	}
+	if n, err := strconv.ParseUint(s, 10, 64); err == nil {
+		if n > math.MaxUint64/multiplier {
+			return fmt.Errorf("%w: byte size %q overflows", ErrInvalidFieldValue, text)
+		}
+		*b = ByteSize(n * multiplier)
+		return nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || f < 0 || math.IsNaN(f) {
+		return fmt.Errorf("%w: invalid byte size %q", ErrInvalidFieldValue, text)
+	}
+	size := f * float64(multiplier)
+	if size >= math.MaxUint64 {
+		return fmt.Errorf("%w: byte size %q overflows", ErrInvalidFieldValue, text)
+	}
+	*b = ByteSize(size)
+	return nil
+}
+
+func (b *ByteSize) UnmarshalJSON(data []byte) error {
+	if bytes.HasPrefix(data, []byte(`"`)) {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		return b.UnmarshalText([]byte(s))
+	}
+	return b.UnmarshalText(data)
+}
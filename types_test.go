@@ -0,0 +1,184 @@
+package confgo
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+type testTypesConfig struct {
+	Timeout     Duration  `json:"timeout" yaml:"timeout" env:"TIMEOUT"`
+	StartAt     TimeOfDay `json:"start_at" yaml:"start_at" env:"START_AT"`
+	Maintenance Schedule  `json:"maintenance" yaml:"maintenance" env:"MAINTENANCE"`
+	MaxBody     ByteSize  `json:"max_body" yaml:"max_body" env:"MAX_BODY"`
+}
+
+func TestTypes_Formatters(t *testing.T) {
+	t.Parallel()
+
+	want := testTypesConfig{
+		Timeout: Duration(90 * time.Second),
+		StartAt: TimeOfDay{Hour: 9, Minute: 30, Second: 0},
+		Maintenance: Schedule{
+			{Days: []time.Weekday{time.Saturday, time.Sunday}, Start: TimeOfDay{Hour: 22}, End: TimeOfDay{Hour: 6}},
+		},
+		MaxBody: 64 << 20,
+	}
+	tests := []struct {
+		name      string
+		formatter Formatter
+		data      string
+	}{
+		{
+			name:      "json",
+			formatter: NewJSONFormatter(),
+			data:      `{"timeout": "1m30s", "start_at": "09:30", "maintenance": "sat-sun 22:00-06:00", "max_body": "64MiB"}`,
+		},
+		{
+			name:      "json numbers",
+			formatter: NewJSONFormatter(),
+			data:      `{"timeout": 90000000000, "start_at": "09:30", "maintenance": "sat,sun 22:00-06:00", "max_body": 67108864}`,
+		},
+		{
+			name:      "yaml",
+			formatter: NewYAMLFormatter(),
+			data:      "timeout: 1m30s\nstart_at: \"09:30\"\nmaintenance: sat-sun 22:00-06:00\nmax_body: 64mib\n",
+		},
+		{
+			name:      "env",
+			formatter: NewEnvFormatter(),
+			data:      "TIMEOUT=90s\nSTART_AT=09:30\nMAINTENANCE=sat-sun 22:00-06:00\nMAX_BODY=64 MiB",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var got testTypesConfig
+			if err := tt.formatter.Unmarshal([]byte(tt.data), &got); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Unmarshal() = %+v, want %+v", got, want)
+			}
+		})
+	}
+
+	data, err := json.Marshal(want)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	wantJSON := `{"timeout":"1m30s","start_at":"09:30","maintenance":"sat,sun 22:00-06:00","max_body":"64MiB"}`
+	if string(data) != wantJSON {
+		t.Errorf("json.Marshal() = %s, want %s", data, wantJSON)
+	}
+	var roundTrip testTypesConfig
+	data, err = yaml.Marshal(want)
+	if err != nil {
+		t.Fatalf("yaml.Marshal() error = %v", err)
+	}
+	if err := yaml.Unmarshal(data, &roundTrip); err != nil || !reflect.DeepEqual(roundTrip, want) {
+		t.Errorf("yaml round trip = %+v, %v, want %+v", roundTrip, err, want)
+	}
+}
+
+func TestByteSize_UnmarshalText(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		text    string
+		want    ByteSize
+		wantErr bool
+	}{
+		{text: "512", want: 512},
+		{text: "512B", want: 512},
+		{text: "1kB", want: 1000},
+		{text: "1KiB", want: 1024},
+		{text: "1.5GB", want: 1_500_000_000},
+		{text: "2 tib", want: 2 << 40},
+		{text: "-1", wantErr: true},
+		{text: "1XB", wantErr: true},
+		{text: "100000000TiB", wantErr: true},
+	}
+	for _, tt := range tests {
+		var got ByteSize
+		err := got.UnmarshalText([]byte(tt.text))
+		if tt.wantErr {
+			if !errors.Is(err, ErrInvalidFieldValue) {
+				t.Errorf("UnmarshalText(%q) error = %v, want %v", tt.text, err, ErrInvalidFieldValue)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("UnmarshalText(%q) = %d, %v, want %d", tt.text, got, err, tt.want)
+		}
+	}
+	for size, want := range map[ByteSize]string{0: "0", 1536: "1536", 3 << 10: "3KiB", 1 << 40: "1TiB"} {
+		if got := size.String(); got != want {
+			t.Errorf("String() = %q, want %q", got, want)
+		}
+	}
+}
+
+func TestSchedule_Contains(t *testing.T) {
+	t.Parallel()
+
+	var s Schedule
+	if err := s.UnmarshalText([]byte("mon-fri 09:00-18:00; sat 22:00-02:00; 12:00:30-12:01")); err != nil {
+		t.Fatalf("UnmarshalText() error = %v", err)
+	}
+	if err := s.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	// 2026-10-12 is Monday.
+	day := func(d, h, m, sec int) time.Time { return time.Date(2026, 10, 11+d, h, m, sec, 0, time.UTC) }
+	tests := []struct {
+		t    time.Time
+		want bool
+	}{
+		{t: day(1, 9, 0, 0), want: true},
+		{t: day(1, 18, 0, 0), want: false},
+		{t: day(5, 17, 59, 59), want: true},
+		{t: day(6, 10, 0, 0), want: false},
+		{t: day(6, 23, 0, 0), want: true},
+		{t: day(7, 1, 59, 0), want: true},
+		{t: day(7, 2, 0, 0), want: false},
+		{t: day(0, 12, 0, 45), want: true},
+		{t: day(0, 12, 0, 0), want: false},
+	}
+	for _, tt := range tests {
+		if got := s.Contains(tt.t); got != tt.want {
+			t.Errorf("Contains(%s) = %v, want %v", tt.t.Format("Mon 15:04:05"), got, tt.want)
+		}
+	}
+}
+
+func TestTypes_UnmarshalErrors(t *testing.T) {
+	t.Parallel()
+
+	var (
+		d Duration
+		o TimeOfDay
+		s Schedule
+	)
+	for name, err := range map[string]error{
+		"duration without unit":    d.UnmarshalText([]byte("30")),
+		"duration json float":      d.UnmarshalJSON([]byte("1.5")),
+		"time of day out of range": o.UnmarshalText([]byte("24:00")),
+		"time of day single digit": o.UnmarshalText([]byte("9:00")),
+		"schedule unknown day":     s.UnmarshalText([]byte("mon-fry 09:00-10:00")),
+		"schedule no time range":   s.UnmarshalText([]byte("mon 09:00")),
+	} {
+		if !errors.Is(err, ErrInvalidFieldValue) {
+			t.Errorf("%s: error = %v, want %v", name, err, ErrInvalidFieldValue)
+		}
+	}
+	if err := (Schedule{{Days: []time.Weekday{9}}}).Validate(); !errors.Is(err, ErrInvalidFieldValue) {
+		t.Errorf("Validate() error = %v, want %v", err, ErrInvalidFieldValue)
+	}
+}
@@ -0,0 +1,99 @@
+package confgo
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+// StructValidator validates a struct by the declarative rules of its field tags.
+// It is satisfied by *validator.Validate of github.com/go-playground/validator/v10,
+// which validates `validate:"..."` tags.
+type StructValidator interface {
+	// Struct validates the exported fields of the struct s by their tags.
+	Struct(s any) error
+}
+
+type namedValidator struct {
+	name string
+	fn   ValidateFunc
+}
+
+// namedValidators is a list of named validators kept in the order of registration.
+type namedValidators []namedValidator
+
+// set adds the validator or replaces the one registered with the same name keeping its position.
+func (nv *namedValidators) set(name string, fn ValidateFunc) {
+	for i := range *nv {
+		if (*nv)[i].name == name {
+			(*nv)[i].fn = fn
+			return
+		}
+	}
+	*nv = append(*nv, namedValidator{name: name, fn: fn})
+}
+
+// ValidatorResult is a result of a single named validator.
+type ValidatorResult struct {
+	Name string `json:"name"`
+	// Err is nil if the validation has passed.
+	Err error `json:"-"`
+}
+
+// Passed reports whether the validation has passed.
+func (r ValidatorResult) Passed() bool {
+	return r.Err == nil
+}
+
+// ValidationReport contains the results of named validators of the last config validation
+// in the order of validators registration.
+type ValidationReport struct {
+	Time    time.Time         `json:"time"`
+	Results []ValidatorResult `json:"results"`
+}
+
+// Passed reports whether all the validators have passed.
+func (r ValidationReport) Passed() bool {
+	for _, res := range r.Results {
+		if !res.Passed() {
+			return false
+		}
+	}
+	return true
+}
+
+// Failed returns the results of the validators which have failed.
+func (r ValidationReport) Failed() []ValidatorResult {
+	failed := make([]ValidatorResult, 0)
+	for _, res := range r.Results {
+		if !res.Passed() {
+			failed = append(failed, res)
+		}
+	}
+	return failed
+}
+
+type validationReportHolder struct {
+	mu     sync.Mutex
+	report ValidationReport
+}
+
+func (h *validationReportHolder) store(report ValidationReport) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.report = report
+}
+
+func (h *validationReportHolder) load() ValidationReport {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	report := h.report
+	report.Results = slices.Clone(report.Results)
+	return report
+}
+
+// ValidationReport returns the report of named validators of the last config validation,
+// including the failed validation of a rejected reload.
+func (cm *ConfigManager) ValidationReport() ValidationReport {
+	return cm.validationReport.load()
+}
@@ -0,0 +1,237 @@
+package confgo
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWithNamedValidator_Order(t *testing.T) {
+	t.Parallel()
+
+	var calls []string
+	validator := func(name string, err error) ValidateFunc {
+		return func() error {
+			calls = append(calls, name)
+			return err
+		}
+	}
+	errFirst := errors.New("first error")
+	errThird := errors.New("third error")
+
+	cm, err := NewConfigManager(
+		testConfigConstructor,
+		WithNamedValidator("c", validator("c", errFirst)),
+		WithNamedValidator("a", validator("a", nil)),
+		WithNamedValidator("b", validator("b", nil)),
+		// Replaces the validator "a" keeping its position.
+		WithNamedValidator("a", validator("a2", errThird)),
+	)
+	if err != nil {
+		t.Fatalf("NewConfigManager() error = %v", err)
+	}
+	cm.AddLoader(Loader{Source: &fakeSource{data: []byte("test")}, Formatter: &fakeFormatter{data: TestConfig{Int: 1}}})
+
+	err = cm.reload()
+	if !errors.Is(err, errFirst) || !errors.Is(err, errThird) {
+		t.Fatalf("reload() error = %v, want both %v and %v", err, errFirst, errThird)
+	}
+	if want := []string{"c", "a2", "b"}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("validators calls = %v, want %v", calls, want)
+	}
+	if want := `validate config: named validator "c": first error` + "\n" + `named validator "a": third error`; err.Error() != want {
+		t.Errorf("reload() error = %q, want %q", err.Error(), want)
+	}
+
+	report := cm.ValidationReport()
+	if report.Passed() {
+		t.Errorf("ValidationReport().Passed() = true, want false")
+	}
+	if report.Time.IsZero() {
+		t.Errorf("ValidationReport().Time is zero")
+	}
+	wantResults := []ValidatorResult{
+		{Name: "c", Err: errFirst},
+		{Name: "a", Err: errThird},
+		{Name: "b", Err: nil},
+	}
+	if !reflect.DeepEqual(report.Results, wantResults) {
+		t.Errorf("ValidationReport().Results = %v, want %v", report.Results, wantResults)
+	}
+	if want := []ValidatorResult{wantResults[0], wantResults[1]}; !reflect.DeepEqual(report.Failed(), want) {
+		t.Errorf("ValidationReport().Failed() = %v, want %v", report.Failed(), want)
+	}
+}
+
+func TestValidationReport_Passed(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		results []ValidatorResult
+		want    bool
+	}{
+		{
+			name:    "no validators",
+			results: nil,
+			want:    true,
+		},
+		{
+			name:    "all passed",
+			results: []ValidatorResult{{Name: "a"}, {Name: "b"}},
+			want:    true,
+		},
+		{
+			name:    "one failed",
+			results: []ValidatorResult{{Name: "a"}, {Name: "b", Err: errors.New("test")}},
+			want:    false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			report := ValidationReport{Results: tt.results}
+			if got := report.Passed(); got != tt.want {
+				t.Errorf("Passed() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// requiredValidator is a minimal StructValidator checking `validate:"required"` tags.
+type requiredValidator struct{}
+
+func (requiredValidator) Struct(s any) error {
+	v := reflect.ValueOf(s).Elem()
+	for i := range v.NumField() {
+		if v.Type().Field(i).Tag.Get("validate") == "required" && v.Field(i).IsZero() {
+			return fmt.Errorf("field %s is required", v.Type().Field(i).Name)
+		}
+	}
+	return nil
+}
+
+func TestWithStructTagValidation(t *testing.T) {
+	t.Parallel()
+
+	type config struct {
+		Host string `json:"host" validate:"required"`
+		Port int    `json:"port"`
+	}
+
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{name: "valid", data: `{"host": "localhost"}`},
+		{name: "invalid", data: `{"port": 80}`, wantErr: "validate config: validate struct tags: field Host is required"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cm, err := NewConfigManagerFor[config](WithStructTagValidation(requiredValidator{}), func(cm *ConfigManager) error {
+				cm.AddLoader(Loader{Source: &fakeSource{data: []byte(tt.data)}, Formatter: NewJSONFormatter()})
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("NewConfigManagerFor() error = %v", err)
+			}
+			err = cm.reload()
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("reload() error = %v, want nil", err)
+			}
+			if tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr) {
+				t.Errorf("reload() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	if _, err := NewConfigManagerFor[config](WithStructTagValidation(nil)); !errors.Is(err, ErrValidatorIsNil) {
+		t.Errorf("NewConfigManagerFor() error = %v, want %v", err, ErrValidatorIsNil)
+	}
+}
+
+func TestWithTypedValidator(t *testing.T) {
+	t.Parallel()
+
+	errPort := errors.New("port is privileged")
+	var seen []any
+	cm, err := NewConfigManager(
+		testConfigConstructor,
+		WithConfigValidator(func(cfg any) error {
+			seen = append(seen, cfg)
+			return nil
+		}),
+		WithTypedValidator(func(cfg *TestConfig) error {
+			if cfg.Int < 1024 {
+				return errPort
+			}
+			return nil
+		}),
+	)
+	if err != nil {
+		t.Fatalf("NewConfigManager() error = %v", err)
+	}
+	formatter := &fakeFormatter{data: TestConfig{Int: 80}}
+	cm.AddLoader(Loader{Source: &fakeSource{data: []byte("test")}, Formatter: formatter})
+
+	if err := cm.reload(); !errors.Is(err, errPort) {
+		t.Fatalf("reload() error = %v, want %v", err, errPort)
+	}
+	if want := []any{&TestConfig{Int: 80}}; !reflect.DeepEqual(seen, want) {
+		t.Errorf("validated configs = %v, want %v", seen, want)
+	}
+	if cfg := cm.Config(); cfg != nil {
+		t.Errorf("Config() = %v, want nil after a failed validation", cfg)
+	}
+
+	formatter.data = TestConfig{Int: 8080}
+	if err := cm.reload(); err != nil {
+		t.Fatalf("reload() error = %v", err)
+	}
+}
+
+func TestWithTypedValidator_TypeMismatch(t *testing.T) {
+	t.Parallel()
+
+	cm, err := NewConfigManager(testConfigConstructor, WithTypedValidator(func(*testInnerConfig) error { return nil }))
+	if err != nil {
+		t.Fatalf("NewConfigManager() error = %v", err)
+	}
+	cm.AddLoader(Loader{Source: &fakeSource{data: []byte("test")}, Formatter: &fakeFormatter{data: TestConfig{Int: 1}}})
+	if err := cm.reload(); !errors.Is(err, ErrConfigTypeMismatch) {
+		t.Errorf("reload() error = %v, want %v", err, ErrConfigTypeMismatch)
+	}
+}
+
+func TestConfigManager_validate_JoinsErrors(t *testing.T) {
+	t.Parallel()
+
+	errNamed := errors.New("named error")
+	errPositional := errors.New("positional error")
+	errConfig := errors.New("config error")
+	cm, err := NewConfigManager(
+		func() any { return &TestConfigAsValidator{} },
+		WithNamedValidator("named", func() error { return errNamed }),
+		WithValidator(func() error { return errPositional }),
+		WithConfigValidator(func(any) error { return errConfig }),
+	)
+	if err != nil {
+		t.Fatalf("NewConfigManager() error = %v", err)
+	}
+
+	err = cm.validate(&TestConfigAsValidator{TestConfig: TestConfig{Int: 123}})
+	for _, want := range []error{errNamed, errPositional, errConfig} {
+		if !errors.Is(err, want) {
+			t.Errorf("validate() error = %v, want %v", err, want)
+		}
+	}
+	if err == nil || !strings.HasPrefix(err.Error(), "test validation error\n") {
+		t.Errorf("validate() error = %v, want Validator error first", err)
+	}
+}
@@ -0,0 +1,135 @@
+package confgo
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+)
+
+var (
+	_ Source           = (*MapSource)(nil)
+	_ Formatter        = (*MapSource)(nil)
+	_ PresenceReporter = (*MapSource)(nil)
+	_ Source           = (*StructSource)(nil)
+	_ Formatter        = (*StructSource)(nil)
+)
+
+// MapSource is both a Source and a Formatter of a programmatically computed layer given as a map,
+// e.g. {"server": {"port": 8080}}, which is set into the config without serialization.
+// Keys are matched to the fields as in dotted field paths, nested maps of type map[string]any set
+// the fields of nested structs, and values are converted only between numeric kinds or between string kinds.
+//
+// The data read by the source is only a fingerprint of the map used to detect changes,
+// so the loader must use the source as its formatter and must have no transformers, see WithMap.
+type MapSource struct {
+	values map[string]any
+}
+
+// NewMapSource creates a MapSource with a deep copy of values, so the caller may reuse the map.
+func NewMapSource(values map[string]any) *MapSource {
+	return &MapSource{values: deepCopy(reflect.ValueOf(values)).Interface().(map[string]any)}
+}
+
+func (ms *MapSource) Read() ([]byte, error) {
+	// The fmt package prints maps sorted by key, so the fingerprint of equal maps is the same.
+	return fmt.Appendf(nil, "%#v", ms.values), nil
+}
+
+// Unmarshal sets the values of the map into the config v, ignoring data.
+func (ms *MapSource) Unmarshal(_ []byte, v any) error {
+	return walkMapValues(reflect.ValueOf(v), "", ms.values, func(field reflect.Value, path string, value any) error {
+		if err := assignValue(field, value); err != nil {
+			return fmt.Errorf("field %q: %w", path, err)
+		}
+		return nil
+	})
+}
+
+// Present returns the paths of the fields of v set by the map, ignoring data.
+func (ms *MapSource) Present(_ []byte, v any) ([]string, error) {
+	paths := make([]string, 0)
+	cfg := reflect.New(reflect.TypeOf(v).Elem())
+	err := walkMapValues(cfg, "", ms.values, func(_ reflect.Value, path string, _ any) error {
+		paths = append(paths, path)
+		return nil
+	})
+	return paths, err
+}
+
+// walkMapValues calls fn for the fields of the struct value v at the paths of the leaf values of the map,
+// allocating nil pointers on the way.
+func walkMapValues(
+	v reflect.Value,
+	prefix string,
+	values map[string]any,
+	fn func(field reflect.Value, path string, value any) error,
+) error {
+	for _, key := range slices.Sorted(maps.Keys(values)) {
+		path := joinPath(prefix, key)
+		field, err := fieldByPath(v, path, true)
+		if err != nil {
+			return err
+		}
+		if nested, ok := values[key].(map[string]any); ok && isSectionType(field.Type()) {
+			if err := walkMapValues(v, path, nested, fn); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := fn(field, path, values[key]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isSectionType reports whether the fields of the type, possibly a pointer, are config sections.
+func isSectionType(typ reflect.Type) bool {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	return typ.Kind() == reflect.Struct && !isLeafStruct(typ)
+}
+
+// StructSource is both a Source and a Formatter of a programmatically computed layer given as a config struct,
+// which is copied into the config without serialization. As with other layers, zero fields of the struct
+// do not override the lower layers.
+//
+// The data read by the source is only a fingerprint of the struct used to detect changes,
+// so the loader must use the source as its formatter and must have no transformers, see WithStruct.
+type StructSource struct {
+	cfg reflect.Value
+}
+
+// NewStructSource creates a StructSource with a deep copy of cfg, a config struct or a pointer to it.
+func NewStructSource(cfg any) *StructSource {
+	v := reflect.ValueOf(cfg)
+	if v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.IsValid() {
+		v = deepCopy(v)
+	}
+	return &StructSource{cfg: v}
+}
+
+func (ss *StructSource) Read() ([]byte, error) {
+	if !ss.cfg.IsValid() {
+		return nil, nil
+	}
+	return fmt.Appendf(nil, "%#v", ss.cfg.Interface()), nil
+}
+
+// Unmarshal copies the struct into the config v, ignoring data.
+func (ss *StructSource) Unmarshal(_ []byte, v any) error {
+	dst := reflect.ValueOf(v)
+	if !ss.cfg.IsValid() {
+		return fmt.Errorf("%w: cannot use nil as %T", ErrConfigTypeMismatch, v)
+	}
+	if dst.Kind() != reflect.Ptr || dst.IsNil() || ss.cfg.Type() != dst.Type().Elem() {
+		return fmt.Errorf("%w: cannot use %s as %T", ErrConfigTypeMismatch, ss.cfg.Type(), v)
+	}
+	dst.Elem().Set(deepCopy(ss.cfg))
+	return nil
+}
@@ -0,0 +1,89 @@
+package confgo
+
+import (
+	"errors"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestMapSource(t *testing.T) {
+	t.Parallel()
+
+	values := map[string]any{
+		"int":       int64(1),
+		"inner":     map[string]any{"string": "str"},
+		"inner_ptr": map[string]any{"int": 2},
+		"map":       map[string]string{"a": "b"},
+	}
+	s := NewMapSource(values)
+	var got TestConfig
+	if err := s.Unmarshal(nil, &got); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	want := TestConfig{
+		Int:      1,
+		Inner:    testInnerConfig{String: "str"},
+		InnerPtr: &testInnerConfig{Int: 2},
+		Map:      map[string]string{"a": "b"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Unmarshal() = %+v, want %+v", got, want)
+	}
+
+	present, err := s.Present(nil, &TestConfig{})
+	if err != nil {
+		t.Fatalf("Present() error = %v", err)
+	}
+	slices.Sort(present)
+	if want := []string{"inner.string", "inner_ptr.int", "int", "map"}; !reflect.DeepEqual(present, want) {
+		t.Errorf("Present() = %q, want %q", present, want)
+	}
+
+	if err := NewMapSource(map[string]any{"int": "1"}).Unmarshal(nil, &TestConfig{}); !errors.Is(err, ErrInvalidFieldValue) {
+		t.Errorf("Unmarshal() error = %v, want %v", err, ErrInvalidFieldValue)
+	}
+	if err := NewMapSource(map[string]any{"unknown": 1}).Unmarshal(nil, &TestConfig{}); !errors.Is(err, ErrInvalidFieldPath) {
+		t.Errorf("Unmarshal() error = %v, want %v", err, ErrInvalidFieldPath)
+	}
+}
+
+func TestStructSource(t *testing.T) {
+	t.Parallel()
+
+	override := &TestConfig{Int: 2, Slice: []string{"a"}}
+	s := NewStructSource(override)
+	override.Slice[0] = "b"
+	var got TestConfig
+	if err := s.Unmarshal(nil, &got); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if want := (TestConfig{Int: 2, Slice: []string{"a"}}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Unmarshal() = %+v, want %+v", got, want)
+	}
+	if err := NewStructSource(testInnerConfig{}).Unmarshal(nil, &TestConfig{}); !errors.Is(err, ErrConfigTypeMismatch) {
+		t.Errorf("Unmarshal() error = %v, want %v", err, ErrConfigTypeMismatch)
+	}
+}
+
+func TestConfigManager_WithMapAndStruct(t *testing.T) {
+	t.Parallel()
+
+	cm, err := NewConfigManager(testConfigConstructor,
+		WithLoader(Loader{Source: NewStringSource(`{"int": 1, "inner": {"int": 1, "string": "base"}}`), Formatter: NewJSONFormatter()}),
+		WithMap(map[string]any{"inner": map[string]any{"string": "map"}}),
+		WithStruct(TestConfig{Slice: []string{"struct"}}),
+	)
+	if err != nil {
+		t.Fatalf("NewConfigManager() error = %v", err)
+	}
+	if err := cm.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer cm.MustStop()
+
+	want := &TestConfig{Int: 1, Inner: testInnerConfig{Int: 1, String: "map"}, Slice: []string{"struct"}}
+	if got := cm.Config(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Config() = %+v, want %+v", got, want)
+	}
+}
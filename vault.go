@@ -0,0 +1,298 @@
+package confgo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	vaultTokenHeader     = "X-Vault-Token"
+	vaultNamespaceHeader = "X-Vault-Namespace"
+	vaultRequestTimeout  = 30 * time.Second
+	// vaultTokenRenewMargin is subtracted from token TTL so the token is renewed before it actually expires.
+	vaultTokenRenewMargin = 10 * time.Second
+	vaultRefreshInterval  = time.Minute
+)
+
+// VaultAuth authenticates requests to HashiCorp Vault.
+type VaultAuth interface {
+	// Login returns a client token and its TTL. Zero TTL means the token never expires.
+	Login(ctx context.Context, vs *VaultSource) (token string, ttl time.Duration, err error)
+}
+
+var _ VaultAuth = VaultTokenAuth("")
+
+// VaultTokenAuth authenticates with a static Vault token.
+type VaultTokenAuth string
+
+func (t VaultTokenAuth) Login(context.Context, *VaultSource) (string, time.Duration, error) {
+	return string(t), 0, nil
+}
+
+var _ VaultAuth = (*VaultAppRoleAuth)(nil)
+
+// VaultAppRoleAuth authenticates with the AppRole auth method.
+// The token is obtained again when it expires.
+type VaultAppRoleAuth struct {
+	RoleID   string
+	SecretID string
+	// MountPath is the path the AppRole auth method is mounted at. Defaults to "approle".
+	MountPath string
+}
+
+func (a *VaultAppRoleAuth) Login(ctx context.Context, vs *VaultSource) (string, time.Duration, error) {
+	mountPath := a.MountPath
+	if mountPath == "" {
+		mountPath = "approle"
+	}
+	body, err := json.Marshal(map[string]string{"role_id": a.RoleID, "secret_id": a.SecretID})
+	if err != nil {
+		return "", 0, err
+	}
+	var resp struct {
+		Auth struct {
+			ClientToken   string `json:"client_token"`
+			LeaseDuration int    `json:"lease_duration"`
+		} `json:"auth"`
+	}
+	if err := vs.do(ctx, http.MethodPost, "auth/"+mountPath+"/login", "", bytes.NewReader(body), &resp); err != nil {
+		return "", 0, fmt.Errorf("approle login: %w", err)
+	}
+	return resp.Auth.ClientToken, time.Duration(resp.Auth.LeaseDuration) * time.Second, nil
+}
+
+// VaultSourceOption configures VaultSource.
+type VaultSourceOption func(vs *VaultSource)
+
+// VaultHTTPClient sets the HTTP client used for requests to Vault.
+func VaultHTTPClient(client *http.Client) VaultSourceOption {
+	return func(vs *VaultSource) {
+		vs.client = client
+	}
+}
+
+// VaultNamespace sets the Vault Enterprise namespace of requests.
+func VaultNamespace(namespace string) VaultSourceOption {
+	return func(vs *VaultSource) {
+		vs.namespace = namespace
+	}
+}
+
+var (
+	_ Source        = (*VaultSource)(nil)
+	_ SourceContext = (*VaultSource)(nil)
+)
+
+// VaultSource is a configuration source that reads a secret from HashiCorp Vault KV v2 secrets engine.
+// Read returns the secret data encoded as JSON, so it is meant to be used with JSONFormatter.
+type VaultSource struct {
+	addr      string
+	mount     string
+	path      string
+	auth      VaultAuth
+	client    *http.Client
+	namespace string
+
+	mu          sync.Mutex
+	token       string
+	tokenExpiry time.Time
+	version     int
+	ttl         time.Duration
+}
+
+// NewVaultSource creates a source reading the secret at path of KV v2 engine mounted at mount,
+// e.g. NewVaultSource("https://vault:8200", "secret", "myapp/db", VaultTokenAuth(token)).
+func NewVaultSource(addr, mount, path string, auth VaultAuth, opts ...VaultSourceOption) *VaultSource {
+	vs := &VaultSource{
+		addr:        strings.TrimSuffix(addr, "/"),
+		mount:       strings.Trim(mount, "/"),
+		path:        strings.Trim(path, "/"),
+		auth:        auth,
+		client:      &http.Client{Timeout: vaultRequestTimeout},
+		namespace:   "",
+		mu:          sync.Mutex{},
+		token:       "",
+		tokenExpiry: time.Time{},
+		version:     0,
+		ttl:         0,
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(vs)
+		}
+	}
+	return vs
+}
+
+func (vs *VaultSource) Read() ([]byte, error) {
+	return vs.ReadContext(context.Background())
+}
+
+// ReadContext is the same as Read but aborts the requests to Vault once ctx is done.
+func (vs *VaultSource) ReadContext(ctx context.Context) ([]byte, error) {
+	data, _, err := vs.readSecret(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// readSecret reads the secret and returns its data as JSON along with its version.
+func (vs *VaultSource) readSecret(ctx context.Context) ([]byte, int, error) {
+	token, err := vs.ensureToken(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	var resp struct {
+		LeaseDuration int `json:"lease_duration"`
+		Data          struct {
+			Data     json.RawMessage `json:"data"`
+			Metadata struct {
+				Version int `json:"version"`
+			} `json:"metadata"`
+		} `json:"data"`
+	}
+	if err := vs.do(ctx, http.MethodGet, vs.mount+"/data/"+vs.path, token, nil, &resp); err != nil {
+		return nil, 0, fmt.Errorf("read vault secret %q: %w", vs.mount+"/"+vs.path, err)
+	}
+
+	ttl := time.Duration(resp.LeaseDuration) * time.Second
+	// KV v2 secrets are not leased, but the conventional "ttl" key hints how often the secret must be re-read.
+	var hint struct {
+		TTL string `json:"ttl"`
+	}
+	if json.Unmarshal(resp.Data.Data, &hint) == nil && hint.TTL != "" {
+		if d, err := time.ParseDuration(hint.TTL); err == nil {
+			ttl = d
+		}
+	}
+
+	vs.mu.Lock()
+	vs.version = resp.Data.Metadata.Version
+	vs.ttl = ttl
+	vs.mu.Unlock()
+	return resp.Data.Data, resp.Data.Metadata.Version, nil
+}
+
+// refreshInterval returns how long the last read secret can be considered up to date.
+func (vs *VaultSource) refreshInterval() time.Duration {
+	vs.mu.Lock()
+	defer vs.mu.Unlock()
+	if vs.ttl > 0 {
+		return vs.ttl
+	}
+	return vaultRefreshInterval
+}
+
+func (vs *VaultSource) ensureToken(ctx context.Context) (string, error) {
+	vs.mu.Lock()
+	if vs.token != "" && (vs.tokenExpiry.IsZero() || time.Now().Before(vs.tokenExpiry)) {
+		token := vs.token
+		vs.mu.Unlock()
+		return token, nil
+	}
+	vs.mu.Unlock()
+
+	// The login is done without the lock, since it sends requests with do which takes the lock on failures.
+	token, ttl, err := vs.auth.Login(ctx, vs)
+	if err != nil {
+		return "", fmt.Errorf("vault login: %w", err)
+	}
+	vs.mu.Lock()
+	defer vs.mu.Unlock()
+	vs.token = token
+	vs.tokenExpiry = time.Time{}
+	if ttl > 0 {
+		vs.tokenExpiry = time.Now().Add(max(ttl-vaultTokenRenewMargin, ttl/2))
+	}
+	return token, nil
+}
+
+func (vs *VaultSource) do(ctx context.Context, method, path, token string, body io.Reader, out any) error {
+	req, err := http.NewRequestWithContext(ctx, method, vs.addr+"/v1/"+path, body)
+	if err != nil {
+		return err
+	}
+	if token != "" {
+		req.Header.Set(vaultTokenHeader, token)
+	}
+	if vs.namespace != "" {
+		req.Header.Set(vaultNamespaceHeader, vs.namespace)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := vs.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		if resp.StatusCode == http.StatusForbidden && token != "" {
+			// The token may have been revoked, so login again on the next request
+			// unless another request has already done it.
+			vs.mu.Lock()
+			if vs.token == token {
+				vs.token = ""
+			}
+			vs.mu.Unlock()
+		}
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<10))
+		return fmt.Errorf("%w: %s: %s", ErrUnexpectedStatus, resp.Status, bytes.TrimSpace(msg))
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+var _ Watcher = (*VaultWatcher)(nil)
+
+// VaultWatcher re-reads the secret of VaultSource when its lease or TTL expires
+// and calls the callback if a new secret version has been written.
+type VaultWatcher struct {
+	source   *VaultSource
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+func NewVaultWatcher(source *VaultSource) *VaultWatcher {
+	return &VaultWatcher{
+		source:   source,
+		stop:     make(chan struct{}),
+		stopOnce: sync.Once{},
+	}
+}
+
+func (vw *VaultWatcher) Watch(callback func()) {
+	go func() {
+		for {
+			select {
+			case <-vw.stop:
+				return
+			case <-time.After(vw.source.refreshInterval()):
+				vw.source.mu.Lock()
+				lastVersion := vw.source.version
+				vw.source.mu.Unlock()
+
+				_, version, err := vw.source.readSecret(context.Background())
+				if err != nil {
+					continue
+				}
+				if version != lastVersion {
+					callback()
+				}
+			}
+		}
+	}()
+}
+
+func (vw *VaultWatcher) Stop() error {
+	vw.stopOnce.Do(func() { close(vw.stop) })
+	return nil
+}
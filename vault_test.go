@@ -0,0 +1,244 @@
+package confgo
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeVault struct {
+	mu      sync.Mutex
+	data    map[string]any
+	version int
+	logins  int
+	tokens  map[string]bool
+}
+
+func newFakeVault(data map[string]any) *fakeVault {
+	return &fakeVault{data: data, version: 1, tokens: map[string]bool{"root": true}}
+}
+
+func (v *fakeVault) set(data map[string]any) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.data = data
+	v.version++
+}
+
+func (v *fakeVault) loginCount() int {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.logins
+}
+
+func (v *fakeVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/v1/auth/approle/login":
+		var body map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&body); err == nil && body["secret_id"] == "revoked" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if body["role_id"] != "role" || body["secret_id"] != "secret" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		v.logins++
+		token := "approle-token"
+		v.tokens[token] = true
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"auth": map[string]any{"client_token": token, "lease_duration": 3600},
+		})
+	case r.Method == http.MethodGet && r.URL.Path == "/v1/secret/data/app":
+		if !v.tokens[r.Header.Get(vaultTokenHeader)] {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"lease_duration": 0,
+			"data": map[string]any{
+				"data":     v.data,
+				"metadata": map[string]any{"version": v.version},
+			},
+		})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestVaultSource_Read(t *testing.T) {
+	t.Parallel()
+
+	vault := newFakeVault(map[string]any{"int": 1, "inner": map[string]any{"string": "secret"}})
+	server := httptest.NewServer(vault)
+	t.Cleanup(server.Close)
+
+	tests := []struct {
+		name    string
+		auth    VaultAuth
+		path    string
+		want    *TestConfig
+		wantErr error
+	}{
+		{
+			name: "token auth",
+			auth: VaultTokenAuth("root"),
+			path: "app",
+			want: &TestConfig{Int: 1, Inner: testInnerConfig{String: "secret"}},
+		},
+		{
+			name: "approle auth",
+			auth: &VaultAppRoleAuth{RoleID: "role", SecretID: "secret"},
+			path: "app",
+			want: &TestConfig{Int: 1, Inner: testInnerConfig{String: "secret"}},
+		},
+		{
+			name:    "invalid token",
+			auth:    VaultTokenAuth("invalid"),
+			path:    "app",
+			wantErr: ErrUnexpectedStatus,
+		},
+		{
+			name:    "invalid approle",
+			auth:    &VaultAppRoleAuth{RoleID: "role", SecretID: "invalid"},
+			path:    "app",
+			wantErr: ErrUnexpectedStatus,
+		},
+		{
+			name:    "unknown path",
+			auth:    VaultTokenAuth("root"),
+			path:    "unknown",
+			wantErr: ErrUnexpectedStatus,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			vs := NewVaultSource(server.URL, "secret", tt.path, tt.auth)
+			data, err := vs.Read()
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Read() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			got := &TestConfig{}
+			if err := NewJSONFormatter().Unmarshal(data, got); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Read() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVaultSource_ReloginOnTokenExpiry(t *testing.T) {
+	t.Parallel()
+
+	vault := newFakeVault(map[string]any{"int": 1})
+	server := httptest.NewServer(vault)
+	t.Cleanup(server.Close)
+
+	vs := NewVaultSource(server.URL, "secret", "app", &VaultAppRoleAuth{RoleID: "role", SecretID: "secret"})
+	for range 2 {
+		if _, err := vs.Read(); err != nil {
+			t.Fatalf("Read() error = %v", err)
+		}
+	}
+	if got := vault.loginCount(); got != 1 {
+		t.Fatalf("logins = %d, want 1", got)
+	}
+
+	vs.tokenExpiry = time.Now().Add(-time.Second)
+	if _, err := vs.Read(); err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if got := vault.loginCount(); got != 2 {
+		t.Fatalf("logins = %d, want 2", got)
+	}
+}
+
+func TestVaultSource_ForbiddenLogin(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(newFakeVault(map[string]any{"int": 1}))
+	t.Cleanup(server.Close)
+
+	vs := NewVaultSource(server.URL, "secret", "app", &VaultAppRoleAuth{RoleID: "role", SecretID: "revoked"})
+	done := make(chan error, 1)
+	go func() {
+		_, err := vs.Read()
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if !errors.Is(err, ErrUnexpectedStatus) {
+			t.Errorf("Read() error = %v, want ErrUnexpectedStatus", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Read() of a forbidden login has not returned")
+	}
+}
+
+func TestVaultWatcher_StopTwice(t *testing.T) {
+	t.Parallel()
+
+	watcher := NewVaultWatcher(NewVaultSource("http://127.0.0.1:0", "secret", "app", VaultTokenAuth("root")))
+	watcher.Watch(func() {})
+	for range 2 {
+		if err := watcher.Stop(); err != nil {
+			t.Errorf("Stop() error = %v", err)
+		}
+	}
+}
+
+func TestVaultWatcher_CallbackOnNewVersion(t *testing.T) {
+	t.Parallel()
+
+	vault := newFakeVault(map[string]any{"int": 1, "ttl": "10ms"})
+	server := httptest.NewServer(vault)
+	t.Cleanup(server.Close)
+
+	vs := NewVaultSource(server.URL, "secret", "app", VaultTokenAuth("root"))
+	if _, err := vs.Read(); err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if got := vs.refreshInterval(); got != 10*time.Millisecond {
+		t.Fatalf("refreshInterval() = %v, want %v", got, 10*time.Millisecond)
+	}
+
+	watcher := NewVaultWatcher(vs)
+	calls := make(chan struct{}, 10)
+	watcher.Watch(func() {
+		calls <- struct{}{}
+	})
+	defer func() {
+		if err := watcher.Stop(); err != nil {
+			t.Errorf("Stop() error = %v", err)
+		}
+	}()
+
+	select {
+	case <-calls:
+		t.Fatalf("unexpected callback without a new version")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	vault.set(map[string]any{"int": 2, "ttl": "10ms"})
+	select {
+	case <-calls:
+		// ok
+	case <-time.After(time.Second):
+		t.Fatalf("callback was not called after a new version was written")
+	}
+}
@@ -1,6 +1,10 @@
 package confgo
 
 import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
 	"sync"
 	"time"
 )
@@ -17,20 +21,69 @@ type ModTimer interface {
 
 var _ Watcher = (*ModTimeWatcher)(nil)
 
+// FileStater is implemented by ModTimers backed by files, such as FileSource.
+type FileStater interface {
+	// Stat returns the file info of the data.
+	Stat() (fs.FileInfo, error)
+}
+
+// ModTimeWatcherOption configures ModTimeWatcher.
+type ModTimeWatcherOption func(fw *ModTimeWatcher)
+
+// WatchSizeAndInode makes ModTimeWatcher also compare file size and inode, not just modification time,
+// if its ModTimer implements FileStater. Any difference of them is reported as a change.
+//
+// Modification time has 1 second granularity on some filesystems, so a file rewritten within
+// the same second is missed by comparing modification times only.
+func WatchSizeAndInode(fw *ModTimeWatcher) {
+	fw.sizeAndInode = true
+}
+
 // ModTimeWatcher is a watcher that monitors file modification times to detect configuration changes.
 type ModTimeWatcher struct {
 	modTimer ModTimer
 	interval time.Duration
 	stop     chan struct{}
 	lastMod  time.Time
+	// initialized is set after the first check, including the one that found the data missing.
+	initialized bool
+	// sizeAndInode enables comparison of lastSize and lastInode.
+	sizeAndInode bool
+	lastSize     int64
+	lastInode    uint64
 }
 
-func NewModTimeWatcher(modTimer ModTimer) *ModTimeWatcher {
-	return &ModTimeWatcher{
+func NewModTimeWatcher(modTimer ModTimer, opts ...ModTimeWatcherOption) *ModTimeWatcher {
+	fw := &ModTimeWatcher{
 		modTimer: modTimer,
 		interval: pollInterval,
 		stop:     make(chan struct{}),
 	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(fw)
+		}
+	}
+	return fw
+}
+
+// check returns the current modification time of the data and reports whether the data has changed
+// since the previous check.
+func (fw *ModTimeWatcher) check() (time.Time, bool, error) {
+	stater, ok := fw.modTimer.(FileStater)
+	if !fw.sizeAndInode || !ok {
+		modTime, err := fw.modTimer.ModTime()
+		return modTime, err == nil && modTime.After(fw.lastMod), err
+	}
+
+	info, err := stater.Stat()
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	size, inode := info.Size(), fileInode(info)
+	changed := !info.ModTime().Equal(fw.lastMod) || size != fw.lastSize || inode != fw.lastInode
+	fw.lastSize, fw.lastInode = size, inode
+	return info.ModTime(), changed, nil
 }
 
 func (fw *ModTimeWatcher) Watch(callback func()) {
@@ -40,13 +93,18 @@ func (fw *ModTimeWatcher) Watch(callback func()) {
 			case <-fw.stop:
 				return
 			case <-time.After(fw.interval):
-				modTime, err := fw.modTimer.ModTime()
+				modTime, changed, err := fw.check()
 				if err != nil {
+					// Data that appears after the first check is reported as a change.
+					if errors.Is(err, fs.ErrNotExist) {
+						fw.initialized = true
+					}
 					continue
 				}
-				if fw.lastMod.IsZero() {
+				if !fw.initialized {
+					fw.initialized = true
 					fw.lastMod = modTime
-				} else if modTime.After(fw.lastMod) {
+				} else if changed {
 					fw.lastMod = modTime
 					callback()
 				}
@@ -60,6 +118,86 @@ func (fw *ModTimeWatcher) Stop() error {
 	return nil
 }
 
+var _ Watcher = (*SymlinkWatcher)(nil)
+
+// SymlinkWatcher is a watcher that monitors a file reached through symlinks, e.g. a file of a mounted
+// Kubernetes ConfigMap or Secret.
+//
+// Kubernetes updates such files by atomically swapping the "..data" symlink to a new directory,
+// which keeps the modification time of the file path unchanged or even moves it back.
+// SymlinkWatcher resolves the symlinks on every check and reports a change whenever the resolved
+// target, its modification time or its size differ from the previous check.
+type SymlinkWatcher struct {
+	path     string
+	interval time.Duration
+	stop     chan struct{}
+	last     symlinkTarget
+	// initialized is set after the first check, including the one that found the file missing.
+	initialized bool
+}
+
+// symlinkTarget is the state of the file a path resolves to.
+type symlinkTarget struct {
+	path    string
+	modTime time.Time
+	size    int64
+}
+
+func NewSymlinkWatcher(path string) *SymlinkWatcher {
+	return &SymlinkWatcher{
+		path:        path,
+		interval:    pollInterval,
+		stop:        make(chan struct{}),
+		last:        symlinkTarget{},
+		initialized: false,
+	}
+}
+
+func (sw *SymlinkWatcher) Watch(callback func()) {
+	go func() {
+		for {
+			select {
+			case <-sw.stop:
+				return
+			case <-time.After(sw.interval):
+				target, err := resolveSymlinkTarget(sw.path)
+				if err != nil {
+					// A file that appears after the first check is reported as a change.
+					if errors.Is(err, fs.ErrNotExist) {
+						sw.initialized = true
+					}
+					continue
+				}
+				if !sw.initialized {
+					sw.initialized = true
+					sw.last = target
+				} else if !target.modTime.Equal(sw.last.modTime) || target.path != sw.last.path ||
+					target.size != sw.last.size {
+					sw.last = target
+					callback()
+				}
+			}
+		}
+	}()
+}
+
+func (sw *SymlinkWatcher) Stop() error {
+	close(sw.stop)
+	return nil
+}
+
+func resolveSymlinkTarget(path string) (symlinkTarget, error) {
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return symlinkTarget{}, err
+	}
+	info, err := os.Stat(resolved)
+	if err != nil {
+		return symlinkTarget{}, err
+	}
+	return symlinkTarget{path: resolved, modTime: info.ModTime(), size: info.Size()}, nil
+}
+
 var _ Watcher = (*TriggerWatcher)(nil)
 
 // TriggerWatcher is a simple watcher that calls a callback every time the Trigger method is called.
@@ -2,6 +2,9 @@ package confgo
 
 import (
 	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
 	"sync"
 	"testing"
 	"time"
@@ -183,3 +186,209 @@ func Test_ModTimeWatcher_Stop(t *testing.T) {
 		t.Errorf("callback called after Stop: before=%d, after=%d", n, calls)
 	}
 }
+
+func Test_ModTimeWatcher_CallbackWhenDataAppears(t *testing.T) {
+	t.Parallel()
+
+	mock := &mockModTimer{
+		times: []time.Time{
+			{},
+			{},
+			time.Unix(100, 0), // data has appeared
+		},
+		errs: []error{
+			fs.ErrNotExist,
+			fs.ErrNotExist,
+			nil,
+		},
+	}
+	watcher := NewModTimeWatcher(mock)
+	watcher.interval = 10 * time.Millisecond
+
+	call := make(chan struct{})
+	watcher.Watch(func() {
+		close(call)
+	})
+
+	select {
+	case <-call:
+		// ok
+	case <-time.After(200 * time.Millisecond):
+		t.Error("callback was not called after data appeared")
+	}
+	if err := watcher.Stop(); err != nil {
+		t.Errorf("Unexpected error while stopping watcher: %v", err)
+	}
+}
+
+// writeConfigMapVersion writes a new version of a mounted ConfigMap into dir and
+// atomically points the "..data" symlink to it, the way kubelet does.
+func writeConfigMapVersion(t *testing.T, dir, version, data string, modTime time.Time) {
+	t.Helper()
+
+	versionDir := filepath.Join(dir, "..version_"+version)
+	if err := os.Mkdir(versionDir, 0o755); err != nil {
+		t.Fatalf("create version dir: %v", err)
+	}
+	file := filepath.Join(versionDir, "config.json")
+	if err := writeFile(file, data); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	if err := os.Chtimes(file, modTime, modTime); err != nil {
+		t.Fatalf("change file times: %v", err)
+	}
+
+	tmpLink := filepath.Join(dir, "..data_tmp")
+	if err := os.Symlink(filepath.Base(versionDir), tmpLink); err != nil {
+		t.Fatalf("create data symlink: %v", err)
+	}
+	if err := os.Rename(tmpLink, filepath.Join(dir, "..data")); err != nil {
+		t.Fatalf("swap data symlink: %v", err)
+	}
+}
+
+func Test_SymlinkWatcher_CallbackOnSymlinkSwap(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	modTime := time.Unix(1000, 0)
+	writeConfigMapVersion(t, dir, "1", `{"int": 1}`, modTime)
+	path := filepath.Join(dir, "config.json")
+	if err := os.Symlink(filepath.Join("..data", "config.json"), path); err != nil {
+		t.Fatalf("create file symlink: %v", err)
+	}
+
+	watcher := NewSymlinkWatcher(path)
+	watcher.interval = 10 * time.Millisecond
+	calls := make(chan struct{}, 10)
+	watcher.Watch(func() {
+		calls <- struct{}{}
+	})
+	t.Cleanup(func() { _ = watcher.Stop() })
+
+	select {
+	case <-calls:
+		t.Fatal("unexpected callback before the swap")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// The new version has the same size and modification time, only the symlink target changes.
+	writeConfigMapVersion(t, dir, "2", `{"int": 2}`, modTime)
+	select {
+	case <-calls:
+	case <-time.After(500 * time.Millisecond):
+		t.Fatal("callback was not called after the symlink swap")
+	}
+
+	data, err := NewFileSource(path).Read()
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if string(data) != `{"int": 2}` {
+		t.Errorf("Read() = %s, want %s", data, `{"int": 2}`)
+	}
+}
+
+func Test_SymlinkWatcher_CallbackWhenFileAppears(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	watcher := NewSymlinkWatcher(path)
+	watcher.interval = 10 * time.Millisecond
+	calls := make(chan struct{}, 10)
+	watcher.Watch(func() {
+		calls <- struct{}{}
+	})
+	t.Cleanup(func() { _ = watcher.Stop() })
+
+	time.Sleep(50 * time.Millisecond)
+	if err := writeFile(path, `{"int": 1}`); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	select {
+	case <-calls:
+	case <-time.After(500 * time.Millisecond):
+		t.Fatal("callback was not called after the file appeared")
+	}
+}
+
+func Test_ModTimeWatcher_WatchSizeAndInode(t *testing.T) {
+	t.Parallel()
+
+	modTime := time.Unix(1000, 0)
+	rewrite := func(t *testing.T, path, data string) {
+		t.Helper()
+		if err := writeFile(path, data); err != nil {
+			t.Fatalf("write file: %v", err)
+		}
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatalf("change file times: %v", err)
+		}
+	}
+	replace := func(t *testing.T, path, data string) {
+		t.Helper()
+		tmp := path + ".tmp"
+		rewrite(t, tmp, data)
+		if err := os.Rename(tmp, path); err != nil {
+			t.Fatalf("rename file: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name       string
+		opts       []ModTimeWatcherOption
+		update     func(t *testing.T, path, data string)
+		data       string
+		wantChange bool
+	}{
+		{
+			name:       "size change within the same mod time",
+			opts:       []ModTimeWatcherOption{WatchSizeAndInode},
+			update:     rewrite,
+			data:       `{"int": 10}`,
+			wantChange: true,
+		},
+		{
+			name:       "file replaced within the same mod time",
+			opts:       []ModTimeWatcherOption{WatchSizeAndInode},
+			update:     replace,
+			data:       `{"int": 2}`,
+			wantChange: true,
+		},
+		{
+			name:       "size change is ignored by default",
+			opts:       nil,
+			update:     rewrite,
+			data:       `{"int": 10}`,
+			wantChange: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			path := filepath.Join(t.TempDir(), "config.json")
+			rewrite(t, path, `{"int": 1}`)
+			watcher := NewModTimeWatcher(NewFileSource(path), tt.opts...)
+			watcher.interval = 10 * time.Millisecond
+			calls := make(chan struct{}, 10)
+			watcher.Watch(func() {
+				calls <- struct{}{}
+			})
+			t.Cleanup(func() { _ = watcher.Stop() })
+
+			time.Sleep(50 * time.Millisecond)
+			tt.update(t, path, tt.data)
+			select {
+			case <-calls:
+				if !tt.wantChange {
+					t.Error("unexpected callback")
+				}
+			case <-time.After(200 * time.Millisecond):
+				if tt.wantChange {
+					t.Error("callback was not called")
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,341 @@
+package confgo
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha1" //nolint:gosec // SHA-1 is required by the WebSocket handshake.
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// webSocketGUID is appended to the handshake key by RFC 6455.
+	webSocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+	// webSocketMaxMessage limits the size of a pushed message.
+	webSocketMaxMessage = 16 << 20
+
+	wsOpContinuation = 0x0
+	wsOpText         = 0x1
+	wsOpBinary       = 0x2
+	wsOpClose        = 0x8
+	wsOpPing         = 0x9
+	wsOpPong         = 0xA
+)
+
+// WebSocketSourceOption configures WebSocketSource.
+type WebSocketSourceOption func(ws *WebSocketSource)
+
+// WebSocketHeader sets the headers of the handshake request, e.g. the Authorization header.
+func WebSocketHeader(header http.Header) WebSocketSourceOption {
+	return func(ws *WebSocketSource) {
+		ws.header = header.Clone()
+	}
+}
+
+// WebSocketHTTPClient sets the HTTP client used for the handshake requests.
+func WebSocketHTTPClient(client *http.Client) WebSocketSourceOption {
+	return func(ws *WebSocketSource) {
+		ws.client = client
+	}
+}
+
+// WebSocketBackoff sets the delay before reconnecting after the failed attempt, which starts from 1.
+// Defaults to the exponential backoff from 100 milliseconds to 30 seconds.
+func WebSocketBackoff(backoff func(attempt int) time.Duration) WebSocketSourceOption {
+	return func(ws *WebSocketSource) {
+		ws.backoff = backoff
+	}
+}
+
+var (
+	_ Source        = (*WebSocketSource)(nil)
+	_ SourceContext = (*WebSocketSource)(nil)
+	_ Watcher       = (*WebSocketWatcher)(nil)
+)
+
+// WebSocketSource is a configuration source that keeps a WebSocket connection to a config server,
+// e.g. "wss://config.example.com/apps/myapp", which pushes the whole configuration as text or binary messages.
+// Read returns the last pushed configuration, waiting for the first one. The connection is opened by
+// the first read and reopened with backoff whenever it fails, until Close.
+type WebSocketSource struct {
+	url     string
+	header  http.Header
+	client  *http.Client
+	backoff func(attempt int) time.Duration
+
+	start    sync.Once
+	stop     chan struct{}
+	stopOnce sync.Once
+	// received is closed once the first message is received.
+	received chan struct{}
+
+	mu       sync.Mutex
+	data     []byte
+	callback func()
+}
+
+// NewWebSocketSource creates a source receiving the configuration from the WebSocket server at url
+// with the "ws" or "wss" scheme.
+func NewWebSocketSource(url string, opts ...WebSocketSourceOption) *WebSocketSource {
+	ws := &WebSocketSource{
+		url:      url,
+		header:   nil,
+		client:   http.DefaultClient,
+		backoff:  ExponentialBackoff(reconnectBaseDelay, reconnectMaxDelay),
+		start:    sync.Once{},
+		stop:     make(chan struct{}),
+		stopOnce: sync.Once{},
+		received: make(chan struct{}),
+		mu:       sync.Mutex{},
+		data:     nil,
+		callback: nil,
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(ws)
+		}
+	}
+	return ws
+}
+
+func (ws *WebSocketSource) Read() ([]byte, error) {
+	return ws.ReadContext(context.Background())
+}
+
+// ReadContext returns the last pushed configuration, waiting for the first one until ctx is done.
+func (ws *WebSocketSource) ReadContext(ctx context.Context) ([]byte, error) {
+	ws.connect()
+	select {
+	case <-ws.received:
+	case <-ws.stop:
+		return nil, fmt.Errorf("websocket %q: source is closed", ws.url)
+	case <-ctx.Done():
+		return nil, fmt.Errorf("websocket %q: wait for config: %w", ws.url, ctx.Err())
+	}
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	return bytes.Clone(ws.data), nil
+}
+
+// Close closes the connection and stops reconnecting.
+func (ws *WebSocketSource) Close() error {
+	ws.stopOnce.Do(func() { close(ws.stop) })
+	return nil
+}
+
+// connect starts maintaining the connection, once.
+func (ws *WebSocketSource) connect() {
+	ws.start.Do(func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			<-ws.stop
+			cancel()
+		}()
+		go reconnect(ctx, ws.backoff, func() bool {
+			received, _ := ws.receive(ctx)
+			return received
+		})
+	})
+}
+
+// receive opens the connection and stores the pushed messages until it fails.
+// It reports whether any message has been received.
+func (ws *WebSocketSource) receive(ctx context.Context) (bool, error) {
+	conn, err := dialWebSocket(ctx, ws.client, ws.url, ws.header)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+	stopClose := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stopClose()
+
+	r := bufio.NewReader(conn)
+	for received := false; ; received = true {
+		msg, err := readWebSocketMessage(r, conn)
+		if err != nil {
+			return received, err
+		}
+		ws.store(msg)
+	}
+}
+
+// store stores the pushed configuration and calls the watcher callback if it has changed.
+func (ws *WebSocketSource) store(msg []byte) {
+	ws.mu.Lock()
+	first := ws.data == nil
+	changed := !first && !bytes.Equal(ws.data, msg)
+	if first || changed {
+		ws.data = msg
+	}
+	callback := ws.callback
+	ws.mu.Unlock()
+	if first {
+		close(ws.received)
+	}
+	if changed && callback != nil {
+		callback()
+	}
+}
+
+// WebSocketWatcher triggers reloads whenever the server of WebSocketSource pushes a changed configuration.
+// Stop closes the source.
+type WebSocketWatcher struct {
+	source *WebSocketSource
+}
+
+func NewWebSocketWatcher(source *WebSocketSource) *WebSocketWatcher {
+	return &WebSocketWatcher{source: source}
+}
+
+func (ww *WebSocketWatcher) Watch(callback func()) {
+	ww.source.mu.Lock()
+	ww.source.callback = callback
+	ww.source.mu.Unlock()
+	ww.source.connect()
+}
+
+func (ww *WebSocketWatcher) Stop() error {
+	return ww.source.Close()
+}
+
+// dialWebSocket performs the opening handshake of RFC 6455 and returns the connection.
+func dialWebSocket(ctx context.Context, client *http.Client, url string, header http.Header) (io.ReadWriteCloser, error) {
+	switch {
+	case strings.HasPrefix(url, "ws://"):
+		url = "http://" + strings.TrimPrefix(url, "ws://")
+	case strings.HasPrefix(url, "wss://"):
+		url = "https://" + strings.TrimPrefix(url, "wss://")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	key := base64.StdEncoding.EncodeToString(nonce)
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", key)
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		resp.Body.Close()
+		return nil, fmt.Errorf("websocket handshake: %w: %s", ErrUnexpectedStatus, resp.Status)
+	}
+	accept := sha1.Sum([]byte(key + webSocketGUID)) //nolint:gosec // SHA-1 is required by the WebSocket handshake.
+	conn, ok := resp.Body.(io.ReadWriteCloser)
+	if !ok || resp.Header.Get("Sec-WebSocket-Accept") != base64.StdEncoding.EncodeToString(accept[:]) {
+		resp.Body.Close()
+		return nil, errors.New("websocket handshake: invalid response")
+	}
+	return conn, nil
+}
+
+// readWebSocketMessage reads the frames of the next data message, answering pings with pongs to w.
+func readWebSocketMessage(r *bufio.Reader, w io.Writer) ([]byte, error) {
+	var msg []byte
+	for {
+		fin, opcode, payload, err := readWebSocketFrame(r)
+		if err != nil {
+			return nil, err
+		}
+		switch opcode {
+		case wsOpText, wsOpBinary, wsOpContinuation:
+			if len(msg)+len(payload) > webSocketMaxMessage {
+				return nil, fmt.Errorf("websocket message exceeds %d bytes", webSocketMaxMessage)
+			}
+			msg = append(msg, payload...)
+			if fin {
+				if msg == nil {
+					msg = []byte{}
+				}
+				return msg, nil
+			}
+		case wsOpPing:
+			if err := writeWebSocketFrame(w, wsOpPong, payload); err != nil {
+				return nil, err
+			}
+		case wsOpClose:
+			_ = writeWebSocketFrame(w, wsOpClose, nil)
+			return nil, io.EOF
+		case wsOpPong:
+		default:
+			return nil, fmt.Errorf("websocket: unknown opcode %#x", opcode)
+		}
+	}
+}
+
+func readWebSocketFrame(r *bufio.Reader) (bool, byte, []byte, error) {
+	var head [2]byte
+	if _, err := io.ReadFull(r, head[:]); err != nil {
+		return false, 0, nil, err
+	}
+	fin, opcode, masked := head[0]&0x80 != 0, head[0]&0x0F, head[1]&0x80 != 0
+	length := uint64(head[1] & 0x7F)
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(r, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(r, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+	if length > webSocketMaxMessage {
+		return false, 0, nil, fmt.Errorf("websocket frame exceeds %d bytes", webSocketMaxMessage)
+	}
+	var mask [4]byte
+	if masked {
+		if _, err := io.ReadFull(r, mask[:]); err != nil {
+			return false, 0, nil, err
+		}
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return false, 0, nil, err
+	}
+	if masked {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+	return fin, opcode, payload, nil
+}
+
+// writeWebSocketFrame writes a single masked frame, as clients must, with a control payload of at most 125 bytes.
+func writeWebSocketFrame(w io.Writer, opcode byte, payload []byte) error {
+	frame := make([]byte, 0, 6+len(payload))
+	frame = append(frame, 0x80|opcode, 0x80|byte(len(payload)))
+	var mask [4]byte
+	if _, err := rand.Read(mask[:]); err != nil {
+		return err
+	}
+	frame = append(frame, mask[:]...)
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+	_, err := w.Write(frame)
+	return err
+}
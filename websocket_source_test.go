@@ -0,0 +1,173 @@
+package confgo
+
+import (
+	"bufio"
+	"crypto/sha1" //nolint:gosec // SHA-1 is required by the WebSocket handshake.
+	"encoding/base64"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeWebSocketServer accepts WebSocket connections and passes them to the test.
+type fakeWebSocketServer struct {
+	*httptest.Server
+	conns   chan *bufio.ReadWriter
+	handled atomic.Int32
+}
+
+func newFakeWebSocketServer(t *testing.T) *fakeWebSocketServer {
+	t.Helper()
+	s := &fakeWebSocketServer{conns: make(chan *bufio.ReadWriter)}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.handled.Add(1)
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		accept := sha1.Sum([]byte(r.Header.Get("Sec-WebSocket-Key") + webSocketGUID)) //nolint:gosec // Handshake.
+		conn, rw, err := http.NewResponseController(w).Hijack()
+		if err != nil {
+			t.Errorf("Hijack() error = %v", err)
+			return
+		}
+		defer conn.Close()
+		rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n" +
+			"Sec-WebSocket-Accept: " + base64.StdEncoding.EncodeToString(accept[:]) + "\r\n\r\n")
+		rw.Flush()
+		select {
+		case s.conns <- rw:
+		case <-r.Context().Done():
+			return
+		}
+		// Keep the connection until the client closes it or the test sends a close frame.
+		for {
+			if _, err := rw.ReadByte(); err != nil {
+				return
+			}
+		}
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *fakeWebSocketServer) url() string {
+	return "ws" + strings.TrimPrefix(s.URL, "http")
+}
+
+// writeServerFrame writes an unmasked frame as servers do.
+func writeServerFrame(t *testing.T, rw *bufio.ReadWriter, fin bool, opcode byte, payload string) {
+	t.Helper()
+	head := opcode
+	if fin {
+		head |= 0x80
+	}
+	rw.WriteByte(head)
+	if len(payload) < 126 {
+		rw.WriteByte(byte(len(payload)))
+	} else {
+		rw.Write([]byte{126, byte(len(payload) >> 8), byte(len(payload))})
+	}
+	rw.WriteString(payload)
+	if err := rw.Flush(); err != nil {
+		t.Fatalf("write frame: %v", err)
+	}
+}
+
+func TestWebSocketSource(t *testing.T) {
+	t.Parallel()
+
+	server := newFakeWebSocketServer(t)
+	s := NewWebSocketSource(server.url(),
+		WebSocketHeader(http.Header{"Authorization": {"Bearer token"}}),
+		WebSocketBackoff(func(int) time.Duration { return time.Millisecond }))
+	updated := make(chan struct{}, 1)
+	cm, err := NewConfigManager(testConfigConstructor, WithLoader(Loader{
+		Source:          s,
+		Formatter:       NewJSONFormatter(),
+		Watcher:         NewWebSocketWatcher(s),
+		OnUpdateSuccess: func() { updated <- struct{}{} },
+	}))
+	if err != nil {
+		t.Fatalf("NewConfigManager() error = %v", err)
+	}
+
+	started := make(chan error, 1)
+	go func() { started <- cm.Start() }()
+	conn := <-server.conns
+	writeServerFrame(t, conn, true, wsOpText, `{"int": 1}`)
+	if err := <-started; err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer cm.MustStop()
+	if got := cm.Config().(*TestConfig).Int; got != 1 {
+		t.Fatalf("Config().Int = %d, want 1", got)
+	}
+
+	wait := func(want int) {
+		t.Helper()
+		select {
+		case <-updated:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("no reload for Int = %d", want)
+		}
+		if got := cm.Config().(*TestConfig).Int; got != want {
+			t.Fatalf("Config().Int = %d, want %d", got, want)
+		}
+	}
+
+	// A fragmented message interleaved with a ping.
+	writeServerFrame(t, conn, false, wsOpText, `{"int"`)
+	writeServerFrame(t, conn, true, wsOpPing, "ping")
+	writeServerFrame(t, conn, true, wsOpContinuation, `: 2}`)
+	wait(2)
+
+	// The same config is pushed again after reconnecting and does not trigger a reload.
+	writeServerFrame(t, conn, true, wsOpClose, "")
+	conn = <-server.conns
+	writeServerFrame(t, conn, true, wsOpText, `{"int": 2}`)
+	writeServerFrame(t, conn, true, wsOpText, strings.Repeat(" ", 200)+`{"int": 3}`)
+	wait(3)
+}
+
+func TestWebSocketSource_Reconnect(t *testing.T) {
+	t.Parallel()
+
+	server := newFakeWebSocketServer(t)
+	s := NewWebSocketSource(server.url(), WebSocketBackoff(func(int) time.Duration { return time.Millisecond }))
+	defer s.Close()
+	s.connect()
+	deadline := time.Now().Add(5 * time.Second)
+	for server.handled.Load() < 3 {
+		if time.Now().After(deadline) {
+			t.Fatal("the rejected handshake is not retried")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if _, err := s.Read(); err == nil {
+		t.Fatal("Read() of the closed source error = nil")
+	}
+}
+
+func TestReadWebSocketFrame_Masked(t *testing.T) {
+	t.Parallel()
+
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+	go writeWebSocketFrame(client, wsOpText, []byte("hello")) //nolint:errcheck // Checked by the reader.
+	fin, opcode, payload, err := readWebSocketFrame(bufio.NewReader(server))
+	if err != nil {
+		t.Fatalf("readWebSocketFrame() error = %v", err)
+	}
+	if !fin || opcode != wsOpText || string(payload) != "hello" {
+		t.Fatalf("readWebSocketFrame() = %v, %#x, %q", fin, opcode, payload)
+	}
+}